@@ -2,13 +2,23 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/fleet"
 	"github.com/pinchtab/pinchtab/internal/server"
 	"github.com/spf13/cobra"
 )
 
-var bridgeEngine string
+var (
+	bridgeEngine       string
+	bridgeRegister     string
+	bridgeJoinToken    string
+	bridgeName         string
+	bridgeAdvertiseURL string
+	bridgeLabels       []string
+)
 
 var bridgeCmd = &cobra.Command{
 	Use:   "bridge",
@@ -20,11 +30,67 @@ var bridgeCmd = &cobra.Command{
 			return err
 		}
 		cfg.Engine = engineMode
-		server.RunBridgeServer(cfg)
+		opts, err := resolveBridgeOptions(cfg)
+		if err != nil {
+			return err
+		}
+		server.RunBridgeServer(cfg, opts)
 		return nil
 	},
 }
 
+// resolveBridgeOptions builds fleet registration options from the flags.
+// The join token falls back to PINCHTAB_JOIN_TOKEN or the config file; the
+// name and advertised URL default to this host.
+func resolveBridgeOptions(cfg *config.RuntimeConfig) (server.BridgeOptions, error) {
+	register := strings.TrimRight(strings.TrimSpace(bridgeRegister), "/")
+	if register == "" {
+		if len(bridgeLabels) > 0 || bridgeAdvertiseURL != "" {
+			return server.BridgeOptions{}, fmt.Errorf("--label and --advertise-url require --register")
+		}
+		return server.BridgeOptions{}, nil
+	}
+
+	joinToken := strings.TrimSpace(bridgeJoinToken)
+	if joinToken == "" {
+		joinToken = cfg.FleetJoinToken
+	}
+	if joinToken == "" {
+		return server.BridgeOptions{}, fmt.Errorf("--register requires a join token (--join-token or PINCHTAB_JOIN_TOKEN)")
+	}
+	if strings.TrimSpace(cfg.Token) == "" {
+		return server.BridgeOptions{}, fmt.Errorf("--register requires the bridge to have its own token (server.token or PINCHTAB_TOKEN)")
+	}
+	labels, err := fleet.ParseLabels(bridgeLabels)
+	if err != nil {
+		return server.BridgeOptions{}, err
+	}
+
+	hostname, _ := os.Hostname()
+	name := strings.TrimSpace(bridgeName)
+	if name == "" {
+		name = hostname
+	}
+	if name == "" {
+		return server.BridgeOptions{}, fmt.Errorf("--name is required when the hostname is unknown")
+	}
+	advertise := strings.TrimSpace(bridgeAdvertiseURL)
+	if advertise == "" {
+		if hostname == "" {
+			return server.BridgeOptions{}, fmt.Errorf("--advertise-url is required when the hostname is unknown")
+		}
+		advertise = "http://" + hostname + ":" + cfg.Port
+	}
+
+	return server.BridgeOptions{
+		Register:     register,
+		JoinToken:    joinToken,
+		Name:         name,
+		AdvertiseURL: advertise,
+		Labels:       labels,
+	}, nil
+}
+
 func resolveBridgeEngine(flagValue, configValue string) (string, error) {
 	engineMode := strings.ToLower(strings.TrimSpace(configValue))
 	if strings.TrimSpace(flagValue) != "" {
@@ -42,5 +108,10 @@ func resolveBridgeEngine(flagValue, configValue string) (string, error) {
 func init() {
 	bridgeCmd.GroupID = "primary"
 	bridgeCmd.Flags().StringVar(&bridgeEngine, "engine", "", "Bridge engine: chrome, lite, or auto (overrides config)")
+	bridgeCmd.Flags().StringVar(&bridgeRegister, "register", "", "Register with a PinchTab server at this URL and send heartbeats")
+	bridgeCmd.Flags().StringVar(&bridgeJoinToken, "join-token", "", "Join token for --register (default: PINCHTAB_JOIN_TOKEN)")
+	bridgeCmd.Flags().StringVar(&bridgeName, "name", "", "Name to register under (default: hostname)")
+	bridgeCmd.Flags().StringVar(&bridgeAdvertiseURL, "advertise-url", "", "URL the server uses to reach this bridge (default: http://<hostname>:<port>)")
+	bridgeCmd.Flags().StringArrayVar(&bridgeLabels, "label", nil, "Label to register with, as key=value (repeatable)")
 	rootCmd.AddCommand(bridgeCmd)
 }
@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
 
 func TestResolveBridgeEngine(t *testing.T) {
 	tests := []struct {
@@ -34,3 +38,54 @@ func TestResolveBridgeEngine(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveBridgeOptions(t *testing.T) {
+	reset := func() {
+		bridgeRegister, bridgeJoinToken, bridgeName, bridgeAdvertiseURL, bridgeLabels = "", "", "", "", nil
+	}
+	t.Cleanup(reset)
+	cfg := &config.RuntimeConfig{Port: "9867"}
+
+	reset()
+	if opts, err := resolveBridgeOptions(cfg); err != nil || opts.Register != "" {
+		t.Fatalf("no --register should leave the bridge standalone, got %+v %v", opts, err)
+	}
+
+	reset()
+	bridgeLabels = []string{"region=eu"}
+	if _, err := resolveBridgeOptions(cfg); err == nil {
+		t.Fatal("expected --label without --register to be rejected")
+	}
+
+	reset()
+	bridgeRegister = "http://server:9867/"
+	if _, err := resolveBridgeOptions(cfg); err == nil {
+		t.Fatal("expected an error without a join token")
+	}
+
+	reset()
+	bridgeRegister = "http://server:9867/"
+	if _, err := resolveBridgeOptions(&config.RuntimeConfig{Port: "9867", FleetJoinToken: "join"}); err == nil {
+		t.Fatal("expected an error without a bridge token")
+	}
+
+	reset()
+	bridgeRegister = "http://server:9867/"
+	bridgeName = "edge"
+	bridgeLabels = []string{"region=eu", "gpu=false"}
+	opts, err := resolveBridgeOptions(&config.RuntimeConfig{Port: "9867", Token: "bridge", FleetJoinToken: "join"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Register != "http://server:9867" || opts.JoinToken != "join" || opts.Name != "edge" || opts.Labels["gpu"] != "false" {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+	if opts.AdvertiseURL == "" {
+		t.Error("expected a default advertise URL")
+	}
+
+	bridgeLabels = []string{"region"}
+	if _, err := resolveBridgeOptions(&config.RuntimeConfig{Token: "bridge", FleetJoinToken: "join"}); err == nil {
+		t.Fatal("expected a malformed label to be rejected")
+	}
+}
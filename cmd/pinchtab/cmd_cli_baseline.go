@@ -0,0 +1,78 @@
+package main
+
+import (
+	browseractions "github.com/pinchtab/pinchtab/internal/cli/actions"
+	"github.com/spf13/cobra"
+)
+
+var screenshotCompareCmd = &cobra.Command{
+	Use:   "compare <name>",
+	Short: "Compare a screenshot with a stored baseline",
+	Long:  "Capture a screenshot and compare it pixel by pixel with the named baseline. A missing baseline is created from the capture. Exits non-zero when the mismatch exceeds --threshold.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.ScreenshotCompare(rt.client, rt.base, rt.token, args, cmd)
+		})
+	},
+}
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage screenshot baselines",
+	Long:  "List, download, upload, accept and delete the baselines used by 'screenshot compare'.",
+}
+
+var baselineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored baselines",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.BaselineList(rt.client, rt.base, rt.token)
+		})
+	},
+}
+
+var baselineGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Save a baseline (or its last diff) to a file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.BaselineGet(rt.client, rt.base, rt.token, args, cmd)
+		})
+	},
+}
+
+var baselineSetCmd = &cobra.Command{
+	Use:   "set <name> <file.png>",
+	Short: "Upload a PNG as a baseline",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.BaselineSet(rt.client, rt.base, rt.token, args)
+		})
+	},
+}
+
+var baselineAcceptCmd = &cobra.Command{
+	Use:   "accept <name>",
+	Short: "Accept the last failed comparison as the new baseline",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.BaselineAccept(rt.client, rt.base, rt.token, args)
+		})
+	},
+}
+
+var baselineDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a baseline",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.BaselineDelete(rt.client, rt.base, rt.token, args)
+		})
+	},
+}
@@ -112,3 +112,62 @@ var networkCmd = &cobra.Command{
 		})
 	},
 }
+
+var networkHARCmd = &cobra.Command{
+	Use:   "har",
+	Short: "Export captured network requests as HAR 1.2",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.NetworkHAR(rt.client, rt.base, rt.token, cmd)
+		})
+	},
+}
+
+var networkReplayCmd = &cobra.Command{
+	Use:   "replay [file.har]",
+	Short: "Serve requests from a HAR file (no file shows status)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.NetworkReplay(rt.client, rt.base, rt.token, cmd, args)
+		})
+	},
+}
+
+var networkInterceptCmd = &cobra.Command{
+	Use:   "intercept",
+	Short: "Mock, modify, delay or block requests",
+	Long:  "Manage per-tab request interception rules backed by CDP Fetch.",
+}
+
+var networkInterceptAddCmd = &cobra.Command{
+	Use:   "add <url-glob>",
+	Short: "Add an interception rule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.InterceptAdd(rt.client, rt.base, rt.token, cmd, args)
+		})
+	},
+}
+
+var networkInterceptListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List interception rules",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.InterceptList(rt.client, rt.base, rt.token, cmd)
+		})
+	},
+}
+
+var networkInterceptRemoveCmd = &cobra.Command{
+	Use:   "remove [ruleId]",
+	Short: "Remove an interception rule (all rules if no ID)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.InterceptRemove(rt.client, rt.base, rt.token, cmd, args)
+		})
+	},
+}
@@ -44,12 +44,22 @@ func registerBrowserCommands() {
 		consoleCmd,
 		errorsCmd,
 		clipboardCmd,
+		storageCmd,
+		stateCmd,
+		runCmd,
+		baselineCmd,
 	)
 
 	tabsCmd.AddCommand(tabNewCmd, tabCloseCmd)
 	clipboardCmd.AddCommand(clipboardReadCmd, clipboardWriteCmd, clipboardCopyCmd, clipboardPasteCmd)
+	storageCmd.AddCommand(storageGetCmd, storageSetCmd, storageDeleteCmd)
+	stateCmd.AddCommand(stateExportCmd, stateImportCmd)
+	screenshotCmd.AddCommand(screenshotCompareCmd)
+	baselineCmd.AddCommand(baselineListCmd, baselineGetCmd, baselineSetCmd, baselineAcceptCmd, baselineDeleteCmd)
 	keyboardCmd.AddCommand(keyboardTypeCmd, keyboardInsertTextCmd)
 	dialogCmd.AddCommand(dialogAcceptCmd, dialogDismissCmd)
+	networkCmd.AddCommand(networkInterceptCmd, networkHARCmd, networkReplayCmd)
+	networkInterceptCmd.AddCommand(networkInterceptAddCmd, networkInterceptListCmd, networkInterceptRemoveCmd)
 
 	configureBrowserFlags()
 
@@ -89,6 +99,10 @@ func registerBrowserCommands() {
 		consoleCmd,
 		errorsCmd,
 		clipboardCmd,
+		storageCmd,
+		stateCmd,
+		runCmd,
+		baselineCmd,
 	)
 }
 
@@ -128,7 +142,22 @@ func configureBrowserFlags() {
 	snapCmd.Flags().String("depth", "", "Tree depth limit")
 
 	screenshotCmd.Flags().StringP("output", "o", "", "Save screenshot to file path")
-	screenshotCmd.Flags().StringP("quality", "q", "", "JPEG quality (0-100)")
+	screenshotCmd.Flags().StringP("quality", "q", "", "JPEG/WebP quality (0-100)")
+	screenshotCmd.Flags().String("format", "", "Image format: jpeg (default), png or webp")
+	screenshotCmd.Flags().StringP("selector", "s", "", "Capture only this element")
+	screenshotCmd.Flags().Bool("full-page", false, "Capture the whole scrollable page")
+	screenshotCmd.Flags().String("clip", "", "Capture a document rectangle x,y,width,height (CSS pixels)")
+	screenshotCmd.Flags().String("scale", "", "Output scale factor (e.g. 2)")
+
+	screenshotCompareCmd.Flags().StringP("selector", "s", "", "Capture only this element")
+	screenshotCompareCmd.Flags().Bool("full-page", false, "Capture the whole page")
+	screenshotCompareCmd.Flags().StringArray("ignore", nil, "Region x,y,width,height to ignore (repeatable)")
+	screenshotCompareCmd.Flags().String("tolerance", "", "Per-pixel color tolerance 0-1 (default 0.1)")
+	screenshotCompareCmd.Flags().String("threshold", "", "Mismatch percentage that still passes (default 0)")
+	screenshotCompareCmd.Flags().Bool("update", false, "Replace the baseline with this capture")
+	screenshotCompareCmd.Flags().String("diff-output", "", "Save the diff image to file path")
+	baselineGetCmd.Flags().StringP("output", "o", "", "Save to file path (default <name>.png)")
+	baselineGetCmd.Flags().Bool("diff", false, "Get the diff from the last failed comparison")
 
 	pdfCmd.Flags().StringP("output", "o", "", "Save PDF to file path")
 	pdfCmd.Flags().Bool("landscape", false, "Landscape orientation")
@@ -166,6 +195,7 @@ func configureBrowserFlags() {
 		reloadCmd,
 		snapCmd,
 		screenshotCmd,
+		screenshotCompareCmd,
 		pdfCmd,
 		findCmd,
 		textCmd,
@@ -187,6 +217,14 @@ func configureBrowserFlags() {
 		keyupCmd,
 		scrollintoviewCmd,
 		networkCmd,
+		networkInterceptAddCmd,
+		networkInterceptListCmd,
+		networkInterceptRemoveCmd,
+		networkHARCmd,
+		networkReplayCmd,
+		storageGetCmd,
+		storageSetCmd,
+		storageDeleteCmd,
 		waitCmd,
 		dialogAcceptCmd,
 		dialogDismissCmd,
@@ -204,6 +242,41 @@ func configureBrowserFlags() {
 	networkCmd.Flags().String("buffer-size", "", "Per-tab network buffer size (default 100)")
 	networkCmd.Flags().Bool("stream", false, "Stream network entries in real-time (like tail -f)")
 
+	networkHARCmd.Flags().StringP("output", "o", "", "Save HAR to file path ('-' for stdout)")
+	networkHARCmd.Flags().Bool("body", false, "Include response bodies")
+	networkHARCmd.Flags().String("filter", "", "URL pattern filter")
+
+	networkReplayCmd.Flags().String("url", "", "Only replay URLs matching this glob")
+	networkReplayCmd.Flags().String("not-found", "", "Unrecorded requests: abort (default) or continue")
+	networkReplayCmd.Flags().Bool("stop", false, "Stop HAR replay")
+
+	for _, cmd := range []*cobra.Command{storageGetCmd, storageSetCmd, storageDeleteCmd} {
+		cmd.Flags().String("origin", "", "Origin (defaults to the tab's current origin)")
+		cmd.Flags().String("type", "", "Storage type: local, session or indexeddb")
+		cmd.Flags().String("database", "", "IndexedDB database")
+		cmd.Flags().String("store", "", "IndexedDB object store")
+	}
+	storageGetCmd.Flags().String("limit", "", "Maximum IndexedDB records to return")
+	storageSetCmd.Flags().Bool("replace", false, "Clear the storage area before writing")
+
+	stateExportCmd.Flags().StringP("output", "o", "", "Save bundle to file path ('-' for stdout)")
+	stateExportCmd.Flags().StringSlice("tab", nil, "Only export these tab IDs (repeatable)")
+
+	runCmd.Flags().String("tab", "", "Tab ID to run in (overrides the workflow's tabId)")
+	runCmd.Flags().StringArray("var", nil, "Set a workflow variable name=value (repeatable)")
+
+	networkInterceptAddCmd.Flags().String("id", "", "Rule ID (reusing an ID replaces that rule)")
+	networkInterceptAddCmd.Flags().String("method", "", "HTTP method matcher (GET, POST, etc)")
+	networkInterceptAddCmd.Flags().String("type", "", "Resource type matcher (xhr, fetch, document, etc)")
+	networkInterceptAddCmd.Flags().Int("status", 0, "Fulfill with this status code")
+	networkInterceptAddCmd.Flags().String("body", "", "Fulfill with this response body")
+	networkInterceptAddCmd.Flags().String("body-file", "", "Fulfill with the contents of this file")
+	networkInterceptAddCmd.Flags().StringArray("header", nil, "Response header 'Name: value' (repeatable)")
+	networkInterceptAddCmd.Flags().StringArray("request-header", nil, "Override request header 'Name: value' (repeatable; empty value removes)")
+	networkInterceptAddCmd.Flags().Bool("abort", false, "Abort matching requests")
+	networkInterceptAddCmd.Flags().String("error", "", "Network error reason when aborting (e.g. Failed, TimedOut, BlockedByClient)")
+	networkInterceptAddCmd.Flags().Int("delay", 0, "Delay matching requests by this many milliseconds")
+
 	waitCmd.Flags().String("text", "", "Wait for text on page")
 	waitCmd.Flags().String("url", "", "Wait for URL glob match")
 	waitCmd.Flags().String("load", "", "Wait for load state (networkidle)")
@@ -223,6 +296,7 @@ func configureManagementFlags() {
 	startInstanceCmd.Flags().String("profile", "", "Profile to use")
 	startInstanceCmd.Flags().String("mode", "", "Instance mode")
 	startInstanceCmd.Flags().String("port", "", "Port number")
+	startInstanceCmd.Flags().String("device", "", "Device preset to emulate on new tabs")
 	startInstanceCmd.Flags().StringArray("extension", nil, "Load browser extension (repeatable)")
 
 	activityCmd.PersistentFlags().Int("limit", 200, "Maximum number of events to return")
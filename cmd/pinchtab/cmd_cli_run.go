@@ -0,0 +1,18 @@
+package main
+
+import (
+	browseractions "github.com/pinchtab/pinchtab/internal/cli/actions"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <flow.yaml>",
+	Short: "Run a YAML or JSON workflow",
+	Long:  "Run a declarative workflow (navigate, action, wait, extract, assert, loop steps with variables) and print the per-step trace. Exits non-zero if the workflow fails.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.RunWorkflow(rt.client, rt.base, rt.token, args, cmd)
+		})
+	},
+}
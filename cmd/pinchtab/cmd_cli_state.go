@@ -0,0 +1,33 @@
+package main
+
+import (
+	browseractions "github.com/pinchtab/pinchtab/internal/cli/actions"
+	"github.com/spf13/cobra"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Export or import browser session state",
+	Long:  "Move a logged-in session between instances as a signed bundle of cookies, web storage and open tabs.",
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Save cookies, storage and open tabs to a signed bundle",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.StateExport(rt.client, rt.base, rt.token, cmd)
+		})
+	},
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Apply a state bundle to this instance",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.StateImport(rt.client, rt.base, rt.token, args)
+		})
+	},
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	browseractions "github.com/pinchtab/pinchtab/internal/cli/actions"
+	"github.com/spf13/cobra"
+)
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Web storage operations",
+	Long:  "Read and write localStorage, sessionStorage and IndexedDB for an origin loaded in a tab.",
+}
+
+var storageGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Read storage (all keys, one key, or IndexedDB data)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.StorageGet(rt.client, rt.base, rt.token, cmd, args)
+		})
+	},
+}
+
+var storageSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Write a storage item",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.StorageSet(rt.client, rt.base, rt.token, cmd, args)
+		})
+	},
+}
+
+var storageDeleteCmd = &cobra.Command{
+	Use:   "delete [key...]",
+	Short: "Remove storage items (clears the area when no keys are given)",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.StorageDelete(rt.client, rt.base, rt.token, cmd, args)
+		})
+	},
+}
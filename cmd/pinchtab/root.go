@@ -51,7 +51,7 @@ browsers, manage tabs, and perform interactive tasks.`,
 			case "daemon":
 				handleDaemonCommand("")
 			case "bridge":
-				server.RunBridgeServer(loadConfig())
+				server.RunBridgeServer(loadConfig(), server.BridgeOptions{})
 			case "mcp":
 				runMCP(loadConfig())
 			case "config":
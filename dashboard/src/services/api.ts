@@ -411,7 +411,11 @@ export async function saveBackendConfig(
 
 // SSE Events — endpoint is /api/events
 export interface SystemEvent {
-  type: "instance.started" | "instance.stopped" | "instance.error";
+  type:
+    | "instance.started"
+    | "instance.stopped"
+    | "instance.error"
+    | "instance.recycled";
   instance?: Instance;
 }
 
@@ -286,3 +286,36 @@ func ResolveUnifiedSelector(ctx context.Context, sel selector.Selector, refCache
 		return 0, fmt.Errorf("unknown selector kind: %q", sel.Kind)
 	}
 }
+
+// ElementValue returns the visible text of a backend node, or the value of
+// attr when set. ok is false when the attribute is absent.
+func ElementValue(ctx context.Context, backendNodeID int64, attr string) (value string, ok bool, err error) {
+	err = chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var node struct {
+			Object struct {
+				ObjectID string `json:"objectId"`
+			} `json:"object"`
+		}
+		if err := chromedp.FromContext(ctx).Target.Execute(ctx, "DOM.resolveNode", map[string]any{
+			"backendNodeId": backendNodeID,
+		}, &node); err != nil {
+			return fmt.Errorf("resolve node: %w", err)
+		}
+		raw, err := callOnValue(ctx, node.Object.ObjectID, `function(attr) {
+			if (attr) return this.hasAttribute(attr) ? this.getAttribute(attr) : null;
+			return ("innerText" in this ? this.innerText : this.textContent) || "";
+		}`, attr)
+		if err != nil {
+			return err
+		}
+		var v *string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		if v != nil {
+			value, ok = *v, true
+		}
+		return nil
+	}))
+	return value, ok, err
+}
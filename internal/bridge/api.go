@@ -49,6 +49,9 @@ type BridgeAPI interface {
 	// Network monitoring
 	NetworkMonitor() *NetworkMonitor
 
+	// Request interception
+	Interceptor() *InterceptManager
+
 	// Dialog management
 	GetDialogManager() *DialogManager
 
@@ -142,6 +145,33 @@ type Instance struct {
 	Attached    bool      `json:"attached"`             // True if attached rather than locally launched
 	AttachType  string    `json:"attachType,omitempty"` // "cdp" or "bridge" for attached instances
 	CdpURL      string    `json:"cdpUrl,omitempty"`     // CDP WebSocket URL (for CDP-attached instances)
+
+	// Registered bridges report these with every heartbeat.
+	Registered bool              `json:"registered,omitempty"` // True if the bridge joined via fleet registration
+	Labels     map[string]string `json:"labels,omitempty"`     // Operator labels, e.g. region=eu
+	Capacity   *InstanceCapacity `json:"capacity,omitempty"`   // Tab capacity from the last heartbeat
+
+	Limits *ResourceLimits `json:"limits,omitempty"` // Resource limits applied to a launched instance
+	Device string          `json:"device,omitempty"` // Device preset requested at launch ("" = config default)
+}
+
+// ResourceLimits bounds the bridge process and every Chrome process it
+// starts. Zero means unlimited.
+type ResourceLimits struct {
+	MemoryMaxMB int     `json:"memoryMaxMB,omitempty"`
+	CPUQuota    float64 `json:"cpuQuota,omitempty"` // CPU time in cores, e.g. 1.5
+	PidsMax     int     `json:"pidsMax,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (l ResourceLimits) IsZero() bool {
+	return l.MemoryMaxMB <= 0 && l.CPUQuota <= 0 && l.PidsMax <= 0
+}
+
+// InstanceCapacity is the tab capacity a registered bridge reports.
+type InstanceCapacity struct {
+	MaxTabs int `json:"maxTabs,omitempty"` // 0 = unlimited
+	Tabs    int `json:"tabs"`
 }
 
 type InstanceTab struct {
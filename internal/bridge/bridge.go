@@ -27,6 +27,9 @@ type TabEntry struct {
 	Policy                TabPolicyState
 	Watching              bool
 	ConsoleCaptureEnabled bool
+	Discarded             bool   // page released under memory pressure
+	DiscardedURL          string // page to reload when a discarded tab is used
+	DiscardedTitle        string
 }
 
 type RefCache struct {
@@ -49,7 +52,8 @@ type Bridge struct {
 	LogStore      *ConsoleLogStore
 
 	// Network monitoring
-	netMonitor *NetworkMonitor
+	netMonitor  *NetworkMonitor
+	interceptor *InterceptManager
 
 	fingerprintMu        sync.RWMutex
 	fingerprintOverlays  map[string]bool
@@ -79,6 +83,7 @@ func New(allocCtx, browserCtx context.Context, cfg *config.RuntimeConfig) *Bridg
 		Config:              cfg,
 		IdMgr:               idMgr,
 		netMonitor:          NewNetworkMonitor(netBufSize),
+		interceptor:         NewInterceptManager(),
 		fingerprintOverlays: make(map[string]bool),
 		LogStore:            logStore,
 		stealthLaunchMode:   stealth.LaunchModeUninitialized,
@@ -88,9 +93,11 @@ func New(allocCtx, browserCtx context.Context, cfg *config.RuntimeConfig) *Bridg
 	if cfg != nil && browserCtx != nil {
 		b.TabManager = NewTabManager(browserCtx, cfg, idMgr, logStore, b.tabSetup)
 		b.SetDialogManager(b.Dialogs)
+		b.SetInterceptManager(b.interceptor)
 		if !b.quietStealthObservers() {
 			b.StartBrowserGuards()
 		}
+		b.StartMemoryPressureWatcher()
 	}
 	b.Locks = NewLockManager()
 	b.Dialogs = NewDialogManager()
@@ -142,6 +149,22 @@ func (b *Bridge) tabSetup(ctx context.Context) {
 			slog.Warn("no-animations injection failed", "err", err)
 		}
 	}
+	if b.Config.Device != "" {
+		b.applyDefaultDevice(ctx)
+	}
+}
+
+// applyDefaultDevice emulates the configured device preset on a new tab.
+// It runs after the stealth overrides so the preset user agent wins.
+func (b *Bridge) applyDefaultDevice(ctx context.Context) {
+	e := Emulation{Device: b.Config.Device}
+	if err := e.Resolve(b.Config.ChromeVersion); err != nil {
+		slog.Warn("device emulation skipped", "device", b.Config.Device, "err", err)
+		return
+	}
+	if err := ApplyEmulation(ctx, &e); err != nil {
+		slog.Warn("device emulation failed", "device", b.Config.Device, "err", err)
+	}
 }
 
 // StartNetworkCapture enables network monitoring for a specific tab.
@@ -277,9 +300,11 @@ func (b *Bridge) EnsureChrome(cfg *config.RuntimeConfig) error {
 		}
 		b.TabManager = NewTabManager(browserCtx, b.Config, b.IdMgr, b.LogStore, b.tabSetup)
 		b.SetDialogManager(b.Dialogs)
+		b.SetInterceptManager(b.interceptor)
 		if !b.quietStealthObservers() {
 			b.StartBrowserGuards()
 		}
+		b.StartMemoryPressureWatcher()
 	}
 
 	// Ensure action registry is populated (idempotent)
@@ -372,6 +397,8 @@ func (b *Bridge) SetBrowserContexts(allocCtx context.Context, allocCancel contex
 		}
 		b.TabManager = NewTabManager(browserCtx, b.Config, b.IdMgr, b.LogStore, b.tabSetup)
 		b.SetDialogManager(b.Dialogs)
+		b.SetInterceptManager(b.interceptor)
+		b.StartMemoryPressureWatcher()
 	}
 }
 
@@ -431,6 +458,11 @@ func (b *Bridge) NetworkMonitor() *NetworkMonitor {
 	return b.netMonitor
 }
 
+// Interceptor returns the bridge's request interception manager.
+func (b *Bridge) Interceptor() *InterceptManager {
+	return b.interceptor
+}
+
 func (b *Bridge) AvailableActions() []string {
 	keys := make([]string, 0, len(b.Actions))
 	for k := range b.Actions {
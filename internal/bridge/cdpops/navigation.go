@@ -44,10 +44,49 @@ func NavigatePage(ctx context.Context, url string) error {
 
 var ErrTooManyRedirects = fmt.Errorf("too many redirects")
 
-func NavigatePageWithRedirectLimit(ctx context.Context, url string, maxRedirects int) error {
-	replaceInitialBlank, _ := shouldReplaceInitialBlankNavigation(ctx)
+// RedirectLimiter counts redirected requests paused in the Fetch domain and
+// fails the first one past the limit. It only applies until the navigation
+// has committed; later requests from the loading page pass through.
+type RedirectLimiter struct {
+	max     int
+	count   atomic.Int32
+	blocked atomic.Bool
+	done    atomic.Bool
+}
+
+func NewRedirectLimiter(maxRedirects int) *RedirectLimiter {
+	return &RedirectLimiter{max: maxRedirects}
+}
 
+// Check fails e when it is a redirect past the limit and reports whether it
+// did. ctx must carry the tab's CDP executor.
+func (l *RedirectLimiter) Check(ctx context.Context, e *fetch.EventRequestPaused) bool {
+	if e.RedirectedRequestID == "" || l.done.Load() {
+		return false
+	}
+	if int(l.count.Add(1)) <= l.max {
+		return false
+	}
+	l.blocked.Store(true)
+	_ = fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient).Do(ctx)
+	return true
+}
+
+// Err returns ErrTooManyRedirects once a redirect has been blocked.
+func (l *RedirectLimiter) Err() error {
+	if !l.blocked.Load() {
+		return nil
+	}
+	return fmt.Errorf("%w: got %d, max %d", ErrTooManyRedirects, l.count.Load(), l.max)
+}
+
+// NavigatePageWithRedirectLimit navigates with its own Fetch listener that
+// fails the navigation after maxRedirects redirects. Tabs with request
+// interception must go through NavigatePageWithLimiter instead, with the
+// limiter consulted by the tab's interception listener.
+func NavigatePageWithRedirectLimit(ctx context.Context, url string, maxRedirects int) error {
 	if maxRedirects < 0 {
+		replaceInitialBlank, _ := shouldReplaceInitialBlankNavigation(ctx)
 		return navigateAndWait(ctx, url, replaceInitialBlank)
 	}
 
@@ -56,39 +95,39 @@ func NavigatePageWithRedirectLimit(ctx context.Context, url string, maxRedirects
 	})); err != nil {
 		return fmt.Errorf("fetch enable: %w", err)
 	}
+	defer func() {
+		_ = chromedp.Run(context.WithoutCancel(ctx), chromedp.ActionFunc(func(ctx context.Context) error {
+			return fetch.Disable().Do(ctx)
+		}))
+	}()
 
-	var redirectCount atomic.Int32
-	var blocked atomic.Bool
-
+	limiter := NewRedirectLimiter(maxRedirects)
 	chromedp.ListenTarget(ctx, func(ev interface{}) {
 		e, ok := ev.(*fetch.EventRequestPaused)
 		if !ok {
 			return
 		}
 		go func() {
-			reqID := e.RequestID
-			if e.RedirectedRequestID != "" {
-				count := int(redirectCount.Add(1))
-				if count > maxRedirects {
-					blocked.Store(true)
-					_ = fetch.FailRequest(reqID, network.ErrorReasonBlockedByClient).Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target))
-					return
-				}
+			execCtx := cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target)
+			if !limiter.Check(execCtx, e) {
+				_ = fetch.ContinueRequest(e.RequestID).Do(execCtx)
 			}
-			_ = fetch.ContinueRequest(reqID).Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target))
 		}()
 	})
+	return NavigatePageWithLimiter(ctx, url, limiter)
+}
 
+// NavigatePageWithLimiter navigates and waits for the page while limiter
+// vets the redirects. The caller owns the Fetch domain and must route the
+// tab's paused requests through limiter.Check.
+func NavigatePageWithLimiter(ctx context.Context, url string, limiter *RedirectLimiter) error {
+	replaceInitialBlank, _ := shouldReplaceInitialBlankNavigation(ctx)
 	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
 		return startNavigation(ctx, url, replaceInitialBlank)
 	}))
-
-	_ = chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
-		return fetch.Disable().Do(ctx)
-	}))
-
-	if blocked.Load() {
-		return fmt.Errorf("%w: got %d, max %d", ErrTooManyRedirects, redirectCount.Load(), maxRedirects)
+	limiter.done.Store(true)
+	if blockedErr := limiter.Err(); blockedErr != nil {
+		return blockedErr
 	}
 	if err != nil {
 		return err
@@ -0,0 +1,331 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/devices"
+)
+
+// Emulated screen orientations.
+const (
+	OrientationPortrait  = "portrait"
+	OrientationLandscape = "landscape"
+)
+
+// Network throttling profiles.
+const (
+	NetworkNone    = "none"
+	NetworkOffline = "offline"
+	NetworkSlow3G  = "slow-3g"
+	NetworkFast3G  = "fast-3g"
+	Network4G      = "4g"
+)
+
+type networkProfile struct {
+	latencyMs     float64
+	downloadBytes float64
+	uploadBytes   float64
+	connection    network.ConnectionType
+}
+
+// networkProfiles mirror the DevTools throttling presets. Throughput is in
+// bytes per second.
+var networkProfiles = map[string]networkProfile{
+	NetworkSlow3G: {latencyMs: 2000, downloadBytes: 500 * 1000 / 8 * 0.8, uploadBytes: 500 * 1000 / 8 * 0.8, connection: network.ConnectionTypeCellular3g},
+	NetworkFast3G: {latencyMs: 562.5, downloadBytes: 1.6 * 1000 * 1000 / 8 * 0.9, uploadBytes: 750 * 1000 / 8 * 0.9, connection: network.ConnectionTypeCellular3g},
+	Network4G:     {latencyMs: 150, downloadBytes: 9 * 1000 * 1000 / 8 * 0.9, uploadBytes: 1.5 * 1000 * 1000 / 8 * 0.9, connection: network.ConnectionTypeCellular4g},
+}
+
+// Geolocation is an emulated position in decimal degrees.
+type Geolocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy,omitempty"`
+}
+
+// Emulation describes the device and environment overrides applied to a
+// tab. Device names a preset from the devices package; any other field set
+// alongside it overrides the preset value. Zero values leave the current
+// setting untouched.
+type Emulation struct {
+	Device            string  `json:"device,omitempty"`
+	Width             int     `json:"width,omitempty"`
+	Height            int     `json:"height,omitempty"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor,omitempty"`
+	Mobile            *bool   `json:"mobile,omitempty"`
+	Touch             *bool   `json:"touch,omitempty"`
+	UserAgent         string  `json:"userAgent,omitempty"`
+	Platform          string  `json:"platform,omitempty"`
+	Orientation       string  `json:"orientation,omitempty"`
+
+	Geolocation   *Geolocation `json:"geolocation,omitempty"`
+	Locale        string       `json:"locale,omitempty"`
+	Timezone      string       `json:"timezone,omitempty"`
+	ColorScheme   string       `json:"colorScheme,omitempty"`
+	ReducedMotion string       `json:"reducedMotion,omitempty"`
+	Network       string       `json:"network,omitempty"`
+}
+
+// IsZero reports whether no override is set.
+func (e *Emulation) IsZero() bool {
+	return e.Device == "" && e.Width == 0 && e.Height == 0 && e.DeviceScaleFactor == 0 &&
+		e.Mobile == nil && e.Touch == nil && e.UserAgent == "" && e.Platform == "" &&
+		e.Orientation == "" && e.Geolocation == nil && e.Locale == "" && e.Timezone == "" &&
+		e.ColorScheme == "" && e.ReducedMotion == "" && e.Network == ""
+}
+
+// Resolve expands the device preset into the explicit fields and validates
+// the result. chromeVersion fills preset user agents.
+func (e *Emulation) Resolve(chromeVersion string) error {
+	if e.Device != "" {
+		d, ok := devices.Lookup(e.Device, chromeVersion)
+		if !ok {
+			return fmt.Errorf("unknown device %q (known: %s)", e.Device, strings.Join(devices.Names(), ", "))
+		}
+		e.Device = d.Name
+		if e.Width == 0 {
+			e.Width = d.Width
+		}
+		if e.Height == 0 {
+			e.Height = d.Height
+		}
+		if e.DeviceScaleFactor == 0 {
+			e.DeviceScaleFactor = d.DeviceScaleFactor
+		}
+		if e.Mobile == nil {
+			e.Mobile = &d.Mobile
+		}
+		if e.Touch == nil {
+			e.Touch = &d.Touch
+		}
+		if e.UserAgent == "" {
+			e.UserAgent = d.UserAgent
+		}
+		if e.Platform == "" {
+			e.Platform = d.Platform
+		}
+	}
+
+	if e.Width < 0 || e.Height < 0 || e.Width > 10000 || e.Height > 10000 {
+		return fmt.Errorf("width and height must be between 0 and 10000")
+	}
+	if (e.Width == 0) != (e.Height == 0) {
+		return fmt.Errorf("width and height must be set together")
+	}
+	if e.DeviceScaleFactor < 0 || e.DeviceScaleFactor > 10 {
+		return fmt.Errorf("deviceScaleFactor must be between 0 and 10")
+	}
+
+	e.Orientation = strings.ToLower(strings.TrimSpace(e.Orientation))
+	switch e.Orientation {
+	case "", OrientationPortrait, OrientationLandscape:
+	default:
+		return fmt.Errorf("invalid orientation %q (want portrait or landscape)", e.Orientation)
+	}
+	if e.Orientation != "" && e.Width == 0 {
+		return fmt.Errorf("orientation requires a device or width and height")
+	}
+
+	if g := e.Geolocation; g != nil {
+		if g.Latitude < -90 || g.Latitude > 90 || g.Longitude < -180 || g.Longitude > 180 {
+			return fmt.Errorf("geolocation out of range")
+		}
+		if g.Accuracy < 0 {
+			return fmt.Errorf("geolocation accuracy must be >= 0")
+		}
+	}
+
+	e.ColorScheme = strings.ToLower(strings.TrimSpace(e.ColorScheme))
+	switch e.ColorScheme {
+	case "", "light", "dark", "no-preference":
+	default:
+		return fmt.Errorf("invalid colorScheme %q (want light, dark or no-preference)", e.ColorScheme)
+	}
+	e.ReducedMotion = strings.ToLower(strings.TrimSpace(e.ReducedMotion))
+	switch e.ReducedMotion {
+	case "", "reduce", "no-preference":
+	default:
+		return fmt.Errorf("invalid reducedMotion %q (want reduce or no-preference)", e.ReducedMotion)
+	}
+
+	e.Network = strings.ToLower(strings.TrimSpace(e.Network))
+	switch e.Network {
+	case "", NetworkNone, NetworkOffline:
+	default:
+		if _, ok := networkProfiles[e.Network]; !ok {
+			return fmt.Errorf("invalid network %q (want none, offline, slow-3g, fast-3g or 4g)", e.Network)
+		}
+	}
+	return nil
+}
+
+// viewport returns the CSS viewport after applying the orientation.
+func (e *Emulation) viewport() (int, int) {
+	w, h := e.Width, e.Height
+	switch e.Orientation {
+	case OrientationLandscape:
+		if w < h {
+			w, h = h, w
+		}
+	case OrientationPortrait:
+		if w > h {
+			w, h = h, w
+		}
+	}
+	return w, h
+}
+
+// ApplyEmulation applies a resolved emulation to the tab in ctx. The
+// overrides last for the lifetime of the target, across navigations.
+func ApplyEmulation(ctx context.Context, e *Emulation) error {
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if e.Width > 0 {
+			w, h := e.viewport()
+			dsf := e.DeviceScaleFactor
+			if dsf == 0 {
+				dsf = 1
+			}
+			mobile := e.Mobile != nil && *e.Mobile
+			// Phones and tablets are portrait-primary, so landscape is a
+			// 90 degree rotation; desktops are landscape-primary at 0.
+			orientation := &emulation.ScreenOrientation{Type: emulation.OrientationTypePortraitPrimary}
+			if w > h {
+				orientation = &emulation.ScreenOrientation{Type: emulation.OrientationTypeLandscapePrimary}
+				if mobile {
+					orientation.Angle = 90
+				}
+			}
+			if err := emulation.SetDeviceMetricsOverride(int64(w), int64(h), dsf, mobile).
+				WithScreenWidth(int64(w)).
+				WithScreenHeight(int64(h)).
+				WithScreenOrientation(orientation).
+				Do(ctx); err != nil {
+				return fmt.Errorf("setDeviceMetricsOverride: %w", err)
+			}
+		}
+		if e.Touch != nil {
+			p := emulation.SetTouchEmulationEnabled(*e.Touch)
+			if *e.Touch {
+				p = p.WithMaxTouchPoints(5)
+			}
+			if err := p.Do(ctx); err != nil {
+				return fmt.Errorf("setTouchEmulationEnabled: %w", err)
+			}
+		}
+		if e.UserAgent != "" {
+			p := emulation.SetUserAgentOverride(e.UserAgent).WithPlatform(e.Platform)
+			if e.Locale != "" {
+				p = p.WithAcceptLanguage(e.Locale)
+			}
+			if e.Mobile != nil {
+				p = p.WithUserAgentMetadata(&emulation.UserAgentMetadata{
+					Platform: uaMetadataPlatform(e.Platform),
+					Mobile:   *e.Mobile,
+				})
+			}
+			if err := p.Do(ctx); err != nil {
+				return fmt.Errorf("setUserAgentOverride: %w", err)
+			}
+		}
+		if e.Locale != "" {
+			if err := emulation.SetLocaleOverride().WithLocale(e.Locale).Do(ctx); err != nil {
+				return fmt.Errorf("setLocaleOverride: %w", err)
+			}
+		}
+		if e.Timezone != "" {
+			if err := emulation.SetTimezoneOverride(e.Timezone).Do(ctx); err != nil {
+				return fmt.Errorf("setTimezoneOverride: %w", err)
+			}
+		}
+		if g := e.Geolocation; g != nil {
+			grantGeolocation(ctx)
+			accuracy := g.Accuracy
+			if accuracy == 0 {
+				accuracy = 10
+			}
+			if err := emulation.SetGeolocationOverride().
+				WithLatitude(g.Latitude).
+				WithLongitude(g.Longitude).
+				WithAccuracy(accuracy).
+				Do(ctx); err != nil {
+				return fmt.Errorf("setGeolocationOverride: %w", err)
+			}
+		}
+		var features []*emulation.MediaFeature
+		if e.ColorScheme != "" {
+			features = append(features, &emulation.MediaFeature{Name: "prefers-color-scheme", Value: e.ColorScheme})
+		}
+		if e.ReducedMotion != "" {
+			features = append(features, &emulation.MediaFeature{Name: "prefers-reduced-motion", Value: e.ReducedMotion})
+		}
+		if len(features) > 0 {
+			if err := emulation.SetEmulatedMedia().WithFeatures(features).Do(ctx); err != nil {
+				return fmt.Errorf("setEmulatedMedia: %w", err)
+			}
+		}
+		if e.Network != "" {
+			if err := applyNetworkConditions(ctx, e.Network); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func applyNetworkConditions(ctx context.Context, name string) error {
+	if err := network.Enable().Do(ctx); err != nil {
+		return fmt.Errorf("network enable: %w", err)
+	}
+	var p *network.EmulateNetworkConditionsParams
+	switch name {
+	case NetworkNone:
+		p = network.EmulateNetworkConditions(false, 0, -1, -1)
+	case NetworkOffline:
+		p = network.EmulateNetworkConditions(true, 0, 0, 0)
+	default:
+		np := networkProfiles[name]
+		p = network.EmulateNetworkConditions(false, np.latencyMs, np.downloadBytes, np.uploadBytes).
+			WithConnectionType(np.connection)
+	}
+	if err := p.Do(ctx); err != nil {
+		return fmt.Errorf("emulateNetworkConditions: %w", err)
+	}
+	return nil
+}
+
+// grantGeolocation grants the geolocation permission so pages can read the
+// overridden position without a prompt. Failures are logged only: the
+// override still applies to pages that already hold the permission.
+func grantGeolocation(ctx context.Context) {
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Browser == nil {
+		return
+	}
+	p := browser.GrantPermissions([]browser.PermissionType{browser.PermissionTypeGeolocation})
+	if err := p.Do(cdp.WithExecutor(ctx, c.Browser)); err != nil {
+		slog.Warn("geolocation permission grant failed", "err", err)
+	}
+}
+
+func uaMetadataPlatform(navigatorPlatform string) string {
+	switch navigatorPlatform {
+	case "iPhone", "iPad":
+		return "iOS"
+	case "Linux armv81":
+		return "Android"
+	case "MacIntel":
+		return "macOS"
+	case "Win32":
+		return "Windows"
+	default:
+		return "Linux"
+	}
+}
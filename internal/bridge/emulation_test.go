@@ -0,0 +1,71 @@
+package bridge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmulationResolve_Preset(t *testing.T) {
+	touch := false
+	e := Emulation{Device: "iPhone-15", Touch: &touch, Orientation: "Landscape"}
+	if err := e.Resolve("150.0.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if e.Device != "iphone-15" || e.Width != 393 || e.Height != 852 || e.DeviceScaleFactor != 3 {
+		t.Errorf("preset not expanded: %+v", e)
+	}
+	if e.Mobile == nil || !*e.Mobile {
+		t.Error("expected mobile from preset")
+	}
+	if e.Touch == nil || *e.Touch {
+		t.Error("explicit touch=false should override the preset")
+	}
+	if !strings.Contains(e.UserAgent, "iPhone") {
+		t.Errorf("expected preset UA, got %q", e.UserAgent)
+	}
+	if w, h := e.viewport(); w != 852 || h != 393 {
+		t.Errorf("landscape viewport = %dx%d", w, h)
+	}
+}
+
+func TestEmulationResolve_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		e    Emulation
+		want string
+	}{
+		{"unknown device", Emulation{Device: "nokia"}, "unknown device"},
+		{"width only", Emulation{Width: 400}, "set together"},
+		{"huge", Emulation{Width: 20000, Height: 100}, "between 0 and 10000"},
+		{"orientation without size", Emulation{Orientation: "landscape"}, "requires a device"},
+		{"bad orientation", Emulation{Device: "pixel-8", Orientation: "sideways"}, "invalid orientation"},
+		{"bad latitude", Emulation{Geolocation: &Geolocation{Latitude: 91}}, "out of range"},
+		{"bad color scheme", Emulation{ColorScheme: "sepia"}, "invalid colorScheme"},
+		{"bad motion", Emulation{ReducedMotion: "slow"}, "invalid reducedMotion"},
+		{"bad network", Emulation{Network: "5g"}, "invalid network"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.e.Resolve("")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestEmulationResolve_CustomValues(t *testing.T) {
+	e := Emulation{Width: 800, Height: 600, ColorScheme: "Dark", ReducedMotion: "reduce", Network: "Slow-3G", Locale: "de-DE", Timezone: "Europe/Berlin"}
+	if err := e.Resolve(""); err != nil {
+		t.Fatal(err)
+	}
+	if e.ColorScheme != "dark" || e.Network != NetworkSlow3G {
+		t.Errorf("values not normalized: %+v", e)
+	}
+	if e.Mobile != nil || e.UserAgent != "" {
+		t.Errorf("custom emulation should not pick up preset fields: %+v", e)
+	}
+	if (&Emulation{}).IsZero() != true || e.IsZero() {
+		t.Error("IsZero mismatch")
+	}
+}
@@ -0,0 +1,243 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/extract"
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+// extractFieldsJS evaluates a compiled field spec in the page and returns
+// raw values in the shape extract.Source.Raw documents. Text is
+// whitespace-normalized innerText. >>> segments pierce nested shadow roots
+// the same way scoped selectors do.
+const extractFieldsJS = `(function(fields){` + shadowQueryJS + `
+  const norm = s => (s || '').replace(/\s+/g, ' ').trim();
+  const el = n => n && n.nodeType === 9 ? n.documentElement : n;
+  function all(root, f) {
+    if (!f.kind) return [el(root)];
+    const deep = !!(f.pierce && f.pierce.length);
+    const out = [];
+    for (const s of pierceRoots(root, f.pierce)) {
+      if (f.kind === 'xpath') {
+        const r = document.evaluate(f.value, s, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+        for (let i = 0; i < r.snapshotLength; i++) { const n = r.snapshotItem(i); if (n.nodeType === 1) out.push(n); }
+      } else if (f.kind === 'text') {
+        for (const e of queryAll(s, '*', deep)) {
+          if (!e.textContent.includes(f.value)) continue;
+          const kids = [...e.children, ...(e.shadowRoot ? e.shadowRoot.children : [])];
+          if (!kids.some(c => c.textContent.includes(f.value))) out.push(e);
+        }
+      } else {
+        out.push(...queryAll(s, f.value, deep));
+      }
+    }
+    return [...new Set(out)];
+  }
+  function value(e, f) {
+    if (f.fields) return object(e, f.fields);
+    if (f.source === 'attr') return e.getAttribute(f.attr);
+    if (f.source === 'html') return e.innerHTML;
+    return norm(e.innerText !== undefined ? e.innerText : e.textContent);
+  }
+  function object(root, fields) {
+    const o = {};
+    for (const f of fields) {
+      const els = all(root, f);
+      o[f.name] = f.list ? els.map(e => value(e, f)) : (els.length ? value(els[0], f) : null);
+    }
+    return o;
+  }
+  return object(document, fields);
+})(%s)`
+
+// extractNextJS finds the first next-page match and remembers it for
+// extractClickNextJS. It returns the current URL and the URL the element
+// links to ("" when it is not a link or form button), or null when nothing
+// matched.
+const extractNextJS = `(function(f){` + shadowQueryJS + `
+  const deep = !!(f.pierce && f.pierce.length);
+  let target = null;
+  for (const s of pierceRoots(document, f.pierce)) {
+    if (f.kind === 'xpath') {
+      const r = document.evaluate(f.value, s, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null);
+      target = r.singleNodeValue;
+    } else if (f.kind === 'text') {
+      for (const e of queryAll(s, 'a,button,[role=button],[role=link],input[type=submit],input[type=button]', deep)) {
+        if ((e.innerText || e.value || '').includes(f.value)) { target = e; break; }
+      }
+    } else {
+      target = queryAll(s, f.value, deep)[0] || null;
+    }
+    if (target) break;
+  }
+  if (!target || target.disabled || target.getAttribute('aria-disabled') === 'true') return null;
+  window.__pinchtabExtractNext = target;
+  const link = target.closest('a[href]');
+  return {from: location.href, to: link ? link.href : (target.form ? target.form.action : '')};
+})(%s)`
+
+// extractClickNextJS clicks the element extractNextJS found and returns a
+// token used to detect that the page changed.
+const extractClickNextJS = `(function(){
+  const target = window.__pinchtabExtractNext;
+  if (!target) return null;
+  delete window.__pinchtabExtractNext;
+  const token = String(Date.now()) + Math.random();
+  window.__pinchtabExtractPage = token;
+  window.__pinchtabExtractSig = document.body ? document.body.innerText.length + ':' + document.body.innerText.slice(0, 2000) : '';
+  window.__pinchtabExtractURL = location.href;
+  target.click();
+  return token;
+})()`
+
+// extractChangedJS reports whether the page navigated or its content
+// changed since the next-page click, and the new document has loaded.
+const extractChangedJS = `(function(token){
+  if (window.__pinchtabExtractPage !== token) return document.readyState === 'complete';
+  if (location.href !== window.__pinchtabExtractURL) return document.readyState === 'complete';
+  const sig = document.body ? document.body.innerText.length + ':' + document.body.innerText.slice(0, 2000) : '';
+  return sig !== window.__pinchtabExtractSig;
+})(%s)`
+
+// ErrNextPageTimeout is returned when the next-page element was clicked
+// but the page neither navigated nor changed.
+var ErrNextPageTimeout = errors.New("next page did not load")
+
+type compiledField struct {
+	Name   string          `json:"name"`
+	Kind   selector.Kind   `json:"kind,omitempty"`
+	Value  string          `json:"value,omitempty"`
+	Pierce []string        `json:"pierce,omitempty"`
+	Source string          `json:"source,omitempty"`
+	Attr   string          `json:"attr,omitempty"`
+	List   bool            `json:"list,omitempty"`
+	Fields []compiledField `json:"fields,omitempty"`
+}
+
+func compileFields(fields []extract.Field) ([]compiledField, error) {
+	out := make([]compiledField, len(fields))
+	for i, f := range fields {
+		c := compiledField{Name: f.Name, Source: f.Source, Attr: f.Attr, List: f.List}
+		if f.Selector != "" {
+			sel, err := extract.ParseSelector(f.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			c.Kind, c.Value, c.Pierce = sel.Kind, sel.Value, sel.Pierce
+		}
+		if len(f.Fields) > 0 {
+			nested, err := compileFields(f.Fields)
+			if err != nil {
+				return nil, err
+			}
+			c.Fields = nested
+		}
+		out[i] = c
+	}
+	return out, nil
+}
+
+// ChromeExtractSource evaluates extraction specs in a Chrome tab. NextTimeout
+// bounds how long a next-page click may take to load or change the page.
+// Guard, when set, vets the linked page before the click and the loaded
+// page after it, which catches script-driven navigation.
+type ChromeExtractSource struct {
+	NextTimeout time.Duration
+	Guard       extract.NextGuard
+}
+
+func (s ChromeExtractSource) Raw(ctx context.Context, fields []extract.Field) (map[string]any, error) {
+	compiled, err := compileFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	arg, err := json.Marshal(compiled)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(extractFieldsJS, arg), &out)); err != nil {
+		return nil, fmt.Errorf("extract: %w", err)
+	}
+	return out, nil
+}
+
+func (s ChromeExtractSource) URL(ctx context.Context) (string, error) {
+	var url string
+	if err := chromedp.Run(ctx, chromedp.Location(&url)); err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+func (s ChromeExtractSource) Next(ctx context.Context, sel selector.Selector) (bool, error) {
+	arg, err := json.Marshal(compiledField{Kind: sel.Kind, Value: sel.Value, Pierce: sel.Pierce})
+	if err != nil {
+		return false, err
+	}
+	var found *struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	if err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(extractNextJS, arg), &found)); err != nil {
+		return false, err
+	}
+	if found == nil {
+		return false, nil
+	}
+	if s.Guard != nil && found.To != "" {
+		if err := s.Guard(ctx, found.From, found.To); err != nil {
+			return false, err
+		}
+	}
+	var token *string
+	if err := chromedp.Run(ctx, chromedp.Evaluate(extractClickNextJS, &token)); err != nil {
+		return false, err
+	}
+	if token == nil {
+		return false, nil
+	}
+
+	timeout := s.NextTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	check := fmt.Sprintf(extractChangedJS, jsonString(*token))
+	for {
+		var changed bool
+		// Evaluation fails while the old document is torn down; keep polling.
+		if err := chromedp.Run(ctx, chromedp.Evaluate(check, &changed)); err == nil && changed {
+			if s.Guard == nil {
+				return true, nil
+			}
+			url, err := s.URL(ctx)
+			if err != nil {
+				return false, err
+			}
+			if err := s.Guard(ctx, found.From, url); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+		if time.Now().After(deadline) {
+			return false, ErrNextPageTimeout
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
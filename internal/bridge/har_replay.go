@@ -0,0 +1,188 @@
+package bridge
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+)
+
+// HAR replay policies for requests that have no recorded response.
+const (
+	HARNotFoundAbort    = "abort"
+	HARNotFoundContinue = "continue"
+)
+
+// harSkipHeaders are recorded response headers that no longer describe the
+// replayed body: it is served decoded and with its own length.
+var harSkipHeaders = map[string]bool{
+	"content-encoding":  true,
+	"content-length":    true,
+	"transfer-encoding": true,
+}
+
+// HARReplayOptions configures how a HAR archive is replayed.
+type HARReplayOptions struct {
+	// URLPattern limits replay to matching URLs (glob, default "*").
+	// Requests outside the pattern always continue to the network.
+	URLPattern string `json:"url,omitempty"`
+	// NotFound is "abort" (default) or "continue" for matching requests
+	// that are not in the archive.
+	NotFound string `json:"notFound,omitempty"`
+}
+
+// HARReplay serves recorded responses from a HAR archive via
+// Fetch.fulfillRequest. Entries are matched by method and URL; when a URL
+// was recorded several times the responses are replayed in order and the
+// last one repeats.
+type HARReplay struct {
+	opts    HARReplayOptions
+	entries map[string][]*HAREntry
+	total   int
+
+	mu     sync.Mutex
+	next   map[string]int
+	hits   int64
+	misses int64
+}
+
+// HARReplayStatus summarises an installed replay.
+type HARReplayStatus struct {
+	URLPattern string `json:"url"`
+	NotFound   string `json:"notFound"`
+	Entries    int    `json:"entries"`
+	Hits       int64  `json:"hits"`
+	Misses     int64  `json:"misses"`
+}
+
+// NewHARReplay indexes a HAR archive for replay. Entries without a
+// recorded status (failed requests) are skipped.
+func NewHARReplay(h *HAR, opts HARReplayOptions) (*HARReplay, error) {
+	if h == nil {
+		return nil, fmt.Errorf("har required")
+	}
+	if opts.URLPattern == "" {
+		opts.URLPattern = "*"
+	}
+	opts.NotFound = strings.ToLower(strings.TrimSpace(opts.NotFound))
+	switch opts.NotFound {
+	case "":
+		opts.NotFound = HARNotFoundAbort
+	case HARNotFoundAbort, HARNotFoundContinue:
+	default:
+		return nil, fmt.Errorf("invalid notFound %q (want abort or continue)", opts.NotFound)
+	}
+
+	r := &HARReplay{
+		opts:    opts,
+		entries: make(map[string][]*HAREntry),
+		next:    make(map[string]int),
+	}
+	for i := range h.Log.Entries {
+		e := &h.Log.Entries[i]
+		if e.Response.Status < 100 || e.Response.Status > 599 {
+			continue
+		}
+		if _, err := e.ResponseBody(); err != nil {
+			return nil, fmt.Errorf("entry %d (%s): invalid body: %w", i, e.Request.URL, err)
+		}
+		key := harKey(e.Request.Method, e.Request.URL)
+		r.entries[key] = append(r.entries[key], e)
+		r.total++
+	}
+	if r.total == 0 {
+		return nil, fmt.Errorf("har contains no replayable entries")
+	}
+	return r, nil
+}
+
+// Status returns replay counters.
+func (r *HARReplay) Status() HARReplayStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return HARReplayStatus{
+		URLPattern: r.opts.URLPattern,
+		NotFound:   r.opts.NotFound,
+		Entries:    r.total,
+		Hits:       r.hits,
+		Misses:     r.misses,
+	}
+}
+
+// Lookup returns the recorded entry for a request, or nil. Lookups advance
+// through repeated recordings of the same request.
+func (r *HARReplay) Lookup(method, url string) *HAREntry {
+	key := harKey(method, url)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.entries[key]
+	if len(list) == 0 {
+		r.misses++
+		return nil
+	}
+	i := r.next[key]
+	if i < len(list)-1 {
+		r.next[key] = i + 1
+	}
+	r.hits++
+	return list[i]
+}
+
+func (r *HARReplay) serve(ctx context.Context, tabID string, e *fetch.EventRequestPaused) {
+	var url, method string
+	if e.Request != nil {
+		url, method = e.Request.URL, e.Request.Method
+	}
+	if !MatchURLGlob(r.opts.URLPattern, url) {
+		_ = fetch.ContinueRequest(e.RequestID).Do(ctx)
+		return
+	}
+
+	entry := r.Lookup(method, url)
+	if entry == nil {
+		var err error
+		if r.opts.NotFound == HARNotFoundContinue {
+			err = fetch.ContinueRequest(e.RequestID).Do(ctx)
+		} else {
+			err = fetch.FailRequest(e.RequestID, network.ErrorReasonInternetDisconnected).Do(ctx)
+		}
+		if err != nil {
+			slog.Debug("har replay miss failed", "tabId", tabID, "url", url, "err", err)
+		}
+		return
+	}
+
+	body, _ := entry.ResponseBody()
+	headers := make([]*fetch.HeaderEntry, 0, len(entry.Response.Headers))
+	for _, h := range entry.Response.Headers {
+		if harSkipHeaders[strings.ToLower(h.Name)] {
+			continue
+		}
+		headers = append(headers, &fetch.HeaderEntry{Name: h.Name, Value: h.Value})
+	}
+	p := fetch.FulfillRequest(e.RequestID, int64(entry.Response.Status)).WithResponseHeaders(headers)
+	if entry.Response.StatusText != "" {
+		p = p.WithResponsePhrase(entry.Response.StatusText)
+	}
+	if len(body) > 0 {
+		p = p.WithBody(base64.StdEncoding.EncodeToString(body))
+	}
+	if err := p.Do(ctx); err != nil {
+		slog.Debug("har replay fulfill failed", "tabId", tabID, "url", url, "err", err)
+	}
+}
+
+func harKey(method, url string) string {
+	if i := strings.IndexByte(url, '#'); i >= 0 {
+		url = url[:i]
+	}
+	if method == "" {
+		method = "GET"
+	}
+	return strings.ToUpper(method) + " " + url
+}
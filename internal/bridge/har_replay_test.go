@@ -0,0 +1,149 @@
+package bridge
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBuildHAR(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []NetworkEntry{
+		{
+			RequestID:       "r1",
+			URL:             "https://api.example.com/users?page=2&q=a",
+			Method:          "POST",
+			Status:          201,
+			StatusText:      "Created",
+			ResourceType:    "XHR",
+			RequestHeaders:  map[string]string{"Content-Type": "application/json"},
+			ResponseHeaders: map[string]string{"Content-Type": "application/json"},
+			PostData:        `{"name":"a"}`,
+			MimeType:        "application/json",
+			StartTime:       start,
+			Duration:        42,
+			Finished:        true,
+		},
+		{RequestID: "r2", URL: "https://cdn.example.com/logo.png", Method: "GET", Status: 200, MimeType: "image/png", StartTime: start, Finished: true},
+	}
+	bodies := map[string]HARBody{
+		"r1": {Body: `{"id":1}`},
+		"r2": {Body: string([]byte{0x89, 'P', 'N', 'G', 0xff})},
+	}
+
+	har := BuildHAR(entries, bodies, HARCreator{})
+	if har.Log.Version != "1.2" || har.Log.Creator.Name != "pinchtab" {
+		t.Fatalf("unexpected log header: %+v", har.Log)
+	}
+	if len(har.Log.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(har.Log.Entries))
+	}
+
+	e := har.Log.Entries[0]
+	if e.StartedDateTime != "2026-01-02T03:04:05Z" || e.Time != 42 {
+		t.Errorf("unexpected timing: %s %v", e.StartedDateTime, e.Time)
+	}
+	if e.Request.PostData == nil || e.Request.PostData.MimeType != "application/json" {
+		t.Errorf("expected postData with mime type, got %+v", e.Request.PostData)
+	}
+	if len(e.Request.QueryString) != 2 || e.Request.QueryString[0].Name != "page" {
+		t.Errorf("unexpected query string: %+v", e.Request.QueryString)
+	}
+	if e.Response.Content.Text != `{"id":1}` || e.Response.Content.Encoding != "" {
+		t.Errorf("expected text body, got %+v", e.Response.Content)
+	}
+
+	bin := har.Log.Entries[1]
+	if bin.Response.Content.Encoding != "base64" {
+		t.Errorf("expected binary body to be base64 encoded, got %+v", bin.Response.Content)
+	}
+	if b, err := bin.ResponseBody(); err != nil || string(b) != bodies["r2"].Body {
+		t.Errorf("binary body did not round-trip: %v", err)
+	}
+
+	data, err := json.Marshal(har)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	parsed, err := ParseHAR(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(parsed.Log.Entries) != 2 {
+		t.Errorf("expected 2 parsed entries, got %d", len(parsed.Log.Entries))
+	}
+}
+
+func TestParseHARRejectsEmpty(t *testing.T) {
+	if _, err := ParseHAR([]byte(`{"log":{"version":"1.2","entries":[]}}`)); err == nil {
+		t.Error("expected error for HAR without entries")
+	}
+	if _, err := ParseHAR([]byte(`not json`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestHARReplayLookup(t *testing.T) {
+	har := BuildHAR([]NetworkEntry{
+		{RequestID: "1", URL: "https://x.test/a", Method: "GET", Status: 200},
+		{RequestID: "2", URL: "https://x.test/a", Method: "GET", Status: 304},
+		{RequestID: "3", URL: "https://x.test/b", Method: "POST", Status: 500},
+		{RequestID: "4", URL: "https://x.test/failed", Method: "GET", Failed: true},
+	}, nil, HARCreator{})
+
+	r, err := NewHARReplay(&har, HARReplayOptions{})
+	if err != nil {
+		t.Fatalf("NewHARReplay: %v", err)
+	}
+	st := r.Status()
+	if st.Entries != 3 || st.NotFound != HARNotFoundAbort || st.URLPattern != "*" {
+		t.Errorf("unexpected status: %+v", st)
+	}
+
+	for i, want := range []int{200, 304, 304} {
+		e := r.Lookup("GET", "https://x.test/a#frag")
+		if e == nil || e.Response.Status != want {
+			t.Fatalf("lookup %d: expected %d, got %+v", i, want, e)
+		}
+	}
+	if e := r.Lookup("post", "https://x.test/b"); e == nil || e.Response.Status != 500 {
+		t.Errorf("expected case-insensitive method match, got %+v", e)
+	}
+	if e := r.Lookup("GET", "https://x.test/b"); e != nil {
+		t.Error("expected method mismatch to miss")
+	}
+	if e := r.Lookup("GET", "https://x.test/failed"); e != nil {
+		t.Error("expected failed entry to be skipped")
+	}
+	st = r.Status()
+	if st.Hits != 4 || st.Misses != 2 {
+		t.Errorf("expected 4 hits / 2 misses, got %+v", st)
+	}
+}
+
+func TestNewHARReplayValidation(t *testing.T) {
+	har := BuildHAR([]NetworkEntry{{RequestID: "1", URL: "https://x.test/", Method: "GET", Status: 200}}, nil, HARCreator{})
+	if _, err := NewHARReplay(&har, HARReplayOptions{NotFound: "ignore"}); err == nil {
+		t.Error("expected invalid notFound error")
+	}
+	empty := BuildHAR([]NetworkEntry{{RequestID: "1", URL: "https://x.test/", Failed: true}}, nil, HARCreator{})
+	if _, err := NewHARReplay(&empty, HARReplayOptions{}); err == nil {
+		t.Error("expected error for HAR without replayable entries")
+	}
+}
+
+func TestInterceptManagerReplayKeepsTabActive(t *testing.T) {
+	im := NewInterceptManager()
+	har := BuildHAR([]NetworkEntry{{RequestID: "1", URL: "https://x.test/", Method: "GET", Status: 200}}, nil, HARCreator{})
+	r, err := NewHARReplay(&har, HARReplayOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	im.tabs["tab1"] = &tabIntercept{replay: r}
+	if !im.Active("tab1") {
+		t.Error("expected replay to keep tab active")
+	}
+	if im.Replay("tab1") != r {
+		t.Error("expected installed replay")
+	}
+}
@@ -0,0 +1,657 @@
+package bridge
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	bridgecdpops "github.com/pinchtab/pinchtab/internal/bridge/cdpops"
+)
+
+// Intercept rule actions.
+const (
+	InterceptContinue = "continue"
+	InterceptFulfill  = "fulfill"
+	InterceptAbort    = "abort"
+)
+
+// ErrInterceptRuleNotFound is returned when removing an unknown rule ID.
+var ErrInterceptRuleNotFound = errors.New("intercept rule not found")
+
+const (
+	maxInterceptRulesPerTab = 100
+	maxInterceptDelay       = 60 * time.Second
+	maxInterceptBodyBytes   = 5 << 20
+)
+
+// InterceptRule describes how matching requests on a tab are handled while
+// paused in the CDP Fetch domain. Matchers are ANDed; an empty matcher
+// matches everything. The first matching rule (in insertion order) wins.
+type InterceptRule struct {
+	ID           string `json:"id"`
+	URLPattern   string `json:"url"`
+	Method       string `json:"method,omitempty"`
+	ResourceType string `json:"resourceType,omitempty"`
+
+	Action string `json:"action"`
+
+	// Fulfill
+	Status     int               `json:"status,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	BodyBase64 bool              `json:"bodyBase64,omitempty"`
+
+	// Continue
+	RequestHeaders map[string]string `json:"requestHeaders,omitempty"`
+
+	// Abort
+	ErrorReason string `json:"errorReason,omitempty"`
+
+	DelayMs int   `json:"delayMs,omitempty"`
+	Hits    int64 `json:"hits"`
+}
+
+// Validate normalizes the rule and reports configuration errors.
+func (r *InterceptRule) Validate() error {
+	if r.URLPattern == "" {
+		r.URLPattern = "*"
+	}
+	r.Action = strings.ToLower(strings.TrimSpace(r.Action))
+	if r.Action == "" {
+		switch {
+		case r.ErrorReason != "":
+			r.Action = InterceptAbort
+		case r.Status != 0 || r.Body != "":
+			r.Action = InterceptFulfill
+		default:
+			r.Action = InterceptContinue
+		}
+	}
+	switch r.Action {
+	case InterceptContinue:
+	case InterceptFulfill:
+		if r.Status == 0 {
+			r.Status = http.StatusOK
+		}
+		if r.Status < 100 || r.Status > 599 {
+			return fmt.Errorf("invalid status %d", r.Status)
+		}
+		if len(r.Body) > maxInterceptBodyBytes {
+			return fmt.Errorf("body exceeds %d bytes", maxInterceptBodyBytes)
+		}
+		if r.BodyBase64 {
+			if _, err := base64.StdEncoding.DecodeString(r.Body); err != nil {
+				return fmt.Errorf("body is not valid base64: %w", err)
+			}
+		}
+	case InterceptAbort:
+		if r.ErrorReason == "" {
+			r.ErrorReason = string(network.ErrorReasonFailed)
+		}
+		var reason network.ErrorReason
+		if err := reason.UnmarshalJSON([]byte(`"` + r.ErrorReason + `"`)); err != nil {
+			return fmt.Errorf("invalid errorReason %q", r.ErrorReason)
+		}
+	default:
+		return fmt.Errorf("invalid action %q (want continue, fulfill or abort)", r.Action)
+	}
+	if r.DelayMs < 0 || time.Duration(r.DelayMs)*time.Millisecond > maxInterceptDelay {
+		return fmt.Errorf("delayMs must be between 0 and %d", maxInterceptDelay.Milliseconds())
+	}
+	r.Method = strings.ToUpper(r.Method)
+	return nil
+}
+
+// Matches reports whether the rule applies to a paused request.
+func (r *InterceptRule) Matches(url, method, resourceType string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if r.ResourceType != "" && !strings.EqualFold(r.ResourceType, resourceType) {
+		return false
+	}
+	return MatchURLGlob(r.URLPattern, url)
+}
+
+// MatchURLGlob matches a URL against a wildcard pattern where '*' matches
+// any run of characters and '?' matches exactly one, as in CDP
+// Fetch.RequestPattern.urlPattern.
+func MatchURLGlob(pattern, s string) bool {
+	px, sx := 0, 0
+	starP, starS := -1, 0
+	for sx < len(s) {
+		switch {
+		case px < len(pattern) && (pattern[px] == '?' || pattern[px] == s[sx]):
+			px++
+			sx++
+		case px < len(pattern) && pattern[px] == '*':
+			starP, starS = px, sx
+			px++
+		case starP >= 0:
+			px = starP + 1
+			starS++
+			sx = starS
+		default:
+			return false
+		}
+	}
+	for px < len(pattern) && pattern[px] == '*' {
+		px++
+	}
+	return px == len(pattern)
+}
+
+// RequestGuard vets a paused request before the tab's rules and HAR replay
+// see it. It reports whether it resolved the request itself, e.g. by failing
+// it; otherwise the request goes on through the normal interception path.
+type RequestGuard func(ctx context.Context, e *fetch.EventRequestPaused) bool
+
+type tabGuard struct {
+	id    int
+	check RequestGuard
+}
+
+type tabIntercept struct {
+	rules     []*InterceptRule
+	replay    *HARReplay
+	guards    []tabGuard
+	nextGuard int
+	listening bool
+	enabled   bool
+}
+
+// active reports whether rules or a HAR replay are installed.
+func (ti *tabIntercept) active() bool {
+	return len(ti.rules) > 0 || ti.replay != nil
+}
+
+// idle reports whether nothing is left that needs the Fetch domain.
+func (ti *tabIntercept) idle() bool {
+	return !ti.active() && len(ti.guards) == 0
+}
+
+func (ti *tabIntercept) indexOf(ruleID string) int {
+	for i, r := range ti.rules {
+		if r.ID == ruleID {
+			return i
+		}
+	}
+	return -1
+}
+
+// InterceptManager owns per-tab request interception rules and the Fetch
+// domain listeners that apply them. It is the only Fetch listener on a tab:
+// other users of the domain, such as redirect limits, register a
+// RequestGuard instead of pausing requests on their own.
+type InterceptManager struct {
+	mu   sync.Mutex
+	tabs map[string]*tabIntercept
+
+	// fetchDomain replaces the CDP Fetch.enable/disable calls in tests.
+	fetchDomain func(tabCtx context.Context, tabID string, enable bool) error
+}
+
+// NewInterceptManager creates an empty interception manager.
+func NewInterceptManager() *InterceptManager {
+	return &InterceptManager{tabs: make(map[string]*tabIntercept)}
+}
+
+// Rules returns a copy of the rules installed on a tab.
+func (im *InterceptManager) Rules(tabID string) []InterceptRule {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	ti := im.tabs[tabID]
+	if ti == nil {
+		return []InterceptRule{}
+	}
+	out := make([]InterceptRule, 0, len(ti.rules))
+	for _, r := range ti.rules {
+		out = append(out, *r)
+	}
+	return out
+}
+
+// AddRules validates and installs rules on a tab, enabling the Fetch domain
+// on first use. Rules with an existing ID replace the previous definition.
+// tabCtx must be the tab's long-lived context: the request listener is bound
+// to it and stops when it is cancelled.
+func (im *InterceptManager) AddRules(tabCtx context.Context, tabID string, rules []InterceptRule) ([]InterceptRule, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("at least one rule required")
+	}
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		if rules[i].ID == "" {
+			rules[i].ID = generateRuleID()
+		}
+		rules[i].Hits = 0
+	}
+
+	im.mu.Lock()
+	ti := im.tabs[tabID]
+	if ti == nil {
+		ti = &tabIntercept{}
+		im.tabs[tabID] = ti
+	}
+	added := 0
+	for i := range rules {
+		if ti.indexOf(rules[i].ID) < 0 {
+			added++
+		}
+	}
+	if len(ti.rules)+added > maxInterceptRulesPerTab {
+		im.mu.Unlock()
+		return nil, fmt.Errorf("too many intercept rules for tab (max %d)", maxInterceptRulesPerTab)
+	}
+	for i := range rules {
+		rule := rules[i]
+		if j := ti.indexOf(rule.ID); j >= 0 {
+			ti.rules[j] = &rule
+		} else {
+			ti.rules = append(ti.rules, &rule)
+		}
+	}
+	im.mu.Unlock()
+
+	if err := im.enable(tabCtx, tabID); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// RemoveRule deletes a single rule. The Fetch domain is disabled when the
+// last rule for the tab is removed.
+func (im *InterceptManager) RemoveRule(tabCtx context.Context, tabID, ruleID string) error {
+	im.mu.Lock()
+	ti := im.tabs[tabID]
+	if ti == nil {
+		im.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrInterceptRuleNotFound, ruleID)
+	}
+	idx := ti.indexOf(ruleID)
+	if idx < 0 {
+		im.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrInterceptRuleNotFound, ruleID)
+	}
+	ti.rules = append(ti.rules[:idx], ti.rules[idx+1:]...)
+	idle := ti.idle()
+	im.mu.Unlock()
+
+	if idle {
+		return im.disable(tabCtx, tabID)
+	}
+	return nil
+}
+
+// ClearTab removes every rule for a tab and disables interception unless a
+// HAR replay is still installed. It returns the number of rules removed.
+func (im *InterceptManager) ClearTab(tabCtx context.Context, tabID string) (int, error) {
+	im.mu.Lock()
+	ti := im.tabs[tabID]
+	n := 0
+	idle := false
+	if ti != nil {
+		n = len(ti.rules)
+		ti.rules = nil
+		idle = ti.idle()
+	}
+	im.mu.Unlock()
+	if !idle {
+		return n, nil
+	}
+	return n, im.disable(tabCtx, tabID)
+}
+
+// SetReplay installs a HAR replay on a tab, replacing any previous one.
+// Requests not handled by a rule are served from the archive.
+func (im *InterceptManager) SetReplay(tabCtx context.Context, tabID string, replay *HARReplay) error {
+	if replay == nil {
+		return fmt.Errorf("replay required")
+	}
+	im.mu.Lock()
+	ti := im.tabs[tabID]
+	if ti == nil {
+		ti = &tabIntercept{}
+		im.tabs[tabID] = ti
+	}
+	ti.replay = replay
+	im.mu.Unlock()
+	return im.enable(tabCtx, tabID)
+}
+
+// Replay returns the HAR replay installed on a tab, or nil.
+func (im *InterceptManager) Replay(tabID string) *HARReplay {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if ti := im.tabs[tabID]; ti != nil {
+		return ti.replay
+	}
+	return nil
+}
+
+// ClearReplay removes the HAR replay from a tab. It reports whether one was
+// installed.
+func (im *InterceptManager) ClearReplay(tabCtx context.Context, tabID string) (bool, error) {
+	im.mu.Lock()
+	ti := im.tabs[tabID]
+	had := ti != nil && ti.replay != nil
+	idle := false
+	if had {
+		ti.replay = nil
+		idle = ti.idle()
+	}
+	im.mu.Unlock()
+	if !idle {
+		return had, nil
+	}
+	return had, im.disable(tabCtx, tabID)
+}
+
+// Active reports whether a tab has interception rules or a HAR replay
+// installed.
+func (im *InterceptManager) Active(tabID string) bool {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	ti := im.tabs[tabID]
+	return ti != nil && ti.active()
+}
+
+// AddGuard installs a RequestGuard on a tab, enabling the Fetch domain if
+// needed. The returned func removes the guard and disables the domain again
+// when nothing else needs it. tabCtx must be the tab's long-lived context,
+// as for AddRules.
+func (im *InterceptManager) AddGuard(tabCtx context.Context, tabID string, guard RequestGuard) (func(), error) {
+	im.mu.Lock()
+	ti := im.tabs[tabID]
+	if ti == nil {
+		ti = &tabIntercept{}
+		im.tabs[tabID] = ti
+	}
+	id := ti.nextGuard
+	ti.nextGuard++
+	ti.guards = append(ti.guards, tabGuard{id: id, check: guard})
+	im.mu.Unlock()
+
+	remove := func() {
+		im.mu.Lock()
+		idle := false
+		if im.tabs[tabID] == ti {
+			for i, g := range ti.guards {
+				if g.id == id {
+					ti.guards = append(ti.guards[:i], ti.guards[i+1:]...)
+					break
+				}
+			}
+			idle = ti.idle()
+		}
+		im.mu.Unlock()
+		if !idle {
+			return
+		}
+		if err := im.disable(context.WithoutCancel(tabCtx), tabID); err != nil {
+			slog.Debug("request guard cleanup failed", "tabId", tabID, "err", err)
+		}
+	}
+	if err := im.enable(tabCtx, tabID); err != nil {
+		remove()
+		return nil, err
+	}
+	return remove, nil
+}
+
+// NavigateWithRedirectLimit navigates a tab and fails after more than
+// maxRedirects redirects. The limit is checked by the tab's interception
+// listener ahead of its rules and HAR replay, so both keep applying to the
+// navigation. A nil manager falls back to a standalone Fetch listener.
+func (im *InterceptManager) NavigateWithRedirectLimit(tabCtx, ctx context.Context, tabID, url string, maxRedirects int) error {
+	if im == nil || maxRedirects < 0 {
+		return bridgecdpops.NavigatePageWithRedirectLimit(ctx, url, maxRedirects)
+	}
+	limiter := bridgecdpops.NewRedirectLimiter(maxRedirects)
+	remove, err := im.AddGuard(tabCtx, tabID, limiter.Check)
+	if err != nil {
+		return err
+	}
+	defer remove()
+	return navigateWithLimiter(ctx, url, limiter)
+}
+
+// navigateWithLimiter is replaced in tests.
+var navigateWithLimiter = bridgecdpops.NavigatePageWithLimiter
+
+// Reapply re-enables the Fetch domain for a tab with active rules, e.g.
+// after a navigation that failed part way or a caller that disabled the
+// domain directly, which would otherwise silently drop interception.
+func (im *InterceptManager) Reapply(tabCtx context.Context, tabID string) error {
+	if !im.Active(tabID) {
+		return nil
+	}
+	return im.enable(tabCtx, tabID)
+}
+
+// Forget drops all state for a tab without touching CDP (the tab is gone).
+func (im *InterceptManager) Forget(tabID string) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	delete(im.tabs, tabID)
+}
+
+func (im *InterceptManager) enable(tabCtx context.Context, tabID string) error {
+	im.mu.Lock()
+	listen := false
+	if ti := im.tabs[tabID]; ti != nil && !ti.listening {
+		ti.listening = true
+		listen = true
+	}
+	im.mu.Unlock()
+	if listen && im.fetchDomain == nil {
+		chromedp.ListenTarget(tabCtx, func(ev interface{}) {
+			if e, ok := ev.(*fetch.EventRequestPaused); ok {
+				go im.handlePaused(tabCtx, tabID, e)
+			}
+		})
+	}
+
+	return im.setEnabled(tabCtx, tabID, true)
+}
+
+func (im *InterceptManager) disable(tabCtx context.Context, tabID string) error {
+	im.mu.Lock()
+	ti := im.tabs[tabID]
+	wasEnabled := ti != nil && ti.enabled
+	im.mu.Unlock()
+	if !wasEnabled {
+		return nil
+	}
+	return im.setEnabled(tabCtx, tabID, false)
+}
+
+func (im *InterceptManager) setEnabled(tabCtx context.Context, tabID string, enable bool) error {
+	var err error
+	switch {
+	case im.fetchDomain != nil:
+		err = im.fetchDomain(tabCtx, tabID, enable)
+	case enable:
+		patterns := []*fetch.RequestPattern{{URLPattern: "*", RequestStage: fetch.RequestStageRequest}}
+		err = chromedp.Run(tabCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return fetch.Enable().WithPatterns(patterns).Do(ctx)
+		}))
+	default:
+		err = chromedp.Run(tabCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return fetch.Disable().Do(ctx)
+		}))
+	}
+	if err != nil {
+		if enable {
+			return fmt.Errorf("fetch enable: %w", err)
+		}
+		return fmt.Errorf("fetch disable: %w", err)
+	}
+	im.mu.Lock()
+	if ti := im.tabs[tabID]; ti != nil {
+		ti.enabled = enable
+	}
+	im.mu.Unlock()
+	if enable {
+		slog.Debug("request interception enabled", "tabId", tabID)
+	} else {
+		slog.Debug("request interception disabled", "tabId", tabID)
+	}
+	return nil
+}
+
+// guarded runs the tab's request guards and reports whether one of them
+// resolved e.
+func (im *InterceptManager) guarded(ctx context.Context, tabID string, e *fetch.EventRequestPaused) bool {
+	im.mu.Lock()
+	var guards []RequestGuard
+	if ti := im.tabs[tabID]; ti != nil {
+		for _, g := range ti.guards {
+			guards = append(guards, g.check)
+		}
+	}
+	im.mu.Unlock()
+	for _, check := range guards {
+		if check(ctx, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (im *InterceptManager) match(tabID, url, method, resourceType string) *InterceptRule {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	ti := im.tabs[tabID]
+	if ti == nil {
+		return nil
+	}
+	for _, r := range ti.rules {
+		if r.Matches(url, method, resourceType) {
+			r.Hits++
+			matched := *r
+			return &matched
+		}
+	}
+	return nil
+}
+
+func (im *InterceptManager) handlePaused(tabCtx context.Context, tabID string, e *fetch.EventRequestPaused) {
+	c := chromedp.FromContext(tabCtx)
+	if c == nil || c.Target == nil {
+		return
+	}
+	ctx := cdp.WithExecutor(tabCtx, c.Target)
+	if im.guarded(ctx, tabID, e) {
+		return
+	}
+
+	var url, method string
+	if e.Request != nil {
+		url, method = e.Request.URL, e.Request.Method
+	}
+	rule := im.match(tabID, url, method, e.ResourceType.String())
+	if rule == nil {
+		if replay := im.Replay(tabID); replay != nil {
+			replay.serve(ctx, tabID, e)
+			return
+		}
+		_ = fetch.ContinueRequest(e.RequestID).Do(ctx)
+		return
+	}
+
+	if rule.DelayMs > 0 {
+		select {
+		case <-time.After(time.Duration(rule.DelayMs) * time.Millisecond):
+		case <-tabCtx.Done():
+			return
+		}
+	}
+
+	var err error
+	switch rule.Action {
+	case InterceptFulfill:
+		body := rule.Body
+		if !rule.BodyBase64 {
+			body = base64.StdEncoding.EncodeToString([]byte(body))
+		}
+		p := fetch.FulfillRequest(e.RequestID, int64(rule.Status)).WithResponseHeaders(headerEntries(rule.Headers))
+		if body != "" {
+			p = p.WithBody(body)
+		}
+		err = p.Do(ctx)
+	case InterceptAbort:
+		err = fetch.FailRequest(e.RequestID, network.ErrorReason(rule.ErrorReason)).Do(ctx)
+	default:
+		p := fetch.ContinueRequest(e.RequestID)
+		if len(rule.RequestHeaders) > 0 {
+			p = p.WithHeaders(mergeRequestHeaders(e.Request, rule.RequestHeaders))
+		}
+		err = p.Do(ctx)
+	}
+	if err != nil {
+		slog.Debug("intercept rule failed", "tabId", tabID, "rule", rule.ID, "err", err)
+	}
+}
+
+func headerEntries(headers map[string]string) []*fetch.HeaderEntry {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]*fetch.HeaderEntry, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, &fetch.HeaderEntry{Name: k, Value: headers[k]})
+	}
+	return out
+}
+
+// mergeRequestHeaders overlays overrides onto the original request headers.
+// Fetch.continueRequest replaces the full header set, so untouched headers
+// must be carried over. An empty override value removes the header.
+func mergeRequestHeaders(req *network.Request, overrides map[string]string) []*fetch.HeaderEntry {
+	merged := make(map[string]string)
+	if req != nil {
+		for k, v := range req.Headers {
+			if s, ok := v.(string); ok {
+				merged[k] = s
+			}
+		}
+	}
+	for k, v := range overrides {
+		for existing := range merged {
+			if strings.EqualFold(existing, k) {
+				delete(merged, existing)
+			}
+		}
+		if v != "" {
+			merged[k] = v
+		}
+	}
+	return headerEntries(merged)
+}
+
+// generateRuleID produces a random rule ID in the format rule_XXXXXXXX.
+func generateRuleID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("rule_%08x", time.Now().UnixNano()&0xFFFFFFFF)
+	}
+	return "rule_" + hex.EncodeToString(b)
+}
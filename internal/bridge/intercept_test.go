@@ -0,0 +1,223 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	bridgecdpops "github.com/pinchtab/pinchtab/internal/bridge/cdpops"
+)
+
+func TestMatchURLGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"*", "https://example.com/", true},
+		{"https://example.com/*", "https://example.com/api/users", true},
+		{"*/api/*", "https://example.com/api/users", true},
+		{"*/api/*", "https://example.com/static/app.js", false},
+		{"*.png", "https://cdn.example.com/a/b.png", true},
+		{"*.png", "https://cdn.example.com/a/b.png?v=1", false},
+		{"https://example.com/v?/*", "https://example.com/v2/items", true},
+		{"https://example.com/v?/*", "https://example.com/v10/items", false},
+		{"https://example.com/", "https://example.com/", true},
+		{"https://example.com/", "https://example.com/x", false},
+	}
+	for _, tt := range tests {
+		if got := MatchURLGlob(tt.pattern, tt.url); got != tt.want {
+			t.Errorf("MatchURLGlob(%q, %q) = %v, want %v", tt.pattern, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestInterceptRuleValidate(t *testing.T) {
+	r := InterceptRule{Body: "hello"}
+	if err := r.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Action != InterceptFulfill || r.Status != 200 || r.URLPattern != "*" {
+		t.Errorf("expected inferred fulfill/200/*, got %q/%d/%q", r.Action, r.Status, r.URLPattern)
+	}
+
+	r = InterceptRule{Action: "ABORT"}
+	if err := r.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Action != InterceptAbort || r.ErrorReason != string(network.ErrorReasonFailed) {
+		t.Errorf("expected abort with Failed, got %q/%q", r.Action, r.ErrorReason)
+	}
+
+	r = InterceptRule{Method: "post"}
+	if err := r.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Action != InterceptContinue || r.Method != "POST" {
+		t.Errorf("expected continue/POST, got %q/%q", r.Action, r.Method)
+	}
+
+	invalid := []InterceptRule{
+		{Action: "redirect"},
+		{Action: "fulfill", Status: 700},
+		{Action: "abort", ErrorReason: "NotAReason"},
+		{Action: "fulfill", Body: "!!", BodyBase64: true},
+		{DelayMs: -1},
+		{DelayMs: 120000},
+	}
+	for i, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("case %d: expected validation error for %+v", i, r)
+		}
+	}
+}
+
+func TestInterceptRuleMatches(t *testing.T) {
+	r := InterceptRule{URLPattern: "*/api/*", Method: "POST", ResourceType: "XHR"}
+	if !r.Matches("https://x.test/api/save", "POST", "XHR") {
+		t.Error("expected match")
+	}
+	if !r.Matches("https://x.test/api/save", "post", "xhr") {
+		t.Error("expected case-insensitive method and type match")
+	}
+	if r.Matches("https://x.test/api/save", "GET", "XHR") {
+		t.Error("expected method mismatch")
+	}
+	if r.Matches("https://x.test/api/save", "POST", "Document") {
+		t.Error("expected resource type mismatch")
+	}
+	if r.Matches("https://x.test/home", "POST", "XHR") {
+		t.Error("expected url mismatch")
+	}
+}
+
+func TestInterceptManagerRulesAndForget(t *testing.T) {
+	im := NewInterceptManager()
+	im.tabs["tab1"] = &tabIntercept{rules: []*InterceptRule{
+		{ID: "rule_a", URLPattern: "*/a", Action: InterceptContinue},
+		{ID: "rule_b", URLPattern: "*", Action: InterceptAbort},
+	}}
+
+	if got := im.match("tab1", "https://x.test/a", "GET", "Document"); got == nil || got.ID != "rule_a" {
+		t.Fatalf("expected first matching rule rule_a, got %+v", got)
+	}
+	if got := im.match("tab1", "https://x.test/b", "GET", "Document"); got == nil || got.ID != "rule_b" {
+		t.Fatalf("expected fallback rule rule_b, got %+v", got)
+	}
+
+	rules := im.Rules("tab1")
+	if len(rules) != 2 || rules[0].Hits != 1 || rules[1].Hits != 1 {
+		t.Errorf("expected hit counts of 1, got %+v", rules)
+	}
+	if !im.Active("tab1") {
+		t.Error("expected tab1 active")
+	}
+
+	im.Forget("tab1")
+	if im.Active("tab1") || len(im.Rules("tab1")) != 0 {
+		t.Error("expected rules dropped after Forget")
+	}
+}
+
+func TestMergeRequestHeaders(t *testing.T) {
+	req := &network.Request{Headers: network.Headers{
+		"Accept":        "text/html",
+		"Authorization": "Bearer old",
+		"X-Drop":        "1",
+	}}
+	entries := mergeRequestHeaders(req, map[string]string{
+		"authorization": "Bearer new",
+		"x-drop":        "",
+		"X-Added":       "yes",
+	})
+
+	got := map[string]string{}
+	for _, e := range entries {
+		got[e.Name] = e.Value
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 headers, got %v", got)
+	}
+	if got["Accept"] != "text/html" || got["authorization"] != "Bearer new" || got["X-Added"] != "yes" {
+		t.Errorf("unexpected merged headers: %v", got)
+	}
+	if _, ok := got["X-Drop"]; ok {
+		t.Error("expected X-Drop removed")
+	}
+}
+
+func TestNavigateWithRedirectLimit_KeepsRulesOnFailure(t *testing.T) {
+	im := NewInterceptManager()
+	var calls []bool
+	im.fetchDomain = func(_ context.Context, _ string, enable bool) error {
+		calls = append(calls, enable)
+		return nil
+	}
+	ctx := context.Background()
+	if _, err := im.AddRules(ctx, "tab1", []InterceptRule{{ID: "rule_a", URLPattern: "*/api/*", Action: InterceptAbort}}); err != nil {
+		t.Fatalf("AddRules: %v", err)
+	}
+
+	var ruleSeen, redirectBlocked bool
+	old := navigateWithLimiter
+	navigateWithLimiter = func(ctx context.Context, url string, limiter *bridgecdpops.RedirectLimiter) error {
+		// The page's own request goes on to the rules.
+		api := &fetch.EventRequestPaused{RequestID: "r1", Request: &network.Request{URL: "https://x.test/api/data", Method: "GET"}}
+		if !im.guarded(ctx, "tab1", api) {
+			ruleSeen = im.match("tab1", api.Request.URL, api.Request.Method, "XHR") != nil
+		}
+		for i := 0; i < 3; i++ {
+			hop := &fetch.EventRequestPaused{RequestID: "r2", RedirectedRequestID: "r2", Request: &network.Request{URL: url}}
+			redirectBlocked = im.guarded(ctx, "tab1", hop)
+		}
+		return limiter.Err()
+	}
+	defer func() { navigateWithLimiter = old }()
+
+	err := im.NavigateWithRedirectLimit(ctx, ctx, "tab1", "https://x.test/loop", 2)
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("expected ErrTooManyRedirects, got %v", err)
+	}
+	if !ruleSeen {
+		t.Error("requests during a redirect-limited navigation must still reach the rules")
+	}
+	if !redirectBlocked {
+		t.Error("expected the third redirect to be blocked by the guard")
+	}
+	if !im.Active("tab1") || len(im.Rules("tab1")) != 1 {
+		t.Fatal("a failed navigation must leave the tab's rules installed")
+	}
+	for _, enable := range calls {
+		if !enable {
+			t.Fatalf("Fetch must stay enabled while rules are active, calls=%v", calls)
+		}
+	}
+	im.mu.Lock()
+	guards := len(im.tabs["tab1"].guards)
+	im.mu.Unlock()
+	if guards != 0 {
+		t.Errorf("expected the redirect guard to be removed, %d left", guards)
+	}
+}
+
+func TestAddGuard_DisablesFetchWhenLastUserLeaves(t *testing.T) {
+	im := NewInterceptManager()
+	var calls []bool
+	im.fetchDomain = func(_ context.Context, _ string, enable bool) error {
+		calls = append(calls, enable)
+		return nil
+	}
+	remove, err := im.AddGuard(context.Background(), "tab1", func(context.Context, *fetch.EventRequestPaused) bool { return false })
+	if err != nil {
+		t.Fatalf("AddGuard: %v", err)
+	}
+	if im.Active("tab1") {
+		t.Error("a guard alone must not report the tab as intercepted")
+	}
+	remove()
+	if len(calls) != 2 || !calls[0] || calls[1] {
+		t.Fatalf("expected enable then disable, got %v", calls)
+	}
+}
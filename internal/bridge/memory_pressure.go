@@ -0,0 +1,267 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	bridgeobserve "github.com/pinchtab/pinchtab/internal/bridge/observe"
+	internalurls "github.com/pinchtab/pinchtab/internal/urls"
+)
+
+const (
+	defaultMemoryPressureInterval = 15 * time.Second
+	// discardMinIdle keeps recently used tabs, which an agent is likely
+	// still working with, out of reach of the discarder.
+	discardMinIdle = time.Minute
+	discardTimeout = 10 * time.Second
+)
+
+// discardDecision is one tab the memory-pressure watcher decided to discard.
+type discardDecision struct {
+	tabID  string
+	reason string
+}
+
+// StartMemoryPressureWatcher starts discarding idle tabs when the configured
+// memory-pressure thresholds are crossed. It does nothing when neither
+// memoryPressure.tabHeapMB nor memoryPressure.discardAboveMB is set, and
+// stops with the browser.
+func (tm *TabManager) StartMemoryPressureWatcher() {
+	if tm == nil || tm.browserCtx == nil || tm.config == nil {
+		return
+	}
+	if tm.config.MemoryPressureTabHeapMB <= 0 && tm.config.MemoryPressureDiscardMB <= 0 {
+		return
+	}
+	tm.pressureOnce.Do(func() {
+		interval := tm.config.MemoryPressureInterval
+		if interval <= 0 {
+			interval = defaultMemoryPressureInterval
+		}
+		go tm.watchMemoryPressure(tm.browserCtx, interval)
+	})
+}
+
+func (tm *TabManager) watchMemoryPressure(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, d := range tm.planDiscards(ctx, time.Now()) {
+			if err := tm.discardTab(ctx, d.tabID, d.reason); err != nil {
+				slog.Warn("memory pressure: discard tab failed", "id", d.tabID, "err", err)
+			}
+		}
+	}
+}
+
+// planDiscards picks the tabs to discard on one tick. Every idle tab whose
+// JS heap is over tabHeapMB is discarded. Otherwise, when the browser as a
+// whole is over discardAboveMB, the least recently used idle tab goes, one
+// per tick so the next measurement sees the effect.
+func (tm *TabManager) planDiscards(ctx context.Context, now time.Time) []discardDecision {
+	idle := tm.idleTabs(now)
+	if len(idle) == 0 {
+		return nil
+	}
+
+	var plan []discardDecision
+	if limit := tm.config.MemoryPressureTabHeapMB; limit > 0 {
+		for _, id := range idle {
+			used, err := tm.measureTabHeap(ctx, id)
+			if err != nil || used <= float64(limit) {
+				continue
+			}
+			plan = append(plan, discardDecision{tabID: id, reason: fmt.Sprintf("JS heap %.0f MB over tabHeapMB=%d", used, limit)})
+		}
+	}
+	if limit := tm.config.MemoryPressureDiscardMB; limit > 0 && len(plan) == 0 {
+		used, err := tm.measureBrowserMemory()
+		if err == nil && used > float64(limit) {
+			plan = append(plan, discardDecision{tabID: idle[0], reason: fmt.Sprintf("browser memory %.0f MB over discardAboveMB=%d", used, limit)})
+		}
+	}
+	return plan
+}
+
+// idleTabs returns the tabs that may be discarded, least recently used
+// first. The current tab, tabs used within discardMinIdle and tabs already
+// discarded are never candidates.
+func (tm *TabManager) idleTabs(now time.Time) []string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	type candidate struct {
+		id       string
+		lastUsed time.Time
+	}
+	var candidates []candidate
+	for id, entry := range tm.tabs {
+		if id == tm.currentTab || entry.Discarded || entry.Ctx == nil {
+			continue
+		}
+		lastUsed := entry.LastUsed
+		if lastUsed.IsZero() {
+			lastUsed = entry.CreatedAt
+		}
+		if now.Sub(lastUsed) < discardMinIdle {
+			continue
+		}
+		candidates = append(candidates, candidate{id: id, lastUsed: lastUsed})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].lastUsed.Equal(candidates[j].lastUsed) {
+			return candidates[i].id < candidates[j].id
+		}
+		return candidates[i].lastUsed.Before(candidates[j].lastUsed)
+	})
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.id
+	}
+	return ids
+}
+
+func (tm *TabManager) measureTabHeap(ctx context.Context, tabID string) (float64, error) {
+	if tm.tabHeapMB != nil {
+		return tm.tabHeapMB(ctx, tabID)
+	}
+	tm.mu.RLock()
+	entry, ok := tm.tabs[tabID]
+	tm.mu.RUnlock()
+	if !ok || entry.Ctx == nil {
+		return 0, fmt.Errorf("tab %s not found", tabID)
+	}
+	tCtx, cancel := context.WithTimeout(entry.Ctx, discardTimeout)
+	defer cancel()
+	var used float64
+	if err := chromedp.Run(tCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		used, _, _, _, err = runtime.GetHeapUsage().Do(ctx)
+		return err
+	})); err != nil {
+		return 0, err
+	}
+	return used / (1024 * 1024), nil
+}
+
+func (tm *TabManager) measureBrowserMemory() (float64, error) {
+	if tm.browserMemoryMB != nil {
+		return tm.browserMemoryMB()
+	}
+	mem, err := bridgeobserve.GetAggregatedMemoryMetrics(tm.browserCtx)
+	if err != nil || mem == nil {
+		return 0, err
+	}
+	return mem.MemoryMB, nil
+}
+
+// discardTab releases a tab's page memory while keeping the tab: it
+// remembers the URL and title, navigates to about:blank and freezes the
+// page. The tab ID stays valid and the page is reloaded on next access.
+func (tm *TabManager) discardTab(ctx context.Context, tabID, reason string) error {
+	tm.mu.RLock()
+	entry, ok := tm.tabs[tabID]
+	tm.mu.RUnlock()
+	if !ok || entry.Ctx == nil {
+		return fmt.Errorf("tab %s not found", tabID)
+	}
+
+	return tm.Execute(ctx, tabID, func(context.Context) error {
+		tCtx, cancel := context.WithTimeout(entry.Ctx, discardTimeout)
+		defer cancel()
+
+		var url, title string
+		if err := chromedp.Run(tCtx, chromedp.Location(&url), chromedp.Title(&title)); err != nil {
+			return fmt.Errorf("read location: %w", err)
+		}
+		if url == "" || IsTransientURL(url) {
+			return nil
+		}
+
+		tm.mu.Lock()
+		if tm.tabs[tabID] != entry || entry.Discarded || tm.currentTab == tabID {
+			tm.mu.Unlock()
+			return nil
+		}
+		entry.Discarded = true
+		entry.DiscardedURL = url
+		entry.DiscardedTitle = title
+		tm.mu.Unlock()
+
+		if err := chromedp.Run(tCtx,
+			chromedp.Navigate("about:blank"),
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				return page.SetWebLifecycleState(page.SetWebLifecycleStateStateFrozen).Do(ctx)
+			}),
+		); err != nil {
+			tm.mu.Lock()
+			entry.Discarded = false
+			entry.DiscardedURL = ""
+			entry.DiscardedTitle = ""
+			tm.mu.Unlock()
+			return err
+		}
+		tm.DeleteRefCache(tabID)
+		slog.Info("discarded idle tab under memory pressure", "id", tabID, "url", internalurls.RedactForLog(url), "reason", reason)
+		return nil
+	})
+}
+
+// restoreDiscardedTab unfreezes a discarded tab and loads its page again.
+// It is a no-op for tabs that were not discarded.
+func (tm *TabManager) restoreDiscardedTab(tabID string) error {
+	tm.mu.Lock()
+	entry, ok := tm.tabs[tabID]
+	if !ok || !entry.Discarded {
+		tm.mu.Unlock()
+		return nil
+	}
+	url := entry.DiscardedURL
+	entry.Discarded = false
+	entry.DiscardedURL = ""
+	entry.DiscardedTitle = ""
+	tm.mu.Unlock()
+
+	tCtx, cancel := context.WithTimeout(entry.Ctx, 30*time.Second)
+	defer cancel()
+	if err := chromedp.Run(tCtx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return page.SetWebLifecycleState(page.SetWebLifecycleStateStateActive).Do(ctx)
+		}),
+		chromedp.Navigate(url),
+	); err != nil {
+		return fmt.Errorf("restore discarded tab %s: %w", tabID, err)
+	}
+	slog.Info("restored discarded tab", "id", tabID, "url", internalurls.RedactForLog(url))
+	return nil
+}
+
+// withDiscardedPages reports discarded tabs under the page they had before
+// they were discarded, so tab listings and saved sessions are unaffected.
+func (tm *TabManager) withDiscardedPages(pages []*target.Info) []*target.Info {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	for i, t := range pages {
+		entry, ok := tm.tabs[tm.idMgr.TabIDFromCDPTarget(string(t.TargetID))]
+		if !ok || !entry.Discarded {
+			continue
+		}
+		info := *t
+		info.URL = entry.DiscardedURL
+		info.Title = entry.DiscardedTitle
+		pages[i] = &info
+	}
+	return pages
+}
@@ -0,0 +1,89 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func newPressureTabManager(cfg *config.RuntimeConfig, now time.Time) *TabManager {
+	tm := NewTabManager(context.Background(), cfg, nil, nil, nil)
+	ctx := context.Background()
+	tm.tabs["old"] = &TabEntry{Ctx: ctx, LastUsed: now.Add(-10 * time.Minute)}
+	tm.tabs["older"] = &TabEntry{Ctx: ctx, LastUsed: now.Add(-20 * time.Minute)}
+	tm.tabs["recent"] = &TabEntry{Ctx: ctx, LastUsed: now.Add(-10 * time.Second)}
+	tm.tabs["current"] = &TabEntry{Ctx: ctx, LastUsed: now.Add(-30 * time.Minute)}
+	tm.tabs["gone"] = &TabEntry{Ctx: ctx, LastUsed: now.Add(-40 * time.Minute), Discarded: true}
+	tm.currentTab = "current"
+	return tm
+}
+
+func TestIdleTabs_LRUOrderSkipsActiveTabs(t *testing.T) {
+	now := time.Now()
+	tm := newPressureTabManager(&config.RuntimeConfig{}, now)
+
+	got := tm.idleTabs(now)
+	if len(got) != 2 || got[0] != "older" || got[1] != "old" {
+		t.Fatalf("idleTabs() = %v, want [older old]", got)
+	}
+}
+
+func TestPlanDiscards(t *testing.T) {
+	now := time.Now()
+
+	t.Run("tab heap over limit", func(t *testing.T) {
+		tm := newPressureTabManager(&config.RuntimeConfig{MemoryPressureTabHeapMB: 200, MemoryPressureDiscardMB: 1000}, now)
+		heaps := map[string]float64{"old": 350, "older": 50}
+		tm.tabHeapMB = func(_ context.Context, id string) (float64, error) { return heaps[id], nil }
+		tm.browserMemoryMB = func() (float64, error) { return 5000, nil }
+
+		plan := tm.planDiscards(context.Background(), now)
+		if len(plan) != 1 || plan[0].tabID != "old" {
+			t.Fatalf("expected only the heavy tab to be discarded, got %+v", plan)
+		}
+	})
+
+	t.Run("browser over limit discards LRU tab", func(t *testing.T) {
+		tm := newPressureTabManager(&config.RuntimeConfig{MemoryPressureDiscardMB: 1000}, now)
+		tm.browserMemoryMB = func() (float64, error) { return 1500, nil }
+
+		plan := tm.planDiscards(context.Background(), now)
+		if len(plan) != 1 || plan[0].tabID != "older" {
+			t.Fatalf("expected the LRU tab to be discarded, got %+v", plan)
+		}
+	})
+
+	t.Run("under limits", func(t *testing.T) {
+		tm := newPressureTabManager(&config.RuntimeConfig{MemoryPressureTabHeapMB: 200, MemoryPressureDiscardMB: 1000}, now)
+		tm.tabHeapMB = func(context.Context, string) (float64, error) { return 10, nil }
+		tm.browserMemoryMB = func() (float64, error) { return 500, nil }
+
+		if plan := tm.planDiscards(context.Background(), now); len(plan) != 0 {
+			t.Fatalf("expected nothing to discard, got %+v", plan)
+		}
+	})
+}
+
+func TestWithDiscardedPages(t *testing.T) {
+	tm := NewTabManager(context.Background(), &config.RuntimeConfig{}, nil, nil, nil)
+	tm.tabs["T1"] = &TabEntry{Discarded: true, DiscardedURL: "https://example.com/", DiscardedTitle: "Example"}
+	tm.tabs["T2"] = &TabEntry{}
+
+	live := &target.Info{TargetID: "T1", Type: TargetTypePage, URL: "about:blank"}
+	pages := tm.withDiscardedPages([]*target.Info{
+		live,
+		{TargetID: "T2", Type: TargetTypePage, URL: "https://pinchtab.com/"},
+	})
+	if pages[0].URL != "https://example.com/" || pages[0].Title != "Example" {
+		t.Fatalf("discarded tab should report its original page, got %+v", pages[0])
+	}
+	if live.URL != "about:blank" {
+		t.Fatal("the CDP target info must not be modified in place")
+	}
+	if pages[1].URL != "https://pinchtab.com/" {
+		t.Fatalf("live tabs must be reported as is, got %+v", pages[1])
+	}
+}
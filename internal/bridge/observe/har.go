@@ -0,0 +1,251 @@
+package observe
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// HARVersion is the HTTP Archive spec version produced by BuildHAR.
+const HARVersion = "1.2"
+
+// HAR is the root object of an HTTP Archive (HAR 1.2).
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog holds the exported entries.
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Pages   []HARPage  `json:"pages,omitempty"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator identifies the tool that produced the archive.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HARPage is accepted on import; BuildHAR does not emit pages.
+type HARPage struct {
+	StartedDateTime string `json:"startedDateTime"`
+	ID              string `json:"id"`
+	Title           string `json:"title"`
+}
+
+// HAREntry is a single request/response pair.
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ResourceType    string      `json:"_resourceType,omitempty"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+// HARRequest describes the request half of an entry.
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARResponse describes the response half of an entry.
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARNameValue is a header, cookie or query parameter pair.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData holds a request body.
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent holds a response body. Binary bodies use Encoding "base64".
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings is required by the spec; only the total wait is known from the
+// network buffer, so unavailable phases are -1.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HARBody is a response body fetched for export.
+type HARBody struct {
+	Body          string
+	Base64Encoded bool
+}
+
+// BuildHAR converts captured network entries into a HAR 1.2 archive.
+// bodies is keyed by request ID and may be nil.
+func BuildHAR(entries []NetworkEntry, bodies map[string]HARBody, creator HARCreator) HAR {
+	out := make([]HAREntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, harEntry(e, bodies[e.RequestID]))
+	}
+	if creator.Name == "" {
+		creator.Name = "pinchtab"
+	}
+	return HAR{Log: HARLog{Version: HARVersion, Creator: creator, Entries: out}}
+}
+
+func harEntry(e NetworkEntry, body HARBody) HAREntry {
+	started := e.StartTime
+	if started.IsZero() {
+		started = time.Now()
+	}
+	reqHeaders := harHeaders(e.RequestHeaders)
+	respHeaders := harHeaders(e.ResponseHeaders)
+
+	entry := HAREntry{
+		StartedDateTime: started.UTC().Format(time.RFC3339Nano),
+		Time:            e.Duration,
+		Request: HARRequest{
+			Method:      e.Method,
+			URL:         e.URL,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []HARNameValue{},
+			Headers:     reqHeaders,
+			QueryString: harQueryString(e.URL),
+			HeadersSize: -1,
+			BodySize:    int64(len(e.PostData)),
+		},
+		Response: HARResponse{
+			Status:      e.Status,
+			StatusText:  e.StatusText,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []HARNameValue{},
+			Headers:     respHeaders,
+			Content:     HARContent{Size: e.Size, MimeType: e.MimeType},
+			RedirectURL: headerValue(e.ResponseHeaders, "Location"),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings:      HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: e.Duration},
+		ResourceType: e.ResourceType,
+		Error:        e.Error,
+	}
+	if e.Size > 0 {
+		entry.Response.BodySize = e.Size
+	}
+	if e.PostData != "" {
+		mime := headerValue(e.RequestHeaders, "Content-Type")
+		entry.Request.PostData = &HARPostData{MimeType: mime, Text: e.PostData}
+	}
+	if body.Body != "" {
+		if body.Base64Encoded || !utf8.ValidString(body.Body) {
+			text := body.Body
+			if !body.Base64Encoded {
+				text = base64.StdEncoding.EncodeToString([]byte(body.Body))
+			}
+			entry.Response.Content.Text = text
+			entry.Response.Content.Encoding = "base64"
+		} else {
+			entry.Response.Content.Text = body.Body
+			entry.Response.Content.Size = int64(len(body.Body))
+		}
+	}
+	return entry
+}
+
+// ParseHAR decodes and minimally validates a HAR document.
+func ParseHAR(data []byte) (*HAR, error) {
+	var h HAR
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("parse har: %w", err)
+	}
+	if len(h.Log.Entries) == 0 {
+		return nil, fmt.Errorf("har contains no entries")
+	}
+	return &h, nil
+}
+
+// ResponseBody returns the decoded response body bytes of a HAR entry.
+func (e HAREntry) ResponseBody() ([]byte, error) {
+	if e.Response.Content.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(e.Response.Content.Text)
+	}
+	return []byte(e.Response.Content.Text), nil
+}
+
+func harHeaders(headers map[string]string) []HARNameValue {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]HARNameValue, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, HARNameValue{Name: k, Value: headers[k]})
+	}
+	return out
+}
+
+func harQueryString(rawURL string) []HARNameValue {
+	out := []HARNameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return out
+	}
+	q := u.Query()
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range q[k] {
+			out = append(out, HARNameValue{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
@@ -24,6 +24,10 @@ type NetworkBuffer = bridgeobserve.NetworkBuffer
 type NetworkFilter = bridgeobserve.NetworkFilter
 type NetworkMonitor = bridgeobserve.NetworkMonitor
 type MemoryMetrics = bridgeobserve.MemoryMetrics
+type HAR = bridgeobserve.HAR
+type HAREntry = bridgeobserve.HAREntry
+type HARCreator = bridgeobserve.HARCreator
+type HARBody = bridgeobserve.HARBody
 
 func frameIDs(tree rawFrameTree) []string {
 	return bridgeobserve.FrameIDs(tree)
@@ -69,6 +73,14 @@ func matchStatusRange(status int, pattern string) bool {
 	return bridgeobserve.MatchStatusRange(status, pattern)
 }
 
+func BuildHAR(entries []NetworkEntry, bodies map[string]HARBody, creator HARCreator) HAR {
+	return bridgeobserve.BuildHAR(entries, bodies, creator)
+}
+
+func ParseHAR(data []byte) (*HAR, error) {
+	return bridgeobserve.ParseHAR(data)
+}
+
 func GetResponseBodyDirect(ctx context.Context, requestID string) (string, bool, error) {
 	return bridgeobserve.GetResponseBodyDirect(ctx, requestID)
 }
@@ -0,0 +1,142 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrScreenshotTooLarge is returned when a capture would be over
+// ScreenshotOptions.MaxPixels.
+var ErrScreenshotTooLarge = errors.New("screenshot too large")
+
+// ScreenshotClip is a capture rectangle in CSS pixels relative to the
+// top-left corner of the document.
+type ScreenshotClip struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// ScreenshotOptions controls CaptureScreenshot.
+type ScreenshotOptions struct {
+	Format page.CaptureScreenshotFormat
+	// Quality applies to JPEG and WebP.
+	Quality int
+	// Scale multiplies the output resolution; 0 means 1. The image is
+	// Scale times the captured area in CSS pixels (at a device scale
+	// factor of 1).
+	Scale float64
+	// FullPage captures the whole scrollable document instead of the
+	// viewport.
+	FullPage bool
+	// Clip restricts the capture to a document rectangle. It may extend
+	// beyond the viewport.
+	Clip *ScreenshotClip
+	// MaxPixels bounds the output width×height of clipped and full-page
+	// captures, which Chrome would otherwise render at any size. 0 means
+	// no bound.
+	MaxPixels int64
+}
+
+// CaptureScreenshot captures the current tab.
+func CaptureScreenshot(ctx context.Context, opts ScreenshotOptions) ([]byte, error) {
+	format := opts.Format
+	if format == "" {
+		format = page.CaptureScreenshotFormatPng
+	}
+	var buf []byte
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		shot := page.CaptureScreenshot().WithFormat(format)
+		if format != page.CaptureScreenshotFormatPng && opts.Quality > 0 {
+			shot = shot.WithQuality(int64(opts.Quality))
+		}
+
+		clip := opts.Clip
+		if clip == nil && (opts.FullPage || screenshotScale(opts) != 1) {
+			_, _, _, _, visual, content, err := page.GetLayoutMetrics().Do(ctx)
+			if err != nil {
+				return fmt.Errorf("layout metrics: %w", err)
+			}
+			clip = defaultScreenshotClip(opts.FullPage, visual, content)
+		}
+		if clip != nil {
+			vp, err := screenshotViewport(clip, screenshotScale(opts), opts.MaxPixels)
+			if err != nil {
+				return err
+			}
+			shot = shot.WithClip(vp).WithCaptureBeyondViewport(true)
+		}
+
+		var err error
+		buf, err = shot.Do(ctx)
+		return err
+	}))
+	return buf, err
+}
+
+func screenshotScale(opts ScreenshotOptions) float64 {
+	if opts.Scale <= 0 {
+		return 1
+	}
+	return opts.Scale
+}
+
+// defaultScreenshotClip is the capture area when no clip was given: the
+// whole document for full-page shots, otherwise the visible viewport. Scale
+// can only be applied through a clip, so a scaled viewport shot needs one.
+func defaultScreenshotClip(fullPage bool, visual *page.VisualViewport, content *dom.Rect) *ScreenshotClip {
+	if fullPage && content != nil {
+		return &ScreenshotClip{Width: math.Ceil(content.Width), Height: math.Ceil(content.Height)}
+	}
+	if visual == nil {
+		return nil
+	}
+	return &ScreenshotClip{X: visual.PageX, Y: visual.PageY, Width: visual.ClientWidth, Height: visual.ClientHeight}
+}
+
+func screenshotViewport(clip *ScreenshotClip, scale float64, maxPixels int64) (*page.Viewport, error) {
+	if clip.Width <= 0 || clip.Height <= 0 {
+		return nil, fmt.Errorf("clip has no area")
+	}
+	if pixels := clip.Width * clip.Height * scale * scale; maxPixels > 0 && pixels > float64(maxPixels) {
+		return nil, fmt.Errorf("%w: %.0fx%.0f at scale %g is over %d pixels", ErrScreenshotTooLarge, clip.Width, clip.Height, scale, maxPixels)
+	}
+	return &page.Viewport{X: clip.X, Y: clip.Y, Width: clip.Width, Height: clip.Height, Scale: scale}, nil
+}
+
+// ElementScreenshotClip scrolls the element into view and returns its box
+// in document coordinates.
+func ElementScreenshotClip(ctx context.Context, backendNodeID int64) (*ScreenshotClip, error) {
+	res, err := ScrollIntoViewAndGetBox(ctx, backendNodeID)
+	if err != nil {
+		return nil, err
+	}
+	box, _ := res["box"].(map[string]any)
+	w, _ := box["width"].(float64)
+	h, _ := box["height"].(float64)
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("element has no visible box")
+	}
+	x, _ := box["x"].(float64)
+	y, _ := box["y"].(float64)
+
+	var pageX, pageY float64
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, _, _, _, vv, _, err := page.GetLayoutMetrics().Do(ctx)
+		if err != nil {
+			return err
+		}
+		pageX, pageY = vv.PageX, vv.PageY
+		return nil
+	})); err != nil {
+		return nil, fmt.Errorf("layout metrics: %w", err)
+	}
+	return &ScreenshotClip{X: x + pageX, Y: y + pageY, Width: w, Height: h}, nil
+}
@@ -0,0 +1,61 @@
+package bridge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/page"
+)
+
+func TestScreenshotViewport_Scale(t *testing.T) {
+	visual := &page.VisualViewport{PageX: 0, PageY: 300, ClientWidth: 800, ClientHeight: 600}
+	content := &dom.Rect{Width: 800, Height: 2399.2}
+
+	tests := []struct {
+		name       string
+		opts       ScreenshotOptions
+		wantY      float64
+		wantPixels [2]int
+	}{
+		{"viewport scaled", ScreenshotOptions{Scale: 2}, 300, [2]int{1600, 1200}},
+		{"viewport half", ScreenshotOptions{Scale: 0.5}, 300, [2]int{400, 300}},
+		{"full page scaled", ScreenshotOptions{Scale: 1.5, FullPage: true}, 0, [2]int{1200, 3600}},
+		{"clip scaled", ScreenshotOptions{Scale: 3, Clip: &ScreenshotClip{X: 10, Y: 20, Width: 100, Height: 50}}, 20, [2]int{300, 150}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clip := tt.opts.Clip
+			if clip == nil {
+				clip = defaultScreenshotClip(tt.opts.FullPage, visual, content)
+			}
+			vp, err := screenshotViewport(clip, screenshotScale(tt.opts), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if vp.Y != tt.wantY {
+				t.Errorf("clip Y = %v, want %v", vp.Y, tt.wantY)
+			}
+			got := [2]int{int(vp.Width * vp.Scale), int(vp.Height * vp.Scale)}
+			if got != tt.wantPixels {
+				t.Errorf("output size = %v, want %v", got, tt.wantPixels)
+			}
+		})
+	}
+}
+
+func TestScreenshotViewport_NoArea(t *testing.T) {
+	if _, err := screenshotViewport(&ScreenshotClip{Width: 10}, 1, 0); err == nil {
+		t.Fatal("expected an error for a clip without area")
+	}
+}
+
+func TestScreenshotViewport_MaxPixels(t *testing.T) {
+	long := &ScreenshotClip{Width: 1280, Height: 20000}
+	if _, err := screenshotViewport(long, 1, 40_000_000); err != nil {
+		t.Fatalf("a 1280x20000 capture at scale 1 should pass: %v", err)
+	}
+	if _, err := screenshotViewport(long, 4, 40_000_000); !errors.Is(err, ErrScreenshotTooLarge) {
+		t.Fatalf("the same page at scale 4 should be refused, got %v", err)
+	}
+}
@@ -0,0 +1,392 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+// ErrScopedNoMatch is returned by ResolveScopedSelector when the frame or
+// element is not (yet) present, as opposed to an invalid selector.
+var ErrScopedNoMatch = errors.New("no matching element")
+
+// frameInfo describes an <iframe> or <frame> element for matchFrame.
+type frameInfo struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+	URL  string `json:"url"`
+	Src  string `json:"src"`
+}
+
+// scopeFramesFn lists the frame elements under a document, including those
+// inside shadow roots. With i < 0 it returns their descriptions, otherwise
+// the i-th element.
+const scopeFramesFn = `function(i) {
+	const frames = [];
+	const walk = (root) => {
+		for (const el of root.querySelectorAll("*")) {
+			if (el.tagName === "IFRAME" || el.tagName === "FRAME") frames.push(el);
+			if (el.shadowRoot) walk(el.shadowRoot);
+		}
+	};
+	walk(this);
+	if (i >= 0) return frames[i] || null;
+	return frames.map((el) => {
+		let url = "";
+		try { url = el.contentWindow.location.href; } catch (e) {}
+		return {name: el.name || "", id: el.id || "", url: url || el.src || "", src: el.src || ""};
+	});
+}`
+
+// shadowQueryJS defines the >>> piercing shared by scopeQueryFn and the
+// extract scripts. queryAll matches sel under root and, when deep, under
+// every shadow root below it. pierceRoots follows the pierce segments from
+// root: each selects shadow hosts, matched through every shadow root below
+// the previous ones, and yields their shadow roots and light DOM.
+const shadowQueryJS = `
+	const queryAll = (root, sel, deep) => {
+		const out = [...root.querySelectorAll(sel)];
+		if (deep) {
+			for (const el of root.querySelectorAll("*")) {
+				if (el.shadowRoot) out.push(...queryAll(el.shadowRoot, sel, true));
+			}
+		}
+		return out;
+	};
+	const pierceRoots = (root, pierce) => {
+		let roots = [root];
+		for (const host of pierce || []) {
+			const next = [];
+			for (const r of roots) {
+				for (const el of queryAll(r, host, true)) {
+					if (el.shadowRoot) next.push(el.shadowRoot);
+					next.push(el);
+				}
+			}
+			roots = next;
+		}
+		return roots;
+	};
+`
+
+// scopeQueryFn finds the first element for a selector in a document. Each
+// pierce segment selects shadow hosts; later segments and the final selector
+// are matched through every shadow root below them.
+const scopeQueryFn = `function(pierce, kind, value) {` + shadowQueryJS + `
+	const deep = pierce.length > 0;
+	const all = (root, sel) => queryAll(root, sel, deep);
+	const text = (el) => el.innerText || el.textContent || "";
+	const query = (root) => {
+		if (kind === "xpath") {
+			const doc = root.ownerDocument || root;
+			return doc.evaluate(value, root, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue;
+		}
+		if (kind === "text") {
+			for (const el of all(root, "*")) {
+				if (!text(el).includes(value)) continue;
+				const kids = [...el.children, ...(el.shadowRoot ? el.shadowRoot.children : [])];
+				if (!kids.some((c) => text(c).includes(value))) return el;
+			}
+			return null;
+		}
+		return all(root, value)[0] || null;
+	};
+	for (const r of pierceRoots(this, pierce)) {
+		const el = query(r);
+		if (el) return el;
+	}
+	return null;
+}`
+
+// ResolveScopedSelector resolves a selector with frame: scoping and/or >>>
+// shadow piercing to a backend node ID. It returns the context the node
+// belongs to: ctx itself for the main frame and same-process iframes, or a
+// context attached to the frame's own target for cross-origin
+// (out-of-process) iframes. Actions on the node must run in that context;
+// it is detached when ctx is done.
+func ResolveScopedSelector(ctx context.Context, sel selector.Selector) (context.Context, int64, error) {
+	if err := sel.Validate(); err != nil {
+		return nil, 0, err
+	}
+	switch sel.Kind {
+	case selector.KindCSS, selector.KindXPath, selector.KindText:
+	default:
+		return nil, 0, fmt.Errorf("%s selectors cannot be scoped", sel.Kind)
+	}
+
+	scope := ctx
+	doc, err := scopeDocument(scope)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, spec := range sel.Frames {
+		scope, doc, err = enterFrame(ctx, scope, doc, spec)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	pierce := sel.Pierce
+	if pierce == nil {
+		pierce = []string{}
+	}
+	var nodeID int64
+	err = chromedp.Run(scope, chromedp.ActionFunc(func(c context.Context) error {
+		obj, err := callOnObject(c, doc, scopeQueryFn, pierce, string(sel.Kind), sel.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sel, err)
+		}
+		if obj == "" {
+			return fmt.Errorf("%s: %w", sel, ErrScopedNoMatch)
+		}
+		id, _, _, err := describeObject(c, obj)
+		nodeID = id
+		return err
+	}))
+	if err != nil {
+		return nil, 0, err
+	}
+	return scope, nodeID, nil
+}
+
+// enterFrame finds the frame matching spec under doc and returns the context
+// and document object of its content.
+func enterFrame(parent, scope context.Context, doc, spec string) (context.Context, string, error) {
+	var (
+		contentDoc int64
+		frameID    string
+	)
+	err := chromedp.Run(scope, chromedp.ActionFunc(func(c context.Context) error {
+		raw, err := callOnValue(c, doc, scopeFramesFn, -1)
+		if err != nil {
+			return fmt.Errorf("list frames: %w", err)
+		}
+		var frames []frameInfo
+		if err := json.Unmarshal(raw, &frames); err != nil {
+			return err
+		}
+		idx := matchFrame(frames, spec)
+		if idx < 0 {
+			return fmt.Errorf("frame %q: %w", spec, ErrScopedNoMatch)
+		}
+		obj, err := callOnObject(c, doc, scopeFramesFn, idx)
+		if err != nil || obj == "" {
+			return fmt.Errorf("frame %q: %w", spec, ErrScopedNoMatch)
+		}
+		_, contentDoc, frameID, err = describeObject(c, obj)
+		return err
+	}))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if contentDoc != 0 {
+		var obj string
+		err := chromedp.Run(scope, chromedp.ActionFunc(func(c context.Context) error {
+			var res struct {
+				Object struct {
+					ObjectID string `json:"objectId"`
+				} `json:"object"`
+			}
+			if err := chromedp.FromContext(c).Target.Execute(c, "DOM.resolveNode", map[string]any{
+				"backendNodeId": contentDoc,
+			}, &res); err != nil {
+				return fmt.Errorf("frame %q document: %w", spec, err)
+			}
+			obj = res.Object.ObjectID
+			return nil
+		}))
+		if err != nil {
+			return nil, "", err
+		}
+		return scope, obj, nil
+	}
+	if frameID == "" {
+		return nil, "", fmt.Errorf("frame %q has no document: %w", spec, ErrScopedNoMatch)
+	}
+
+	fctx, err := attachFrameTarget(parent, scope, frameID)
+	if err != nil {
+		return nil, "", err
+	}
+	obj, err := scopeDocument(fctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return fctx, obj, nil
+}
+
+// attachFrameTarget attaches to the target of an out-of-process iframe and
+// detaches once parent is done. chromedp closes a context's target on
+// cancel, and closing an iframe target closes its whole page, so the target
+// ID is cleared first to only detach the session.
+func attachFrameTarget(parent, scope context.Context, frameID string) (context.Context, error) {
+	fctx, cancel := chromedp.NewContext(context.WithoutCancel(scope), chromedp.WithTargetID(target.ID(frameID)))
+	detach := func() {
+		if c := chromedp.FromContext(fctx); c != nil && c.Target != nil {
+			c.Target.TargetID = ""
+		}
+		cancel()
+	}
+	if err := chromedp.Run(fctx); err != nil {
+		detach()
+		return nil, fmt.Errorf("attach to frame %s: %w", frameID, err)
+	}
+	context.AfterFunc(parent, detach)
+
+	// Bound to parent's deadline and cancellation so actions run in the frame
+	// keep the caller's timeout. Cancelling this child does not detach.
+	var (
+		bound       context.Context
+		cancelBound context.CancelFunc
+	)
+	if deadline, ok := parent.Deadline(); ok {
+		bound, cancelBound = context.WithDeadline(fctx, deadline)
+	} else {
+		bound, cancelBound = context.WithCancel(fctx)
+	}
+	context.AfterFunc(parent, cancelBound)
+	return bound, nil
+}
+
+// matchFrame picks the frame for spec: an exact name or id, then an exact
+// URL, then a URL glob when spec contains wildcards, then a URL substring.
+func matchFrame(frames []frameInfo, spec string) int {
+	for i, f := range frames {
+		if f.Name == spec || f.ID == spec {
+			return i
+		}
+	}
+	for i, f := range frames {
+		if f.URL == spec || f.Src == spec {
+			return i
+		}
+	}
+	if strings.ContainsAny(spec, "*?") {
+		for i, f := range frames {
+			if MatchURLGlob(spec, f.URL) || MatchURLGlob(spec, f.Src) {
+				return i
+			}
+		}
+	}
+	for i, f := range frames {
+		if strings.Contains(f.URL, spec) || strings.Contains(f.Src, spec) {
+			return i
+		}
+	}
+	return -1
+}
+
+func scopeDocument(ctx context.Context) (string, error) {
+	var obj string
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
+		var res struct {
+			Result struct {
+				ObjectID string `json:"objectId"`
+			} `json:"result"`
+		}
+		if err := chromedp.FromContext(c).Target.Execute(c, "Runtime.evaluate", map[string]any{
+			"expression": "document",
+		}, &res); err != nil {
+			return fmt.Errorf("resolve document: %w", err)
+		}
+		if res.Result.ObjectID == "" {
+			return fmt.Errorf("document object not found")
+		}
+		obj = res.Result.ObjectID
+		return nil
+	}))
+	return obj, err
+}
+
+type callFunctionResult struct {
+	Result struct {
+		ObjectID string          `json:"objectId"`
+		Subtype  string          `json:"subtype"`
+		Value    json.RawMessage `json:"value"`
+	} `json:"result"`
+	ExceptionDetails *struct {
+		Text      string `json:"text"`
+		Exception struct {
+			Description string `json:"description"`
+		} `json:"exception"`
+	} `json:"exceptionDetails"`
+}
+
+func callOn(ctx context.Context, objectID, fn string, byValue bool, args ...any) (*callFunctionResult, error) {
+	callArgs := make([]map[string]any, len(args))
+	for i, a := range args {
+		callArgs[i] = map[string]any{"value": a}
+	}
+	var res callFunctionResult
+	if err := chromedp.FromContext(ctx).Target.Execute(ctx, "Runtime.callFunctionOn", map[string]any{
+		"functionDeclaration": fn,
+		"objectId":            objectID,
+		"arguments":           callArgs,
+		"returnByValue":       byValue,
+	}, &res); err != nil {
+		return nil, err
+	}
+	if ex := res.ExceptionDetails; ex != nil {
+		msg := ex.Exception.Description
+		if msg == "" {
+			msg = ex.Text
+		}
+		if i := strings.IndexByte(msg, '\n'); i >= 0 {
+			msg = msg[:i]
+		}
+		return nil, errors.New(msg)
+	}
+	return &res, nil
+}
+
+func callOnValue(ctx context.Context, objectID, fn string, args ...any) (json.RawMessage, error) {
+	res, err := callOn(ctx, objectID, fn, true, args...)
+	if err != nil {
+		return nil, err
+	}
+	return res.Result.Value, nil
+}
+
+// callOnObject returns the object ID of the function's result, or "" when
+// it returned null.
+func callOnObject(ctx context.Context, objectID, fn string, args ...any) (string, error) {
+	res, err := callOn(ctx, objectID, fn, false, args...)
+	if err != nil {
+		return "", err
+	}
+	if res.Result.Subtype == "null" {
+		return "", nil
+	}
+	return res.Result.ObjectID, nil
+}
+
+// describeObject returns the backend node ID of a DOM object and, for frame
+// owner elements, the content document's backend node ID or the frame ID
+// when the content lives in another process.
+func describeObject(ctx context.Context, objectID string) (nodeID, contentDoc int64, frameID string, err error) {
+	var res struct {
+		Node struct {
+			BackendNodeID   int64  `json:"backendNodeId"`
+			FrameID         string `json:"frameId"`
+			ContentDocument *struct {
+				BackendNodeID int64 `json:"backendNodeId"`
+			} `json:"contentDocument"`
+		} `json:"node"`
+	}
+	if err := chromedp.FromContext(ctx).Target.Execute(ctx, "DOM.describeNode", map[string]any{
+		"objectId": objectID,
+	}, &res); err != nil {
+		return 0, 0, "", fmt.Errorf("describe node: %w", err)
+	}
+	if res.Node.ContentDocument != nil {
+		contentDoc = res.Node.ContentDocument.BackendNodeID
+	}
+	return res.Node.BackendNodeID, contentDoc, res.Node.FrameID, nil
+}
@@ -0,0 +1,45 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+func TestMatchFrame(t *testing.T) {
+	frames := []frameInfo{
+		{Name: "ads", URL: "https://ads.example.net/slot?id=1", Src: "https://ads.example.net/slot?id=1"},
+		{ID: "login", URL: "https://auth.example.com/login", Src: "https://auth.example.com/login"},
+		{Name: "pay", URL: "about:blank", Src: "https://pay.example.org/checkout"},
+	}
+	tests := []struct {
+		spec string
+		want int
+	}{
+		{"ads", 0},
+		{"login", 1},
+		{"pay", 2},
+		{"https://auth.example.com/login", 1},
+		{"https://pay.example.org/checkout", 2},
+		{"https://*.example.com/*", 1},
+		{"*checkout", 2},
+		{"auth.example", 1},
+		{"slot?id=1", 0},
+		{"missing", -1},
+		{"https://*.invalid/*", -1},
+	}
+	for _, tt := range tests {
+		if got := matchFrame(frames, tt.spec); got != tt.want {
+			t.Errorf("matchFrame(%q) = %d, want %d", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestResolveScopedSelector_RejectsUnscopable(t *testing.T) {
+	for _, raw := range []string{"frame:login e5", "frame:login find:submit", " >>> button"} {
+		if _, _, err := ResolveScopedSelector(context.Background(), selector.Parse(raw)); err == nil {
+			t.Errorf("ResolveScopedSelector(%q) = nil error", raw)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package bridge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// StateBundleVersion is the bundle format produced by /state/export.
+const StateBundleVersion = 1
+
+// stateBundleKeyFile holds the signing key of instances that run without
+// a server token.
+const stateBundleKeyFile = "state-bundle.key"
+
+// ErrStateBundleSignature is returned when a bundle is unsigned or its
+// signature does not match the payload.
+var ErrStateBundleSignature = errors.New("state bundle signature mismatch")
+
+// StateBundle is a portable snapshot of a browser session: cookies,
+// per-origin web storage and the open tabs. It is signed with HMAC-SHA256 so
+// an instance only applies bundles produced with the same key.
+type StateBundle struct {
+	Version   int                           `json:"version"`
+	CreatedAt time.Time                     `json:"createdAt"`
+	Cookies   []StateCookie                 `json:"cookies"`
+	Origins   map[string]StateOriginStorage `json:"origins"`
+	Tabs      []StateTab                    `json:"tabs"`
+	Signature string                        `json:"signature,omitempty"`
+}
+
+// StateCookie is a browser cookie in a CDP-version independent form.
+type StateCookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	Expires  float64 `json:"expires,omitempty"`
+	HTTPOnly bool    `json:"httpOnly,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+// StateOriginStorage holds the localStorage and sessionStorage items of one
+// origin.
+type StateOriginStorage struct {
+	Local   map[string]string `json:"local,omitempty"`
+	Session map[string]string `json:"session,omitempty"`
+}
+
+// StateTab is an open tab with its current URL and scroll offset.
+type StateTab struct {
+	URL     string  `json:"url"`
+	Title   string  `json:"title,omitempty"`
+	ScrollX float64 `json:"scrollX"`
+	ScrollY float64 `json:"scrollY"`
+}
+
+// StateBundleKey returns the HMAC key for state bundles. The server token is
+// used when set, so instances sharing a token accept each other's bundles.
+// Otherwise the key handed down by the launching server is used, so its
+// instances accept each other's bundles, and failing that a random key is
+// created once under StateDir.
+func StateBundleKey(cfg *config.RuntimeConfig) ([]byte, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("no config")
+	}
+	if token := strings.TrimSpace(cfg.Token); token != "" {
+		return []byte(token), nil
+	}
+	if key := strings.TrimSpace(cfg.StateBundleKey); key != "" {
+		return []byte(key), nil
+	}
+	if cfg.StateDir == "" {
+		return nil, fmt.Errorf("no server token or state dir to derive a signing key")
+	}
+	path := filepath.Join(cfg.StateDir, stateBundleKeyFile)
+	if data, err := os.ReadFile(path); err == nil {
+		if key := strings.TrimSpace(string(data)); key != "" {
+			return []byte(key), nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("generate signing key: %w", err)
+	}
+	key := hex.EncodeToString(raw)
+	if err := os.MkdirAll(cfg.StateDir, 0750); err != nil {
+		return nil, fmt.Errorf("create state dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(key), 0600); err != nil {
+		return nil, fmt.Errorf("write signing key: %w", err)
+	}
+	return []byte(key), nil
+}
+
+// stateBundleMAC computes the signature over the bundle with its Signature
+// field cleared. encoding/json emits struct fields in declaration order and
+// map keys sorted, so the payload is canonical after a decode/encode round trip.
+func stateBundleMAC(b StateBundle, key []byte) (string, error) {
+	b.Signature = ""
+	payload, err := json.Marshal(b)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// SignStateBundle sets the bundle signature.
+func SignStateBundle(b *StateBundle, key []byte) error {
+	sig, err := stateBundleMAC(*b, key)
+	if err != nil {
+		return fmt.Errorf("sign state bundle: %w", err)
+	}
+	b.Signature = sig
+	return nil
+}
+
+// VerifyStateBundle checks the bundle signature against key.
+func VerifyStateBundle(b *StateBundle, key []byte) error {
+	if b.Signature == "" {
+		return ErrStateBundleSignature
+	}
+	want, err := stateBundleMAC(*b, key)
+	if err != nil {
+		return fmt.Errorf("verify state bundle: %w", err)
+	}
+	if !hmac.Equal([]byte(want), []byte(strings.ToLower(b.Signature))) {
+		return ErrStateBundleSignature
+	}
+	return nil
+}
+
+// browserExecutor returns ctx bound to the browser target. Running browser
+// commands directly avoids chromedp.Run allocating a tab on a browser context.
+func browserExecutor(ctx context.Context) (context.Context, error) {
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Browser == nil {
+		return nil, fmt.Errorf("no browser connection")
+	}
+	return cdp.WithExecutor(ctx, c.Browser), nil
+}
+
+// BrowserCookies returns every cookie in the browser, across all sites.
+// ctx may be the browser context or any tab context.
+func BrowserCookies(ctx context.Context) ([]StateCookie, error) {
+	bctx, err := browserExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cookies, err := storage.GetCookies().Do(bctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]StateCookie, 0, len(cookies))
+	for _, c := range cookies {
+		sc := StateCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: c.SameSite.String(),
+		}
+		if !c.Session && c.Expires > 0 {
+			sc.Expires = c.Expires
+		}
+		out = append(out, sc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Domain != out[j].Domain {
+			return out[i].Domain < out[j].Domain
+		}
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// SetBrowserCookies writes cookies into the browser's cookie store. ctx may
+// be the browser context or any tab context.
+func SetBrowserCookies(ctx context.Context, cookies []StateCookie) error {
+	if len(cookies) == 0 {
+		return nil
+	}
+	params := make([]*network.CookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		p := &network.CookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+		}
+		switch strings.ToLower(c.SameSite) {
+		case "strict":
+			p.SameSite = network.CookieSameSiteStrict
+		case "lax":
+			p.SameSite = network.CookieSameSiteLax
+		case "none":
+			p.SameSite = network.CookieSameSiteNone
+		}
+		if c.Expires > 0 {
+			sec := int64(c.Expires)
+			nsec := int64((c.Expires - float64(sec)) * float64(time.Second))
+			expires := cdp.TimeSinceEpoch(time.Unix(sec, nsec))
+			p.Expires = &expires
+		}
+		params = append(params, p)
+	}
+	bctx, err := browserExecutor(ctx)
+	if err != nil {
+		return err
+	}
+	return storage.SetCookies(params).Do(bctx)
+}
+
+// TabScroll returns the page scroll offset of the tab.
+func TabScroll(ctx context.Context) (x, y float64, err error) {
+	var pos struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	}
+	err = chromedp.Run(ctx, chromedp.Evaluate(`({x: window.scrollX, y: window.scrollY})`, &pos))
+	return pos.X, pos.Y, err
+}
+
+// ScrollTab scrolls the page to an absolute offset.
+func ScrollTab(ctx context.Context, x, y float64) error {
+	return chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf("window.scrollTo(%g, %g)", x, y), nil))
+}
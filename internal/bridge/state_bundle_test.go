@@ -0,0 +1,99 @@
+package bridge
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func testStateBundle() StateBundle {
+	return StateBundle{
+		Version:   StateBundleVersion,
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC),
+		Cookies: []StateCookie{
+			{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/", Expires: 1893456000.5, HTTPOnly: true, Secure: true, SameSite: "Lax"},
+		},
+		Origins: map[string]StateOriginStorage{
+			"https://example.com": {Local: map[string]string{"b": "2", "a": "1"}, Session: map[string]string{"s": "x"}},
+		},
+		Tabs: []StateTab{{URL: "https://example.com/app", Title: "App", ScrollY: 420.5}},
+	}
+}
+
+func TestStateBundleSignRoundTrip(t *testing.T) {
+	key := []byte("secret")
+	b := testStateBundle()
+	if err := SignStateBundle(&b, key); err != nil {
+		t.Fatal(err)
+	}
+	if b.Signature == "" {
+		t.Fatal("expected signature")
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded StateBundle
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyStateBundle(&decoded, key); err != nil {
+		t.Fatalf("verify after round trip: %v", err)
+	}
+}
+
+func TestStateBundleVerifyRejects(t *testing.T) {
+	key := []byte("secret")
+	b := testStateBundle()
+	if err := VerifyStateBundle(&b, key); !errors.Is(err, ErrStateBundleSignature) {
+		t.Fatalf("unsigned bundle: got %v", err)
+	}
+	if err := SignStateBundle(&b, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyStateBundle(&b, []byte("other")); !errors.Is(err, ErrStateBundleSignature) {
+		t.Fatalf("wrong key: got %v", err)
+	}
+	b.Cookies[0].Value = "stolen"
+	if err := VerifyStateBundle(&b, key); !errors.Is(err, ErrStateBundleSignature) {
+		t.Fatalf("tampered bundle: got %v", err)
+	}
+}
+
+func TestStateBundleKey(t *testing.T) {
+	key, err := StateBundleKey(&config.RuntimeConfig{Token: "tok"})
+	if err != nil || string(key) != "tok" {
+		t.Fatalf("token key = %q, %v", key, err)
+	}
+
+	dir := t.TempDir()
+	cfg := &config.RuntimeConfig{StateDir: dir}
+	first, err := StateBundleKey(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 64 {
+		t.Fatalf("expected 32-byte hex key, got %d chars", len(first))
+	}
+	info, err := os.Stat(filepath.Join(dir, stateBundleKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v", info.Mode().Perm())
+	}
+	second, err := StateBundleKey(cfg)
+	if err != nil || string(second) != string(first) {
+		t.Fatalf("key not reused: %q vs %q (%v)", second, first, err)
+	}
+
+	if _, err := StateBundleKey(&config.RuntimeConfig{}); err == nil {
+		t.Fatal("expected error without token or state dir")
+	}
+}
@@ -0,0 +1,310 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/cdproto/domstorage"
+	"github.com/chromedp/cdproto/indexeddb"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// Web storage areas.
+const (
+	StorageLocal     = "local"
+	StorageSession   = "session"
+	StorageIndexedDB = "indexeddb"
+)
+
+// DefaultIndexedDBPageSize is the number of object store entries returned
+// when no limit is given.
+const DefaultIndexedDBPageSize = 100
+
+// StorageOrigin normalizes a URL or origin string to "scheme://host[:port]".
+// Only http and https origins carry web storage.
+func StorageOrigin(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("invalid origin %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("origin %q has no web storage (want http or https)", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid origin %q: missing host", raw)
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host), nil
+}
+
+func storageID(origin string, local bool) *domstorage.StorageID {
+	return &domstorage.StorageID{SecurityOrigin: origin, IsLocalStorage: local}
+}
+
+// WebStorageItems returns the localStorage (local=true) or sessionStorage
+// items for an origin loaded in the tab.
+func WebStorageItems(ctx context.Context, origin string, local bool) (map[string]string, error) {
+	var items []domstorage.Item
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		items, err = domstorage.GetDOMStorageItems(storageID(origin, local)).Do(ctx)
+		return err
+	})); err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(items))
+	for _, item := range items {
+		if len(item) == 2 {
+			out[item[0]] = item[1]
+		}
+	}
+	return out, nil
+}
+
+// SetWebStorageItems writes items to local or session storage. When replace
+// is set, the area is cleared first.
+func SetWebStorageItems(ctx context.Context, origin string, local bool, items map[string]string, replace bool) error {
+	id := storageID(origin, local)
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if replace {
+			if err := domstorage.Clear(id).Do(ctx); err != nil {
+				return err
+			}
+		}
+		for _, k := range keys {
+			if err := domstorage.SetDOMStorageItem(id, k, items[k]).Do(ctx); err != nil {
+				return fmt.Errorf("set %q: %w", k, err)
+			}
+		}
+		return nil
+	}))
+}
+
+// RemoveWebStorageItems removes the given keys, or clears the whole area
+// when keys is empty.
+func RemoveWebStorageItems(ctx context.Context, origin string, local bool, keys []string) error {
+	id := storageID(origin, local)
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if len(keys) == 0 {
+			return domstorage.Clear(id).Do(ctx)
+		}
+		for _, k := range keys {
+			if err := domstorage.RemoveDOMStorageItem(id, k).Do(ctx); err != nil {
+				return fmt.Errorf("remove %q: %w", k, err)
+			}
+		}
+		return nil
+	}))
+}
+
+// IndexedDBDatabase describes a database and its object stores.
+type IndexedDBDatabase struct {
+	Name    string           `json:"name"`
+	Version float64          `json:"version"`
+	Stores  []IndexedDBStore `json:"stores"`
+}
+
+// IndexedDBStore describes an object store.
+type IndexedDBStore struct {
+	Name          string   `json:"name"`
+	KeyPath       string   `json:"keyPath,omitempty"`
+	AutoIncrement bool     `json:"autoIncrement"`
+	Indexes       []string `json:"indexes,omitempty"`
+}
+
+// IndexedDBEntry is a single object store record.
+type IndexedDBEntry struct {
+	Key   any `json:"key"`
+	Value any `json:"value"`
+}
+
+// IndexedDBDatabases lists the databases for an origin with their stores.
+func IndexedDBDatabases(ctx context.Context, origin string) ([]IndexedDBDatabase, error) {
+	var out []IndexedDBDatabase
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		names, err := indexeddb.RequestDatabaseNames().WithSecurityOrigin(origin).Do(ctx)
+		if err != nil {
+			return err
+		}
+		sort.Strings(names)
+		out = make([]IndexedDBDatabase, 0, len(names))
+		for _, name := range names {
+			db, err := indexeddb.RequestDatabase(name).WithSecurityOrigin(origin).Do(ctx)
+			if err != nil {
+				return fmt.Errorf("database %q: %w", name, err)
+			}
+			entry := IndexedDBDatabase{Name: db.Name, Version: db.Version, Stores: []IndexedDBStore{}}
+			for _, s := range db.ObjectStores {
+				store := IndexedDBStore{Name: s.Name, KeyPath: keyPathString(s.KeyPath), AutoIncrement: s.AutoIncrement}
+				for _, idx := range s.Indexes {
+					store.Indexes = append(store.Indexes, idx.Name)
+				}
+				entry.Stores = append(entry.Stores, store)
+			}
+			out = append(out, entry)
+		}
+		return nil
+	}))
+	return out, err
+}
+
+// IndexedDBEntries reads up to limit records from an object store, starting
+// after skip records. hasMore reports whether further records exist.
+func IndexedDBEntries(ctx context.Context, origin, database, store string, skip, limit int) ([]IndexedDBEntry, bool, error) {
+	if limit <= 0 {
+		limit = DefaultIndexedDBPageSize
+	}
+	var out []IndexedDBEntry
+	var hasMore bool
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		entries, more, err := indexeddb.RequestData(database, store, "", int64(skip), int64(limit)).
+			WithSecurityOrigin(origin).Do(ctx)
+		if err != nil {
+			return err
+		}
+		hasMore = more
+		out = make([]IndexedDBEntry, 0, len(entries))
+		for _, e := range entries {
+			out = append(out, IndexedDBEntry{
+				Key:   remoteObjectValue(ctx, e.PrimaryKey),
+				Value: remoteObjectValue(ctx, e.Value),
+			})
+		}
+		return nil
+	}))
+	return out, hasMore, err
+}
+
+// DeleteIndexedDB deletes a database (store empty), clears an object store
+// (key nil), or deletes a single record by key.
+func DeleteIndexedDB(ctx context.Context, origin, database, store string, key any) error {
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		switch {
+		case store == "":
+			return indexeddb.DeleteDatabase(database).WithSecurityOrigin(origin).Do(ctx)
+		case key == nil:
+			return indexeddb.ClearObjectStore(database, store).WithSecurityOrigin(origin).Do(ctx)
+		default:
+			k, err := indexedDBKey(key)
+			if err != nil {
+				return err
+			}
+			kr := &indexeddb.KeyRange{Lower: k, Upper: k}
+			return indexeddb.DeleteObjectStoreEntries(database, store, kr).WithSecurityOrigin(origin).Do(ctx)
+		}
+	}))
+}
+
+// indexedDBPutJS writes records with the page's own IndexedDB API; the CDP
+// IndexedDB domain is read/delete only. Records without a key rely on the
+// store's keyPath or autoIncrement.
+const indexedDBPutJS = `(db, store, entries) => new Promise((resolve, reject) => {
+  const req = indexedDB.open(db);
+  req.onerror = () => reject(req.error && req.error.message || "open failed");
+  req.onupgradeneeded = () => {
+    if (!req.result.objectStoreNames.contains(store)) req.result.createObjectStore(store);
+  };
+  req.onsuccess = () => {
+    const conn = req.result;
+    if (!conn.objectStoreNames.contains(store)) {
+      conn.close();
+      reject("object store " + store + " does not exist");
+      return;
+    }
+    const tx = conn.transaction(store, "readwrite");
+    const os = tx.objectStore(store);
+    for (const e of entries) {
+      if (e.key === undefined || e.key === null) os.put(e.value); else os.put(e.value, e.key);
+    }
+    tx.oncomplete = () => { conn.close(); resolve(entries.length); };
+    tx.onerror = () => { conn.close(); reject(tx.error && tx.error.message || "write failed"); };
+  };
+})`
+
+// PutIndexedDBEntries writes records into an object store of the tab's
+// current origin, creating the database and store if neither exists.
+func PutIndexedDBEntries(ctx context.Context, database, store string, entries []IndexedDBEntry) (int, error) {
+	args, err := json.Marshal([]any{database, store, entries})
+	if err != nil {
+		return 0, err
+	}
+	expr := fmt.Sprintf("(%s)(...%s)", indexedDBPutJS, args)
+	var n int
+	err = chromedp.Run(ctx, chromedp.Evaluate(expr, &n, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+		return p.WithAwaitPromise(true)
+	}))
+	return n, err
+}
+
+func remoteObjectValue(ctx context.Context, obj *runtime.RemoteObject) any {
+	if obj == nil {
+		return nil
+	}
+	if obj.ObjectID == "" {
+		if len(obj.Value) > 0 {
+			var v any
+			if err := json.Unmarshal(obj.Value, &v); err == nil {
+				return v
+			}
+		}
+		if obj.UnserializableValue != "" {
+			return string(obj.UnserializableValue)
+		}
+		return nil
+	}
+	defer func() { _ = runtime.ReleaseObject(obj.ObjectID).Do(ctx) }()
+	res, exc, err := runtime.CallFunctionOn("function() { return this; }").
+		WithObjectID(obj.ObjectID).
+		WithReturnByValue(true).
+		Do(ctx)
+	if err != nil || exc != nil || res == nil || len(res.Value) == 0 {
+		return obj.Description
+	}
+	var v any
+	if err := json.Unmarshal(res.Value, &v); err != nil {
+		return obj.Description
+	}
+	return v
+}
+
+func indexedDBKey(key any) (*indexeddb.Key, error) {
+	switch k := key.(type) {
+	case string:
+		return &indexeddb.Key{Type: indexeddb.KeyTypeString, String: k}, nil
+	case float64:
+		return &indexeddb.Key{Type: indexeddb.KeyTypeNumber, Number: k}, nil
+	case int:
+		return &indexeddb.Key{Type: indexeddb.KeyTypeNumber, Number: float64(k)}, nil
+	case []any:
+		arr := make([]*indexeddb.Key, 0, len(k))
+		for _, item := range k {
+			ik, err := indexedDBKey(item)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, ik)
+		}
+		return &indexeddb.Key{Type: indexeddb.KeyTypeArray, Array: arr}, nil
+	default:
+		return nil, fmt.Errorf("unsupported IndexedDB key type %T (want string, number or array)", key)
+	}
+}
+
+func keyPathString(kp *indexeddb.KeyPath) string {
+	if kp == nil {
+		return ""
+	}
+	if len(kp.Array) > 0 {
+		return strings.Join(kp.Array, ",")
+	}
+	return kp.String
+}
@@ -0,0 +1,50 @@
+package bridge
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/indexeddb"
+)
+
+func TestStorageOrigin(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"https://Example.com/path?q=1", "https://example.com", false},
+		{"http://localhost:8080", "http://localhost:8080", false},
+		{" https://a.b.test ", "https://a.b.test", false},
+		{"about:blank", "", true},
+		{"file:///tmp/x.html", "", true},
+		{"https://", "", true},
+	}
+	for _, tt := range tests {
+		got, err := StorageOrigin(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("StorageOrigin(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("StorageOrigin(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIndexedDBKey(t *testing.T) {
+	k, err := indexedDBKey("user-1")
+	if err != nil || k.Type != indexeddb.KeyTypeString || k.String != "user-1" {
+		t.Errorf("unexpected string key: %+v %v", k, err)
+	}
+	k, err = indexedDBKey(float64(7))
+	if err != nil || k.Type != indexeddb.KeyTypeNumber || k.Number != 7 {
+		t.Errorf("unexpected number key: %+v %v", k, err)
+	}
+	k, err = indexedDBKey([]any{"a", float64(1)})
+	if err != nil || k.Type != indexeddb.KeyTypeArray || len(k.Array) != 2 {
+		t.Errorf("unexpected array key: %+v %v", k, err)
+	}
+	if _, err := indexedDBKey(map[string]any{}); err == nil {
+		t.Error("expected error for object key")
+	}
+}
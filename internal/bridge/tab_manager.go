@@ -31,10 +31,17 @@ type TabManager struct {
 	onTabSetup TabSetupFunc
 	dialogMgr  *DialogManager
 	logStore   *ConsoleLogStore
+	intercept  *InterceptManager
 	currentTab string // ID of the most recently used tab
 	executor   *TabExecutor
 	guardOnce  sync.Once
 	mu         sync.RWMutex
+
+	pressureOnce sync.Once
+	// Memory probes for the memory-pressure watcher; nil uses CDP and the
+	// browser process tree. Tests replace them.
+	tabHeapMB       func(ctx context.Context, tabID string) (float64, error)
+	browserMemoryMB func() (float64, error)
 }
 
 func NewTabManager(browserCtx context.Context, cfg *config.RuntimeConfig, idMgr *ids.Manager, logStore *ConsoleLogStore, onTabSetup TabSetupFunc) *TabManager {
@@ -63,6 +70,12 @@ func (tm *TabManager) SetDialogManager(dm *DialogManager) {
 	tm.dialogMgr = dm
 }
 
+// SetInterceptManager sets the request interception manager so rules are
+// dropped when their tab goes away.
+func (tm *TabManager) SetInterceptManager(im *InterceptManager) {
+	tm.intercept = im
+}
+
 func shouldBlockPopupTarget(info *target.Info) bool {
 	return info != nil && info.Type == TargetTypePage && info.OpenerID != ""
 }
@@ -218,7 +231,11 @@ func (tm *TabManager) TabContext(tabID string) (context.Context, string, error)
 		return nil, "", fmt.Errorf("tab %s has no active context", tabID)
 	}
 
+	// Marking the tab current first keeps the discarder away from it.
 	tm.markAccessed(tabID)
+	if err := tm.restoreDiscardedTab(tabID); err != nil {
+		slog.Warn("restore discarded tab", "id", tabID, "err", err)
+	}
 
 	return entry.Ctx, tabID, nil
 }
@@ -482,7 +499,7 @@ func (tm *TabManager) ListTargets() ([]*target.Info, error) {
 			pages = append(pages, t)
 		}
 	}
-	return pages, nil
+	return tm.withDiscardedPages(pages), nil
 }
 
 // ListTargetsWithContext is like ListTargets but uses a custom context
@@ -511,7 +528,7 @@ func (tm *TabManager) ListTargetsWithContext(ctx context.Context) ([]*target.Inf
 			pages = append(pages, t)
 		}
 	}
-	return pages, nil
+	return tm.withDiscardedPages(pages), nil
 }
 
 func (tm *TabManager) GetRefCache(tabID string) *RefCache {
@@ -640,6 +657,9 @@ func (tm *TabManager) purgeTrackedTabState(tabID, cdpTargetID string) bool {
 	if tm.logStore != nil {
 		tm.logStore.RemoveTab(resolvedCDPID)
 	}
+	if tm.intercept != nil {
+		tm.intercept.Forget(resolvedTabID)
+	}
 	return true
 }
 
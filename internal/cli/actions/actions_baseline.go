@@ -0,0 +1,151 @@
+package actions
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/cli"
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// ScreenshotCompare captures a screenshot and compares it with a stored
+// baseline. It exits non-zero when the comparison fails.
+func ScreenshotCompare(client *http.Client, base, token string, args []string, cmd *cobra.Command) {
+	body := map[string]any{"name": args[0]}
+	if v, _ := cmd.Flags().GetString("tab"); v != "" {
+		body["tabId"] = v
+	}
+	if v, _ := cmd.Flags().GetString("selector"); v != "" {
+		body["selector"] = v
+	}
+	if v, _ := cmd.Flags().GetBool("full-page"); v {
+		body["fullPage"] = true
+	}
+	if v, _ := cmd.Flags().GetBool("update"); v {
+		body["update"] = true
+	}
+	for _, flag := range []string{"tolerance", "threshold"} {
+		v, _ := cmd.Flags().GetString(flag)
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			cli.Fatal("Invalid --%s %q: %v", flag, v, err)
+		}
+		body[flag] = f
+	}
+	if regions, _ := cmd.Flags().GetStringArray("ignore"); len(regions) > 0 {
+		ignore := make([]map[string]int, 0, len(regions))
+		for _, raw := range regions {
+			r, err := parseRegion(raw)
+			if err != nil {
+				cli.Fatal("Invalid --ignore %q: %v", raw, err)
+			}
+			ignore = append(ignore, r)
+		}
+		body["ignore"] = ignore
+	}
+
+	data := apiclient.DoPostRaw(client, base, token, "/screenshot/compare", body)
+	if data == nil {
+		return
+	}
+	var result map[string]any
+	if err := json.Unmarshal(data, &result); err != nil {
+		cli.Fatal("Invalid response: %v", err)
+	}
+
+	diff, _ := result["diff"].(string)
+	delete(result, "diff")
+	if out, _ := cmd.Flags().GetString("diff-output"); out != "" && diff != "" {
+		png, err := base64.StdEncoding.DecodeString(diff)
+		if err != nil {
+			cli.Fatal("Invalid diff image: %v", err)
+		}
+		if err := os.WriteFile(out, png, 0600); err != nil {
+			cli.Fatal("Write failed: %v", err)
+		}
+		result["diffFile"] = out
+	}
+
+	pretty, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(pretty))
+	if result["status"] == "failed" {
+		cli.Fatal("Screenshot differs from baseline %q (%v%% mismatch)", args[0], result["mismatchPercent"])
+	}
+}
+
+// parseRegion parses "x,y,width,height".
+func parseRegion(raw string) (map[string]int, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("expected x,y,width,height")
+	}
+	keys := []string{"x", "y", "width", "height"}
+	region := make(map[string]int, len(keys))
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", keys[i], err)
+		}
+		region[keys[i]] = n
+	}
+	return region, nil
+}
+
+// BaselineList lists stored screenshot baselines.
+func BaselineList(client *http.Client, base, token string) {
+	apiclient.DoGet(client, base, token, "/screenshot/baselines", nil)
+}
+
+// BaselineGet saves a baseline, or the diff from its last failed
+// comparison, to a file.
+func BaselineGet(client *http.Client, base, token string, args []string, cmd *cobra.Command) {
+	name := args[0]
+	params := url.Values{}
+	suffix := ""
+	if v, _ := cmd.Flags().GetBool("diff"); v {
+		params.Set("diff", "true")
+		suffix = ".diff"
+	}
+	outFile, _ := cmd.Flags().GetString("output")
+	if outFile == "" {
+		outFile = name + suffix + ".png"
+	}
+	data := apiclient.DoGetRaw(client, base, token, "/screenshot/baselines/"+url.PathEscape(name), params)
+	if data == nil {
+		return
+	}
+	if err := os.WriteFile(outFile, data, 0600); err != nil {
+		cli.Fatal("Write failed: %v", err)
+	}
+	fmt.Println(cli.StyleStdout(cli.SuccessStyle, fmt.Sprintf("Saved %s (%d bytes)", outFile, len(data))))
+}
+
+// BaselineSet uploads a PNG file as a baseline.
+func BaselineSet(client *http.Client, base, token string, args []string) {
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		cli.Fatal("Read failed: %v", err)
+	}
+	apiclient.DoPut(client, base, token, "/screenshot/baselines/"+url.PathEscape(args[0]), "image/png", data)
+}
+
+// BaselineAccept promotes the screenshot from the last failed comparison
+// to be the baseline.
+func BaselineAccept(client *http.Client, base, token string, args []string) {
+	apiclient.DoPost(client, base, token, "/screenshot/baselines/"+url.PathEscape(args[0])+"/accept", nil)
+}
+
+// BaselineDelete removes a baseline.
+func BaselineDelete(client *http.Client, base, token string, args []string) {
+	apiclient.DoDelete(client, base, token, "/screenshot/baselines/"+url.PathEscape(args[0]), nil)
+}
@@ -0,0 +1,80 @@
+package actions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestScreenshotCompare(t *testing.T) {
+	m := newMockServer()
+	m.response = `{"status":"passed","mismatchPercent":0.5,"diff":"iVBORw0KGgo="}`
+	defer m.close()
+	client := m.server.Client()
+
+	diffFile := filepath.Join(t.TempDir(), "diff.png")
+	cmd := &cobra.Command{}
+	cmd.Flags().String("tab", "tab1", "")
+	cmd.Flags().String("selector", "#hero", "")
+	cmd.Flags().Bool("full-page", false, "")
+	cmd.Flags().Bool("update", false, "")
+	cmd.Flags().String("tolerance", "0.2", "")
+	cmd.Flags().String("threshold", "1", "")
+	cmd.Flags().StringArray("ignore", []string{"0, 0, 100, 20"}, "")
+	cmd.Flags().String("diff-output", diffFile, "")
+	ScreenshotCompare(client, m.base(), "", []string{"home"}, cmd)
+
+	if m.lastPath != "/screenshot/compare" {
+		t.Errorf("expected /screenshot/compare, got %s", m.lastPath)
+	}
+	var body map[string]any
+	if err := json.Unmarshal([]byte(m.lastBody), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["name"] != "home" || body["tabId"] != "tab1" || body["selector"] != "#hero" {
+		t.Errorf("unexpected body %s", m.lastBody)
+	}
+	if body["tolerance"] != 0.2 || body["threshold"] != 1.0 {
+		t.Errorf("expected numeric tolerance/threshold, got %s", m.lastBody)
+	}
+	ignore, _ := body["ignore"].([]any)
+	if len(ignore) != 1 || ignore[0].(map[string]any)["width"] != 100.0 {
+		t.Errorf("unexpected ignore %v", body["ignore"])
+	}
+	if data, err := os.ReadFile(diffFile); err != nil || len(data) != 8 {
+		t.Errorf("diff file not written: %v", err)
+	}
+}
+
+func TestParseRegion(t *testing.T) {
+	if _, err := parseRegion("1,2,3"); err == nil {
+		t.Error("expected error for 3 values")
+	}
+	if _, err := parseRegion("1,2,x,4"); err == nil {
+		t.Error("expected error for non-numeric value")
+	}
+	r, err := parseRegion("10,20,30,40")
+	if err != nil || r["x"] != 10 || r["height"] != 40 {
+		t.Errorf("unexpected region %v (%v)", r, err)
+	}
+}
+
+func TestBaselineSet(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+
+	file := filepath.Join(t.TempDir(), "home.png")
+	if err := os.WriteFile(file, []byte("PNGDATA"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	BaselineSet(m.server.Client(), m.base(), "", []string{"home", file})
+	if m.lastMethod != "PUT" || m.lastPath != "/screenshot/baselines/home" {
+		t.Errorf("unexpected request %s %s", m.lastMethod, m.lastPath)
+	}
+	if m.lastBody != "PNGDATA" || m.lastHeaders.Get("Content-Type") != "image/png" {
+		t.Errorf("unexpected upload %q %q", m.lastBody, m.lastHeaders.Get("Content-Type"))
+	}
+}
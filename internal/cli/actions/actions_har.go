@@ -0,0 +1,82 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/cli"
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// NetworkHAR exports the tab's network buffer as a HAR file.
+func NetworkHAR(client *http.Client, base, token string, cmd *cobra.Command) {
+	params := url.Values{}
+	if v, _ := cmd.Flags().GetString("tab"); v != "" {
+		params.Set("tabId", v)
+	}
+	if v, _ := cmd.Flags().GetBool("body"); v {
+		params.Set("body", "true")
+	}
+	if v, _ := cmd.Flags().GetString("filter"); v != "" {
+		params.Set("filter", v)
+	}
+	outFile, _ := cmd.Flags().GetString("output")
+	if outFile == "" {
+		outFile = fmt.Sprintf("network-%s.har", time.Now().Format("20060102-150405"))
+	}
+
+	data := apiclient.DoGetRaw(client, base, token, "/network/har", params)
+	if data == nil {
+		return
+	}
+	if outFile == "-" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(outFile, data, 0600); err != nil {
+		cli.Fatal("Write failed: %v", err)
+	}
+	fmt.Println(cli.StyleStdout(cli.SuccessStyle, fmt.Sprintf("Saved %s (%d bytes)", outFile, len(data))))
+}
+
+// NetworkReplay starts, inspects or stops HAR replay on a tab.
+func NetworkReplay(client *http.Client, base, token string, cmd *cobra.Command, args []string) {
+	tabID, _ := cmd.Flags().GetString("tab")
+	params := url.Values{}
+	if tabID != "" {
+		params.Set("tabId", tabID)
+	}
+	if v, _ := cmd.Flags().GetBool("stop"); v {
+		apiclient.DoDelete(client, base, token, "/network/har/replay", params)
+		return
+	}
+	if len(args) == 0 {
+		apiclient.DoGet(client, base, token, "/network/har/replay", params)
+		return
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		cli.Fatal("Read failed: %v", err)
+	}
+	var har map[string]any
+	if err := json.Unmarshal(data, &har); err != nil {
+		cli.Fatal("Invalid HAR file: %v", err)
+	}
+	body := map[string]any{"har": har}
+	if tabID != "" {
+		body["tabId"] = tabID
+	}
+	if v, _ := cmd.Flags().GetString("url"); v != "" {
+		body["url"] = v
+	}
+	if v, _ := cmd.Flags().GetString("not-found"); v != "" {
+		body["notFound"] = v
+	}
+	apiclient.DoPost(client, base, token, "/network/har/replay", body)
+}
@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// InterceptAdd installs a request interception rule on a tab.
+func InterceptAdd(client *http.Client, base, token string, cmd *cobra.Command, args []string) {
+	body := map[string]any{"url": args[0]}
+	if v, _ := cmd.Flags().GetString("tab"); v != "" {
+		body["tabId"] = v
+	}
+	if v, _ := cmd.Flags().GetString("id"); v != "" {
+		body["id"] = v
+	}
+	if v, _ := cmd.Flags().GetString("method"); v != "" {
+		body["method"] = v
+	}
+	if v, _ := cmd.Flags().GetString("type"); v != "" {
+		body["resourceType"] = v
+	}
+	if v, _ := cmd.Flags().GetInt("status"); v != 0 {
+		body["status"] = v
+		body["action"] = "fulfill"
+	}
+	if v, _ := cmd.Flags().GetString("body"); v != "" {
+		body["body"] = v
+		body["action"] = "fulfill"
+	}
+	if v, _ := cmd.Flags().GetString("body-file"); v != "" {
+		data, err := os.ReadFile(v)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read body file: %v\n", err)
+			os.Exit(1)
+		}
+		body["body"] = string(data)
+		body["action"] = "fulfill"
+	}
+	if v, _ := cmd.Flags().GetStringArray("header"); len(v) > 0 {
+		body["headers"] = parseHeaderFlags(v)
+	}
+	if v, _ := cmd.Flags().GetStringArray("request-header"); len(v) > 0 {
+		body["requestHeaders"] = parseHeaderFlags(v)
+	}
+	if v, _ := cmd.Flags().GetBool("abort"); v {
+		body["action"] = "abort"
+	}
+	if v, _ := cmd.Flags().GetString("error"); v != "" {
+		body["errorReason"] = v
+		body["action"] = "abort"
+	}
+	if v, _ := cmd.Flags().GetInt("delay"); v > 0 {
+		body["delayMs"] = v
+	}
+	apiclient.DoPost(client, base, token, "/intercept", body)
+}
+
+// InterceptList lists the interception rules on a tab.
+func InterceptList(client *http.Client, base, token string, cmd *cobra.Command) {
+	params := url.Values{}
+	if v, _ := cmd.Flags().GetString("tab"); v != "" {
+		params.Set("tabId", v)
+	}
+	apiclient.DoGet(client, base, token, "/intercept", params)
+}
+
+// InterceptRemove removes a rule by ID, or all rules when no ID is given.
+func InterceptRemove(client *http.Client, base, token string, cmd *cobra.Command, args []string) {
+	params := url.Values{}
+	if v, _ := cmd.Flags().GetString("tab"); v != "" {
+		params.Set("tabId", v)
+	}
+	if len(args) > 0 {
+		params.Set("id", args[0])
+	}
+	apiclient.DoDelete(client, base, token, "/intercept", params)
+}
+
+// parseHeaderFlags converts "Name: value" flag values into a header map.
+func parseHeaderFlags(values []string) map[string]string {
+	headers := make(map[string]string, len(values))
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "invalid header %q (expected Name: value)\n", v)
+			os.Exit(1)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers
+}
@@ -22,9 +22,21 @@ func Screenshot(client *http.Client, base, token string, cmd *cobra.Command) {
 	if v, _ := cmd.Flags().GetString("tab"); v != "" {
 		params.Set("tabId", v)
 	}
+	for _, flag := range []string{"format", "selector", "clip", "scale"} {
+		if v, _ := cmd.Flags().GetString(flag); v != "" {
+			params.Set(flag, v)
+		}
+	}
+	if v, _ := cmd.Flags().GetBool("full-page"); v {
+		params.Set("fullPage", "true")
+	}
 
 	if outFile == "" {
-		outFile = fmt.Sprintf("screenshot-%s.jpg", time.Now().Format("20060102-150405"))
+		ext := "jpg"
+		if f := params.Get("format"); f == "png" || f == "webp" {
+			ext = f
+		}
+		outFile = fmt.Sprintf("screenshot-%s.%s", time.Now().Format("20060102-150405"), ext)
 	}
 
 	data := apiclient.DoGetRaw(client, base, token, "/screenshot", params)
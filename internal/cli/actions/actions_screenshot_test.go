@@ -20,6 +20,11 @@ func TestScreenshot(t *testing.T) {
 	cmd.Flags().String("output", outFile, "")
 	cmd.Flags().String("quality", "50", "")
 	cmd.Flags().String("tab", "", "")
+	cmd.Flags().String("format", "", "")
+	cmd.Flags().String("selector", "", "")
+	cmd.Flags().String("clip", "", "")
+	cmd.Flags().String("scale", "", "")
+	cmd.Flags().Bool("full-page", false, "")
 	Screenshot(client, m.base(), "", cmd)
 	if m.lastPath != "/screenshot" {
 		t.Errorf("expected /screenshot, got %s", m.lastPath)
@@ -35,3 +40,29 @@ func TestScreenshot(t *testing.T) {
 		t.Errorf("unexpected content: %s", string(data))
 	}
 }
+
+func TestScreenshot_Options(t *testing.T) {
+	m := newMockServer()
+	m.response = "FAKEWEBPDATA"
+	defer m.close()
+
+	outFile := filepath.Join(t.TempDir(), "test.webp")
+	cmd := &cobra.Command{}
+	cmd.Flags().String("output", outFile, "")
+	cmd.Flags().String("quality", "", "")
+	cmd.Flags().String("tab", "", "")
+	cmd.Flags().String("format", "webp", "")
+	cmd.Flags().String("selector", "#hero", "")
+	cmd.Flags().String("clip", "", "")
+	cmd.Flags().String("scale", "2", "")
+	cmd.Flags().Bool("full-page", false, "")
+	Screenshot(m.server.Client(), m.base(), "", cmd)
+	for _, want := range []string{"format=webp", "selector=%23hero", "scale=2"} {
+		if !strings.Contains(m.lastQuery, want) {
+			t.Errorf("expected %s in query, got %s", want, m.lastQuery)
+		}
+	}
+	if strings.Contains(m.lastQuery, "fullPage") {
+		t.Errorf("unexpected fullPage in query %s", m.lastQuery)
+	}
+}
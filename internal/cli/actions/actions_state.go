@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/cli"
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// StateExport saves cookies, web storage and open tabs as a signed bundle.
+func StateExport(client *http.Client, base, token string, cmd *cobra.Command) {
+	body := map[string]any{}
+	if tabs, _ := cmd.Flags().GetStringSlice("tab"); len(tabs) > 0 {
+		body["tabIds"] = tabs
+	}
+	outFile, _ := cmd.Flags().GetString("output")
+	if outFile == "" {
+		outFile = fmt.Sprintf("state-%s.json", time.Now().Format("20060102-150405"))
+	}
+
+	data := apiclient.DoPostRaw(client, base, token, "/state/export", body)
+	if data == nil {
+		return
+	}
+	if outFile == "-" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(outFile, data, 0600); err != nil {
+		cli.Fatal("Write failed: %v", err)
+	}
+	fmt.Println(cli.StyleStdout(cli.SuccessStyle, fmt.Sprintf("Saved %s (%d bytes)", outFile, len(data))))
+}
+
+// StateImport applies a bundle written by StateExport.
+func StateImport(client *http.Client, base, token string, args []string) {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		cli.Fatal("Read failed: %v", err)
+	}
+	var bundle map[string]any
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		cli.Fatal("Invalid state bundle: %v", err)
+	}
+	apiclient.DoPost(client, base, token, "/state/import", bundle)
+}
@@ -0,0 +1,73 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// StorageGet reads local/session storage or IndexedDB data.
+func StorageGet(client *http.Client, base, token string, cmd *cobra.Command, args []string) {
+	params := storageParams(cmd)
+	if len(args) > 0 {
+		params.Set("key", args[0])
+	}
+	if v, _ := cmd.Flags().GetString("limit"); v != "" {
+		params.Set("limit", v)
+	}
+	apiclient.DoGet(client, base, token, "/storage", params)
+}
+
+// StorageSet writes a key/value pair. For IndexedDB the value is parsed as
+// JSON when possible.
+func StorageSet(client *http.Client, base, token string, cmd *cobra.Command, args []string) {
+	body := map[string]any{}
+	for k, v := range storageParams(cmd) {
+		body[k] = v[0]
+	}
+	if body["type"] == "indexeddb" {
+		var value any = args[1]
+		var parsed any
+		if json.Unmarshal([]byte(args[1]), &parsed) == nil {
+			value = parsed
+		}
+		body["entries"] = []map[string]any{{"key": args[0], "value": value}}
+	} else {
+		body["items"] = map[string]string{args[0]: args[1]}
+	}
+	if v, _ := cmd.Flags().GetBool("replace"); v {
+		body["replace"] = true
+	}
+	apiclient.DoPost(client, base, token, "/storage", body)
+}
+
+// StorageDelete removes keys, or clears the storage area when none are given.
+func StorageDelete(client *http.Client, base, token string, cmd *cobra.Command, args []string) {
+	params := storageParams(cmd)
+	if params.Get("type") == "" {
+		params.Set("type", "local")
+	}
+	for _, k := range args {
+		params.Add("key", k)
+	}
+	apiclient.DoDelete(client, base, token, "/storage", params)
+}
+
+func storageParams(cmd *cobra.Command) url.Values {
+	params := url.Values{}
+	for flag, param := range map[string]string{
+		"tab":      "tabId",
+		"origin":   "origin",
+		"type":     "type",
+		"database": "database",
+		"store":    "store",
+	} {
+		if v, _ := cmd.Flags().GetString(flag); v != "" {
+			params.Set(param, v)
+		}
+	}
+	return params
+}
@@ -0,0 +1,86 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/cli"
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/pinchtab/pinchtab/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// RunWorkflow runs a YAML or JSON workflow file via /workflows/run and exits
+// non-zero when the run fails.
+func RunWorkflow(client *http.Client, base, token string, args []string, cmd *cobra.Command) {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		cli.Fatal("Read failed: %v", err)
+	}
+	wf, err := workflow.Parse(data)
+	if err != nil {
+		cli.Fatal("Invalid workflow: %v", err)
+	}
+
+	vars, _ := cmd.Flags().GetStringArray("var")
+	for _, kv := range vars {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || name == "" {
+			cli.Fatal("Invalid --var %q (want name=value)", kv)
+		}
+		if wf.Vars == nil {
+			wf.Vars = map[string]any{}
+		}
+		wf.Vars[name] = parseVarValue(value)
+	}
+	if tab, _ := cmd.Flags().GetString("tab"); tab != "" {
+		wf.TabID = tab
+	}
+
+	raw, err := json.Marshal(wf)
+	if err != nil {
+		cli.Fatal("Encode workflow: %v", err)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(raw, &body); err != nil {
+		cli.Fatal("Encode workflow: %v", err)
+	}
+
+	path := "/workflows/run"
+	if wf.TabID != "" {
+		path += "?tabId=" + url.QueryEscape(wf.TabID)
+	}
+	resp := apiclient.DoPostRaw(client, base, token, path, body)
+	if resp == nil {
+		return
+	}
+	var buf bytes.Buffer
+	if json.Indent(&buf, resp, "", "  ") == nil {
+		fmt.Println(buf.String())
+	} else {
+		fmt.Println(string(resp))
+	}
+
+	var res workflow.Result
+	if err := json.Unmarshal(resp, &res); err != nil {
+		cli.Fatal("Invalid response: %v", err)
+	}
+	if res.Status != workflow.RunPassed {
+		cli.Fatal("Workflow failed at step %s: %s", res.FailedStep, res.Error)
+	}
+}
+
+// parseVarValue keeps numbers, booleans and JSON values typed and treats
+// anything else as a string.
+func parseVarValue(s string) any {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err == nil {
+		return v
+	}
+	return s
+}
@@ -76,6 +76,28 @@ func DoGetRaw(client *http.Client, base, token, path string, params url.Values)
 	return body
 }
 
+func DoPostRaw(client *http.Client, base, token, path string, body map[string]any) []byte {
+	data, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", base+path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set(activity.HeaderAgentID, "cli")
+	resp, err := client.Do(req)
+	if err != nil {
+		fatal("Request failed: %v", err)
+		return nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 400 {
+		fmt.Fprintf(os.Stderr, "Error %d: %s\n", resp.StatusCode, string(respBody))
+		os.Exit(1)
+	}
+	return respBody
+}
+
 func DoPost(client *http.Client, base, token, path string, body map[string]any) map[string]any {
 	data, _ := json.Marshal(body)
 	req, _ := http.NewRequest("POST", base+path, bytes.NewReader(data))
@@ -111,6 +133,76 @@ func DoPost(client *http.Client, base, token, path string, body map[string]any)
 	return result
 }
 
+func DoDelete(client *http.Client, base, token, path string, params url.Values) map[string]any {
+	u := base + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, _ := http.NewRequest("DELETE", u, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set(activity.HeaderAgentID, "cli")
+	resp, err := client.Do(req)
+	if err != nil {
+		fatal("Request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode >= 400 {
+		fmt.Fprintf(os.Stderr, "Error %d: %s\n", resp.StatusCode, string(body))
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if json.Indent(&buf, body, "", "  ") == nil {
+		fmt.Println(buf.String())
+	} else {
+		fmt.Println(string(body))
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		log.Printf("warning: error unmarshaling response: %v", err)
+	}
+	return result
+}
+
+// DoPut sends body with the given content type and prints the JSON response.
+func DoPut(client *http.Client, base, token, path, contentType string, body []byte) map[string]any {
+	req, _ := http.NewRequest("PUT", base+path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set(activity.HeaderAgentID, "cli")
+	resp, err := client.Do(req)
+	if err != nil {
+		fatal("Request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode >= 400 {
+		fmt.Fprintf(os.Stderr, "Error %d: %s\n", resp.StatusCode, string(respBody))
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if json.Indent(&buf, respBody, "", "  ") == nil {
+		fmt.Println(buf.String())
+	} else {
+		fmt.Println(string(respBody))
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		log.Printf("warning: error unmarshaling response: %v", err)
+	}
+	return result
+}
+
 // ResolveInstanceBase fetches the named instance from the orchestrator and returns
 // a base URL pointing directly at that instance's API port.
 func ResolveInstanceBase(orchBase, token, instanceID, bind string) string {
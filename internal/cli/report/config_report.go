@@ -32,6 +32,12 @@ func HandleConfigShow(cfg *config.RuntimeConfig) {
 	fmt.Printf("  Stealth:        %s\n", cfg.StealthLevel)
 	fmt.Printf("  Tab Eviction:   %s\n", cfg.TabEvictionPolicy)
 	fmt.Printf("  Extensions:     %v\n", cfg.ExtensionPaths)
+	if cfg.InstanceMemoryMaxMB > 0 || cfg.InstanceCPUQuota > 0 || cfg.InstancePidsMax > 0 {
+		fmt.Printf("  Limits:         memory %d MB, cpu %g cores, pids %d (0 = unlimited)\n", cfg.InstanceMemoryMaxMB, cfg.InstanceCPUQuota, cfg.InstancePidsMax)
+	}
+	if cfg.MemoryPressureTabHeapMB > 0 || cfg.MemoryPressureDiscardMB > 0 || cfg.MemoryPressureRecycleMB > 0 {
+		fmt.Printf("  Mem Pressure:   tab heap %d MB, discard above %d MB, recycle above %d MB (0 = off)\n", cfg.MemoryPressureTabHeapMB, cfg.MemoryPressureDiscardMB, cfg.MemoryPressureRecycleMB)
+	}
 	fmt.Println()
 	fmt.Println(styleStdout(headingStyle, "Multi-Instance"))
 	fmt.Printf("  Strategy:       %s\n", cfg.Strategy)
@@ -40,6 +46,12 @@ func HandleConfigShow(cfg *config.RuntimeConfig) {
 	fmt.Printf("  Init Backoff:   %v\n", cfg.RestartInitBackoff)
 	fmt.Printf("  Max Backoff:    %v\n", cfg.RestartMaxBackoff)
 	fmt.Printf("  Stable After:   %v\n", cfg.RestartStableAfter)
+	if cfg.Strategy == "elastic" {
+		fmt.Printf("  Elastic Pool:   %d-%d instances, idle after %v\n", cfg.ElasticMinInstances, cfg.ElasticMaxInstances, cfg.ElasticIdleCooldown)
+	}
+	if cfg.FleetJoinToken != "" {
+		fmt.Printf("  Fleet:          heartbeat %v, removed after %d missed\n", cfg.FleetHeartbeatInterval, cfg.FleetMissedHeartbeats)
+	}
 	fmt.Println()
 	fmt.Println(styleStdout(headingStyle, "Attach"))
 	fmt.Printf("  Enabled:        %v\n", cfg.AttachEnabled)
@@ -18,6 +18,14 @@ func DefaultFileConfig() FileConfig {
 	restartInitBackoffSec := 2
 	restartMaxBackoffSec := 60
 	restartStableAfterSec := 300
+	elasticMinInstances := 1
+	elasticMaxInstances := 4
+	elasticScaleUpQueueDepth := 10
+	elasticScaleUpTabs := 10
+	elasticIdleCooldownSec := 300
+	fleetHeartbeatIntervalSec := 10
+	fleetMissedHeartbeats := 3
+	memoryCheckIntervalSec := 15
 	maxTabs := 20
 	allowEvaluate := false
 	allowMacro := false
@@ -50,6 +58,9 @@ func DefaultFileConfig() FileConfig {
 			MaxTabs:           &maxTabs,
 			StealthLevel:      "light",
 			TabEvictionPolicy: "close_lru",
+			MemoryPressure: MemoryPressureConfig{
+				CheckIntervalSec: &memoryCheckIntervalSec,
+			},
 		},
 		Security: SecurityConfig{
 			AllowEvaluate:          &allowEvaluate,
@@ -94,6 +105,17 @@ func DefaultFileConfig() FileConfig {
 				MaxBackoffSec:  &restartMaxBackoffSec,
 				StableAfterSec: &restartStableAfterSec,
 			},
+			Elastic: MultiInstanceElasticConfig{
+				MinInstances:           &elasticMinInstances,
+				MaxInstances:           &elasticMaxInstances,
+				ScaleUpQueueDepth:      &elasticScaleUpQueueDepth,
+				ScaleUpTabsPerInstance: &elasticScaleUpTabs,
+				IdleCooldownSec:        &elasticIdleCooldownSec,
+			},
+			Fleet: MultiInstanceFleetConfig{
+				HeartbeatIntervalSec: &fleetHeartbeatIntervalSec,
+				MissedHeartbeats:     &fleetMissedHeartbeats,
+			},
 		},
 		Timeouts: TimeoutsConfig{
 			ActionSec:   30,
@@ -155,6 +177,20 @@ type instanceDefaultsConfigJSON struct {
 	NoAnimations      *bool  `json:"noAnimations"`
 	StealthLevel      string `json:"stealthLevel"`
 	TabEvictionPolicy string `json:"tabEvictionPolicy"`
+	Device            string `json:"device"`
+
+	MemoryMaxMB *int     `json:"memoryMaxMB"`
+	CPUQuota    *float64 `json:"cpuQuota"`
+	PidsMax     *int     `json:"pidsMax"`
+
+	MemoryPressure memoryPressureJSON `json:"memoryPressure"`
+}
+
+type memoryPressureJSON struct {
+	TabHeapMB        *int `json:"tabHeapMB"`
+	DiscardAboveMB   *int `json:"discardAboveMB"`
+	RecycleAboveMB   *int `json:"recycleAboveMB"`
+	CheckIntervalSec *int `json:"checkIntervalSec"`
 }
 
 type profilesConfigJSON struct {
@@ -204,6 +240,8 @@ type multiInstanceConfigJSON struct {
 	InstancePortStart *int                     `json:"instancePortStart"`
 	InstancePortEnd   *int                     `json:"instancePortEnd"`
 	Restart           multiInstanceRestartJSON `json:"restart"`
+	Elastic           multiInstanceElasticJSON `json:"elastic"`
+	Fleet             multiInstanceFleetJSON   `json:"fleet"`
 }
 
 type multiInstanceRestartJSON struct {
@@ -213,6 +251,20 @@ type multiInstanceRestartJSON struct {
 	StableAfterSec *int `json:"stableAfterSec"`
 }
 
+type multiInstanceElasticJSON struct {
+	MinInstances           *int `json:"minInstances"`
+	MaxInstances           *int `json:"maxInstances"`
+	ScaleUpQueueDepth      *int `json:"scaleUpQueueDepth"`
+	ScaleUpTabsPerInstance *int `json:"scaleUpTabsPerInstance"`
+	IdleCooldownSec        *int `json:"idleCooldownSec"`
+}
+
+type multiInstanceFleetJSON struct {
+	JoinToken            string `json:"joinToken"`
+	HeartbeatIntervalSec *int   `json:"heartbeatIntervalSec"`
+	MissedHeartbeats     *int   `json:"missedHeartbeats"`
+}
+
 type timeoutsConfigJSON struct {
 	ActionSec   int `json:"actionSec"`
 	NavigateSec int `json:"navigateSec"`
@@ -229,6 +281,20 @@ type schedulerFileConfigJSON struct {
 	MaxPerAgentFlight *int   `json:"maxPerAgentInflight"`
 	ResultTTLSec      *int   `json:"resultTTLSec"`
 	WorkerCount       *int   `json:"workerCount"`
+	Store             string `json:"store"`
+	InflightPolicy    string `json:"inflightPolicy"`
+
+	AgentWeights       map[string]int `json:"agentWeights"`
+	StarvationAfterSec *int           `json:"starvationAfterSec"`
+	SerializeTabs      *bool          `json:"serializeTabs"`
+
+	Limits *SchedulerLimitsConfig `json:"limits"`
+
+	WebhookSecret      string `json:"webhookSecret"`
+	WebhookMaxAttempts *int   `json:"webhookMaxAttempts"`
+	WebhookBackoffSec  *int   `json:"webhookBackoffSec"`
+
+	TabPoolSize *int `json:"tabPoolSize"`
 }
 
 type observabilityFileConfigJSON struct {
@@ -283,6 +349,16 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 			NoAnimations:      fc.InstanceDefaults.NoAnimations,
 			StealthLevel:      fc.InstanceDefaults.StealthLevel,
 			TabEvictionPolicy: fc.InstanceDefaults.TabEvictionPolicy,
+			Device:            fc.InstanceDefaults.Device,
+			MemoryMaxMB:       fc.InstanceDefaults.MemoryMaxMB,
+			CPUQuota:          fc.InstanceDefaults.CPUQuota,
+			PidsMax:           fc.InstanceDefaults.PidsMax,
+			MemoryPressure: memoryPressureJSON{
+				TabHeapMB:        fc.InstanceDefaults.MemoryPressure.TabHeapMB,
+				DiscardAboveMB:   fc.InstanceDefaults.MemoryPressure.DiscardAboveMB,
+				RecycleAboveMB:   fc.InstanceDefaults.MemoryPressure.RecycleAboveMB,
+				CheckIntervalSec: fc.InstanceDefaults.MemoryPressure.CheckIntervalSec,
+			},
 		},
 		Security: securityConfigJSON{
 			AllowEvaluate:          fc.Security.AllowEvaluate,
@@ -330,6 +406,18 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 				MaxBackoffSec:  fc.MultiInstance.Restart.MaxBackoffSec,
 				StableAfterSec: fc.MultiInstance.Restart.StableAfterSec,
 			},
+			Elastic: multiInstanceElasticJSON{
+				MinInstances:           fc.MultiInstance.Elastic.MinInstances,
+				MaxInstances:           fc.MultiInstance.Elastic.MaxInstances,
+				ScaleUpQueueDepth:      fc.MultiInstance.Elastic.ScaleUpQueueDepth,
+				ScaleUpTabsPerInstance: fc.MultiInstance.Elastic.ScaleUpTabsPerInstance,
+				IdleCooldownSec:        fc.MultiInstance.Elastic.IdleCooldownSec,
+			},
+			Fleet: multiInstanceFleetJSON{
+				JoinToken:            fc.MultiInstance.Fleet.JoinToken,
+				HeartbeatIntervalSec: fc.MultiInstance.Fleet.HeartbeatIntervalSec,
+				MissedHeartbeats:     fc.MultiInstance.Fleet.MissedHeartbeats,
+			},
 		},
 		Timeouts: timeoutsConfigJSON{
 			ActionSec:   fc.Timeouts.ActionSec,
@@ -346,6 +434,17 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 			MaxPerAgentFlight: fc.Scheduler.MaxPerAgentFlight,
 			ResultTTLSec:      fc.Scheduler.ResultTTLSec,
 			WorkerCount:       fc.Scheduler.WorkerCount,
+			Store:             fc.Scheduler.Store,
+			InflightPolicy:    fc.Scheduler.InflightPolicy,
+
+			AgentWeights:       fc.Scheduler.AgentWeights,
+			StarvationAfterSec: fc.Scheduler.StarvationAfterSec,
+			SerializeTabs:      fc.Scheduler.SerializeTabs,
+			Limits:             fc.Scheduler.Limits,
+			WebhookSecret:      fc.Scheduler.WebhookSecret,
+			WebhookMaxAttempts: fc.Scheduler.WebhookMaxAttempts,
+			WebhookBackoffSec:  fc.Scheduler.WebhookBackoffSec,
+			TabPoolSize:        fc.Scheduler.TabPoolSize,
 		},
 		Observability: observabilityFileConfigJSON{
 			Activity: activityConfigJSON{
@@ -391,6 +490,13 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 	restartInitBackoffSec := int(cfg.RestartInitBackoff / time.Second)
 	restartMaxBackoffSec := int(cfg.RestartMaxBackoff / time.Second)
 	restartStableAfterSec := int(cfg.RestartStableAfter / time.Second)
+	elasticMinInstances := cfg.ElasticMinInstances
+	elasticMaxInstances := cfg.ElasticMaxInstances
+	elasticScaleUpQueueDepth := cfg.ElasticScaleUpQueueDepth
+	elasticScaleUpTabs := cfg.ElasticScaleUpTabs
+	elasticIdleCooldownSec := int(cfg.ElasticIdleCooldown / time.Second)
+	fleetHeartbeatIntervalSec := int(cfg.FleetHeartbeatInterval / time.Second)
+	fleetMissedHeartbeats := cfg.FleetMissedHeartbeats
 	activityEnabled := cfg.Observability.Activity.Enabled
 	activitySessionIdleSec := cfg.Observability.Activity.SessionIdleSec
 	activityRetentionDays := cfg.Observability.Activity.RetentionDays
@@ -436,6 +542,16 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 			NoAnimations:      &noAnimations,
 			StealthLevel:      cfg.StealthLevel,
 			TabEvictionPolicy: cfg.TabEvictionPolicy,
+			Device:            cfg.Device,
+			MemoryMaxMB:       intPtrIfPositive(cfg.InstanceMemoryMaxMB),
+			CPUQuota:          float64PtrIfPositive(cfg.InstanceCPUQuota),
+			PidsMax:           intPtrIfPositive(cfg.InstancePidsMax),
+			MemoryPressure: MemoryPressureConfig{
+				TabHeapMB:        intPtrIfPositive(cfg.MemoryPressureTabHeapMB),
+				DiscardAboveMB:   intPtrIfPositive(cfg.MemoryPressureDiscardMB),
+				RecycleAboveMB:   intPtrIfPositive(cfg.MemoryPressureRecycleMB),
+				CheckIntervalSec: intPtrIfPositive(int(cfg.MemoryPressureInterval / time.Second)),
+			},
 		},
 		Security: SecurityConfig{
 			AllowEvaluate:          &allowEvaluate,
@@ -474,6 +590,18 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 				MaxBackoffSec:  &restartMaxBackoffSec,
 				StableAfterSec: &restartStableAfterSec,
 			},
+			Elastic: MultiInstanceElasticConfig{
+				MinInstances:           &elasticMinInstances,
+				MaxInstances:           &elasticMaxInstances,
+				ScaleUpQueueDepth:      &elasticScaleUpQueueDepth,
+				ScaleUpTabsPerInstance: &elasticScaleUpTabs,
+				IdleCooldownSec:        &elasticIdleCooldownSec,
+			},
+			Fleet: MultiInstanceFleetConfig{
+				JoinToken:            cfg.FleetJoinToken,
+				HeartbeatIntervalSec: &fleetHeartbeatIntervalSec,
+				MissedHeartbeats:     &fleetMissedHeartbeats,
+			},
 		},
 		Timeouts: TimeoutsConfig{
 			ActionSec:   int(cfg.ActionTimeout / time.Second),
@@ -501,6 +629,14 @@ func intPtrIfPositive(v int) *int {
 	return &n
 }
 
+func float64PtrIfPositive(v float64) *float64 {
+	if v <= 0 {
+		return nil
+	}
+	n := v
+	return &n
+}
+
 // legacyFileConfig is the old flat structure for backward compatibility.
 type legacyFileConfig struct {
 	Port              string `json:"port"`
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -19,6 +20,7 @@ func Load() *RuntimeConfig {
 		InstancePortEnd:   9968,
 		Token:             os.Getenv("PINCHTAB_TOKEN"),
 		StateDir:          userConfigDir(),
+		StateBundleKey:    os.Getenv("PINCHTAB_STATE_BUNDLE_KEY"),
 
 		// Security defaults
 		AllowEvaluate:          false,
@@ -55,6 +57,8 @@ func Load() *RuntimeConfig {
 		StealthLevel:      "light",
 		TabEvictionPolicy: "close_lru",
 
+		MemoryPressureInterval: 15 * time.Second,
+
 		// Timeout defaults
 		ActionTimeout:   30 * time.Second,
 		NavigateTimeout: 60 * time.Second,
@@ -69,6 +73,16 @@ func Load() *RuntimeConfig {
 		RestartMaxBackoff:  60 * time.Second,
 		RestartStableAfter: 5 * time.Minute,
 
+		ElasticMinInstances:      1,
+		ElasticMaxInstances:      4,
+		ElasticScaleUpQueueDepth: 10,
+		ElasticScaleUpTabs:       10,
+		ElasticIdleCooldown:      5 * time.Minute,
+
+		FleetJoinToken:         os.Getenv("PINCHTAB_JOIN_TOKEN"),
+		FleetHeartbeatInterval: 10 * time.Second,
+		FleetMissedHeartbeats:  3,
+
 		// Attach defaults
 		AttachEnabled:      false,
 		AttachAllowHosts:   []string{"127.0.0.1", "localhost", "::1"},
@@ -95,6 +109,10 @@ func Load() *RuntimeConfig {
 				RetentionDays:  1,
 			},
 		},
+
+		Scheduler: SchedulerConfig{
+			WebhookSecret: os.Getenv("PINCHTAB_WEBHOOK_SECRET"),
+		},
 	}
 	finalizeProfileConfig(cfg)
 
@@ -307,9 +325,33 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.InstanceDefaults.TabEvictionPolicy != "" {
 		cfg.TabEvictionPolicy = fc.InstanceDefaults.TabEvictionPolicy
 	}
+	if fc.InstanceDefaults.Device != "" {
+		cfg.Device = fc.InstanceDefaults.Device
+	}
 	if fc.InstanceDefaults.DialogAutoAccept != nil {
 		cfg.DialogAutoAccept = *fc.InstanceDefaults.DialogAutoAccept
 	}
+	if fc.InstanceDefaults.MemoryMaxMB != nil {
+		cfg.InstanceMemoryMaxMB = *fc.InstanceDefaults.MemoryMaxMB
+	}
+	if fc.InstanceDefaults.CPUQuota != nil {
+		cfg.InstanceCPUQuota = *fc.InstanceDefaults.CPUQuota
+	}
+	if fc.InstanceDefaults.PidsMax != nil {
+		cfg.InstancePidsMax = *fc.InstanceDefaults.PidsMax
+	}
+	if fc.InstanceDefaults.MemoryPressure.TabHeapMB != nil {
+		cfg.MemoryPressureTabHeapMB = *fc.InstanceDefaults.MemoryPressure.TabHeapMB
+	}
+	if fc.InstanceDefaults.MemoryPressure.DiscardAboveMB != nil {
+		cfg.MemoryPressureDiscardMB = *fc.InstanceDefaults.MemoryPressure.DiscardAboveMB
+	}
+	if fc.InstanceDefaults.MemoryPressure.RecycleAboveMB != nil {
+		cfg.MemoryPressureRecycleMB = *fc.InstanceDefaults.MemoryPressure.RecycleAboveMB
+	}
+	if fc.InstanceDefaults.MemoryPressure.CheckIntervalSec != nil {
+		cfg.MemoryPressureInterval = time.Duration(*fc.InstanceDefaults.MemoryPressure.CheckIntervalSec) * time.Second
+	}
 
 	// Profiles
 	if fc.Profiles.BaseDir != "" {
@@ -346,6 +388,32 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.MultiInstance.Restart.StableAfterSec != nil {
 		cfg.RestartStableAfter = time.Duration(*fc.MultiInstance.Restart.StableAfterSec) * time.Second
 	}
+	// Elastic
+	if fc.MultiInstance.Elastic.MinInstances != nil {
+		cfg.ElasticMinInstances = *fc.MultiInstance.Elastic.MinInstances
+	}
+	if fc.MultiInstance.Elastic.MaxInstances != nil {
+		cfg.ElasticMaxInstances = *fc.MultiInstance.Elastic.MaxInstances
+	}
+	if fc.MultiInstance.Elastic.ScaleUpQueueDepth != nil {
+		cfg.ElasticScaleUpQueueDepth = *fc.MultiInstance.Elastic.ScaleUpQueueDepth
+	}
+	if fc.MultiInstance.Elastic.ScaleUpTabsPerInstance != nil {
+		cfg.ElasticScaleUpTabs = *fc.MultiInstance.Elastic.ScaleUpTabsPerInstance
+	}
+	if fc.MultiInstance.Elastic.IdleCooldownSec != nil {
+		cfg.ElasticIdleCooldown = time.Duration(*fc.MultiInstance.Elastic.IdleCooldownSec) * time.Second
+	}
+	// Fleet
+	if os.Getenv("PINCHTAB_JOIN_TOKEN") == "" {
+		cfg.FleetJoinToken = fc.MultiInstance.Fleet.JoinToken
+	}
+	if fc.MultiInstance.Fleet.HeartbeatIntervalSec != nil {
+		cfg.FleetHeartbeatInterval = time.Duration(*fc.MultiInstance.Fleet.HeartbeatIntervalSec) * time.Second
+	}
+	if fc.MultiInstance.Fleet.MissedHeartbeats != nil {
+		cfg.FleetMissedHeartbeats = *fc.MultiInstance.Fleet.MissedHeartbeats
+	}
 
 	// Attach
 	if fc.Security.Attach.Enabled != nil {
@@ -397,6 +465,36 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.Scheduler.WorkerCount != nil {
 		cfg.Scheduler.WorkerCount = *fc.Scheduler.WorkerCount
 	}
+	if fc.Scheduler.Store != "" {
+		cfg.Scheduler.Store = fc.Scheduler.Store
+	}
+	if fc.Scheduler.InflightPolicy != "" {
+		cfg.Scheduler.InflightPolicy = fc.Scheduler.InflightPolicy
+	}
+	if fc.Scheduler.AgentWeights != nil {
+		cfg.Scheduler.AgentWeights = maps.Clone(fc.Scheduler.AgentWeights)
+	}
+	if fc.Scheduler.StarvationAfterSec != nil {
+		cfg.Scheduler.StarvationAfterSec = *fc.Scheduler.StarvationAfterSec
+	}
+	if fc.Scheduler.SerializeTabs != nil {
+		cfg.Scheduler.SerializeTabs = *fc.Scheduler.SerializeTabs
+	}
+	if fc.Scheduler.Limits != nil {
+		cfg.Scheduler.Limits = *fc.Scheduler.Limits
+	}
+	if os.Getenv("PINCHTAB_WEBHOOK_SECRET") == "" {
+		cfg.Scheduler.WebhookSecret = fc.Scheduler.WebhookSecret
+	}
+	if fc.Scheduler.WebhookMaxAttempts != nil {
+		cfg.Scheduler.WebhookMaxAttempts = *fc.Scheduler.WebhookMaxAttempts
+	}
+	if fc.Scheduler.WebhookBackoffSec != nil {
+		cfg.Scheduler.WebhookBackoffSec = *fc.Scheduler.WebhookBackoffSec
+	}
+	if fc.Scheduler.TabPoolSize != nil {
+		cfg.Scheduler.TabPoolSize = *fc.Scheduler.TabPoolSize
+	}
 }
 
 // ApplyFileConfigToRuntime merges file configuration into an existing runtime
@@ -12,6 +12,10 @@ type RuntimeConfig struct {
 	InstancePortEnd   int // Ending port for instances (default 9968)
 	Token             string
 	StateDir          string
+	// StateBundleKey signs state bundles when no Token is set. A server
+	// passes its own key to the instances it launches so they accept each
+	// other's bundles; it is only read from PINCHTAB_STATE_BUNDLE_KEY.
+	StateBundleKey    string
 	TrustProxyHeaders bool // Only trust X-Forwarded-*/Forwarded headers when behind a trusted reverse proxy
 
 	// Security settings
@@ -51,6 +55,18 @@ type RuntimeConfig struct {
 	NoAnimations      bool
 	StealthLevel      string
 	TabEvictionPolicy string // "close_lru" (default), "reject", "close_oldest"
+	Device            string // device preset emulated on every new tab ("" = none)
+
+	// Resource limits for orchestrator-launched instances (0 = unlimited)
+	InstanceMemoryMaxMB int     // Memory ceiling for the bridge and its Chrome processes
+	InstanceCPUQuota    float64 // CPU ceiling in cores, e.g. 1.5
+	InstancePidsMax     int     // Maximum number of processes and threads
+
+	// Memory pressure handling (0 = off)
+	MemoryPressureTabHeapMB int           // JS heap above which an idle tab is discarded
+	MemoryPressureDiscardMB int           // Instance memory above which idle tabs are discarded, LRU first
+	MemoryPressureRecycleMB int           // Instance memory above which the orchestrator restarts the instance
+	MemoryPressureInterval  time.Duration // How often memory is checked
 
 	// Timeout settings
 	ActionTimeout   time.Duration
@@ -59,13 +75,25 @@ type RuntimeConfig struct {
 	WaitNavDelay    time.Duration
 
 	// Orchestrator settings (dashboard mode only)
-	Strategy           string        // "always-on" (default), "simple", "explicit", or "simple-autorestart"
-	AllocationPolicy   string        // "fcfs" (default), "round_robin", "random"
+	Strategy           string        // "always-on" (default), "simple", "explicit", "simple-autorestart", or "elastic"
+	AllocationPolicy   string        // "fcfs" (default), "round_robin", "random", "least_loaded", "sticky", "profile_affinity"
 	RestartMaxRestarts int           // Max restart attempts for restart-managed strategies (-1 = unlimited, 0 = strategy default)
 	RestartInitBackoff time.Duration // Initial restart backoff (0 = strategy default)
 	RestartMaxBackoff  time.Duration // Maximum restart backoff cap (0 = strategy default)
 	RestartStableAfter time.Duration // Stable runtime window that resets the restart counter (0 = strategy default)
 
+	// Elastic strategy settings
+	ElasticMinInstances      int           // Instances kept running even when idle
+	ElasticMaxInstances      int           // Upper bound on instances the strategy launches
+	ElasticScaleUpQueueDepth int           // Scheduler queue depth that triggers a scale-up
+	ElasticScaleUpTabs       int           // Average tabs per instance that triggers a scale-up
+	ElasticIdleCooldown      time.Duration // How long an instance must stay idle before it is stopped
+
+	// Fleet registration settings
+	FleetJoinToken         string        // Token remote bridges present to register; empty disables registration
+	FleetHeartbeatInterval time.Duration // How often registered bridges send heartbeats
+	FleetMissedHeartbeats  int           // Missed heartbeats before a registered bridge is removed
+
 	// Attach settings
 	AttachEnabled      bool
 	AttachAllowHosts   []string
@@ -116,6 +144,41 @@ type SchedulerConfig struct {
 	MaxPerAgentFlight int    `json:"maxPerAgentInflight,omitempty"`
 	ResultTTLSec      int    `json:"resultTTLSec,omitempty"`
 	WorkerCount       int    `json:"workerCount,omitempty"`
+	Store             string `json:"store,omitempty"`          // "memory" (default) or "file"
+	InflightPolicy    string `json:"inflightPolicy,omitempty"` // "fail" (default) or "requeue"
+	// AgentWeights are per-agent shares for the "weighted-fair" strategy.
+	AgentWeights       map[string]int `json:"agentWeights,omitempty"`
+	StarvationAfterSec int            `json:"starvationAfterSec,omitempty"` // "priority" strategy starvation bound
+	SerializeTabs      bool           `json:"serializeTabs,omitempty"`
+	// Limits are per-agent rate limits and daily quotas.
+	Limits SchedulerLimitsConfig `json:"limits,omitempty"`
+	// WebhookSecret signs task callbacks. PINCHTAB_WEBHOOK_SECRET overrides it.
+	WebhookSecret      string `json:"webhookSecret,omitempty"`
+	WebhookMaxAttempts int    `json:"webhookMaxAttempts,omitempty"`
+	WebhookBackoffSec  int    `json:"webhookBackoffSec,omitempty"` // first retry delay, doubled per attempt
+	// TabPoolSize is how many idle scheduler-opened tabs are kept per profile.
+	TabPoolSize int `json:"tabPoolSize,omitempty"`
+}
+
+// SchedulerRateLimit caps an agent's submissions. Zero fields are unlimited.
+type SchedulerRateLimit struct {
+	PerSecond float64 `json:"perSecond,omitempty"` // token-bucket refill rate
+	Burst     int     `json:"burst,omitempty"`     // bucket size
+	Daily     int     `json:"daily,omitempty"`     // tasks per UTC day
+}
+
+// SchedulerAgentLimits are one agent's limits, with extra limits per
+// action kind (e.g. "evaluate").
+type SchedulerAgentLimits struct {
+	SchedulerRateLimit
+	Actions map[string]SchedulerRateLimit `json:"actions,omitempty"`
+}
+
+// SchedulerLimitsConfig holds the limits for every agent and per-agent
+// overrides that replace them.
+type SchedulerLimitsConfig struct {
+	Default SchedulerAgentLimits            `json:"default,omitempty"`
+	Agents  map[string]SchedulerAgentLimits `json:"agents,omitempty"`
 }
 
 type ObservabilityConfig struct {
@@ -174,6 +237,23 @@ type InstanceDefaultsConfig struct {
 	StealthLevel      string `json:"stealthLevel,omitempty"`
 	TabEvictionPolicy string `json:"tabEvictionPolicy,omitempty"`
 	DialogAutoAccept  *bool  `json:"dialogAutoAccept,omitempty"`
+	Device            string `json:"device,omitempty"`
+
+	MemoryMaxMB *int     `json:"memoryMaxMB,omitempty"`
+	CPUQuota    *float64 `json:"cpuQuota,omitempty"`
+	PidsMax     *int     `json:"pidsMax,omitempty"`
+
+	MemoryPressure MemoryPressureConfig `json:"memoryPressure,omitempty"`
+}
+
+// MemoryPressureConfig controls how instances shed memory before they hit
+// their limits: idle tabs are discarded first, then the whole instance is
+// restarted with its tabs restored.
+type MemoryPressureConfig struct {
+	TabHeapMB        *int `json:"tabHeapMB,omitempty"`
+	DiscardAboveMB   *int `json:"discardAboveMB,omitempty"`
+	RecycleAboveMB   *int `json:"recycleAboveMB,omitempty"`
+	CheckIntervalSec *int `json:"checkIntervalSec,omitempty"`
 }
 
 type ProfilesConfig struct {
@@ -206,6 +286,8 @@ type MultiInstanceConfig struct {
 	InstancePortStart *int                       `json:"instancePortStart,omitempty"`
 	InstancePortEnd   *int                       `json:"instancePortEnd,omitempty"`
 	Restart           MultiInstanceRestartConfig `json:"restart,omitempty"`
+	Elastic           MultiInstanceElasticConfig `json:"elastic,omitempty"`
+	Fleet             MultiInstanceFleetConfig   `json:"fleet,omitempty"`
 }
 
 // MultiInstanceRestartConfig controls restart-managed strategy recovery behavior.
@@ -216,6 +298,23 @@ type MultiInstanceRestartConfig struct {
 	StableAfterSec *int `json:"stableAfterSec,omitempty"`
 }
 
+// MultiInstanceElasticConfig controls pool sizing for the elastic strategy.
+type MultiInstanceElasticConfig struct {
+	MinInstances           *int `json:"minInstances,omitempty"`
+	MaxInstances           *int `json:"maxInstances,omitempty"`
+	ScaleUpQueueDepth      *int `json:"scaleUpQueueDepth,omitempty"`
+	ScaleUpTabsPerInstance *int `json:"scaleUpTabsPerInstance,omitempty"`
+	IdleCooldownSec        *int `json:"idleCooldownSec,omitempty"`
+}
+
+// MultiInstanceFleetConfig controls registration of remote bridges started
+// with `pinchtab bridge --register`.
+type MultiInstanceFleetConfig struct {
+	JoinToken            string `json:"joinToken,omitempty"`
+	HeartbeatIntervalSec *int   `json:"heartbeatIntervalSec,omitempty"`
+	MissedHeartbeats     *int   `json:"missedHeartbeats,omitempty"`
+}
+
 type AttachConfig struct {
 	Enabled      *bool    `json:"enabled,omitempty"`
 	AllowHosts   []string `json:"allowHosts,omitempty"`
@@ -238,6 +337,20 @@ type SchedulerFileConfig struct {
 	MaxPerAgentFlight *int   `json:"maxPerAgentInflight,omitempty"`
 	ResultTTLSec      *int   `json:"resultTTLSec,omitempty"`
 	WorkerCount       *int   `json:"workerCount,omitempty"`
+	Store             string `json:"store,omitempty"`
+	InflightPolicy    string `json:"inflightPolicy,omitempty"`
+
+	AgentWeights       map[string]int `json:"agentWeights,omitempty"`
+	StarvationAfterSec *int           `json:"starvationAfterSec,omitempty"`
+	SerializeTabs      *bool          `json:"serializeTabs,omitempty"`
+
+	Limits *SchedulerLimitsConfig `json:"limits,omitempty"`
+
+	WebhookSecret      string `json:"webhookSecret,omitempty"`
+	WebhookMaxAttempts *int   `json:"webhookMaxAttempts,omitempty"`
+	WebhookBackoffSec  *int   `json:"webhookBackoffSec,omitempty"`
+
+	TabPoolSize *int `json:"tabPoolSize,omitempty"`
 }
 
 type ObservabilityFileConfig struct {
@@ -92,6 +92,8 @@ func getInstanceDefaultsField(c *InstanceDefaultsConfig, field string) (string,
 		return c.StealthLevel, nil
 	case "tabEvictionPolicy":
 		return c.TabEvictionPolicy, nil
+	case "device":
+		return c.Device, nil
 	default:
 		return "", fmt.Errorf("unknown field instanceDefaults.%s", field)
 	}
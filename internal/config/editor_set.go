@@ -125,6 +125,8 @@ func setInstanceDefaultsField(c *InstanceDefaultsConfig, field, value string) er
 		c.StealthLevel = value
 	case "tabEvictionPolicy":
 		c.TabEvictionPolicy = value
+	case "device":
+		c.Device = value
 	default:
 		return fmt.Errorf("unknown field instanceDefaults.%s", field)
 	}
@@ -44,6 +44,7 @@ func TestSetConfigValue_BrowserAndInstanceDefaultsFields(t *testing.T) {
 		{"instanceDefaults.maxTabs", "50", func(fc *FileConfig) bool { return *fc.InstanceDefaults.MaxTabs == 50 }, false},
 		{"instanceDefaults.stealthLevel", "full", func(fc *FileConfig) bool { return fc.InstanceDefaults.StealthLevel == "full" }, false},
 		{"instanceDefaults.tabEvictionPolicy", "close_lru", func(fc *FileConfig) bool { return fc.InstanceDefaults.TabEvictionPolicy == "close_lru" }, false},
+		{"instanceDefaults.device", "pixel-8", func(fc *FileConfig) bool { return fc.InstanceDefaults.Device == "pixel-8" }, false},
 		{"instanceDefaults.blockAds", "yes", func(fc *FileConfig) bool { return *fc.InstanceDefaults.BlockAds == true }, false},
 		{"profiles.baseDir", "/tmp/profiles", func(fc *FileConfig) bool { return fc.Profiles.BaseDir == "/tmp/profiles" }, false},
 		{"instanceDefaults.noRestore", "maybe", nil, true},
@@ -2,8 +2,18 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/devices"
+)
+
+// Lower bounds for instance resource limits. Chrome cannot start below
+// these, so smaller values are almost certainly typos.
+const (
+	minInstanceMemoryMaxMB = 128
+	minInstanceCPUQuota    = 0.01
 )
 
 // ValidationError represents a configuration validation error.
@@ -80,6 +90,56 @@ func ValidateFileConfig(fc *FileConfig) []error {
 			Message: fmt.Sprintf("init backoff (%d) must be <= max backoff (%d)", *fc.MultiInstance.Restart.InitBackoffSec, *fc.MultiInstance.Restart.MaxBackoffSec),
 		})
 	}
+	elastic := fc.MultiInstance.Elastic
+	if elastic.MinInstances != nil && *elastic.MinInstances < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "multiInstance.elastic.minInstances",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", *elastic.MinInstances),
+		})
+	}
+	if elastic.MaxInstances != nil && *elastic.MaxInstances < 1 {
+		errs = append(errs, ValidationError{
+			Field:   "multiInstance.elastic.maxInstances",
+			Message: fmt.Sprintf("must be >= 1 (got %d)", *elastic.MaxInstances),
+		})
+	}
+	if elastic.MinInstances != nil && elastic.MaxInstances != nil && *elastic.MinInstances > *elastic.MaxInstances {
+		errs = append(errs, ValidationError{
+			Field:   "multiInstance.elastic.minInstances/maxInstances",
+			Message: fmt.Sprintf("min instances (%d) must be <= max instances (%d)", *elastic.MinInstances, *elastic.MaxInstances),
+		})
+	}
+	if elastic.ScaleUpQueueDepth != nil && *elastic.ScaleUpQueueDepth < 1 {
+		errs = append(errs, ValidationError{
+			Field:   "multiInstance.elastic.scaleUpQueueDepth",
+			Message: fmt.Sprintf("must be >= 1 (got %d)", *elastic.ScaleUpQueueDepth),
+		})
+	}
+	if elastic.ScaleUpTabsPerInstance != nil && *elastic.ScaleUpTabsPerInstance < 1 {
+		errs = append(errs, ValidationError{
+			Field:   "multiInstance.elastic.scaleUpTabsPerInstance",
+			Message: fmt.Sprintf("must be >= 1 (got %d)", *elastic.ScaleUpTabsPerInstance),
+		})
+	}
+	if elastic.IdleCooldownSec != nil && *elastic.IdleCooldownSec < 1 {
+		errs = append(errs, ValidationError{
+			Field:   "multiInstance.elastic.idleCooldownSec",
+			Message: fmt.Sprintf("must be >= 1 (got %d)", *elastic.IdleCooldownSec),
+		})
+	}
+	fleet := fc.MultiInstance.Fleet
+	if fleet.HeartbeatIntervalSec != nil && *fleet.HeartbeatIntervalSec < 1 {
+		errs = append(errs, ValidationError{
+			Field:   "multiInstance.fleet.heartbeatIntervalSec",
+			Message: fmt.Sprintf("must be >= 1 (got %d)", *fleet.HeartbeatIntervalSec),
+		})
+	}
+	if fleet.MissedHeartbeats != nil && *fleet.MissedHeartbeats < 1 {
+		errs = append(errs, ValidationError{
+			Field:   "multiInstance.fleet.missedHeartbeats",
+			Message: fmt.Sprintf("must be >= 1 (got %d)", *fleet.MissedHeartbeats),
+		})
+	}
 
 	// Instance defaults validation
 	if fc.InstanceDefaults.Mode != "" && fc.InstanceDefaults.Mode != "headless" && fc.InstanceDefaults.Mode != "headed" {
@@ -104,6 +164,12 @@ func ValidateFileConfig(fc *FileConfig) []error {
 			})
 		}
 	}
+	if fc.InstanceDefaults.Device != "" && !devices.Valid(fc.InstanceDefaults.Device) {
+		errs = append(errs, ValidationError{
+			Field:   "instanceDefaults.device",
+			Message: fmt.Sprintf("invalid value %q (must be one of %s)", fc.InstanceDefaults.Device, strings.Join(devices.Names(), ", ")),
+		})
+	}
 	if fc.InstanceDefaults.MaxTabs != nil && *fc.InstanceDefaults.MaxTabs < 1 {
 		errs = append(errs, ValidationError{
 			Field:   "instanceDefaults.maxTabs",
@@ -116,13 +182,64 @@ func ValidateFileConfig(fc *FileConfig) []error {
 			Message: fmt.Sprintf("must be >= 0 (got %d)", *fc.InstanceDefaults.MaxParallelTabs),
 		})
 	}
+	if fc.InstanceDefaults.MemoryMaxMB != nil {
+		if v := *fc.InstanceDefaults.MemoryMaxMB; v != 0 && v < minInstanceMemoryMaxMB {
+			errs = append(errs, ValidationError{
+				Field:   "instanceDefaults.memoryMaxMB",
+				Message: fmt.Sprintf("must be 0 (unlimited) or >= %d (got %d)", minInstanceMemoryMaxMB, v),
+			})
+		}
+	}
+	if fc.InstanceDefaults.CPUQuota != nil {
+		if v := *fc.InstanceDefaults.CPUQuota; v != 0 && v < minInstanceCPUQuota {
+			errs = append(errs, ValidationError{
+				Field:   "instanceDefaults.cpuQuota",
+				Message: fmt.Sprintf("must be 0 (unlimited) or >= %g cores (got %g)", minInstanceCPUQuota, v),
+			})
+		}
+	}
+	if fc.InstanceDefaults.PidsMax != nil && *fc.InstanceDefaults.PidsMax < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "instanceDefaults.pidsMax",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", *fc.InstanceDefaults.PidsMax),
+		})
+	}
+	pressure := fc.InstanceDefaults.MemoryPressure
+	for _, f := range []struct {
+		name string
+		v    *int
+	}{
+		{"tabHeapMB", pressure.TabHeapMB},
+		{"discardAboveMB", pressure.DiscardAboveMB},
+		{"recycleAboveMB", pressure.RecycleAboveMB},
+	} {
+		if f.v != nil && *f.v < 0 {
+			errs = append(errs, ValidationError{
+				Field:   "instanceDefaults.memoryPressure." + f.name,
+				Message: fmt.Sprintf("must be >= 0 (got %d)", *f.v),
+			})
+		}
+	}
+	if pressure.DiscardAboveMB != nil && pressure.RecycleAboveMB != nil &&
+		*pressure.DiscardAboveMB > 0 && *pressure.RecycleAboveMB > 0 && *pressure.RecycleAboveMB < *pressure.DiscardAboveMB {
+		errs = append(errs, ValidationError{
+			Field:   "instanceDefaults.memoryPressure.discardAboveMB/recycleAboveMB",
+			Message: fmt.Sprintf("recycle threshold (%d) must be >= discard threshold (%d)", *pressure.RecycleAboveMB, *pressure.DiscardAboveMB),
+		})
+	}
+	if pressure.CheckIntervalSec != nil && *pressure.CheckIntervalSec < 1 {
+		errs = append(errs, ValidationError{
+			Field:   "instanceDefaults.memoryPressure.checkIntervalSec",
+			Message: fmt.Sprintf("must be >= 1 (got %d)", *pressure.CheckIntervalSec),
+		})
+	}
 
 	// Multi-instance validation
 	if fc.MultiInstance.Strategy != "" {
 		if !isValidStrategy(fc.MultiInstance.Strategy) {
 			errs = append(errs, ValidationError{
 				Field:   "multiInstance.strategy",
-				Message: fmt.Sprintf("invalid value %q (must be simple, explicit, simple-autorestart, or always-on)", fc.MultiInstance.Strategy),
+				Message: fmt.Sprintf("invalid value %q (must be simple, explicit, simple-autorestart, always-on, no-instance, or elastic)", fc.MultiInstance.Strategy),
 			})
 		}
 	}
@@ -130,7 +247,7 @@ func ValidateFileConfig(fc *FileConfig) []error {
 		if !isValidAllocationPolicy(fc.MultiInstance.AllocationPolicy) {
 			errs = append(errs, ValidationError{
 				Field:   "multiInstance.allocationPolicy",
-				Message: fmt.Sprintf("invalid value %q (must be fcfs, round_robin, or random)", fc.MultiInstance.AllocationPolicy),
+				Message: fmt.Sprintf("invalid value %q (must be fcfs, round_robin, random, least_loaded, sticky, or profile_affinity)", fc.MultiInstance.AllocationPolicy),
 			})
 		}
 	}
@@ -191,6 +308,64 @@ func ValidateFileConfig(fc *FileConfig) []error {
 		})
 	}
 
+	// Scheduler validation
+	if fc.Scheduler.Store != "" && fc.Scheduler.Store != "memory" && fc.Scheduler.Store != "file" {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.store",
+			Message: fmt.Sprintf("invalid value %q (must be memory or file)", fc.Scheduler.Store),
+		})
+	}
+	if fc.Scheduler.InflightPolicy != "" && fc.Scheduler.InflightPolicy != "fail" && fc.Scheduler.InflightPolicy != "requeue" {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.inflightPolicy",
+			Message: fmt.Sprintf("invalid value %q (must be fail or requeue)", fc.Scheduler.InflightPolicy),
+		})
+	}
+	if fc.Scheduler.Strategy != "" && !isValidSchedulerStrategy(fc.Scheduler.Strategy) {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.strategy",
+			Message: fmt.Sprintf("invalid value %q (must be fair-fifo, weighted-fair or priority)", fc.Scheduler.Strategy),
+		})
+	}
+	for agentID, w := range fc.Scheduler.AgentWeights {
+		if w <= 0 {
+			errs = append(errs, ValidationError{
+				Field:   "scheduler.agentWeights." + agentID,
+				Message: fmt.Sprintf("must be > 0 (got %d)", w),
+			})
+		}
+	}
+	if fc.Scheduler.StarvationAfterSec != nil && *fc.Scheduler.StarvationAfterSec < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.starvationAfterSec",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", *fc.Scheduler.StarvationAfterSec),
+		})
+	}
+	if n := fc.Scheduler.WebhookMaxAttempts; n != nil && (*n < 0 || *n > 10) {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.webhookMaxAttempts",
+			Message: fmt.Sprintf("must be between 0 and 10 (got %d)", *n),
+		})
+	}
+	if n := fc.Scheduler.WebhookBackoffSec; n != nil && *n < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.webhookBackoffSec",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", *n),
+		})
+	}
+	if n := fc.Scheduler.TabPoolSize; n != nil && *n < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.tabPoolSize",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", *n),
+		})
+	}
+	if l := fc.Scheduler.Limits; l != nil {
+		errs = append(errs, validateSchedulerAgentLimits("scheduler.limits.default", l.Default)...)
+		for agentID, al := range l.Agents {
+			errs = append(errs, validateSchedulerAgentLimits("scheduler.limits.agents."+agentID, al)...)
+		}
+	}
+
 	if fc.Observability.Activity.SessionIdleSec != nil && *fc.Observability.Activity.SessionIdleSec < 0 {
 		errs = append(errs, ValidationError{
 			Field:   "observability.activity.sessionIdleSec",
@@ -261,9 +436,50 @@ func isValidEvictionPolicy(policy string) bool {
 	}
 }
 
+// validateSchedulerAgentLimits rejects negative rates and counts.
+func validateSchedulerAgentLimits(field string, l SchedulerAgentLimits) []error {
+	errs := validateSchedulerRateLimit(field, l.SchedulerRateLimit)
+	for action, al := range l.Actions {
+		errs = append(errs, validateSchedulerRateLimit(field+".actions."+action, al)...)
+	}
+	return errs
+}
+
+func validateSchedulerRateLimit(field string, l SchedulerRateLimit) []error {
+	var errs []error
+	if l.PerSecond < 0 || math.IsNaN(l.PerSecond) || math.IsInf(l.PerSecond, 0) {
+		errs = append(errs, ValidationError{
+			Field:   field + ".perSecond",
+			Message: fmt.Sprintf("must be a non-negative number (got %g)", l.PerSecond),
+		})
+	}
+	if l.Burst < 0 {
+		errs = append(errs, ValidationError{
+			Field:   field + ".burst",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", l.Burst),
+		})
+	}
+	if l.Daily < 0 {
+		errs = append(errs, ValidationError{
+			Field:   field + ".daily",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", l.Daily),
+		})
+	}
+	return errs
+}
+
+func isValidSchedulerStrategy(strategy string) bool {
+	switch strategy {
+	case "fair-fifo", "weighted-fair", "priority":
+		return true
+	default:
+		return false
+	}
+}
+
 func isValidStrategy(strategy string) bool {
 	switch strategy {
-	case "simple", "explicit", "simple-autorestart", "always-on", "no-instance":
+	case "simple", "explicit", "simple-autorestart", "always-on", "no-instance", "elastic":
 		return true
 	default:
 		return false
@@ -272,7 +488,7 @@ func isValidStrategy(strategy string) bool {
 
 func isValidAllocationPolicy(policy string) bool {
 	switch policy {
-	case "fcfs", "round_robin", "random":
+	case "fcfs", "round_robin", "random", "least_loaded", "sticky", "profile_affinity":
 		return true
 	default:
 		return false
@@ -297,7 +513,7 @@ func ValidEvictionPolicies() []string {
 }
 
 func ValidStrategies() []string {
-	return []string{"simple", "explicit", "simple-autorestart", "always-on", "no-instance"}
+	return []string{"simple", "explicit", "simple-autorestart", "always-on", "no-instance", "elastic"}
 }
 
 // validateIDPIConfig validates the security.idpi sub-section.
@@ -370,7 +586,7 @@ func validatePositiveIntLimit(field string, value *int, max int) []error {
 
 // ValidAllocationPolicies returns all valid allocation policy values.
 func ValidAllocationPolicies() []string {
-	return []string{"fcfs", "round_robin", "random"}
+	return []string{"fcfs", "round_robin", "random", "least_loaded", "sticky", "profile_affinity"}
 }
 
 // ValidAttachSchemes returns all valid attach URL schemes.
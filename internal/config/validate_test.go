@@ -216,6 +216,75 @@ func TestValidateFileConfig_InvalidEvictionPolicy(t *testing.T) {
 	}
 }
 
+func TestValidateFileConfig_InvalidDevice(t *testing.T) {
+	tests := []struct {
+		device  string
+		wantErr bool
+	}{
+		{"", false},
+		{"pixel-8", false},
+		{"iPhone-15", false},
+		{"nokia-3310", true},
+	}
+
+	for _, tt := range tests {
+		fc := &FileConfig{
+			InstanceDefaults: InstanceDefaultsConfig{Device: tt.device},
+		}
+		errs := ValidateFileConfig(fc)
+		hasErr := len(errs) > 0
+		if hasErr != tt.wantErr {
+			t.Errorf("device=%q: got error=%v, want error=%v", tt.device, hasErr, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateFileConfig_Elastic(t *testing.T) {
+	tests := []struct {
+		name    string
+		elastic MultiInstanceElasticConfig
+		wantErr bool
+	}{
+		{"defaults", MultiInstanceElasticConfig{}, false},
+		{"scale to zero", MultiInstanceElasticConfig{MinInstances: intPtr(0), MaxInstances: intPtr(2)}, false},
+		{"negative min", MultiInstanceElasticConfig{MinInstances: intPtr(-1)}, true},
+		{"zero max", MultiInstanceElasticConfig{MaxInstances: intPtr(0)}, true},
+		{"min above max", MultiInstanceElasticConfig{MinInstances: intPtr(3), MaxInstances: intPtr(2)}, true},
+		{"zero queue depth", MultiInstanceElasticConfig{ScaleUpQueueDepth: intPtr(0)}, true},
+		{"zero tabs per instance", MultiInstanceElasticConfig{ScaleUpTabsPerInstance: intPtr(0)}, true},
+		{"zero cooldown", MultiInstanceElasticConfig{IdleCooldownSec: intPtr(0)}, true},
+	}
+
+	for _, tt := range tests {
+		fc := &FileConfig{MultiInstance: MultiInstanceConfig{Elastic: tt.elastic}}
+		errs := ValidateFileConfig(fc)
+		if hasErr := len(errs) > 0; hasErr != tt.wantErr {
+			t.Errorf("%s: got errors %v, want error=%v", tt.name, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateFileConfig_Fleet(t *testing.T) {
+	tests := []struct {
+		name    string
+		fleet   MultiInstanceFleetConfig
+		wantErr bool
+	}{
+		{"defaults", MultiInstanceFleetConfig{}, false},
+		{"custom", MultiInstanceFleetConfig{JoinToken: "join", HeartbeatIntervalSec: intPtr(5), MissedHeartbeats: intPtr(2)}, false},
+		{"zero interval", MultiInstanceFleetConfig{HeartbeatIntervalSec: intPtr(0)}, true},
+		{"zero missed", MultiInstanceFleetConfig{MissedHeartbeats: intPtr(0)}, true},
+	}
+
+	for _, tt := range tests {
+		fc := &FileConfig{MultiInstance: MultiInstanceConfig{Fleet: tt.fleet}}
+		errs := ValidateFileConfig(fc)
+		if hasErr := len(errs) > 0; hasErr != tt.wantErr {
+			t.Errorf("%s: got errors %v, want error=%v", tt.name, errs, tt.wantErr)
+		}
+	}
+}
+
 func TestValidateFileConfig_InvalidStrategy(t *testing.T) {
 	tests := []struct {
 		strategy string
@@ -225,6 +294,7 @@ func TestValidateFileConfig_InvalidStrategy(t *testing.T) {
 		{"explicit", false},
 		{"simple-autorestart", false},
 		{"always-on", false},
+		{"elastic", false},
 		{"", false},
 		{"auto", true},
 		{"default", true},
@@ -242,6 +312,53 @@ func TestValidateFileConfig_InvalidStrategy(t *testing.T) {
 	}
 }
 
+func TestValidateFileConfig_SchedulerStrategy(t *testing.T) {
+	tests := []struct {
+		scheduler SchedulerFileConfig
+		wantErr   bool
+	}{
+		{SchedulerFileConfig{Strategy: "fair-fifo"}, false},
+		{SchedulerFileConfig{Strategy: "weighted-fair", AgentWeights: map[string]int{"a1": 3}}, false},
+		{SchedulerFileConfig{Strategy: "priority"}, false},
+		{SchedulerFileConfig{Strategy: "lifo"}, true},
+		{SchedulerFileConfig{AgentWeights: map[string]int{"a1": 0}}, true},
+		{SchedulerFileConfig{StarvationAfterSec: intPtr(-1)}, true},
+		{SchedulerFileConfig{WebhookMaxAttempts: intPtr(3), WebhookBackoffSec: intPtr(2)}, false},
+		{SchedulerFileConfig{WebhookMaxAttempts: intPtr(11)}, true},
+		{SchedulerFileConfig{WebhookBackoffSec: intPtr(-1)}, true},
+		{SchedulerFileConfig{TabPoolSize: intPtr(8)}, false},
+		{SchedulerFileConfig{TabPoolSize: intPtr(-1)}, true},
+	}
+
+	for _, tt := range tests {
+		errs := ValidateFileConfig(&FileConfig{Scheduler: tt.scheduler})
+		hasErr := len(errs) > 0
+		if hasErr != tt.wantErr {
+			t.Errorf("scheduler=%+v: got errors=%v, want error=%v", tt.scheduler, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateFileConfig_SchedulerLimits(t *testing.T) {
+	tests := []struct {
+		limits  SchedulerLimitsConfig
+		wantErr bool
+	}{
+		{SchedulerLimitsConfig{Default: SchedulerAgentLimits{SchedulerRateLimit: SchedulerRateLimit{PerSecond: 2, Burst: 5, Daily: 1000}}}, false},
+		{SchedulerLimitsConfig{Default: SchedulerAgentLimits{SchedulerRateLimit: SchedulerRateLimit{PerSecond: -1}}}, true},
+		{SchedulerLimitsConfig{Agents: map[string]SchedulerAgentLimits{"a1": {SchedulerRateLimit: SchedulerRateLimit{Daily: -5}}}}, true},
+		{SchedulerLimitsConfig{Default: SchedulerAgentLimits{Actions: map[string]SchedulerRateLimit{"evaluate": {Burst: -1}}}}, true},
+	}
+
+	for _, tt := range tests {
+		errs := ValidateFileConfig(&FileConfig{Scheduler: SchedulerFileConfig{Limits: &tt.limits}})
+		hasErr := len(errs) > 0
+		if hasErr != tt.wantErr {
+			t.Errorf("limits=%+v: got errors=%v, want error=%v", tt.limits, errs, tt.wantErr)
+		}
+	}
+}
+
 func TestValidateFileConfig_InvalidAllocationPolicy(t *testing.T) {
 	tests := []struct {
 		policy  string
@@ -250,6 +367,9 @@ func TestValidateFileConfig_InvalidAllocationPolicy(t *testing.T) {
 		{"fcfs", false},
 		{"round_robin", false},
 		{"random", false},
+		{"least_loaded", false},
+		{"sticky", false},
+		{"profile_affinity", false},
 		{"", false},
 		{"fifo", true},
 		{"roundrobin", true}, // underscore required
@@ -326,6 +446,53 @@ func TestValidateFileConfig_InvalidMaxTabs(t *testing.T) {
 	}
 }
 
+func TestValidateFileConfig_ResourceLimits(t *testing.T) {
+	quota := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name     string
+		defaults InstanceDefaultsConfig
+		wantErr  bool
+	}{
+		{"unset", InstanceDefaultsConfig{}, false},
+		{"unlimited", InstanceDefaultsConfig{MemoryMaxMB: intPtr(0), CPUQuota: quota(0), PidsMax: intPtr(0)}, false},
+		{"custom", InstanceDefaultsConfig{MemoryMaxMB: intPtr(2048), CPUQuota: quota(1.5), PidsMax: intPtr(512)}, false},
+		{"tiny memory", InstanceDefaultsConfig{MemoryMaxMB: intPtr(16)}, true},
+		{"negative memory", InstanceDefaultsConfig{MemoryMaxMB: intPtr(-1)}, true},
+		{"tiny cpu", InstanceDefaultsConfig{CPUQuota: quota(0.001)}, true},
+		{"negative pids", InstanceDefaultsConfig{PidsMax: intPtr(-5)}, true},
+	}
+
+	for _, tt := range tests {
+		fc := &FileConfig{InstanceDefaults: tt.defaults}
+		errs := ValidateFileConfig(fc)
+		if hasErr := len(errs) > 0; hasErr != tt.wantErr {
+			t.Errorf("%s: got errors %v, want error=%v", tt.name, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateFileConfig_MemoryPressure(t *testing.T) {
+	tests := []struct {
+		name     string
+		pressure MemoryPressureConfig
+		wantErr  bool
+	}{
+		{"unset", MemoryPressureConfig{}, false},
+		{"custom", MemoryPressureConfig{TabHeapMB: intPtr(512), DiscardAboveMB: intPtr(1500), RecycleAboveMB: intPtr(2500), CheckIntervalSec: intPtr(10)}, false},
+		{"negative tab heap", MemoryPressureConfig{TabHeapMB: intPtr(-1)}, true},
+		{"recycle below discard", MemoryPressureConfig{DiscardAboveMB: intPtr(2000), RecycleAboveMB: intPtr(1000)}, true},
+		{"zero interval", MemoryPressureConfig{CheckIntervalSec: intPtr(0)}, true},
+	}
+
+	for _, tt := range tests {
+		fc := &FileConfig{InstanceDefaults: InstanceDefaultsConfig{MemoryPressure: tt.pressure}}
+		errs := ValidateFileConfig(fc)
+		if hasErr := len(errs) > 0; hasErr != tt.wantErr {
+			t.Errorf("%s: got errors %v, want error=%v", tt.name, errs, tt.wantErr)
+		}
+	}
+}
+
 func TestValidateFileConfig_InvalidTimeouts(t *testing.T) {
 	fc := &FileConfig{
 		Timeouts: TimeoutsConfig{
@@ -136,6 +136,11 @@ func (c *ConfigAPI) HandlePutConfig(w http.ResponseWriter, r *http.Request) {
 		Server struct {
 			Token *string `json:"token"`
 		} `json:"server"`
+		MultiInstance struct {
+			Fleet struct {
+				JoinToken *string `json:"joinToken"`
+			} `json:"fleet"`
+		} `json:"multiInstance"`
 	}
 	if err := json.Unmarshal(body, &tokenProbe); err != nil {
 		httpx.ErrorCode(w, 400, "bad_config_json", "invalid config payload", false, nil)
@@ -145,6 +150,10 @@ func (c *ConfigAPI) HandlePutConfig(w http.ResponseWriter, r *http.Request) {
 		httpx.ErrorCode(w, 400, "token_write_only", "manage the API token outside the dashboard", false, nil)
 		return
 	}
+	if joinToken := tokenProbe.MultiInstance.Fleet.JoinToken; joinToken != nil && strings.TrimSpace(*joinToken) != "" {
+		httpx.ErrorCode(w, 400, "token_write_only", "manage the fleet join token outside the dashboard", false, nil)
+		return
+	}
 
 	normalized := *current
 	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&normalized); err != nil {
@@ -163,6 +172,7 @@ func (c *ConfigAPI) HandlePutConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	normalized.Server.Token = current.Server.Token
+	normalized.MultiInstance.Fleet.JoinToken = current.MultiInstance.Fleet.JoinToken
 	if err := config.SaveFileConfig(&normalized, path); err != nil {
 		httpx.Error(w, 500, err)
 		return
@@ -257,6 +267,7 @@ func (c *ConfigAPI) tokenConfigured(cfg config.FileConfig) bool {
 
 func redactToken(cfg config.FileConfig) config.FileConfig {
 	cfg.Server.Token = ""
+	cfg.MultiInstance.Fleet.JoinToken = ""
 	return cfg
 }
 
@@ -281,6 +292,13 @@ func (c *ConfigAPI) restartReasonsFor(next config.FileConfig) []string {
 		!sameIntPtr(c.boot.MultiInstance.Restart.StableAfterSec, next.MultiInstance.Restart.StableAfterSec) {
 		reasons = append(reasons, "Restart policy")
 	}
+	if !sameIntPtr(c.boot.MultiInstance.Elastic.MinInstances, next.MultiInstance.Elastic.MinInstances) ||
+		!sameIntPtr(c.boot.MultiInstance.Elastic.MaxInstances, next.MultiInstance.Elastic.MaxInstances) ||
+		!sameIntPtr(c.boot.MultiInstance.Elastic.ScaleUpQueueDepth, next.MultiInstance.Elastic.ScaleUpQueueDepth) ||
+		!sameIntPtr(c.boot.MultiInstance.Elastic.ScaleUpTabsPerInstance, next.MultiInstance.Elastic.ScaleUpTabsPerInstance) ||
+		!sameIntPtr(c.boot.MultiInstance.Elastic.IdleCooldownSec, next.MultiInstance.Elastic.IdleCooldownSec) {
+		reasons = append(reasons, "Elastic pool")
+	}
 
 	return reasons
 }
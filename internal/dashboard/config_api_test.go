@@ -214,6 +214,41 @@ func TestHandlePutConfigRejectsWriteOnlyTokenField(t *testing.T) {
 	}
 }
 
+func TestHandleConfigKeepsFleetJoinTokenWriteOnly(t *testing.T) {
+	fc := config.DefaultFileConfig()
+	fc.MultiInstance.Fleet.JoinToken = "join-secret"
+
+	api := newConfigAPITestAPI(t, fc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+	w := httptest.NewRecorder()
+	api.HandleGetConfig(w, req)
+	if env := decodeConfigEnvelope(t, w); env.Config.MultiInstance.Fleet.JoinToken != "" {
+		t.Fatalf("join token = %q, want redacted", env.Config.MultiInstance.Fleet.JoinToken)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/config", strings.NewReader(`{"multiInstance":{"fleet":{"joinToken":"other"}}}`))
+	w = httptest.NewRecorder()
+	api.HandlePutConfig(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "token_write_only") {
+		t.Fatalf("HandlePutConfig() = %d %q, want token_write_only", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/config", strings.NewReader(`{"server":{"port":"9999"}}`))
+	w = httptest.NewRecorder()
+	api.HandlePutConfig(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("HandlePutConfig() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	saved, _, err := config.LoadFileConfig()
+	if err != nil {
+		t.Fatalf("LoadFileConfig() error = %v", err)
+	}
+	if saved.MultiInstance.Fleet.JoinToken != "join-secret" {
+		t.Fatalf("saved join token = %q, want existing token preserved", saved.MultiInstance.Fleet.JoinToken)
+	}
+}
+
 func TestHandleHealthIncludesAgentCount(t *testing.T) {
 	fc := config.DefaultFileConfig()
 	api := newConfigAPITestAPI(t, fc)
@@ -40,7 +40,7 @@ var dashboardFS embed.FS
 
 // SystemEvent is sent for instance lifecycle changes.
 type SystemEvent struct {
-	Type     string      `json:"type"` // "instance.started", "instance.stopped", "instance.error"
+	Type     string      `json:"type"` // "instance.started", "instance.stopped", "instance.error", "instance.recycled"
 	Instance interface{} `json:"instance,omitempty"`
 }
 
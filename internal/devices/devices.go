@@ -0,0 +1,98 @@
+// Package devices holds the device presets used for viewport and user
+// agent emulation.
+package devices
+
+import (
+	"sort"
+	"strings"
+)
+
+// chromeVersionToken is replaced with the browser's Chrome version in
+// preset user agents.
+const chromeVersionToken = "{chrome}"
+
+// fallbackChromeVersion fills preset user agents when the Chrome version
+// is unknown.
+const fallbackChromeVersion = "144.0.0.0"
+
+// Device describes an emulated device. Dimensions are the portrait CSS
+// viewport.
+type Device struct {
+	Name              string  `json:"name"`
+	Width             int     `json:"width"`
+	Height            int     `json:"height"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor"`
+	Mobile            bool    `json:"mobile"`
+	Touch             bool    `json:"touch"`
+	// UserAgent is empty for desktop presets, which keep the instance's
+	// user agent.
+	UserAgent string `json:"userAgent,omitempty"`
+	Platform  string `json:"platform,omitempty"`
+}
+
+const (
+	iosUA     = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	ipadUA    = "Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	pixelUA   = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/" + chromeVersionToken + " Mobile Safari/537.36"
+	galaxyUA  = "Mozilla/5.0 (Linux; Android 14; SM-S911B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/" + chromeVersionToken + " Mobile Safari/537.36"
+	tabletUA  = "Mozilla/5.0 (Linux; Android 14; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/" + chromeVersionToken + " Safari/537.36"
+	iosPlat   = "iPhone"
+	ipadPlat  = "iPad"
+	droidPlat = "Linux armv81"
+)
+
+var presets = map[string]Device{
+	"iphone-se":         {Width: 375, Height: 667, DeviceScaleFactor: 2, Mobile: true, Touch: true, UserAgent: iosUA, Platform: iosPlat},
+	"iphone-15":         {Width: 393, Height: 852, DeviceScaleFactor: 3, Mobile: true, Touch: true, UserAgent: iosUA, Platform: iosPlat},
+	"iphone-15-pro-max": {Width: 430, Height: 932, DeviceScaleFactor: 3, Mobile: true, Touch: true, UserAgent: iosUA, Platform: iosPlat},
+	"pixel-8":           {Width: 412, Height: 915, DeviceScaleFactor: 2.625, Mobile: true, Touch: true, UserAgent: pixelUA, Platform: droidPlat},
+	"galaxy-s23":        {Width: 360, Height: 780, DeviceScaleFactor: 3, Mobile: true, Touch: true, UserAgent: galaxyUA, Platform: droidPlat},
+	"ipad-mini":         {Width: 744, Height: 1133, DeviceScaleFactor: 2, Mobile: true, Touch: true, UserAgent: ipadUA, Platform: ipadPlat},
+	"ipad-pro-11":       {Width: 834, Height: 1194, DeviceScaleFactor: 2, Mobile: true, Touch: true, UserAgent: ipadUA, Platform: ipadPlat},
+	"galaxy-tab-s9":     {Width: 800, Height: 1280, DeviceScaleFactor: 2, Mobile: true, Touch: true, UserAgent: tabletUA, Platform: droidPlat},
+	"laptop":            {Width: 1366, Height: 768, DeviceScaleFactor: 1},
+	"desktop-1080p":     {Width: 1920, Height: 1080, DeviceScaleFactor: 1},
+	"desktop-1440p":     {Width: 2560, Height: 1440, DeviceScaleFactor: 1},
+}
+
+// Lookup returns the named preset. Names are case-insensitive. The
+// preset's user agent is filled in with chromeVersion.
+func Lookup(name, chromeVersion string) (Device, bool) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	d, ok := presets[key]
+	if !ok {
+		return Device{}, false
+	}
+	d.Name = key
+	if chromeVersion == "" {
+		chromeVersion = fallbackChromeVersion
+	}
+	d.UserAgent = strings.ReplaceAll(d.UserAgent, chromeVersionToken, chromeVersion)
+	return d, true
+}
+
+// Valid reports whether name is a known preset.
+func Valid(name string) bool {
+	_, ok := presets[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// Names returns the preset names in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// All returns every preset sorted by name.
+func All(chromeVersion string) []Device {
+	out := make([]Device, 0, len(presets))
+	for _, name := range Names() {
+		d, _ := Lookup(name, chromeVersion)
+		out = append(out, d)
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package devices
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	d, ok := Lookup(" Pixel-8 ", "150.0.1.2")
+	if !ok {
+		t.Fatal("expected pixel-8 preset")
+	}
+	if d.Name != "pixel-8" || !d.Mobile || !d.Touch || d.Width != 412 {
+		t.Errorf("unexpected preset %+v", d)
+	}
+	if !strings.Contains(d.UserAgent, "Chrome/150.0.1.2 ") {
+		t.Errorf("expected chrome version in UA, got %q", d.UserAgent)
+	}
+
+	d, _ = Lookup("galaxy-s23", "")
+	if strings.Contains(d.UserAgent, chromeVersionToken) {
+		t.Errorf("unexpanded token in UA %q", d.UserAgent)
+	}
+
+	if _, ok := Lookup("nokia-3310", ""); ok {
+		t.Error("expected unknown preset")
+	}
+}
+
+func TestAll(t *testing.T) {
+	all := All("")
+	if len(all) != len(Names()) {
+		t.Fatalf("All returned %d presets, Names %d", len(all), len(Names()))
+	}
+	for _, d := range all {
+		if d.Width <= 0 || d.Height <= 0 || d.DeviceScaleFactor <= 0 {
+			t.Errorf("%s: invalid metrics %+v", d.Name, d)
+		}
+		if d.Mobile && d.UserAgent == "" {
+			t.Errorf("%s: mobile preset without user agent", d.Name)
+		}
+	}
+}
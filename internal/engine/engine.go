@@ -6,7 +6,11 @@
 // or any other package.
 package engine
 
-import "context"
+import (
+	"context"
+
+	"github.com/pinchtab/pinchtab/internal/extract"
+)
 
 // Capability identifies an operation the engine may handle.
 type Capability string
@@ -21,6 +25,7 @@ const (
 	CapPDF        Capability = "pdf"
 	CapEvaluate   Capability = "evaluate"
 	CapCookies    Capability = "cookies"
+	CapExtract    Capability = "extract"
 )
 
 // Mode controls the engine selection strategy.
@@ -67,6 +72,7 @@ type Engine interface {
 	Text(ctx context.Context, tabID string) (string, error)
 	Click(ctx context.Context, tabID, ref string) error
 	Type(ctx context.Context, tabID, ref, text string) error
+	Extract(ctx context.Context, tabID string, spec extract.Spec, guard extract.NextGuard) (*extract.Result, error)
 	Capabilities() []Capability
 	Close() error
 }
@@ -47,7 +47,7 @@ func NewLiteEngine() *LiteEngine {
 func (l *LiteEngine) Name() string { return "lite" }
 
 func (l *LiteEngine) Capabilities() []Capability {
-	return []Capability{CapNavigate, CapSnapshot, CapText, CapClick, CapType}
+	return []Capability{CapNavigate, CapSnapshot, CapText, CapClick, CapType, CapExtract}
 }
 
 // Navigate opens a URL in the lite engine and returns the result.
@@ -55,48 +55,9 @@ func (l *LiteEngine) Navigate(ctx context.Context, url string) (*NavigateResult,
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	// Validate and sanitize URL to prevent SSRF (CodeQL go/request-forgery).
-	safeURL, err := urls.Sanitize(url)
-	if err != nil {
-		return nil, fmt.Errorf("lite navigate: %w", err)
-	}
-
-	// Fetch HTML via HTTP.
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, safeURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("lite navigate: %w", err)
-	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; PinchTab-Lite/1.0)")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*")
-
-	resp, err := l.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("lite navigate fetch: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("lite navigate: HTTP %d from %s", resp.StatusCode, url)
-	}
-
-	// Detect content type — only process HTML.
-	ct := resp.Header.Get("Content-Type")
-	if ct != "" && !strings.Contains(ct, "html") && !strings.Contains(ct, "xml") {
-		return nil, fmt.Errorf("lite navigate: unsupported content type %q", ct)
-	}
-
-	// Strip <script> elements to prevent gost-dom panics (no JS engine).
-	cleanBody, err := stripScripts(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("lite navigate strip scripts: %w", err)
-	}
-
-	// Parse the cleaned HTML directly using gost-dom's reader API,
-	// avoiding a second HTTP fetch.
-	parsedURL := gosturl.ParseURL(url)
-	win, err := html.NewWindowReader(cleanBody, parsedURL)
+	win, err := l.load(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("lite navigate open: %w", err)
+		return nil, err
 	}
 
 	l.seq++
@@ -234,6 +195,54 @@ func (l *LiteEngine) Close() error {
 	return nil
 }
 
+// load fetches url and parses it into a new Gost-DOM window.
+func (l *LiteEngine) load(ctx context.Context, url string) (html.Window, error) {
+	// Validate and sanitize URL to prevent SSRF (CodeQL go/request-forgery).
+	safeURL, err := urls.Sanitize(url)
+	if err != nil {
+		return nil, fmt.Errorf("lite navigate: %w", err)
+	}
+
+	// Fetch HTML via HTTP.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, safeURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("lite navigate: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; PinchTab-Lite/1.0)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*")
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("lite navigate fetch: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("lite navigate: HTTP %d from %s", resp.StatusCode, url)
+	}
+
+	// Detect content type — only process HTML.
+	ct := resp.Header.Get("Content-Type")
+	if ct != "" && !strings.Contains(ct, "html") && !strings.Contains(ct, "xml") {
+		return nil, fmt.Errorf("lite navigate: unsupported content type %q", ct)
+	}
+
+	// Strip <script> elements to prevent gost-dom panics (no JS engine).
+	cleanBody, err := stripScripts(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("lite navigate strip scripts: %w", err)
+	}
+
+	// Parse the cleaned HTML directly using gost-dom's reader API,
+	// avoiding a second HTTP fetch.
+	parsedURL := gosturl.ParseURL(url)
+	win, err := html.NewWindowReader(cleanBody, parsedURL)
+	if err != nil {
+		return nil, fmt.Errorf("lite navigate open: %w", err)
+	}
+	return win, nil
+}
+
 func (l *LiteEngine) resolveTab(tabID string) (*liteTab, error) {
 	if tabID == "" {
 		tabID = l.current
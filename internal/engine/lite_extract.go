@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"github.com/gost-dom/browser/dom"
+	"github.com/pinchtab/pinchtab/internal/extract"
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+// Extract runs a field spec against a lite tab. Next-page selectors must
+// match a link with an href; the linked page replaces the tab's document.
+// guard, when set, vets every linked page before it is fetched.
+func (l *LiteEngine) Extract(ctx context.Context, tabID string, spec extract.Spec, guard extract.NextGuard) (*extract.Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tab, err := l.resolveTab(tabID)
+	if err != nil {
+		return nil, err
+	}
+	return extract.Run(ctx, spec, &liteExtractSource{engine: l, tab: tab, guard: guard})
+}
+
+// liteExtractSource evaluates fields with Gost-DOM. XPath and shadow DOM
+// are not available without a browser.
+type liteExtractSource struct {
+	engine *LiteEngine
+	tab    *liteTab
+	guard  extract.NextGuard
+}
+
+type liteQuerier interface {
+	QuerySelectorAll(string) (dom.NodeList, error)
+}
+
+func (s *liteExtractSource) Raw(_ context.Context, fields []extract.Field) (map[string]any, error) {
+	doc := s.tab.window.Document()
+	if doc == nil {
+		return nil, errors.New("no document")
+	}
+	return liteObject(doc, fields)
+}
+
+func (s *liteExtractSource) URL(context.Context) (string, error) {
+	return s.tab.url, nil
+}
+
+func (s *liteExtractSource) Next(ctx context.Context, sel selector.Selector) (bool, error) {
+	doc := s.tab.window.Document()
+	if doc == nil {
+		return false, errors.New("no document")
+	}
+	els, err := liteSelect(doc, sel)
+	if err != nil || len(els) == 0 {
+		return false, err
+	}
+	href, ok := els[0].GetAttribute("href")
+	if !ok || href == "" {
+		return false, fmt.Errorf("%w: next element has no href", ErrLiteNotSupported)
+	}
+	base, err := neturl.Parse(s.tab.url)
+	if err != nil {
+		return false, err
+	}
+	ref, err := neturl.Parse(href)
+	if err != nil {
+		return false, fmt.Errorf("next href %q: %w", href, err)
+	}
+	next := base.ResolveReference(ref).String()
+	if next == s.tab.url {
+		return false, nil
+	}
+	if s.guard != nil {
+		if err := s.guard(ctx, s.tab.url, next); err != nil {
+			return false, err
+		}
+	}
+
+	win, err := s.engine.load(ctx, next)
+	if err != nil {
+		return false, err
+	}
+	s.tab.window.Close()
+	s.tab.window = win
+	s.tab.url = next
+	s.tab.refMap = make(map[string]dom.Element)
+	return true, nil
+}
+
+func liteObject(root liteQuerier, fields []extract.Field) (map[string]any, error) {
+	out := make(map[string]any, len(fields))
+	for _, f := range fields {
+		var els []dom.Element
+		if f.Selector == "" {
+			if el := liteSelf(root); el != nil {
+				els = []dom.Element{el}
+			}
+		} else {
+			sel, err := extract.ParseSelector(f.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if els, err = liteSelect(root, sel); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+
+		if !f.List {
+			if len(els) == 0 {
+				out[f.Name] = nil
+				continue
+			}
+			v, err := liteValue(els[0], f)
+			if err != nil {
+				return nil, err
+			}
+			out[f.Name] = v
+			continue
+		}
+		list := make([]any, 0, len(els))
+		for _, el := range els {
+			v, err := liteValue(el, f)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		out[f.Name] = list
+	}
+	return out, nil
+}
+
+func liteValue(el dom.Element, f extract.Field) (any, error) {
+	if len(f.Fields) > 0 {
+		return liteObject(el, f.Fields)
+	}
+	switch f.Source {
+	case extract.SourceAttr:
+		if v, ok := el.GetAttribute(f.Attr); ok {
+			return v, nil
+		}
+		return nil, nil
+	case extract.SourceHTML:
+		return el.InnerHTML(), nil
+	}
+	return normalizeWhitespace(el.TextContent()), nil
+}
+
+// liteSelf returns the element a field with no selector reads from: the
+// parent element, or the root element at the top level.
+func liteSelf(root liteQuerier) dom.Element {
+	switch n := root.(type) {
+	case dom.Element:
+		return n
+	case dom.Document:
+		return n.DocumentElement()
+	}
+	return nil
+}
+
+func liteSelect(root liteQuerier, sel selector.Selector) ([]dom.Element, error) {
+	if len(sel.Pierce) > 0 {
+		return nil, fmt.Errorf("%w: shadow DOM selectors", ErrLiteNotSupported)
+	}
+	switch sel.Kind {
+	case selector.KindCSS:
+		return liteQueryAll(root, sel.Value)
+	case selector.KindText:
+		all, err := liteQueryAll(root, "*")
+		if err != nil {
+			return nil, err
+		}
+		var out []dom.Element
+		for _, el := range all {
+			if !containsText(el, sel.Value) {
+				continue
+			}
+			// Keep the innermost match only.
+			inner := false
+			for child := el.FirstChild(); child != nil; child = child.NextSibling() {
+				if c, ok := child.(dom.Element); ok && containsText(c, sel.Value) {
+					inner = true
+					break
+				}
+			}
+			if !inner {
+				out = append(out, el)
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%w: %s selectors", ErrLiteNotSupported, sel.Kind)
+}
+
+func liteQueryAll(root liteQuerier, css string) ([]dom.Element, error) {
+	nodes, err := root.QuerySelectorAll(css)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dom.Element, 0, nodes.Length())
+	for i := 0; i < nodes.Length(); i++ {
+		if el, ok := nodes.Item(i).(dom.Element); ok {
+			out = append(out, el)
+		}
+	}
+	return out, nil
+}
+
+func containsText(el dom.Element, text string) bool {
+	switch strings.ToLower(el.TagName()) {
+	case "script", "style":
+		return false
+	}
+	return strings.Contains(el.TextContent(), text)
+}
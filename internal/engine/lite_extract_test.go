@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/extract"
+)
+
+const catalogPage1 = `<!DOCTYPE html>
+<html><head><title>Catalog</title></head>
+<body>
+	<h1>  Catalog
+	  page 1 </h1>
+	<div class="product" data-sku="a1"><span class="name">Widget</span><span class="price">$1,299.50</span><a href="/w">view</a></div>
+	<div class="product" data-sku="b2"><span class="name">Gadget</span><span class="price">12 USD</span><span class="sale">on sale</span></div>
+	<p>Contact: <b>sales team</b></p>
+	<a class="next" href="/page2">Next</a>
+</body></html>`
+
+const catalogPage2 = `<!DOCTYPE html>
+<html><head><title>Catalog</title></head>
+<body>
+	<h1>Catalog page 2</h1>
+	<div class="product" data-sku="c3"><span class="name">Doohickey</span><span class="price">3</span></div>
+</body></html>`
+
+func TestLiteEngine_Extract(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(catalogPage1))
+	})
+	mux.HandleFunc("/page2", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(catalogPage2))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	lite := NewLiteEngine()
+	defer func() { _ = lite.Close() }()
+	if _, err := lite.Navigate(context.Background(), ts.URL+"/"); err != nil {
+		t.Fatalf("Navigate: %v", err)
+	}
+
+	spec := extract.Spec{
+		Fields: []extract.Field{
+			{Name: "title", Selector: "h1"},
+			{Name: "contact", Selector: "text:sales team"},
+			{Name: "missing", Selector: ".nope"},
+			{Name: "products", Selector: ".product", List: true, Fields: []extract.Field{
+				{Name: "sku", Attr: "data-sku"},
+				{Name: "name", Selector: ".name"},
+				{Name: "price", Selector: ".price", Type: extract.TypeNumber},
+				{Name: "onSale", Selector: ".sale", Type: extract.TypeBoolean},
+				{Name: "link", Selector: "a", Attr: "href"},
+			}},
+		},
+		Next: "a.next",
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	res, err := lite.Extract(context.Background(), "", spec, nil)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	if res.Data["title"] != "Catalog page 1" {
+		t.Errorf("title = %q", res.Data["title"])
+	}
+	if res.Data["contact"] != "sales team" {
+		t.Errorf("contact = %q", res.Data["contact"])
+	}
+	if res.Data["missing"] != nil {
+		t.Errorf("missing = %v, want nil", res.Data["missing"])
+	}
+	products, _ := res.Data["products"].([]any)
+	if len(products) != 3 {
+		t.Fatalf("products across pages = %d, want 3", len(products))
+	}
+	first := products[0].(map[string]any)
+	if first["sku"] != "a1" || first["name"] != "Widget" || first["price"] != 1299.5 || first["onSale"] != false || first["link"] != "/w" {
+		t.Errorf("first product = %v", first)
+	}
+	second := products[1].(map[string]any)
+	if second["price"] != float64(12) || second["onSale"] != true || second["link"] != nil {
+		t.Errorf("second product = %v", second)
+	}
+	if len(res.Pages) != 2 || res.StoppedBy != extract.StopNoNext {
+		t.Errorf("pages = %d stoppedBy = %q", len(res.Pages), res.StoppedBy)
+	}
+	if res.Pages[1].URL != ts.URL+"/page2" {
+		t.Errorf("page 2 url = %q", res.Pages[1].URL)
+	}
+}
+
+func TestLiteEngine_ExtractXPathUnsupported(t *testing.T) {
+	ts := newTestServer(testPage)
+	defer ts.Close()
+
+	lite := NewLiteEngine()
+	defer func() { _ = lite.Close() }()
+	if _, err := lite.Navigate(context.Background(), ts.URL); err != nil {
+		t.Fatalf("Navigate: %v", err)
+	}
+	spec := extract.Spec{Fields: []extract.Field{{Name: "h", Selector: "xpath://h1"}}}
+	if _, err := lite.Extract(context.Background(), "", spec, nil); err == nil {
+		t.Error("expected xpath to be unsupported in lite mode")
+	}
+}
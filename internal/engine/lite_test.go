@@ -317,8 +317,8 @@ func TestLiteEngine_Capabilities(t *testing.T) {
 	defer func() { _ = lite.Close() }()
 
 	caps := lite.Capabilities()
-	if len(caps) != 5 {
-		t.Errorf("expected 5 capabilities, got %d", len(caps))
+	if len(caps) != 6 {
+		t.Errorf("expected 6 capabilities, got %d", len(caps))
 	}
 }
 
@@ -3,6 +3,8 @@ package engine
 import (
 	"context"
 	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/extract"
 )
 
 // fakeEngine implements Engine for testing.
@@ -16,8 +18,11 @@ func (f *fakeEngine) Snapshot(_ context.Context, _, _ string) ([]SnapshotNode, e
 func (f *fakeEngine) Text(_ context.Context, _ string) (string, error) { return "", nil }
 func (f *fakeEngine) Click(_ context.Context, _, _ string) error       { return nil }
 func (f *fakeEngine) Type(_ context.Context, _, _, _ string) error     { return nil }
-func (f *fakeEngine) Capabilities() []Capability                       { return nil }
-func (f *fakeEngine) Close() error                                     { return nil }
+func (f *fakeEngine) Extract(_ context.Context, _ string, _ extract.Spec, _ extract.NextGuard) (*extract.Result, error) {
+	return nil, nil
+}
+func (f *fakeEngine) Capabilities() []Capability { return nil }
+func (f *fakeEngine) Close() error               { return nil }
 
 func TestRouterChromeMode(t *testing.T) {
 	r := NewRouter(ModeChrome, nil)
@@ -32,7 +32,7 @@ type ContentHintRule struct{}
 func (ContentHintRule) Name() string { return "content-hint" }
 
 func (ContentHintRule) Decide(op Capability, url string) Decision {
-	if op != CapNavigate && op != CapSnapshot && op != CapText {
+	if op != CapNavigate && op != CapSnapshot && op != CapText && op != CapExtract {
 		return Undecided
 	}
 	// Static content is well-suited for Gost-DOM because it does not
@@ -53,7 +53,7 @@ func (DefaultLiteRule) Name() string { return "default-lite" }
 
 func (DefaultLiteRule) Decide(op Capability, _ string) Decision {
 	switch op {
-	case CapNavigate, CapSnapshot, CapText, CapClick, CapType:
+	case CapNavigate, CapSnapshot, CapText, CapClick, CapType, CapExtract:
 		return UseLite
 	}
 	return Undecided
@@ -0,0 +1,339 @@
+// Package extract implements structured data extraction from a page using
+// a field spec, shared by the Chrome and lite engines.
+//
+// A spec lists named fields. Each field selects elements with a selector
+// from the selector package (CSS, XPath, text, or a ">>>" shadow chain),
+// reads their text, inner HTML or an attribute, and converts the value to
+// the requested type. Fields with nested fields produce objects instead of
+// scalars, and list fields produce arrays:
+//
+//	{
+//	  "fields": [
+//	    {"name": "title", "selector": "h1"},
+//	    {"name": "products", "selector": ".product", "list": true, "fields": [
+//	      {"name": "name", "selector": ".name"},
+//	      {"name": "price", "selector": ".price", "type": "number"},
+//	      {"name": "url", "selector": "a", "attr": "href"}
+//	    ]}
+//	  ],
+//	  "next": "a.next-page",
+//	  "maxPages": 5
+//	}
+//
+// Engines supply raw values through a Source; this package handles typing
+// and pagination.
+package extract
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+// Limits that keep an extraction bounded.
+const (
+	// DefaultMaxPages is used when a spec sets next but no maxPages.
+	DefaultMaxPages = 10
+	// MaxPages is the hard cap on followed pages.
+	MaxPages = 50
+	// MaxDepth caps nested field levels.
+	MaxDepth = 8
+)
+
+// Value sources.
+const (
+	SourceText = "text"
+	SourceHTML = "html"
+	SourceAttr = "attr"
+)
+
+// Value types.
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+)
+
+// Spec describes what to extract from each page.
+type Spec struct {
+	Fields []Field `json:"fields"`
+	// Next selects the element that leads to the next page. Pagination
+	// stops when it no longer matches, or after MaxPages pages.
+	Next     string `json:"next,omitempty"`
+	MaxPages int    `json:"maxPages,omitempty"`
+}
+
+// Field is one named value in the extracted object.
+type Field struct {
+	Name string `json:"name"`
+	// Selector is resolved relative to the parent field's element, or the
+	// document at the top level. Empty selects the parent element itself.
+	Selector string `json:"selector,omitempty"`
+	// Source is text (default), html (inner HTML) or attr. Setting Attr
+	// implies attr.
+	Source string `json:"source,omitempty"`
+	Attr   string `json:"attr,omitempty"`
+	// Type is string (default), number, integer or boolean. Booleans
+	// report whether the selector matched.
+	Type string `json:"type,omitempty"`
+	// List returns every match instead of the first.
+	List bool `json:"list,omitempty"`
+	// Fields turns each match into an object built from these fields.
+	Fields []Field `json:"fields,omitempty"`
+}
+
+// Validate checks the spec and normalizes field sources.
+func (s *Spec) Validate() error {
+	if len(s.Fields) == 0 {
+		return fmt.Errorf("extract spec has no fields")
+	}
+	if s.MaxPages < 0 || s.MaxPages > MaxPages {
+		return fmt.Errorf("maxPages must be between 0 and %d", MaxPages)
+	}
+	if s.Next != "" {
+		if _, err := ParseSelector(s.Next); err != nil {
+			return fmt.Errorf("next: %w", err)
+		}
+	}
+	return validateFields(s.Fields, "", 1)
+}
+
+func validateFields(fields []Field, prefix string, depth int) error {
+	if depth > MaxDepth {
+		return fmt.Errorf("fields nested deeper than %d levels", MaxDepth)
+	}
+	seen := make(map[string]bool, len(fields))
+	for i := range fields {
+		f := &fields[i]
+		path := prefix + f.Name
+		if f.Name == "" {
+			return fmt.Errorf("field %s#%d: name is required", prefix, i)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("field %s: duplicate name", path)
+		}
+		seen[f.Name] = true
+		if f.Selector != "" {
+			if _, err := ParseSelector(f.Selector); err != nil {
+				return fmt.Errorf("field %s: %w", path, err)
+			}
+		}
+		if f.Attr != "" && f.Source == "" {
+			f.Source = SourceAttr
+		}
+		switch f.Source {
+		case "", SourceText, SourceHTML:
+		case SourceAttr:
+			if f.Attr == "" {
+				return fmt.Errorf("field %s: source attr requires attr", path)
+			}
+		default:
+			return fmt.Errorf("field %s: unknown source %q (want text, html or attr)", path, f.Source)
+		}
+		switch f.Type {
+		case "", TypeString, TypeNumber, TypeInteger, TypeBoolean:
+		default:
+			return fmt.Errorf("field %s: unknown type %q (want string, number, integer or boolean)", path, f.Type)
+		}
+		if len(f.Fields) > 0 {
+			if f.Source != "" || f.Type != "" {
+				return fmt.Errorf("field %s: nested fields cannot set source, attr or type", path)
+			}
+			if err := validateFields(f.Fields, path+".", depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ParseSelector parses a field selector and rejects the kinds extraction
+// cannot evaluate relative to an element: refs, semantic queries, frame
+// scoping, and XPath inside shadow trees.
+func ParseSelector(s string) (selector.Selector, error) {
+	sel := selector.Parse(s)
+	if err := sel.Validate(); err != nil {
+		return sel, err
+	}
+	switch {
+	case sel.Kind == selector.KindRef:
+		return sel, fmt.Errorf("ref selectors are not supported in extraction")
+	case sel.Kind == selector.KindSemantic:
+		return sel, fmt.Errorf("find: selectors are not supported in extraction")
+	case len(sel.Frames) > 0:
+		return sel, fmt.Errorf("frame: selectors are not supported in extraction")
+	case len(sel.Pierce) > 0 && sel.Kind == selector.KindXPath:
+		return sel, fmt.Errorf("xpath selectors cannot follow >>>")
+	}
+	return sel, nil
+}
+
+// Source is one engine's view of the current page.
+type Source interface {
+	// Raw evaluates fields against the current page. Scalar fields yield a
+	// string, or nil when nothing matched; list fields yield []any; nested
+	// fields yield map[string]any.
+	Raw(ctx context.Context, fields []Field) (map[string]any, error)
+	// URL returns the current page URL.
+	URL(ctx context.Context) (string, error)
+	// Next follows the next-page element. It returns false when the
+	// selector matches nothing.
+	Next(ctx context.Context, sel selector.Selector) (bool, error)
+}
+
+// NextGuard vets a page pagination is about to load: from is the current
+// page and to the page the next element leads to. An error stops the
+// extraction before the page is requested.
+type NextGuard func(ctx context.Context, from, to string) error
+
+// Page is the data extracted from one page.
+type Page struct {
+	URL  string         `json:"url"`
+	Data map[string]any `json:"data"`
+}
+
+// Stop reasons for paginated extraction.
+const (
+	StopNoNext   = "no_next"
+	StopMaxPages = "max_pages"
+)
+
+// Result is the outcome of an extraction. Data holds the first page's
+// values with list fields concatenated across all pages.
+type Result struct {
+	Data      map[string]any `json:"data"`
+	Pages     []Page         `json:"pages,omitempty"`
+	StoppedBy string         `json:"stoppedBy,omitempty"`
+}
+
+// Run extracts the spec from the source, following the next-page selector
+// when one is set. The spec must already be validated.
+func Run(ctx context.Context, spec Spec, src Source) (*Result, error) {
+	var next selector.Selector
+	limit := 1
+	if spec.Next != "" {
+		next, _ = ParseSelector(spec.Next)
+		limit = spec.MaxPages
+		if limit == 0 {
+			limit = DefaultMaxPages
+		}
+	}
+
+	res := &Result{}
+	for {
+		raw, err := src.Raw(ctx, spec.Fields)
+		if err != nil {
+			return nil, err
+		}
+		data := Coerce(spec.Fields, raw)
+		if res.Data == nil {
+			res.Data = maps.Clone(data)
+		} else {
+			merge(spec.Fields, res.Data, data)
+		}
+		if spec.Next == "" {
+			return res, nil
+		}
+
+		url, err := src.URL(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res.Pages = append(res.Pages, Page{URL: url, Data: data})
+		if len(res.Pages) >= limit {
+			res.StoppedBy = StopMaxPages
+			return res, nil
+		}
+		ok, err := src.Next(ctx, next)
+		if err != nil {
+			return nil, fmt.Errorf("next page after %d: %w", len(res.Pages), err)
+		}
+		if !ok {
+			res.StoppedBy = StopNoNext
+			return res, nil
+		}
+	}
+}
+
+// merge appends list fields of page onto acc.
+func merge(fields []Field, acc, page map[string]any) {
+	for _, f := range fields {
+		if !f.List {
+			continue
+		}
+		a, _ := acc[f.Name].([]any)
+		p, _ := page[f.Name].([]any)
+		acc[f.Name] = append(a, p...)
+	}
+}
+
+// Coerce converts raw engine values to the field types.
+func Coerce(fields []Field, raw map[string]any) map[string]any {
+	out := make(map[string]any, len(fields))
+	for _, f := range fields {
+		v := raw[f.Name]
+		if !f.List {
+			out[f.Name] = coerceOne(f, v)
+			continue
+		}
+		items, _ := v.([]any)
+		list := make([]any, 0, len(items))
+		for _, item := range items {
+			list = append(list, coerceOne(f, item))
+		}
+		out[f.Name] = list
+	}
+	return out
+}
+
+func coerceOne(f Field, v any) any {
+	if len(f.Fields) > 0 {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		return Coerce(f.Fields, m)
+	}
+	if f.Type == TypeBoolean {
+		return v != nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	switch f.Type {
+	case TypeNumber:
+		if n, ok := parseNumber(s); ok {
+			return n
+		}
+		return nil
+	case TypeInteger:
+		if n, ok := parseNumber(s); ok {
+			return int64(n)
+		}
+		return nil
+	}
+	return s
+}
+
+var numberRe = regexp.MustCompile(`-?\d[\d,]*(\.\d+)?|-?\.\d+`)
+
+// parseNumber reads the first number in s, ignoring currency symbols,
+// units and thousands separators: "$1,299.00" is 1299.
+func parseNumber(s string) (float64, bool) {
+	m := numberRe.FindString(s)
+	if m == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(m, ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,141 @@
+package extract
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Spec{Fields: []Field{
+		{Name: "title", Selector: "h1"},
+		{Name: "link", Selector: "a", Attr: "href"},
+		{Name: "items", Selector: "xpath://li", List: true, Fields: []Field{{Name: "v"}}},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if valid.Fields[1].Source != SourceAttr {
+		t.Errorf("attr should imply source attr, got %q", valid.Fields[1].Source)
+	}
+
+	invalid := map[string]Spec{
+		"no fields":      {},
+		"no name":        {Fields: []Field{{Selector: "h1"}}},
+		"duplicate":      {Fields: []Field{{Name: "a"}, {Name: "a"}}},
+		"ref":            {Fields: []Field{{Name: "a", Selector: "e5"}}},
+		"semantic":       {Fields: []Field{{Name: "a", Selector: "find:login button"}}},
+		"frame":          {Fields: []Field{{Name: "a", Selector: "frame:login #user"}}},
+		"source":         {Fields: []Field{{Name: "a", Source: "json"}}},
+		"attr no name":   {Fields: []Field{{Name: "a", Source: SourceAttr}}},
+		"type":           {Fields: []Field{{Name: "a", Type: "date"}}},
+		"nested typed":   {Fields: []Field{{Name: "a", Type: TypeNumber, Fields: []Field{{Name: "b"}}}}},
+		"max pages":      {Fields: []Field{{Name: "a"}}, Next: "a.next", MaxPages: MaxPages + 1},
+		"bad next":       {Fields: []Field{{Name: "a"}}, Next: "find:next"},
+		"nested invalid": {Fields: []Field{{Name: "a", Fields: []Field{{Name: ""}}}}},
+	}
+	for name, spec := range invalid {
+		if err := spec.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	fields := []Field{
+		{Name: "price", Type: TypeNumber},
+		{Name: "count", Type: TypeInteger},
+		{Name: "bad", Type: TypeNumber},
+		{Name: "flag", Type: TypeBoolean},
+		{Name: "absent", Type: TypeBoolean},
+		{Name: "tags", List: true},
+		{Name: "rows", List: true, Fields: []Field{{Name: "n", Type: TypeInteger}}},
+	}
+	got := Coerce(fields, map[string]any{
+		"price":  "€1,234.50 incl. VAT",
+		"count":  "42 results",
+		"bad":    "n/a",
+		"flag":   "",
+		"absent": nil,
+		"tags":   []any{"a", "b"},
+		"rows":   []any{map[string]any{"n": "7.9"}},
+	})
+	if got["price"] != 1234.5 || got["count"] != int64(42) || got["bad"] != nil {
+		t.Errorf("numbers: %v %v %v", got["price"], got["count"], got["bad"])
+	}
+	if got["flag"] != true || got["absent"] != false {
+		t.Errorf("booleans: %v %v", got["flag"], got["absent"])
+	}
+	if tags := got["tags"].([]any); len(tags) != 2 {
+		t.Errorf("tags = %v", tags)
+	}
+	if rows := got["rows"].([]any); rows[0].(map[string]any)["n"] != int64(7) {
+		t.Errorf("rows = %v", rows)
+	}
+}
+
+type fakeSource struct {
+	pages []map[string]any
+	page  int
+}
+
+func (f *fakeSource) Raw(context.Context, []Field) (map[string]any, error) {
+	return f.pages[f.page], nil
+}
+
+func (f *fakeSource) URL(context.Context) (string, error) {
+	return "https://example.com/" + string(rune('a'+f.page)), nil
+}
+
+func (f *fakeSource) Next(context.Context, selector.Selector) (bool, error) {
+	if f.page+1 >= len(f.pages) {
+		return false, nil
+	}
+	f.page++
+	return true, nil
+}
+
+func TestRun_Pagination(t *testing.T) {
+	src := &fakeSource{pages: []map[string]any{
+		{"title": "one", "items": []any{"1", "2"}},
+		{"title": "two", "items": []any{"3"}},
+		{"title": "three", "items": []any{"4"}},
+	}}
+	spec := Spec{
+		Fields: []Field{{Name: "title"}, {Name: "items", List: true, Type: TypeInteger}},
+		Next:   "a.next",
+	}
+	res, err := Run(context.Background(), spec, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Data["title"] != "one" || len(res.Data["items"].([]any)) != 4 {
+		t.Errorf("data = %v", res.Data)
+	}
+	if len(res.Pages) != 3 || res.StoppedBy != StopNoNext {
+		t.Errorf("pages = %d stoppedBy = %q", len(res.Pages), res.StoppedBy)
+	}
+	if n := len(res.Pages[0].Data["items"].([]any)); n != 2 {
+		t.Errorf("first page items = %d, want 2", n)
+	}
+
+	src.page = 0
+	spec.MaxPages = 2
+	res, err = Run(context.Background(), spec, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Pages) != 2 || res.StoppedBy != StopMaxPages {
+		t.Errorf("pages = %d stoppedBy = %q", len(res.Pages), res.StoppedBy)
+	}
+
+	src.page = 0
+	res, err = Run(context.Background(), Spec{Fields: spec.Fields}, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Pages != nil || src.page != 0 {
+		t.Errorf("single page extraction should not paginate: %+v", res)
+	}
+}
@@ -0,0 +1,185 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+const (
+	defaultHeartbeatInterval = 10 * time.Second
+	registerRetryMin         = time.Second
+	registerRetryMax         = 30 * time.Second
+	requestTimeout           = 10 * time.Second
+)
+
+// errNotRegistered is returned when the server no longer knows the bridge,
+// e.g. after a server restart or missed heartbeats.
+var errNotRegistered = errors.New("bridge is not registered")
+
+// Client keeps a bridge registered with a server.
+type Client struct {
+	// Server is the base URL of the PinchTab server to join.
+	Server    string
+	JoinToken string
+	// Registration describes the bridge. Its Capacity is refreshed from
+	// Capacity before every request when Capacity is set.
+	Registration Registration
+	Capacity     func() bridge.InstanceCapacity
+	HTTPClient   *http.Client
+}
+
+// Run registers the bridge, retrying with backoff until the server accepts
+// it, then sends heartbeats at the interval the server asks for. When the
+// server has forgotten the bridge it registers again. When ctx ends the
+// bridge deregisters and Run returns.
+func (c *Client) Run(ctx context.Context) {
+	backoff := registerRetryMin
+	for {
+		reg, err := c.register(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("fleet: registration failed", "server", c.Server, "retryIn", backoff, "err", err)
+			if !sleep(ctx, backoff) {
+				return
+			}
+			backoff = min(backoff*2, registerRetryMax)
+			continue
+		}
+		backoff = registerRetryMin
+		slog.Info("fleet: registered", "server", c.Server, "id", reg.ID, "heartbeatSec", reg.HeartbeatIntervalSec)
+
+		err = c.heartbeatLoop(ctx, reg)
+		if ctx.Err() != nil {
+			c.deregister(reg.ID)
+			return
+		}
+		slog.Warn("fleet: re-registering", "server", c.Server, "id", reg.ID, "err", err)
+	}
+}
+
+// heartbeatLoop sends heartbeats until the server forgets the bridge or
+// ctx ends. Transient failures are logged and retried at the next beat;
+// the server decides when too many were missed.
+func (c *Client) heartbeatLoop(ctx context.Context, reg Registered) error {
+	interval := time.Duration(reg.HeartbeatIntervalSec) * time.Second
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		hb := Heartbeat{ID: reg.ID, Token: c.Registration.Token, Labels: c.Registration.Labels, Capacity: c.capacity()}
+		err := c.post(ctx, HeartbeatPath, hb, nil)
+		if errors.Is(err, errNotRegistered) {
+			return err
+		}
+		if err != nil {
+			slog.Warn("fleet: heartbeat failed", "server", c.Server, "id", reg.ID, "err", err)
+		}
+	}
+}
+
+func (c *Client) register(ctx context.Context) (Registered, error) {
+	body := c.Registration
+	body.Capacity = c.capacity()
+	var reg Registered
+	if err := c.post(ctx, RegisterPath, body, &reg); err != nil {
+		return Registered{}, err
+	}
+	if reg.ID == "" {
+		return Registered{}, fmt.Errorf("server returned no instance id")
+	}
+	return reg, nil
+}
+
+// deregister tells the server the bridge is leaving. It runs after ctx has
+// ended, so it uses its own timeout.
+func (c *Client) deregister(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := c.post(ctx, DeregisterPath, Deregistration{ID: id, Token: c.Registration.Token}, nil); err != nil && !errors.Is(err, errNotRegistered) {
+		slog.Warn("fleet: deregistration failed", "server", c.Server, "id", id, "err", err)
+		return
+	}
+	slog.Info("fleet: deregistered", "server", c.Server, "id", id)
+}
+
+func (c *Client) capacity() bridge.InstanceCapacity {
+	if c.Capacity != nil {
+		return c.Capacity()
+	}
+	return c.Registration.Capacity
+}
+
+func (c *Client) post(ctx context.Context, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.Server, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderJoinToken, c.JoinToken)
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode == http.StatusNotFound && path != RegisterPath {
+		return errNotRegistered
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(respBody, &e) == nil && e.Error != "" {
+			return fmt.Errorf("%s: HTTP %d: %s", path, resp.StatusCode, e.Error)
+		}
+		return fmt.Errorf("%s: HTTP %d", path, resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("%s: decode response: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
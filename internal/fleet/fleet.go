@@ -0,0 +1,120 @@
+// Package fleet defines how bridges on other machines join a PinchTab
+// server. A bridge registers with the server's join token, then sends
+// heartbeats carrying its tab capacity and labels. The server removes a
+// bridge that misses several heartbeats in a row.
+package fleet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+// HeaderJoinToken carries the join token on fleet requests. Fleet
+// endpoints do not accept the server API token.
+const HeaderJoinToken = "X-PinchTab-Join-Token"
+
+// HeaderLabels selects instances by label on shorthand requests, as
+// comma-separated k=v pairs, e.g. "region=eu,gpu=false".
+const HeaderLabels = "X-PinchTab-Labels"
+
+// Fleet endpoints on the server.
+const (
+	RegisterPath   = "/fleet/register"
+	HeartbeatPath  = "/fleet/heartbeat"
+	DeregisterPath = "/fleet/deregister"
+)
+
+// Registration is sent by a bridge to join the fleet. Registering again
+// under the same name with the same token updates the existing instance.
+type Registration struct {
+	Name    string `json:"name"`
+	BaseURL string `json:"baseUrl"`
+	// Token is the bridge's own API token, used by the server to proxy
+	// requests to it. It is required: the registration is bound to it, and
+	// heartbeats and deregistration must present it.
+	Token    string                  `json:"token,omitempty"`
+	Labels   map[string]string       `json:"labels,omitempty"`
+	Capacity bridge.InstanceCapacity `json:"capacity"`
+}
+
+// Registered is the server's answer to a Registration.
+type Registered struct {
+	ID                   string `json:"id"`
+	HeartbeatIntervalSec int    `json:"heartbeatIntervalSec"`
+	MissedHeartbeats     int    `json:"missedHeartbeats"`
+}
+
+// Heartbeat keeps a registered bridge in the fleet. Labels replace the
+// registered labels when set. Token is the bridge token it registered with.
+type Heartbeat struct {
+	ID       string                  `json:"id"`
+	Token    string                  `json:"token,omitempty"`
+	Labels   map[string]string       `json:"labels,omitempty"`
+	Capacity bridge.InstanceCapacity `json:"capacity"`
+}
+
+// Deregistration removes a bridge from the fleet when it shuts down. Token
+// is the bridge token it registered with.
+type Deregistration struct {
+	ID    string `json:"id"`
+	Token string `json:"token,omitempty"`
+}
+
+var (
+	labelKeyRe   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,62})$`)
+	labelValueRe = regexp.MustCompile(`^[A-Za-z0-9._-]{0,63}$`)
+)
+
+// ParseLabels parses k=v pairs. Each entry may hold several pairs
+// separated by commas, so both repeated --label flags and a single
+// "region=eu,gpu=false" header value work.
+func ParseLabels(entries []string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, entry := range entries {
+		for _, pair := range strings.Split(entry, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("label %q must be key=value", pair)
+			}
+			if err := ValidateLabel(k, v); err != nil {
+				return nil, err
+			}
+			labels[k] = v
+		}
+	}
+	return labels, nil
+}
+
+// ValidateLabel checks one label key and value.
+func ValidateLabel(key, value string) error {
+	if !labelKeyRe.MatchString(key) {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	if !labelValueRe.MatchString(value) {
+		return fmt.Errorf("invalid value %q for label %q", value, key)
+	}
+	return nil
+}
+
+// ValidateLabels checks every label in a map.
+func ValidateLabels(labels map[string]string) error {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := ValidateLabel(k, labels[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels([]string{"region=eu", "gpu=false, tier=spot", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 3 || labels["region"] != "eu" || labels["gpu"] != "false" || labels["tier"] != "spot" {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+	for _, bad := range []string{"region", "=eu", "region=e u", "-x=1"} {
+		if _, err := ParseLabels([]string{bad}); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+// fakeServer accepts one registration and counts heartbeats. It answers
+// 404 to heartbeats once forget is set, like a server that expired the
+// bridge.
+type fakeServer struct {
+	mu           sync.Mutex
+	registers    int
+	heartbeats   int
+	deregistered string
+	forget       bool
+	tokens       []string
+}
+
+func (f *fakeServer) handler(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = append(f.tokens, r.Header.Get(HeaderJoinToken))
+	switch r.URL.Path {
+	case RegisterPath:
+		var reg Registration
+		_ = json.NewDecoder(r.Body).Decode(&reg)
+		f.registers++
+		f.forget = false
+		_ = json.NewEncoder(w).Encode(Registered{ID: "inst_" + reg.Name, HeartbeatIntervalSec: 1})
+	case HeartbeatPath:
+		var hb Heartbeat
+		_ = json.NewDecoder(r.Body).Decode(&hb)
+		if hb.Token != "bridge-secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if f.forget {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.heartbeats++
+		if f.heartbeats == 1 {
+			f.forget = true
+		}
+	case DeregisterPath:
+		var d Deregistration
+		_ = json.NewDecoder(r.Body).Decode(&d)
+		if d.Token == "bridge-secret" {
+			f.deregistered = d.ID
+		}
+	}
+}
+
+func (f *fakeServer) counts() (int, int, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.registers, f.heartbeats, f.deregistered
+}
+
+func TestClientRegistersHeartbeatsAndDeregisters(t *testing.T) {
+	fs := &fakeServer{}
+	srv := httptest.NewServer(http.HandlerFunc(fs.handler))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{
+		Server:       srv.URL,
+		JoinToken:    "join-secret",
+		Registration: Registration{Name: "edge", BaseURL: "http://edge:9867", Token: "bridge-secret"},
+		Capacity:     func() bridge.InstanceCapacity { return bridge.InstanceCapacity{MaxTabs: 5, Tabs: 2} },
+	}
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if registers, _, _ := fs.counts(); registers >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("client should register again after the server forgets it")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+
+	_, heartbeats, deregistered := fs.counts()
+	if heartbeats < 1 || deregistered != "inst_edge" {
+		t.Fatalf("expected heartbeats and a deregistration, got %d %q", heartbeats, deregistered)
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for _, tok := range fs.tokens {
+		if tok != "join-secret" {
+			t.Fatalf("every request should carry the join token, got %q", tok)
+		}
+	}
+}
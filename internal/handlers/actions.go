@@ -159,6 +159,21 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 	// into the unified Selector, then resolve to a nodeID when possible.
 	req.NormalizeSelector()
 	refMissing := false
+	if !useLiteAction && req.NodeID == 0 && req.Selector != "" {
+		// frame: and >>> scoped selectors resolve to a node that may live in
+		// an out-of-process iframe; the action then runs in that frame.
+		if sel := selector.Parse(req.Selector); sel.Scoped() {
+			frameCtx, nid, err := bridge.ResolveScopedSelector(tCtx, sel)
+			if err != nil {
+				httpx.Error(w, 400, fmt.Errorf("selector: %w", err))
+				return
+			}
+			tCtx = frameCtx
+			req.NodeID = nid
+			req.Selector = ""
+			req.Ref = ""
+		}
+	}
 	if !useLiteAction && req.NodeID == 0 && req.Selector != "" {
 		sel := selector.Parse(req.Selector)
 		switch sel.Kind {
@@ -500,6 +515,27 @@ func (h *Handlers) handleActionsBatch(w http.ResponseWriter, r *http.Request, re
 		// Unified selector resolution for batch actions.
 		action.NormalizeSelector()
 		refMissing := false
+		actionCtx := tCtx
+		if !useLiteAction && action.NodeID == 0 && action.Selector != "" {
+			if sel := selector.Parse(action.Selector); sel.Scoped() {
+				frameCtx, nid, resolveErr := bridge.ResolveScopedSelector(tCtx, sel)
+				if resolveErr != nil {
+					tCancel()
+					results = append(results, actionResult{
+						Index: i, Success: false,
+						Error: fmt.Sprintf("selector: %v", resolveErr),
+					})
+					if req.StopOnError {
+						break
+					}
+					continue
+				}
+				actionCtx = frameCtx
+				action.NodeID = nid
+				action.Selector = ""
+				action.Ref = ""
+			}
+		}
 		if !useLiteAction && action.NodeID == 0 && action.Selector != "" {
 			sel := selector.Parse(action.Selector)
 			switch sel.Kind {
@@ -656,7 +692,7 @@ func (h *Handlers) handleActionsBatch(w http.ResponseWriter, r *http.Request, re
 			}
 			continue
 		} else {
-			actionRes, _, err = h.executeAction(tCtx, action)
+			actionRes, _, err = h.executeAction(actionCtx, action)
 			if err != nil && action.Ref != "" && shouldRetryStaleRef(err) {
 				recordStaleRefRetry()
 				h.refreshRefCache(tCtx, resolvedTabID)
@@ -775,6 +811,29 @@ func (h *Handlers) HandleMacro(w http.ResponseWriter, r *http.Request) {
 		// Unified selector resolution for macro steps (mirrors HandleAction).
 		step.NormalizeSelector()
 		stepRefMissing := false
+		var frameCtx context.Context
+		var frameCancel context.CancelFunc
+		if !useLiteAction && step.NodeID == 0 && step.Selector != "" {
+			if sel := selector.Parse(step.Selector); sel.Scoped() {
+				sCtx, sCancel := context.WithTimeout(ctx, stepTimeout)
+				fCtx, nid, resolveErr := bridge.ResolveScopedSelector(sCtx, sel)
+				if resolveErr != nil {
+					sCancel()
+					results = append(results, actionResult{
+						Index: i, Success: false,
+						Error: fmt.Sprintf("selector: %v", resolveErr),
+					})
+					if req.StopOnError {
+						break
+					}
+					continue
+				}
+				frameCtx, frameCancel = fCtx, sCancel
+				step.NodeID = nid
+				step.Selector = ""
+				step.Ref = ""
+			}
+		}
 		if !useLiteAction && step.NodeID == 0 && step.Selector != "" {
 			sel := selector.Parse(step.Selector)
 			switch sel.Kind {
@@ -887,7 +946,18 @@ func (h *Handlers) HandleMacro(w http.ResponseWriter, r *http.Request) {
 			h.cacheActionIntent(resolvedTabID, step)
 		}
 
-		tCtx, cancel := context.WithTimeout(ctx, stepTimeout)
+		stepCtx := ctx
+		if frameCtx != nil {
+			stepCtx = frameCtx
+		}
+		tCtx, cancel := context.WithTimeout(stepCtx, stepTimeout)
+		if frameCancel != nil {
+			stepCancel := cancel
+			cancel = func() {
+				stepCancel()
+				frameCancel()
+			}
+		}
 
 		var res map[string]any
 		var err error
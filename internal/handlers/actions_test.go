@@ -13,6 +13,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/engine"
+	"github.com/pinchtab/pinchtab/internal/extract"
 )
 
 type failMockBridge struct {
@@ -34,8 +35,9 @@ func (m *liteActionBridge) EnsureChrome(cfg *config.RuntimeConfig) error {
 }
 
 type fakeLiteEngine struct {
-	clickRefs []string
-	typeCalls []struct {
+	clickRefs   []string
+	extractTabs []string
+	typeCalls   []struct {
 		ref  string
 		text string
 	}
@@ -62,6 +64,10 @@ func (f *fakeLiteEngine) Type(ctx context.Context, tabID, ref, text string) erro
 	}{ref: ref, text: text})
 	return nil
 }
+func (f *fakeLiteEngine) Extract(ctx context.Context, tabID string, spec extract.Spec, guard extract.NextGuard) (*extract.Result, error) {
+	f.extractTabs = append(f.extractTabs, tabID)
+	return &extract.Result{Data: map[string]any{spec.Fields[0].Name: "lite"}}, nil
+}
 func (f *fakeLiteEngine) Capabilities() []engine.Capability {
 	return []engine.Capability{engine.CapClick, engine.CapType}
 }
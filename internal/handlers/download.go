@@ -296,36 +296,39 @@ func (h *Handlers) HandleDownload(w http.ResponseWriter, r *http.Request) {
 	var receivedBytes atomic.Int64
 
 	// Intercept every browser-side request so redirects and follow-on navigations
-	// cannot escape the public-only URL policy enforced for /download.
-	if err := chromedp.Run(tCtx, chromedp.ActionFunc(func(ctx context.Context) error {
-		return fetch.Enable().Do(ctx)
-	})); err != nil {
-		httpx.Error(w, 500, fmt.Errorf("fetch enable: %w", err))
+	// cannot escape the public-only URL policy enforced for /download. The
+	// check runs as a guard on the tab's interception listener, the single
+	// consumer of paused requests.
+	if err := chromedp.Run(tCtx); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("new tab: %w", err))
 		return
 	}
-	defer func() {
-		_ = chromedp.Run(tCtx, chromedp.ActionFunc(func(ctx context.Context) error {
-			return fetch.Disable().Do(ctx)
-		}))
-	}()
+	im := h.Bridge.Interceptor()
+	if im == nil {
+		im = bridge.NewInterceptManager()
+	}
+	guardTabID := string(chromedp.FromContext(tCtx).Target.TargetID)
+	defer im.Forget(guardTabID)
+	removeGuard, err := im.AddGuard(tCtx, guardTabID, func(ctx context.Context, e *fetch.EventRequestPaused) bool {
+		if err := requestGuard.Validate(e.Request.URL, e.RedirectedRequestID != ""); err != nil {
+			requestGuard.NoteBlocked(err)
+			select {
+			case done <- struct{}{}:
+			default:
+			}
+			_ = fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient).Do(ctx)
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	defer removeGuard()
 
 	chromedp.ListenTarget(tCtx, func(ev interface{}) {
 		switch e := ev.(type) {
-		case *fetch.EventRequestPaused:
-			// Handle in goroutine to avoid deadlocking the event dispatcher.
-			go func() {
-				reqID := e.RequestID
-				if err := requestGuard.Validate(e.Request.URL, e.RedirectedRequestID != ""); err != nil {
-					requestGuard.NoteBlocked(err)
-					select {
-					case done <- struct{}{}:
-					default:
-					}
-					_ = fetch.FailRequest(reqID, network.ErrorReasonBlockedByClient).Do(cdp.WithExecutor(tCtx, chromedp.FromContext(tCtx).Target))
-					return
-				}
-				_ = fetch.ContinueRequest(reqID).Do(cdp.WithExecutor(tCtx, chromedp.FromContext(tCtx).Target))
-			}()
 		case *network.EventRequestWillBeSent:
 			if e.Type != network.ResourceTypeDocument {
 				return
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/devices"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+type emulateRequest struct {
+	TabID string `json:"tabId"`
+	bridge.Emulation
+}
+
+// HandleEmulateDevices lists the device presets accepted by /emulate.
+//
+// @Endpoint GET /emulate/devices
+// @Description Returns the built-in device presets with their viewport, scale factor and user agent
+//
+// @Response 200 application/json List of device presets
+func (h *Handlers) HandleEmulateDevices(w http.ResponseWriter, r *http.Request) {
+	all := devices.All(h.Config.ChromeVersion)
+	httpx.JSON(w, 200, map[string]any{"devices": all, "count": len(all)})
+}
+
+// HandleEmulate applies device and environment emulation to a tab.
+//
+// @Endpoint POST /emulate
+// @Description Emulates a device preset or custom viewport, plus geolocation, locale, timezone, media features and network conditions
+//
+// @Param tabId string body Tab ID (optional, uses current tab if empty)
+// @Param device string body Device preset name, see GET /emulate/devices (optional)
+// @Param width int body Viewport width in CSS pixels (optional, overrides preset)
+// @Param height int body Viewport height in CSS pixels (optional, overrides preset)
+// @Param deviceScaleFactor float body Device pixel ratio (optional, overrides preset)
+// @Param mobile bool body Mobile viewport and meta-viewport handling (optional)
+// @Param touch bool body Touch event emulation (optional)
+// @Param userAgent string body User agent override (optional)
+// @Param platform string body navigator.platform override, used with userAgent (optional)
+// @Param orientation string body "portrait" or "landscape" (optional)
+// @Param geolocation object body {latitude, longitude, accuracy} (optional)
+// @Param locale string body Locale such as "de-DE" (optional)
+// @Param timezone string body IANA timezone such as "Europe/Berlin" (optional)
+// @Param colorScheme string body "light", "dark" or "no-preference" (optional)
+// @Param reducedMotion string body "reduce" or "no-preference" (optional)
+// @Param network string body "none", "offline", "slow-3g", "fast-3g" or "4g" (optional)
+//
+// @Response 200 application/json Applied emulation
+// @Response 400 application/json Invalid emulation
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleEmulate(w http.ResponseWriter, r *http.Request) {
+	var req emulateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	h.handleEmulate(w, r, req)
+}
+
+// HandleTabEmulate applies emulation to a tab identified by path ID.
+//
+// @Endpoint POST /tabs/{id}/emulate
+func (h *Handlers) HandleTabEmulate(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	var req emulateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.TabID != "" && req.TabID != tabID {
+		httpx.Error(w, 400, fmt.Errorf("tabId in body does not match path id"))
+		return
+	}
+	req.TabID = tabID
+	h.handleEmulate(w, r, req)
+}
+
+func (h *Handlers) handleEmulate(w http.ResponseWriter, r *http.Request, req emulateRequest) {
+	em := req.Emulation
+	if em.IsZero() {
+		httpx.Error(w, 400, fmt.Errorf("device or at least one emulation field required"))
+		return
+	}
+	if err := em.Resolve(h.Config.ChromeVersion); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	if err := bridge.ApplyEmulation(tCtx, &em); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("emulate: %w", err))
+		return
+	}
+
+	httpx.JSON(w, 200, map[string]any{
+		"emulation": em,
+		"status":    "applied",
+		"tabId":     resolvedTabID,
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestHandleEmulate_Validation(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", `{}`, "at least one emulation field"},
+		{"unknown device", `{"device":"nokia-3310"}`, "unknown device"},
+		{"bad network", `{"network":"5g"}`, "invalid network"},
+		{"bad color scheme", `{"colorScheme":"sepia"}`, "invalid colorScheme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/emulate", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.HandleEmulate(w, req)
+			if w.Code != 400 {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("expected %q, got %s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleEmulate_TabNotFound(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/emulate", strings.NewReader(`{"tabId":"missing","device":"pixel-8"}`))
+	w := httptest.NewRecorder()
+	h.HandleEmulate(w, req)
+	if w.Code != 404 {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleTabEmulate_TabIDMismatch(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/tabs/tab1/emulate", strings.NewReader(`{"tabId":"other","device":"pixel-8"}`))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabEmulate(w, req)
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleEmulateDevices(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{ChromeVersion: "150.0.0.0"}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleEmulateDevices(w, httptest.NewRequest("GET", "/emulate/devices", nil))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Devices []struct {
+			Name      string `json:"name"`
+			UserAgent string `json:"userAgent"`
+		} `json:"devices"`
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Count == 0 || resp.Count != len(resp.Devices) {
+		t.Fatalf("unexpected device list: %s", w.Body.String())
+	}
+	for _, d := range resp.Devices {
+		if d.Name == "pixel-8" && !strings.Contains(d.UserAgent, "Chrome/150.0.0.0") {
+			t.Errorf("pixel-8 UA not filled with chrome version: %q", d.UserAgent)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/engine"
+	"github.com/pinchtab/pinchtab/internal/extract"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/netguard"
+)
+
+// maxExtractTimeout caps the timeout of a paginated extraction.
+const maxExtractTimeout = 5 * time.Minute
+
+type extractRequest struct {
+	TabID string `json:"tabId"`
+	extract.Spec
+	// Timeout bounds the whole extraction in seconds, including every
+	// followed page.
+	Timeout float64 `json:"timeout,omitempty"`
+}
+
+// HandleExtract returns typed JSON built from a field spec.
+//
+// @Endpoint POST /extract
+// @Description Extracts structured data from the current page using a field spec (selector, text/html/attr, list, nested fields, type), optionally following a next-page selector
+//
+// @Param tabId string body Tab ID (optional)
+// @Param fields array body Field specs (required)
+// @Param next string body Selector of the next-page element (optional)
+// @Param maxPages int body Maximum pages to follow (optional, default: 10, max: 50)
+// @Param timeout number body Timeout in seconds (optional)
+//
+// @Response 200 application/json Extracted data, per-page results when paginating
+// @Response 400 application/json Invalid spec
+func (h *Handlers) HandleExtract(w http.ResponseWriter, r *http.Request) {
+	var req extractRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	h.handleExtract(w, r, req)
+}
+
+// HandleTabExtract extracts structured data from a tab identified by path ID.
+//
+// @Endpoint POST /tabs/{id}/extract
+func (h *Handlers) HandleTabExtract(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	var req extractRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.TabID != "" && req.TabID != tabID {
+		httpx.Error(w, 400, fmt.Errorf("tabId in body does not match path id"))
+		return
+	}
+	req.TabID = tabID
+	h.handleExtract(w, r, req)
+}
+
+func (h *Handlers) handleExtract(w http.ResponseWriter, r *http.Request, req extractRequest) {
+	if err := req.Validate(); err != nil {
+		httpx.ErrorCode(w, 400, "bad_request", err.Error(), false, nil)
+		return
+	}
+	if req.Timeout < 0 {
+		httpx.Error(w, 400, fmt.Errorf("timeout must be >= 0"))
+		return
+	}
+	timeout := h.Config.ActionTimeout
+	if req.Next != "" {
+		timeout = 2 * time.Minute
+	}
+	if req.Timeout > 0 {
+		timeout = min(time.Duration(req.Timeout*float64(time.Second)), maxExtractTimeout)
+	}
+	h.recordReadRequest(r, "extract", req.TabID)
+
+	if h.useLite(engine.CapExtract, "") {
+		h.recordEngine(r, "lite")
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		res, err := h.Router.Lite().Extract(ctx, req.TabID, req.Spec, h.extractNextGuard)
+		if err != nil {
+			writeExtractError(w, fmt.Errorf("lite extract: %w", err))
+			return
+		}
+		w.Header().Set("X-Engine", "lite")
+		httpx.JSON(w, 200, res)
+		return
+	}
+
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+	// Following next-page links drives the tab, so it needs the lease.
+	if req.Next != "" {
+		if err := h.enforceTabLease(resolvedTabID, resolveOwner(r, "")); err != nil {
+			httpx.ErrorCode(w, 423, "tab_locked", err.Error(), false, nil)
+			return
+		}
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, timeout)
+	defer tCancel()
+	go httpx.CancelOnClientDone(r.Context(), tCancel)
+
+	src := bridge.ChromeExtractSource{NextTimeout: h.Config.ActionTimeout, Guard: h.extractNextGuard}
+	res, err := extract.Run(tCtx, req.Spec, src)
+	if err != nil {
+		writeExtractError(w, err)
+		return
+	}
+	httpx.JSON(w, 200, struct {
+		TabID string `json:"tabId"`
+		*extract.Result
+	}{resolvedTabID, res})
+}
+
+func writeExtractError(w http.ResponseWriter, err error) {
+	var blocked errBlockedPage
+	if errors.As(err, &blocked) {
+		httpx.ErrorCode(w, http.StatusForbidden, "idpi_domain_blocked", err.Error(), false, nil)
+		return
+	}
+	var unsafe errUnsafeNextPage
+	if errors.As(err, &unsafe) {
+		httpx.ErrorCode(w, http.StatusForbidden, "next_page_blocked", err.Error(), false, nil)
+		return
+	}
+	httpx.Error(w, 500, err)
+}
+
+type errBlockedPage struct{ reason string }
+
+func (e errBlockedPage) Error() string {
+	return "next page blocked by IDPI: " + e.reason
+}
+
+type errUnsafeNextPage struct{ err error }
+
+func (e errUnsafeNextPage) Error() string {
+	return "next page blocked: " + e.err.Error()
+}
+
+// extractNextGuard runs the checks /navigate applies to every page
+// pagination loads, so a page cannot steer extraction to a host the caller
+// could not navigate to. Links that are not http(s), such as javascript:
+// pagers, are vetted by the page they lead to instead. A loopback host is
+// only followed from a page that is itself on one.
+func (h *Handlers) extractNextGuard(_ context.Context, from, to string) error {
+	parsed, err := url.Parse(strings.TrimSpace(to))
+	if err != nil {
+		return errUnsafeNextPage{fmt.Errorf("invalid url")}
+	}
+	if scheme := strings.ToLower(parsed.Scheme); scheme != "http" && scheme != "https" {
+		return nil
+	}
+	if result := h.IDPIGuard.CheckDomain(to); result.Blocked {
+		return errBlockedPage{reason: result.Reason}
+	}
+	if _, err := validateNavigateTarget(to, h.IDPIGuard.DomainAllowed(to)); err != nil {
+		return errUnsafeNextPage{err}
+	}
+	if host, ok := extractNavigateHost(to); ok && netguard.IsLocalHost(host) {
+		if fromHost, ok := extractNavigateHost(from); !ok || !netguard.IsLocalHost(fromHost) {
+			return errUnsafeNextPage{fmt.Errorf("link leads to a local host")}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/engine"
+)
+
+func TestHandleExtract_InvalidSpec(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	for _, body := range []string{
+		`{"fields": []}`,
+		`{"fields": [{"selector": "h1"}]}`,
+		`{"fields": [{"name": "a", "selector": "e5"}]}`,
+		`{"fields": [{"name": "a", "type": "date"}]}`,
+		`{"fields": [{"name": "a"}], "maxPages": 500}`,
+	} {
+		req := httptest.NewRequest("POST", "/extract", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+		h.HandleExtract(w, req)
+		if w.Code != 400 {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestHandleTabExtract_TabIDMismatch(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/tabs/tab1/extract", bytes.NewReader([]byte(`{"tabId":"tab2","fields":[{"name":"a"}]}`)))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabExtract(w, req)
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleTabExtract_LiteRoutesWithoutChrome(t *testing.T) {
+	b := &liteActionBridge{}
+	lite := &fakeLiteEngine{}
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+	h.Router = engine.NewRouter(engine.ModeLite, lite)
+
+	req := httptest.NewRequest("POST", "/tabs/lite-1/extract", bytes.NewReader([]byte(`{"fields":[{"name":"title","selector":"h1"}]}`)))
+	req.SetPathValue("id", "lite-1")
+	w := httptest.NewRecorder()
+	h.HandleTabExtract(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("X-Engine"); got != "lite" {
+		t.Errorf("expected X-Engine=lite, got %q", got)
+	}
+	if b.ensureChromeCalled {
+		t.Error("expected lite extract to skip chrome initialization")
+	}
+	if len(lite.extractTabs) != 1 || lite.extractTabs[0] != "lite-1" {
+		t.Errorf("extract tabs = %v", lite.extractTabs)
+	}
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Data["title"] != "lite" {
+		t.Errorf("unexpected response %s", w.Body.String())
+	}
+}
+
+func TestHandleExtract_LiteNextToBlockedHostStops(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		next string
+		idpi config.IDPIConfig
+		code string
+	}{
+		{name: "metadata address", next: "http://169.254.169.254/latest/meta-data/", code: "next_page_blocked"},
+		{name: "private address", next: "http://10.0.0.5/admin", code: "next_page_blocked"},
+		{
+			name: "idpi blocked domain",
+			next: "https://evil.example/page2",
+			idpi: config.IDPIConfig{Enabled: true, AllowedDomains: []string{"127.0.0.1"}, StrictMode: true},
+			code: "idpi_domain_blocked",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`<!doctype html><html><body><h1>Page 1</h1><a class="next" href="` + tc.next + `">Next</a></body></html>`))
+			}))
+			defer ts.Close()
+
+			lite := engine.NewLiteEngine()
+			defer func() { _ = lite.Close() }()
+			if _, err := lite.Navigate(context.Background(), ts.URL); err != nil {
+				t.Fatalf("navigate: %v", err)
+			}
+			h := New(&mockBridge{}, &config.RuntimeConfig{Engine: "lite", IDPI: tc.idpi}, nil, nil, nil)
+			h.Router = engine.NewRouter(engine.ModeLite, lite)
+
+			req := httptest.NewRequest("POST", "/extract", bytes.NewReader([]byte(`{"fields":[{"name":"title","selector":"h1"}],"next":"a.next"}`)))
+			w := httptest.NewRecorder()
+			h.HandleExtract(w, req)
+			if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), tc.code) {
+				t.Fatalf("expected 403 %s, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "Page 1") {
+				t.Fatalf("a blocked extraction must not return page data: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestExtractNextGuard(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	ctx := context.Background()
+	if err := h.extractNextGuard(ctx, "http://127.0.0.1:8080/list", "http://127.0.0.1:8080/list?page=2"); err != nil {
+		t.Errorf("local page to local page should be allowed: %v", err)
+	}
+	if err := h.extractNextGuard(ctx, "https://pinchtab.com/", "http://localhost:9867/tabs"); err == nil {
+		t.Error("a public page must not lead pagination to a local host")
+	}
+	if err := h.extractNextGuard(ctx, "https://pinchtab.com/", "javascript:void(0)"); err != nil {
+		t.Errorf("script links are vetted after navigation: %v", err)
+	}
+}
@@ -140,7 +140,28 @@ func (h *Handlers) RegisterRoutes(mux *http.ServeMux, doShutdown func()) {
 	mux.HandleFunc("POST /tabs/{id}/cookies", h.HandleTabSetCookies)
 	mux.HandleFunc("GET /cookies", h.HandleGetCookies)
 	mux.HandleFunc("POST /cookies", h.HandleSetCookies)
+	mux.HandleFunc("GET /storage", h.HandleGetStorage)
+	mux.HandleFunc("POST /storage", h.HandleSetStorage)
+	mux.HandleFunc("DELETE /storage", h.HandleDeleteStorage)
+	mux.HandleFunc("POST /state/export", h.HandleStateExport)
+	mux.HandleFunc("POST /state/import", h.HandleStateImport)
+	mux.HandleFunc("POST /workflows/run", h.HandleWorkflowRun)
+	mux.HandleFunc("POST /extract", h.HandleExtract)
+	mux.HandleFunc("POST /tabs/{id}/extract", h.HandleTabExtract)
+	mux.HandleFunc("POST /screenshot/compare", h.HandleScreenshotCompare)
+	mux.HandleFunc("POST /tabs/{id}/screenshot/compare", h.HandleTabScreenshotCompare)
+	mux.HandleFunc("GET /screenshot/baselines", h.HandleListBaselines)
+	mux.HandleFunc("GET /screenshot/baselines/{name}", h.HandleGetBaseline)
+	mux.HandleFunc("PUT /screenshot/baselines/{name}", h.HandlePutBaseline)
+	mux.HandleFunc("DELETE /screenshot/baselines/{name}", h.HandleDeleteBaseline)
+	mux.HandleFunc("POST /screenshot/baselines/{name}/accept", h.HandleAcceptBaseline)
+	mux.HandleFunc("GET /tabs/{id}/storage", h.HandleTabGetStorage)
+	mux.HandleFunc("POST /tabs/{id}/storage", h.HandleTabSetStorage)
+	mux.HandleFunc("DELETE /tabs/{id}/storage", h.HandleTabDeleteStorage)
 	mux.HandleFunc("POST /fingerprint/rotate", h.HandleFingerprintRotate)
+	mux.HandleFunc("GET /emulate/devices", h.HandleEmulateDevices)
+	mux.HandleFunc("POST /emulate", h.HandleEmulate)
+	mux.HandleFunc("POST /tabs/{id}/emulate", h.HandleTabEmulate)
 	mux.HandleFunc("GET /stealth/status", h.HandleStealthStatus)
 	mux.HandleFunc("GET /tabs/{id}/download", h.HandleTabDownload)
 	mux.HandleFunc("POST /tabs/{id}/upload", h.HandleTabUpload)
@@ -163,6 +184,21 @@ func (h *Handlers) RegisterRoutes(mux *http.ServeMux, doShutdown func()) {
 	mux.HandleFunc("GET /tabs/{id}/network", h.HandleTabNetwork)
 	mux.HandleFunc("GET /tabs/{id}/network/stream", h.HandleTabNetworkStream)
 	mux.HandleFunc("GET /tabs/{id}/network/{requestId}", h.HandleTabNetworkByID)
+	mux.HandleFunc("GET /network/har", h.HandleNetworkHAR)
+	mux.HandleFunc("GET /tabs/{id}/network/har", h.HandleTabNetworkHAR)
+	mux.HandleFunc("GET /network/har/replay", h.HandleHARReplayStatus)
+	mux.HandleFunc("POST /network/har/replay", h.HandleHARReplayStart)
+	mux.HandleFunc("DELETE /network/har/replay", h.HandleHARReplayStop)
+	mux.HandleFunc("GET /tabs/{id}/network/har/replay", h.HandleTabHARReplayStatus)
+	mux.HandleFunc("POST /tabs/{id}/network/har/replay", h.HandleTabHARReplayStart)
+	mux.HandleFunc("DELETE /tabs/{id}/network/har/replay", h.HandleTabHARReplayStop)
+	mux.HandleFunc("GET /intercept", h.HandleInterceptList)
+	mux.HandleFunc("POST /intercept", h.HandleInterceptAdd)
+	mux.HandleFunc("DELETE /intercept", h.HandleInterceptRemove)
+	mux.HandleFunc("GET /tabs/{id}/intercept", h.HandleTabInterceptList)
+	mux.HandleFunc("POST /tabs/{id}/intercept", h.HandleTabInterceptAdd)
+	mux.HandleFunc("DELETE /tabs/{id}/intercept", h.HandleTabInterceptClear)
+	mux.HandleFunc("DELETE /tabs/{id}/intercept/{ruleId}", h.HandleTabInterceptRemove)
 	mux.HandleFunc("POST /dialog", h.HandleDialog)
 	mux.HandleFunc("POST /tabs/{id}/dialog", h.HandleTabDialog)
 	mux.HandleFunc("POST /wait", h.HandleWait)
@@ -98,6 +98,10 @@ func (m *mockBridge) NetworkMonitor() *bridge.NetworkMonitor {
 	return nil
 }
 
+func (m *mockBridge) Interceptor() *bridge.InterceptManager {
+	return nil
+}
+
 func (m *mockBridge) GetDialogManager() *bridge.DialogManager {
 	return bridge.NewDialogManager()
 }
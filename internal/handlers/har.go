@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+type harReplayRequest struct {
+	TabID string          `json:"tabId"`
+	HAR   json.RawMessage `json:"har"`
+	bridge.HARReplayOptions
+}
+
+// HandleNetworkHAR exports captured network entries as HAR 1.2.
+//
+// @Endpoint GET /network/har
+// @Description Exports the tab's network buffer as an HTTP Archive (HAR 1.2)
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+// @Param body bool query Include response bodies via Network.getResponseBody (optional, default: false)
+// @Param filter string query URL pattern filter (optional)
+// @Param method string query HTTP method filter (optional)
+// @Param status string query Status code range filter (optional)
+// @Param type string query Resource type filter (optional)
+//
+// @Response 200 application/json HAR document
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleNetworkHAR(w http.ResponseWriter, r *http.Request) {
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+
+	tabCtx, resolvedTabID, err := h.tabContext(r, r.URL.Query().Get("tabId"))
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, tabCtx, resolvedTabID); !ok {
+		return
+	}
+
+	var entries []bridge.NetworkEntry
+	if nm := h.Bridge.NetworkMonitor(); nm != nil {
+		if buf := nm.GetBuffer(resolvedTabID); buf != nil {
+			entries = buf.List(bridge.NetworkFilter{
+				URLPattern:   r.URL.Query().Get("filter"),
+				Method:       r.URL.Query().Get("method"),
+				StatusRange:  r.URL.Query().Get("status"),
+				ResourceType: r.URL.Query().Get("type"),
+			})
+		}
+	}
+
+	var bodies map[string]bridge.HARBody
+	if r.URL.Query().Get("body") == "true" {
+		bodies = make(map[string]bridge.HARBody)
+		budget := maxHARExportBodyBytes
+		for _, e := range entries {
+			if !e.Finished || e.Failed {
+				continue
+			}
+			body, base64Encoded, err := bridge.GetResponseBodyDirect(tabCtx, e.RequestID)
+			if err != nil || len(body) > budget {
+				continue
+			}
+			budget -= len(body)
+			bodies[e.RequestID] = bridge.HARBody{Body: body, Base64Encoded: base64Encoded}
+		}
+	}
+
+	httpx.JSON(w, 200, bridge.BuildHAR(entries, bodies, bridge.HARCreator{Name: "pinchtab"}))
+}
+
+// HandleTabNetworkHAR exports network entries for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/network/har
+func (h *Handlers) HandleTabNetworkHAR(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.HandleNetworkHAR(w, withTabIDQuery(r, tabID))
+}
+
+// HandleHARReplayStart starts serving recorded responses from a HAR archive.
+//
+// @Endpoint POST /network/har/replay
+// @Description Fulfils matching requests from a HAR archive via CDP Fetch.fulfillRequest
+//
+// @Param tabId string body Tab ID (optional, uses current tab if empty)
+// @Param har object body HAR 1.2 document (required)
+// @Param url string body URL glob limiting which requests are replayed (optional, default "*")
+// @Param notFound string body "abort" (default) or "continue" for requests missing from the archive
+//
+// @Response 200 application/json Replay status
+// @Response 400 application/json Invalid HAR
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleHARReplayStart(w http.ResponseWriter, r *http.Request) {
+	var req harReplayRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHARBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	h.handleHARReplayStart(w, r, req)
+}
+
+// HandleTabHARReplayStart starts HAR replay on a tab identified by path ID.
+//
+// @Endpoint POST /tabs/{id}/network/har/replay
+func (h *Handlers) HandleTabHARReplayStart(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	var req harReplayRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHARBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.TabID != "" && req.TabID != tabID {
+		httpx.Error(w, 400, fmt.Errorf("tabId in body does not match path id"))
+		return
+	}
+	req.TabID = tabID
+	h.handleHARReplayStart(w, r, req)
+}
+
+func (h *Handlers) handleHARReplayStart(w http.ResponseWriter, r *http.Request, req harReplayRequest) {
+	if len(req.HAR) == 0 {
+		httpx.Error(w, 400, fmt.Errorf("har required"))
+		return
+	}
+	har, err := bridge.ParseHAR(req.HAR)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	replay, err := bridge.NewHARReplay(har, req.HARReplayOptions)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	tabCtx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, tabCtx, resolvedTabID); !ok {
+		return
+	}
+
+	im := h.Bridge.Interceptor()
+	if im == nil {
+		httpx.Error(w, 500, fmt.Errorf("request interception not available"))
+		return
+	}
+	if err := im.SetReplay(tabCtx, resolvedTabID, replay); err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"replay": replay.Status(), "active": true, "tabId": resolvedTabID})
+}
+
+// HandleHARReplayStatus reports the HAR replay installed on a tab.
+//
+// @Endpoint GET /network/har/replay
+// @Description Returns HAR replay status and hit/miss counters
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json Replay status
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleHARReplayStatus(w http.ResponseWriter, r *http.Request) {
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	_, resolvedTabID, err := h.tabContext(r, r.URL.Query().Get("tabId"))
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	resp := map[string]any{"active": false, "tabId": resolvedTabID}
+	if im := h.Bridge.Interceptor(); im != nil {
+		if replay := im.Replay(resolvedTabID); replay != nil {
+			resp["active"] = true
+			resp["replay"] = replay.Status()
+		}
+	}
+	httpx.JSON(w, 200, resp)
+}
+
+// HandleTabHARReplayStatus reports HAR replay for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/network/har/replay
+func (h *Handlers) HandleTabHARReplayStatus(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.HandleHARReplayStatus(w, withTabIDQuery(r, tabID))
+}
+
+// HandleHARReplayStop removes the HAR replay from a tab.
+//
+// @Endpoint DELETE /network/har/replay
+// @Description Stops HAR replay; requests go back to the network
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json Stop result
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleHARReplayStop(w http.ResponseWriter, r *http.Request) {
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	tabCtx, resolvedTabID, err := h.tabContext(r, r.URL.Query().Get("tabId"))
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	stopped := false
+	if im := h.Bridge.Interceptor(); im != nil {
+		if stopped, err = im.ClearReplay(tabCtx, resolvedTabID); err != nil {
+			httpx.Error(w, 500, err)
+			return
+		}
+	}
+	httpx.JSON(w, 200, map[string]any{"stopped": stopped, "tabId": resolvedTabID})
+}
+
+// HandleTabHARReplayStop removes HAR replay from a tab identified by path ID.
+//
+// @Endpoint DELETE /tabs/{id}/network/har/replay
+func (h *Handlers) HandleTabHARReplayStop(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.HandleHARReplayStop(w, withTabIDQuery(r, tabID))
+}
+
+// withTabIDQuery returns a clone of r with tabId set in the query string.
+func withTabIDQuery(r *http.Request, tabID string) *http.Request {
+	q := r.URL.Query()
+	q.Set("tabId", tabID)
+	req := r.Clone(r.Context())
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	req.URL = &u
+	return req
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+func TestHandleNetworkHAR_ExportsBuffer(t *testing.T) {
+	nm := bridge.NewNetworkMonitor(100)
+	seedBuffer(nm, "tab1")
+	h := newNetworkTestHandler(nm)
+
+	req := httptest.NewRequest("GET", "/network/har?method=GET", nil)
+	w := httptest.NewRecorder()
+	h.HandleNetworkHAR(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var har bridge.HAR
+	if err := json.Unmarshal(w.Body.Bytes(), &har); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if har.Log.Version != "1.2" {
+		t.Errorf("expected HAR 1.2, got %q", har.Log.Version)
+	}
+	if len(har.Log.Entries) != 2 {
+		t.Fatalf("expected 2 GET entries, got %d", len(har.Log.Entries))
+	}
+	if har.Log.Entries[0].Request.URL != "https://api.example.com/users" {
+		t.Errorf("unexpected first entry: %+v", har.Log.Entries[0].Request)
+	}
+}
+
+func TestHandleTabNetworkHAR_NoCapture(t *testing.T) {
+	h := newNetworkTestHandler(bridge.NewNetworkMonitor(100))
+
+	req := httptest.NewRequest("GET", "/tabs/tab1/network/har", nil)
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabNetworkHAR(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"entries":[]`) {
+		t.Errorf("expected empty entries, got %s", w.Body.String())
+	}
+}
+
+func TestHandleHARReplayStart_Validation(t *testing.T) {
+	h := newInterceptTestHandler()
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"missing har", `{}`, "har required"},
+		{"no entries", `{"har":{"log":{"version":"1.2","entries":[]}}}`, "no entries"},
+		{"bad policy", `{"notFound":"ignore","har":{"log":{"entries":[{"request":{"method":"GET","url":"https://x.test/"},"response":{"status":200,"content":{}}}]}}}`, "invalid notFound"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/network/har/replay", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.HandleHARReplayStart(w, req)
+			if w.Code != 400 {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("expected %q in error, got %s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleHARReplayStatus_Inactive(t *testing.T) {
+	h := newInterceptTestHandler()
+	req := httptest.NewRequest("GET", "/network/har/replay", nil)
+	w := httptest.NewRecorder()
+	h.HandleHARReplayStatus(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"active":false`) {
+		t.Errorf("expected inactive replay, got %s", w.Body.String())
+	}
+}
@@ -453,6 +453,10 @@ func (m *MockBridge) NetworkMonitor() *bridge.NetworkMonitor {
 	return nil
 }
 
+func (m *MockBridge) Interceptor() *bridge.InterceptManager {
+	return nil
+}
+
 func (m *MockBridge) GetDialogManager() *bridge.DialogManager {
 	return bridge.NewDialogManager()
 }
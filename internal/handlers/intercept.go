@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+type interceptRequest struct {
+	TabID string                 `json:"tabId"`
+	Rules []bridge.InterceptRule `json:"rules"`
+	bridge.InterceptRule
+}
+
+// HandleInterceptList lists the request interception rules for a tab.
+//
+// @Endpoint GET /intercept
+// @Description Returns the request interception rules installed on a tab
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json List of rules with hit counts
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleInterceptList(w http.ResponseWriter, r *http.Request) {
+	h.handleInterceptList(w, r, r.URL.Query().Get("tabId"))
+}
+
+// HandleTabInterceptList lists interception rules for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/intercept
+func (h *Handlers) HandleTabInterceptList(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.handleInterceptList(w, r, tabID)
+}
+
+func (h *Handlers) handleInterceptList(w http.ResponseWriter, r *http.Request, tabID string) {
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	_, resolvedTabID, err := h.tabContext(r, tabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	im := h.Bridge.Interceptor()
+	if im == nil {
+		httpx.JSON(w, 200, map[string]any{"rules": []any{}, "count": 0, "tabId": resolvedTabID})
+		return
+	}
+	rules := im.Rules(resolvedTabID)
+	httpx.JSON(w, 200, map[string]any{"rules": rules, "count": len(rules), "tabId": resolvedTabID})
+}
+
+// HandleInterceptAdd installs request interception rules on a tab.
+//
+// @Endpoint POST /intercept
+// @Description Adds rules that fulfil, modify, delay or abort matching requests via CDP Fetch
+//
+// @Param tabId string body Tab ID (optional, uses current tab if empty)
+// @Param rules array body List of rules; a single rule may also be given inline
+// @Param url string body URL glob ('*' any run, '?' one char; default '*')
+// @Param method string body HTTP method matcher (optional)
+// @Param resourceType string body Resource type matcher e.g. "xhr", "fetch", "document" (optional)
+// @Param action string body "fulfill", "continue" or "abort"
+// @Param status int body Response status for fulfill (default 200)
+// @Param headers object body Response headers for fulfill
+// @Param body string body Response body for fulfill
+// @Param bodyBase64 bool body Treat body as base64-encoded bytes
+// @Param requestHeaders object body Request header overrides for continue ("" removes a header)
+// @Param errorReason string body CDP network error reason for abort (default "Failed")
+// @Param delayMs int body Delay before applying the action (max 60000)
+//
+// @Response 200 application/json Installed rules
+// @Response 400 application/json Invalid rule
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleInterceptAdd(w http.ResponseWriter, r *http.Request) {
+	var req interceptRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	h.handleInterceptAdd(w, r, req)
+}
+
+// HandleTabInterceptAdd installs interception rules on a tab identified by path ID.
+//
+// @Endpoint POST /tabs/{id}/intercept
+func (h *Handlers) HandleTabInterceptAdd(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	var req interceptRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.TabID != "" && req.TabID != tabID {
+		httpx.Error(w, 400, fmt.Errorf("tabId in body does not match path id"))
+		return
+	}
+	req.TabID = tabID
+	h.handleInterceptAdd(w, r, req)
+}
+
+func (h *Handlers) handleInterceptAdd(w http.ResponseWriter, r *http.Request, req interceptRequest) {
+	rules := req.Rules
+	if len(rules) == 0 && (req.URLPattern != "" || req.Action != "" || req.Status != 0 || req.Body != "" || req.ErrorReason != "") {
+		rules = []bridge.InterceptRule{req.InterceptRule}
+	}
+	if len(rules) == 0 {
+		httpx.Error(w, 400, fmt.Errorf("at least one rule required"))
+		return
+	}
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			httpx.Error(w, 400, fmt.Errorf("rule %d: %w", i, err))
+			return
+		}
+	}
+
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	tabCtx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, tabCtx, resolvedTabID); !ok {
+		return
+	}
+
+	im := h.Bridge.Interceptor()
+	if im == nil {
+		httpx.Error(w, 500, fmt.Errorf("request interception not available"))
+		return
+	}
+	added, err := im.AddRules(tabCtx, resolvedTabID, rules)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"added": added,
+		"count": len(im.Rules(resolvedTabID)),
+		"tabId": resolvedTabID,
+	})
+}
+
+// HandleInterceptRemove removes one or all interception rules from a tab.
+//
+// @Endpoint DELETE /intercept
+// @Description Removes a rule by ID, or every rule on the tab when no ID is given
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+// @Param id string query Rule ID (optional, clears all if empty)
+//
+// @Response 200 application/json Removal result
+// @Response 404 application/json Tab or rule not found
+func (h *Handlers) HandleInterceptRemove(w http.ResponseWriter, r *http.Request) {
+	h.handleInterceptRemove(w, r, r.URL.Query().Get("tabId"), r.URL.Query().Get("id"))
+}
+
+// HandleTabInterceptClear removes every interception rule from a tab identified by path ID.
+//
+// @Endpoint DELETE /tabs/{id}/intercept
+func (h *Handlers) HandleTabInterceptClear(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.handleInterceptRemove(w, r, tabID, "")
+}
+
+// HandleTabInterceptRemove removes a single interception rule from a tab.
+//
+// @Endpoint DELETE /tabs/{id}/intercept/{ruleId}
+func (h *Handlers) HandleTabInterceptRemove(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	ruleID := r.PathValue("ruleId")
+	if tabID == "" || ruleID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id and rule id required"))
+		return
+	}
+	h.handleInterceptRemove(w, r, tabID, ruleID)
+}
+
+func (h *Handlers) handleInterceptRemove(w http.ResponseWriter, r *http.Request, tabID, ruleID string) {
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	tabCtx, resolvedTabID, err := h.tabContext(r, tabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	im := h.Bridge.Interceptor()
+	if im == nil {
+		httpx.JSON(w, 200, map[string]any{"removed": 0, "tabId": resolvedTabID})
+		return
+	}
+
+	if ruleID == "" {
+		n, err := im.ClearTab(tabCtx, resolvedTabID)
+		if err != nil {
+			httpx.Error(w, 500, err)
+			return
+		}
+		httpx.JSON(w, 200, map[string]any{"removed": n, "tabId": resolvedTabID})
+		return
+	}
+
+	if err := im.RemoveRule(tabCtx, resolvedTabID, ruleID); err != nil {
+		code := 500
+		if errors.Is(err, bridge.ErrInterceptRuleNotFound) {
+			code = 404
+		}
+		httpx.Error(w, code, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"removed": 1, "id": ruleID, "tabId": resolvedTabID})
+}
+
+// navigateWithRedirectLimit navigates a tab under the configured redirect
+// limit. The limit is enforced by the tab's interception listener, so rules
+// and HAR replay installed on the tab still apply to the navigation.
+func (h *Handlers) navigateWithRedirectLimit(tabCtx, ctx context.Context, tabID, url string) error {
+	return h.Bridge.Interceptor().NavigateWithRedirectLimit(tabCtx, ctx, tabID, url, h.Config.MaxRedirects)
+}
+
+// restoreInterception re-enables request interception after a
+// redirect-limited navigation. It is deferred so that every exit path,
+// including failed navigations, leaves the tab's rules in force.
+func (h *Handlers) restoreInterception(tabCtx context.Context, tabID string) {
+	if h.Config.MaxRedirects < 0 {
+		return
+	}
+	im := h.Bridge.Interceptor()
+	if im == nil {
+		return
+	}
+	if err := im.Reapply(tabCtx, tabID); err != nil {
+		slog.Warn("restore request interception failed", "tabId", tabID, "err", err)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// interceptMockBridge extends mockBridge with a real InterceptManager.
+type interceptMockBridge struct {
+	mockBridge
+	im *bridge.InterceptManager
+}
+
+func (m *interceptMockBridge) Interceptor() *bridge.InterceptManager {
+	return m.im
+}
+
+func newInterceptTestHandler() *Handlers {
+	return New(&interceptMockBridge{im: bridge.NewInterceptManager()}, &config.RuntimeConfig{}, nil, nil, nil)
+}
+
+func TestHandleInterceptAdd_RequiresRule(t *testing.T) {
+	h := newInterceptTestHandler()
+	req := httptest.NewRequest("POST", "/intercept", strings.NewReader(`{"tabId":"tab1"}`))
+	w := httptest.NewRecorder()
+	h.HandleInterceptAdd(w, req)
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleInterceptAdd_InvalidRule(t *testing.T) {
+	h := newInterceptTestHandler()
+	body := `{"rules":[{"url":"*","action":"redirect"}]}`
+	req := httptest.NewRequest("POST", "/intercept", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.HandleInterceptAdd(w, req)
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "invalid action") {
+		t.Errorf("expected invalid action error, got %s", w.Body.String())
+	}
+}
+
+func TestHandleTabInterceptAdd_TabIDMismatch(t *testing.T) {
+	h := newInterceptTestHandler()
+	req := httptest.NewRequest("POST", "/tabs/tab1/intercept", strings.NewReader(`{"tabId":"other","url":"*","status":204}`))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabInterceptAdd(w, req)
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleInterceptList_Empty(t *testing.T) {
+	h := newInterceptTestHandler()
+	req := httptest.NewRequest("GET", "/intercept", nil)
+	w := httptest.NewRecorder()
+	h.HandleInterceptList(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Rules []bridge.InterceptRule `json:"rules"`
+		Count int                    `json:"count"`
+		TabID string                 `json:"tabId"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Count != 0 || resp.TabID != "tab1" {
+		t.Errorf("expected empty list for tab1, got %+v", resp)
+	}
+}
+
+func TestHandleInterceptList_TabNotFound(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("GET", "/intercept?tabId=missing", nil)
+	w := httptest.NewRecorder()
+	h.HandleInterceptList(w, req)
+	if w.Code != 404 {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleTabInterceptRemove_UnknownRule(t *testing.T) {
+	h := newInterceptTestHandler()
+	req := httptest.NewRequest("DELETE", "/tabs/tab1/intercept/rule_missing", nil)
+	req.SetPathValue("id", "tab1")
+	req.SetPathValue("ruleId", "rule_missing")
+	w := httptest.NewRecorder()
+	h.HandleTabInterceptRemove(w, req)
+	if w.Code != 404 {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleInterceptRemove_ClearEmptyTab(t *testing.T) {
+	h := newInterceptTestHandler()
+	req := httptest.NewRequest("DELETE", "/intercept", nil)
+	w := httptest.NewRecorder()
+	h.HandleInterceptRemove(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"removed":0`) {
+		t.Errorf("expected removed 0, got %s", w.Body.String())
+	}
+}
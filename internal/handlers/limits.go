@@ -1,3 +1,13 @@
 package handlers
 
 const maxBodySize = 1 << 20
+
+// maxHARBodySize bounds uploaded HAR archives for replay.
+const maxHARBodySize = 64 << 20
+
+// maxHARExportBodyBytes bounds the total response body bytes embedded in a
+// HAR export.
+const maxHARExportBodyBytes = 32 << 20
+
+// maxStateBundleSize bounds state bundles posted to /state/import.
+const maxStateBundleSize = 32 << 20
@@ -14,6 +14,7 @@ import (
 
 	"github.com/pinchtab/pinchtab/internal/authn"
 	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/fleet"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
@@ -78,7 +79,7 @@ func AuthMiddleware(cfg *config.RuntimeConfig, next http.Handler) http.Handler {
 
 func AuthMiddlewareWithSessions(cfg *config.RuntimeConfig, sessions *authn.SessionManager, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if isPublicDashboardPath(r.URL.Path) || isPublicAuthPath(r.URL.Path) {
+		if isPublicDashboardPath(r.URL.Path) || isPublicAuthPath(r.URL.Path) || isFleetPath(r) {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -155,6 +156,20 @@ func isPublicAuthPath(path string) bool {
 	}
 }
 
+// isFleetPath reports fleet registration requests. Their handlers check the
+// join token instead of the server token.
+func isFleetPath(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+	switch r.URL.Path {
+	case fleet.RegisterPath, fleet.HeartbeatPath, fleet.DeregisterPath:
+		return true
+	default:
+		return false
+	}
+}
+
 func cookieAuthAllowed(r *http.Request) bool {
 	path := strings.TrimSpace(r.URL.Path)
 	switch r.Method {
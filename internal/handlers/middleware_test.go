@@ -154,6 +154,32 @@ func TestAuthMiddleware_MissingTokenHeader(t *testing.T) {
 	}
 }
 
+func TestAuthMiddleware_FleetPathsSkipServerToken(t *testing.T) {
+	cfg := &config.RuntimeConfig{Token: "secret123"}
+
+	handler := AuthMiddleware(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{"POST", "/fleet/register", 200},
+		{"POST", "/fleet/heartbeat", 200},
+		{"POST", "/fleet/deregister", 200},
+		{"GET", "/fleet/register", 401},
+		{"POST", "/fleet/other", 401},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, w.Code)
+		}
+	}
+}
+
 func TestAuthMiddleware_ValidCookie(t *testing.T) {
 	cfg := &config.RuntimeConfig{Token: "secret123"}
 	sessions := authn.NewSessionManager(authn.SessionConfig{})
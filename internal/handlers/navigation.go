@@ -195,7 +195,7 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 			_ = bridge.SetResourceBlocking(tCtx, blockPatterns)
 		}
 
-		if err := bridge.NavigatePageWithRedirectLimit(tCtx, req.URL, h.Config.MaxRedirects); err != nil {
+		if err := h.navigateWithRedirectLimit(newCtx, tCtx, newTabID, req.URL); err != nil {
 			if navGuard != nil {
 				if blockedErr := navGuard.blocked(); blockedErr != nil {
 					httpx.Error(w, http.StatusForbidden, blockedErr)
@@ -249,7 +249,8 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 		_ = bridge.SetResourceBlocking(tCtx, nil)
 	}
 
-	if err := bridge.NavigatePageWithRedirectLimit(tCtx, req.URL, h.Config.MaxRedirects); err != nil {
+	defer h.restoreInterception(ctx, resolvedTabID)
+	if err := h.navigateWithRedirectLimit(ctx, tCtx, resolvedTabID, req.URL); err != nil {
 		if navGuard != nil {
 			if blockedErr := navGuard.blocked(); blockedErr != nil {
 				httpx.Error(w, http.StatusForbidden, blockedErr)
@@ -358,7 +359,7 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 		if req.URL != "" && req.URL != "about:blank" {
 			tCtx, tCancel := context.WithTimeout(ctx, h.Config.NavigateTimeout)
 			defer tCancel()
-			if err := bridge.NavigatePageWithRedirectLimit(tCtx, req.URL, h.Config.MaxRedirects); err != nil {
+			if err := h.navigateWithRedirectLimit(ctx, tCtx, newTabID, req.URL); err != nil {
 				_ = h.Bridge.CloseTab(newTabID)
 				code := 500
 				if errors.Is(err, bridge.ErrTooManyRedirects) {
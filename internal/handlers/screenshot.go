@@ -3,33 +3,61 @@ package handlers
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chromedp/cdproto/page"
-	"github.com/chromedp/chromedp"
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/imagediff"
 )
 
+// maxScreenshotScale bounds the scale query parameter.
+const maxScreenshotScale = 4
+
 // HandleScreenshot captures a screenshot of the current tab.
 //
 // @Endpoint GET /screenshot
+// @Description Captures the viewport, the full page, an element or a clip rectangle as JPEG, PNG or WebP
+//
+// @Param tabId string query Tab ID (optional)
+// @Param format string query jpeg (default), png or webp (optional)
+// @Param quality int query JPEG/WebP quality 0-100 (optional, default: 80 for JPEG)
+// @Param selector string query Capture only this element (optional)
+// @Param fullPage bool query Capture the whole scrollable page (optional)
+// @Param clip string query Document rectangle x,y,width,height in CSS pixels (optional)
+// @Param scale number query Output scale factor, up to 4 (optional, default: 1)
+// @Param raw bool query Return image bytes instead of JSON (optional)
+// @Param output string query "file" saves under the state directory (optional)
+//
+// @Response 200 application/json Base64 image, or raw bytes with raw=true
+// @Response 400 application/json Invalid parameters
+// @Response 404 application/json Tab or element not found
 func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts, err := parseScreenshotOptions(q)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+
 	// Ensure Chrome is initialized
 	if err := h.ensureChrome(); err != nil {
 		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
 		return
 	}
 
-	tabID := r.URL.Query().Get("tabId")
-	output := r.URL.Query().Get("output")
-	reqNoAnim := r.URL.Query().Get("noAnimations") == "true"
+	tabID := q.Get("tabId")
+	output := q.Get("output")
+	reqNoAnim := q.Get("noAnimations") == "true"
 
 	ctx, resolvedTabID, err := h.tabContext(r, tabID)
 	if err != nil {
@@ -51,38 +79,30 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var buf []byte
-	quality := 80
-	if q := r.URL.Query().Get("quality"); q != "" {
-		if qn, err := strconv.Atoi(q); err == nil {
-			quality = qn
+	if sel := q.Get("selector"); sel != "" {
+		clip, status, err := h.elementScreenshotClip(tCtx, resolvedTabID, sel)
+		if err != nil {
+			httpx.Error(w, status, err)
+			return
 		}
+		opts.Clip = clip
 	}
 
-	format := page.CaptureScreenshotFormatJpeg
-	contentType := "image/jpeg"
-	ext := ".jpg"
-
-	if r.URL.Query().Get("format") == "png" {
-		format = page.CaptureScreenshotFormatPng
-		contentType = "image/png"
-		ext = ".png"
+	buf, err := bridge.CaptureScreenshot(tCtx, opts)
+	if errors.Is(err, bridge.ErrScreenshotTooLarge) {
+		httpx.Error(w, 400, err)
+		return
 	}
-
-	if err := chromedp.Run(tCtx,
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			var err error
-			shot := page.CaptureScreenshot().WithFormat(format)
-			if format == page.CaptureScreenshotFormatJpeg {
-				shot = shot.WithQuality(int64(quality))
-			}
-			buf, err = shot.Do(ctx)
-			return err
-		}),
-	); err != nil {
+	if err != nil {
 		httpx.Error(w, 500, fmt.Errorf("screenshot: %w", err))
 		return
 	}
+	format := opts.Format
+	contentType := "image/" + string(format)
+	ext := "." + string(format)
+	if format == page.CaptureScreenshotFormatJpeg {
+		ext = ".jpg"
+	}
 
 	if output == "file" {
 		screenshotDir := filepath.Join(h.Config.StateDir, "screenshots")
@@ -109,7 +129,7 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if r.URL.Query().Get("raw") == "true" {
+	if q.Get("raw") == "true" {
 		w.Header().Set("Content-Type", contentType)
 		if _, err := w.Write(buf); err != nil {
 			slog.Error("screenshot write", "err", err)
@@ -123,6 +143,88 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// parseScreenshotOptions reads the format, quality, fullPage, clip and
+// scale query parameters. The selector is resolved later, against the tab.
+func parseScreenshotOptions(q url.Values) (bridge.ScreenshotOptions, error) {
+	opts := bridge.ScreenshotOptions{Format: page.CaptureScreenshotFormatJpeg, MaxPixels: imagediff.MaxPixels}
+	switch f := q.Get("format"); f {
+	case "", "jpeg", "jpg":
+	case "png":
+		opts.Format = page.CaptureScreenshotFormatPng
+	case "webp":
+		opts.Format = page.CaptureScreenshotFormatWebp
+	default:
+		return opts, fmt.Errorf("unsupported format %q: use jpeg, png or webp", f)
+	}
+
+	if opts.Format == page.CaptureScreenshotFormatJpeg {
+		opts.Quality = 80
+	}
+	if v := q.Get("quality"); v != "" {
+		if qn, err := strconv.Atoi(v); err == nil {
+			opts.Quality = qn
+		}
+	}
+
+	opts.FullPage = q.Get("fullPage") == "true"
+	if v := q.Get("clip"); v != "" {
+		clip, err := parseScreenshotClip(v)
+		if err != nil {
+			return opts, err
+		}
+		opts.Clip = clip
+	}
+	modes := 0
+	for _, set := range []bool{q.Get("selector") != "", opts.FullPage, opts.Clip != nil} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return opts, fmt.Errorf("selector, fullPage and clip are mutually exclusive")
+	}
+
+	if v := q.Get("scale"); v != "" {
+		scale, err := strconv.ParseFloat(v, 64)
+		if err != nil || scale <= 0 || scale > maxScreenshotScale {
+			return opts, fmt.Errorf("scale must be a number in (0, %d]", maxScreenshotScale)
+		}
+		opts.Scale = scale
+	}
+	// Full-page and selector captures are checked once their size is
+	// known; an explicit clip can be refused before touching the tab.
+	if c := opts.Clip; c != nil {
+		scale := opts.Scale
+		if scale <= 0 {
+			scale = 1
+		}
+		if c.Width*c.Height*scale*scale > imagediff.MaxPixels {
+			return opts, fmt.Errorf("%w: clip at scale %g is over %d pixels", bridge.ErrScreenshotTooLarge, scale, imagediff.MaxPixels)
+		}
+	}
+	return opts, nil
+}
+
+// parseScreenshotClip parses "x,y,width,height" in CSS pixels.
+func parseScreenshotClip(raw string) (*bridge.ScreenshotClip, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("clip must be x,y,width,height")
+	}
+	var vals [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("clip must be x,y,width,height: %w", err)
+		}
+		vals[i] = f
+	}
+	if vals[0] < 0 || vals[1] < 0 || vals[2] <= 0 || vals[3] <= 0 {
+		return nil, fmt.Errorf("clip needs a non-negative origin and a positive size")
+	}
+	return &bridge.ScreenshotClip{X: vals[0], Y: vals[1], Width: vals[2], Height: vals[3]}, nil
+}
+
 // HandleTabScreenshot returns screenshot bytes for a tab identified by path ID.
 //
 // @Endpoint GET /tabs/{id}/screenshot
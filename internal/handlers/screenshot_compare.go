@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/imagediff"
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+// maxBaselineSize bounds uploaded baseline images.
+const maxBaselineSize = 32 << 20
+
+// Comparison statuses.
+const (
+	compareCreated = "created"
+	compareUpdated = "updated"
+	comparePassed  = "passed"
+	compareFailed  = "failed"
+)
+
+type screenshotCompareRequest struct {
+	TabID    string `json:"tabId"`
+	Name     string `json:"name"`
+	Selector string `json:"selector,omitempty"`
+	FullPage bool   `json:"fullPage,omitempty"`
+	// Ignore lists regions, in screenshot pixels, excluded from the diff.
+	Ignore []imagediff.Region `json:"ignore,omitempty"`
+	// Tolerance is the per-pixel color difference (0-1) treated as equal.
+	Tolerance *float64 `json:"tolerance,omitempty"`
+	// Threshold is the mismatch percentage (0-100) at which the comparison
+	// still passes.
+	Threshold float64 `json:"threshold,omitempty"`
+	// Update replaces the baseline with the new screenshot.
+	Update       bool `json:"update,omitempty"`
+	NoAnimations bool `json:"noAnimations,omitempty"`
+}
+
+func (h *Handlers) baselineStore() *imagediff.Store {
+	return imagediff.NewStore(filepath.Join(h.Config.StateDir, "baselines"))
+}
+
+// HandleScreenshotCompare compares a screenshot against a stored baseline.
+//
+// @Endpoint POST /screenshot/compare
+// @Description Captures a PNG screenshot (viewport, full page or element) and compares it with a named baseline. Missing baselines are created from the capture.
+//
+// @Param tabId string body Tab ID (optional)
+// @Param name string body Baseline name (required)
+// @Param selector string body Capture only this element (optional)
+// @Param fullPage bool body Capture the whole page (optional)
+// @Param ignore array body Regions {x,y,width,height} to ignore, in screenshot pixels (optional)
+// @Param tolerance number body Per-pixel color tolerance 0-1 (optional, default: 0.1)
+// @Param threshold number body Mismatch percentage that still passes (optional, default: 0)
+// @Param update bool body Replace the baseline with this capture (optional)
+//
+// @Response 200 application/json Comparison status, mismatch percentage and base64 PNG diff
+// @Response 400 application/json Invalid request
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleScreenshotCompare(w http.ResponseWriter, r *http.Request) {
+	var req screenshotCompareRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	h.handleScreenshotCompare(w, r, req)
+}
+
+// HandleTabScreenshotCompare compares a screenshot of a tab identified by
+// path ID against a baseline.
+//
+// @Endpoint POST /tabs/{id}/screenshot/compare
+func (h *Handlers) HandleTabScreenshotCompare(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	var req screenshotCompareRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.TabID != "" && req.TabID != tabID {
+		httpx.Error(w, 400, fmt.Errorf("tabId in body does not match path id"))
+		return
+	}
+	req.TabID = tabID
+	h.handleScreenshotCompare(w, r, req)
+}
+
+func (h *Handlers) handleScreenshotCompare(w http.ResponseWriter, r *http.Request, req screenshotCompareRequest) {
+	if err := imagediff.ValidName(req.Name); err != nil {
+		httpx.ErrorCode(w, 400, "bad_request", err.Error(), false, nil)
+		return
+	}
+	tolerance := imagediff.DefaultTolerance
+	if req.Tolerance != nil {
+		tolerance = *req.Tolerance
+	}
+	if tolerance < 0 || tolerance > 1 {
+		httpx.Error(w, 400, fmt.Errorf("tolerance must be between 0 and 1"))
+		return
+	}
+	if req.Threshold < 0 || req.Threshold > 100 {
+		httpx.Error(w, 400, fmt.Errorf("threshold must be between 0 and 100"))
+		return
+	}
+	for _, reg := range req.Ignore {
+		if reg.Width <= 0 || reg.Height <= 0 {
+			httpx.Error(w, 400, fmt.Errorf("ignore regions need a positive width and height"))
+			return
+		}
+	}
+	if req.Selector != "" && req.FullPage {
+		httpx.Error(w, 400, fmt.Errorf("selector and fullPage are mutually exclusive"))
+		return
+	}
+
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	go httpx.CancelOnClientDone(r.Context(), tCancel)
+
+	if req.NoAnimations && !h.Config.NoAnimations {
+		if err := bridge.DisableAnimationsOnce(tCtx); err != nil {
+			httpx.Error(w, 500, fmt.Errorf("disable animations: %w", err))
+			return
+		}
+	}
+
+	opts := bridge.ScreenshotOptions{FullPage: req.FullPage}
+	if req.Selector != "" {
+		clip, status, err := h.elementScreenshotClip(tCtx, resolvedTabID, req.Selector)
+		if err != nil {
+			httpx.Error(w, status, err)
+			return
+		}
+		opts.Clip = clip
+	}
+	opts.MaxPixels = imagediff.MaxPixels
+	shot, err := bridge.CaptureScreenshot(tCtx, opts)
+	if errors.Is(err, bridge.ErrScreenshotTooLarge) {
+		httpx.Error(w, 400, err)
+		return
+	}
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("screenshot: %w", err))
+		return
+	}
+
+	store := h.baselineStore()
+	resp := map[string]any{
+		"tabId":     resolvedTabID,
+		"name":      req.Name,
+		"tolerance": tolerance,
+		"threshold": req.Threshold,
+	}
+
+	baseline, err := store.Load(req.Name)
+	if req.Update || errors.Is(err, imagediff.ErrNoBaseline) {
+		if err := store.Save(req.Name, shot); err != nil {
+			writeBaselineError(w, fmt.Errorf("save baseline: %w", err))
+			return
+		}
+		resp["status"] = compareCreated
+		if req.Update && baseline != nil {
+			resp["status"] = compareUpdated
+		}
+		resp["mismatchPercent"] = 0
+		httpx.JSON(w, 200, resp)
+		return
+	}
+	if err != nil {
+		writeBaselineError(w, err)
+		return
+	}
+
+	if _, err := imagediff.CheckPNG(shot); err != nil {
+		writeBaselineError(w, fmt.Errorf("screenshot: %w", err))
+		return
+	}
+	actual, err := png.Decode(bytes.NewReader(shot))
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("decode screenshot: %w", err))
+		return
+	}
+	res := imagediff.Compare(baseline, actual, imagediff.Options{Tolerance: tolerance, Ignore: req.Ignore})
+	resp["width"] = res.Width
+	resp["height"] = res.Height
+	resp["diffPixels"] = res.DiffPixels
+	resp["comparedPixels"] = res.ComparedPixels
+	resp["mismatchPercent"] = res.MismatchPercent
+	if res.SizeMismatch {
+		resp["sizeMismatch"] = true
+	}
+
+	passed := !res.SizeMismatch && res.MismatchPercent <= req.Threshold
+	if passed {
+		resp["status"] = comparePassed
+	} else {
+		resp["status"] = compareFailed
+		if err := store.SaveResult(req.Name, shot, res.Diff); err != nil {
+			slog.Warn("screenshot compare: save result", "name", req.Name, "err", err)
+		}
+	}
+	if res.DiffPixels > 0 {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, res.Diff); err == nil {
+			resp["diff"] = base64.StdEncoding.EncodeToString(buf.Bytes())
+		}
+	}
+	httpx.JSON(w, 200, resp)
+}
+
+// elementScreenshotClip resolves a selector to the element's document box.
+// Elements inside frames are rejected because their boxes are relative to
+// the frame.
+func (h *Handlers) elementScreenshotClip(ctx context.Context, tabID, raw string) (*bridge.ScreenshotClip, int, error) {
+	sel := selector.Parse(raw)
+	if err := sel.Validate(); err != nil {
+		return nil, 400, err
+	}
+	if len(sel.Frames) > 0 {
+		return nil, 400, fmt.Errorf("frame: selectors are not supported for screenshots")
+	}
+	var nodeID int64
+	var err error
+	if sel.Scoped() {
+		_, nodeID, err = bridge.ResolveScopedSelector(ctx, sel)
+	} else {
+		nodeID, err = bridge.ResolveUnifiedSelector(ctx, sel, h.Bridge.GetRefCache(tabID))
+	}
+	if err != nil {
+		return nil, 404, fmt.Errorf("selector %q: %w", raw, err)
+	}
+	clip, err := bridge.ElementScreenshotClip(ctx, nodeID)
+	if err != nil {
+		return nil, 500, fmt.Errorf("element box: %w", err)
+	}
+	return clip, 0, nil
+}
+
+// HandleListBaselines lists stored screenshot baselines.
+//
+// @Endpoint GET /screenshot/baselines
+func (h *Handlers) HandleListBaselines(w http.ResponseWriter, r *http.Request) {
+	list, err := h.baselineStore().List()
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"baselines": list})
+}
+
+// HandleGetBaseline returns a baseline PNG, or the diff from its last
+// failed comparison with ?diff=true.
+//
+// @Endpoint GET /screenshot/baselines/{name}
+func (h *Handlers) HandleGetBaseline(w http.ResponseWriter, r *http.Request) {
+	store := h.baselineStore()
+	name := r.PathValue("name")
+	read := store.Read
+	if r.URL.Query().Get("diff") == "true" {
+		read = store.ReadDiff
+	}
+	data, err := read(name)
+	if err != nil {
+		writeBaselineError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	if _, err := w.Write(data); err != nil {
+		slog.Error("baseline write", "err", err)
+	}
+}
+
+// HandlePutBaseline stores the PNG request body as a baseline.
+//
+// @Endpoint PUT /screenshot/baselines/{name}
+func (h *Handlers) HandlePutBaseline(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBaselineSize))
+	if err != nil {
+		httpx.Error(w, 400, fmt.Errorf("read body: %w", err))
+		return
+	}
+	name := r.PathValue("name")
+	if err := h.baselineStore().Save(name, data); err != nil {
+		if errors.Is(err, imagediff.ErrTooLarge) {
+			writeBaselineError(w, err)
+			return
+		}
+		httpx.Error(w, 400, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"name": name, "saved": true})
+}
+
+// HandleAcceptBaseline promotes the screenshot from the last failed
+// comparison to be the baseline.
+//
+// @Endpoint POST /screenshot/baselines/{name}/accept
+func (h *Handlers) HandleAcceptBaseline(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := h.baselineStore().Accept(name); err != nil {
+		writeBaselineError(w, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"name": name, "accepted": true})
+}
+
+// HandleDeleteBaseline removes a baseline.
+//
+// @Endpoint DELETE /screenshot/baselines/{name}
+func (h *Handlers) HandleDeleteBaseline(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := h.baselineStore().Delete(name); err != nil {
+		writeBaselineError(w, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"name": name, "deleted": true})
+}
+
+func writeBaselineError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, imagediff.ErrNoBaseline), errors.Is(err, imagediff.ErrNoActual):
+		httpx.ErrorCode(w, 404, "baseline_not_found", err.Error(), false, nil)
+	case errors.Is(err, imagediff.ErrInvalidName):
+		httpx.ErrorCode(w, 400, "bad_request", err.Error(), false, nil)
+	case errors.Is(err, imagediff.ErrTooLarge):
+		httpx.ErrorCode(w, 413, "image_too_large", err.Error(), false, nil)
+	default:
+		httpx.Error(w, 500, err)
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestHandleScreenshotCompare_Invalid(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	for _, body := range []string{
+		`{}`,
+		`{"name": "../etc"}`,
+		`{"name": "home", "tolerance": 2}`,
+		`{"name": "home", "threshold": -1}`,
+		`{"name": "home", "ignore": [{"x": 0, "y": 0, "width": 0, "height": 10}]}`,
+		`{"name": "home", "selector": "#hero", "fullPage": true}`,
+	} {
+		req := httptest.NewRequest("POST", "/screenshot/compare", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+		h.HandleScreenshotCompare(w, req)
+		if w.Code != 400 {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestHandleTabScreenshotCompare_TabIDMismatch(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/tabs/tab1/screenshot/compare", bytes.NewReader([]byte(`{"tabId":"tab2","name":"home"}`)))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabScreenshotCompare(w, req)
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestBaselineHandlers(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal(err)
+	}
+
+	put := httptest.NewRequest("PUT", "/screenshot/baselines/home", bytes.NewReader(img.Bytes()))
+	put.SetPathValue("name", "home")
+	w := httptest.NewRecorder()
+	h.HandlePutBaseline(w, put)
+	if w.Code != 200 {
+		t.Fatalf("put: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	bad := httptest.NewRequest("PUT", "/screenshot/baselines/home", bytes.NewReader([]byte("not a png")))
+	bad.SetPathValue("name", "home")
+	w = httptest.NewRecorder()
+	h.HandlePutBaseline(w, bad)
+	if w.Code != 400 {
+		t.Errorf("put non-PNG: expected 400, got %d", w.Code)
+	}
+
+	huge := bytes.Clone(img.Bytes())
+	binary.BigEndian.PutUint32(huge[16:20], 1<<16)
+	binary.BigEndian.PutUint32(huge[20:24], 1<<16)
+	binary.BigEndian.PutUint32(huge[29:33], crc32.ChecksumIEEE(huge[12:29]))
+	tooBig := httptest.NewRequest("PUT", "/screenshot/baselines/big", bytes.NewReader(huge))
+	tooBig.SetPathValue("name", "big")
+	w = httptest.NewRecorder()
+	h.HandlePutBaseline(w, tooBig)
+	if w.Code != 413 {
+		t.Errorf("put oversized PNG: expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleListBaselines(w, httptest.NewRequest("GET", "/screenshot/baselines", nil))
+	var list struct {
+		Baselines []struct {
+			Name   string `json:"name"`
+			Width  int    `json:"width"`
+			Height int    `json:"height"`
+		} `json:"baselines"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Baselines) != 1 || list.Baselines[0].Name != "home" || list.Baselines[0].Width != 4 || list.Baselines[0].Height != 3 {
+		t.Errorf("unexpected list %s", w.Body.String())
+	}
+
+	get := httptest.NewRequest("GET", "/screenshot/baselines/home", nil)
+	get.SetPathValue("name", "home")
+	w = httptest.NewRecorder()
+	h.HandleGetBaseline(w, get)
+	if w.Code != 200 || w.Header().Get("Content-Type") != "image/png" || !bytes.Equal(w.Body.Bytes(), img.Bytes()) {
+		t.Errorf("get: unexpected response %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	accept := httptest.NewRequest("POST", "/screenshot/baselines/home/accept", nil)
+	accept.SetPathValue("name", "home")
+	w = httptest.NewRecorder()
+	h.HandleAcceptBaseline(w, accept)
+	if w.Code != 404 {
+		t.Errorf("accept without result: expected 404, got %d", w.Code)
+	}
+
+	del := httptest.NewRequest("DELETE", "/screenshot/baselines/home", nil)
+	del.SetPathValue("name", "home")
+	w = httptest.NewRecorder()
+	h.HandleDeleteBaseline(w, del)
+	if w.Code != 200 {
+		t.Errorf("delete: expected 200, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	h.HandleGetBaseline(w, get)
+	if w.Code != 404 {
+		t.Errorf("get after delete: expected 404, got %d", w.Code)
+	}
+
+	invalid := httptest.NewRequest("DELETE", "/screenshot/baselines/..", nil)
+	invalid.SetPathValue("name", "..")
+	w = httptest.NewRecorder()
+	h.HandleDeleteBaseline(w, invalid)
+	if w.Code != 400 {
+		t.Errorf("delete invalid name: expected 400, got %d", w.Code)
+	}
+}
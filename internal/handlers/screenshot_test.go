@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
+	"github.com/chromedp/cdproto/page"
+	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/config"
 )
 
@@ -49,3 +53,60 @@ func TestHandleTabScreenshot_NoTab(t *testing.T) {
 		t.Errorf("expected 404, got %d", w.Code)
 	}
 }
+
+func TestHandleScreenshot_InvalidOptions(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	for _, query := range []string{
+		"format=gif",
+		"scale=0",
+		"scale=10",
+		"clip=0,0,100",
+		"clip=0,0,-5,10",
+		"fullPage=true&selector=%23hero",
+		"clip=0,0,10,10&fullPage=true",
+	} {
+		req := httptest.NewRequest("GET", "/screenshot?"+query, nil)
+		w := httptest.NewRecorder()
+		h.HandleScreenshot(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestParseScreenshotOptions(t *testing.T) {
+	opts, err := parseScreenshotOptions(url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Format != page.CaptureScreenshotFormatJpeg || opts.Quality != 80 {
+		t.Errorf("unexpected defaults %+v", opts)
+	}
+
+	opts, err = parseScreenshotOptions(url.Values{
+		"format": {"webp"},
+		"clip":   {"10, 20.5, 300, 200"},
+		"scale":  {"2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Format != page.CaptureScreenshotFormatWebp || opts.Quality != 0 || opts.Scale != 2 {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if opts.Clip == nil || opts.Clip.Y != 20.5 || opts.Clip.Width != 300 {
+		t.Errorf("unexpected clip %+v", opts.Clip)
+	}
+
+	if _, err := parseScreenshotOptions(url.Values{"clip": {"0,0,1920,20000"}, "scale": {"4"}}); !errors.Is(err, bridge.ErrScreenshotTooLarge) {
+		t.Errorf("an oversized clip should be refused up front, got %v", err)
+	}
+
+	opts, err = parseScreenshotOptions(url.Values{"format": {"png"}, "fullPage": {"true"}, "quality": {"50"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.FullPage || opts.Format != page.CaptureScreenshotFormatPng {
+		t.Errorf("unexpected options %+v", opts)
+	}
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+type stateExportRequest struct {
+	TabIDs []string `json:"tabIds"`
+}
+
+type stateSkipped struct {
+	URL    string `json:"url,omitempty"`
+	Origin string `json:"origin,omitempty"`
+	Cookie string `json:"cookie,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// HandleStateExport snapshots the browser session as a signed bundle.
+//
+// @Endpoint POST /state/export
+// @Description Exports cookies, per-origin local/session storage and open tabs (URL and scroll position) as a signed JSON bundle
+//
+// @Param tabIds []string body Only export these tabs (optional, default: all tabs)
+//
+// @Response 200 application/json Signed state bundle, accepted by POST /state/import
+// @Response 500 application/json Chrome or signing key unavailable
+func (h *Handlers) HandleStateExport(w http.ResponseWriter, r *http.Request) {
+	var req stateExportRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	key, err := bridge.StateBundleKey(h.Config)
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("state bundle key: %w", err))
+		return
+	}
+	targets, err := h.Bridge.ListTargets()
+	if err != nil {
+		httpx.Error(w, 503, err)
+		return
+	}
+
+	wanted := make(map[string]bool, len(req.TabIDs))
+	for _, id := range req.TabIDs {
+		wanted[id] = true
+	}
+
+	bundle := bridge.StateBundle{
+		Version:   bridge.StateBundleVersion,
+		CreatedAt: time.Now().UTC(),
+		Cookies:   []bridge.StateCookie{},
+		Origins:   map[string]bridge.StateOriginStorage{},
+		Tabs:      []bridge.StateTab{},
+	}
+	for _, t := range targets {
+		tabID := string(t.TargetID)
+		if t.Type != "page" || (len(wanted) > 0 && !wanted[tabID]) {
+			continue
+		}
+		origin, err := bridge.StorageOrigin(t.URL)
+		if err != nil {
+			continue
+		}
+		if result := h.IDPIGuard.CheckDomain(t.URL); result.Blocked {
+			slog.Warn("state export: tab skipped by IDPI", "tabId", tabID, "reason", result.Reason)
+			continue
+		}
+		ctx, _, err := h.Bridge.TabContext(tabID)
+		if err != nil {
+			continue
+		}
+
+		tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+		tab := bridge.StateTab{URL: t.URL, Title: t.Title}
+		if x, y, err := bridge.TabScroll(tCtx); err == nil {
+			tab.ScrollX, tab.ScrollY = x, y
+		}
+		if _, seen := bundle.Origins[origin]; !seen {
+			var st bridge.StateOriginStorage
+			if items, err := bridge.WebStorageItems(tCtx, origin, true); err == nil && len(items) > 0 {
+				st.Local = items
+			}
+			if items, err := bridge.WebStorageItems(tCtx, origin, false); err == nil && len(items) > 0 {
+				st.Session = items
+			}
+			bundle.Origins[origin] = st
+		}
+		tCancel()
+		bundle.Tabs = append(bundle.Tabs, tab)
+	}
+
+	cCtx, cCancel := context.WithTimeout(h.Bridge.BrowserContext(), 10*time.Second)
+	defer cCancel()
+	cookies, err := bridge.BrowserCookies(cCtx)
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("get cookies: %w", err))
+		return
+	}
+	for _, c := range cookies {
+		if h.IDPIGuard.CheckDomain(cookieURL(c)).Blocked {
+			continue
+		}
+		bundle.Cookies = append(bundle.Cookies, c)
+	}
+
+	if err := bridge.SignStateBundle(&bundle, key); err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, bundle)
+}
+
+// HandleStateImport applies a bundle produced by /state/export.
+//
+// @Endpoint POST /state/import
+// @Description Verifies a signed state bundle, sets its cookies, reopens its tabs and restores web storage and scroll positions
+//
+// @Param body object body State bundle as returned by POST /state/export
+//
+// @Response 200 application/json Opened tabs, applied counts and skipped entries
+// @Response 400 application/json Invalid bundle
+// @Response 403 application/json Signature mismatch
+func (h *Handlers) HandleStateImport(w http.ResponseWriter, r *http.Request) {
+	var bundle bridge.StateBundle
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxStateBundleSize)).Decode(&bundle); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if bundle.Version != bridge.StateBundleVersion {
+		httpx.Error(w, 400, fmt.Errorf("unsupported state bundle version %d (want %d)", bundle.Version, bridge.StateBundleVersion))
+		return
+	}
+	key, err := bridge.StateBundleKey(h.Config)
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("state bundle key: %w", err))
+		return
+	}
+	if err := bridge.VerifyStateBundle(&bundle, key); err != nil {
+		httpx.ErrorCode(w, http.StatusForbidden, "state_bundle_signature_invalid",
+			"state bundle signature does not match; bundles are signed with the server token of the exporting instance, or without a token with a key shared only by instances of the same server", false, nil)
+		return
+	}
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+
+	skipped := []stateSkipped{}
+	cookies := make([]bridge.StateCookie, 0, len(bundle.Cookies))
+	for _, c := range bundle.Cookies {
+		if result := h.IDPIGuard.CheckDomain(cookieURL(c)); result.Blocked {
+			skipped = append(skipped, stateSkipped{Cookie: c.Name + "@" + c.Domain, Reason: result.Reason})
+			continue
+		}
+		cookies = append(cookies, c)
+	}
+	cCtx, cCancel := context.WithTimeout(h.Bridge.BrowserContext(), 10*time.Second)
+	err = bridge.SetBrowserCookies(cCtx, cookies)
+	cCancel()
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("set cookies: %w", err))
+		return
+	}
+
+	opened := []map[string]any{}
+	restored := map[string]bool{}
+	for _, tab := range bundle.Tabs {
+		tabID, err := h.restoreStateTab(r, tab, bundle.Origins, restored)
+		if err != nil {
+			skipped = append(skipped, stateSkipped{URL: tab.URL, Reason: err.Error()})
+			continue
+		}
+		opened = append(opened, map[string]any{"tabId": tabID, "url": tab.URL})
+	}
+	for origin := range bundle.Origins {
+		if !restored[origin] {
+			skipped = append(skipped, stateSkipped{Origin: origin, Reason: "no restored tab for origin"})
+		}
+	}
+
+	httpx.JSON(w, 200, map[string]any{
+		"tabs":    opened,
+		"cookies": len(cookies),
+		"origins": len(restored),
+		"skipped": skipped,
+	})
+}
+
+// restoreStateTab opens a tab on the bundled URL, writes the origin's web
+// storage, reloads so the page sees it, and restores the scroll offset.
+// Local storage is written once per origin; session storage is per tab.
+func (h *Handlers) restoreStateTab(r *http.Request, tab bridge.StateTab, origins map[string]bridge.StateOriginStorage, restored map[string]bool) (string, error) {
+	if err := validateNavigateURL(tab.URL); err != nil {
+		return "", err
+	}
+	if result := h.IDPIGuard.CheckDomain(tab.URL); result.Blocked {
+		return "", fmt.Errorf("blocked by IDPI: %s", result.Reason)
+	}
+	target, err := validateNavigateTarget(tab.URL, h.IDPIGuard.DomainAllowed(tab.URL))
+	if err != nil {
+		return "", err
+	}
+
+	tabID, tabCtx, _, err := h.Bridge.CreateTab("")
+	if err != nil {
+		return "", fmt.Errorf("new tab: %w", err)
+	}
+	fail := func(err error) (string, error) {
+		_ = h.Bridge.CloseTab(tabID)
+		return "", err
+	}
+
+	navTimeout := h.Config.NavigateTimeout
+	if navTimeout <= 0 {
+		navTimeout = 30 * time.Second
+	}
+	tCtx, tCancel := context.WithTimeout(tabCtx, navTimeout)
+	defer tCancel()
+	go httpx.CancelOnClientDone(r.Context(), tCancel)
+	navGuard, err := installNavigateRuntimeGuard(tCtx, tCancel, target, parseCIDRs(h.Config.TrustedProxyCIDRs))
+	if err != nil {
+		return fail(fmt.Errorf("navigation guard: %w", err))
+	}
+	if err := h.navigateWithRedirectLimit(tabCtx, tCtx, tabID, tab.URL); err != nil {
+		if navGuard != nil {
+			if blockedErr := navGuard.blocked(); blockedErr != nil {
+				return fail(blockedErr)
+			}
+		}
+		return fail(fmt.Errorf("navigate: %w", err))
+	}
+
+	origin, err := bridge.StorageOrigin(tab.URL)
+	if err == nil {
+		if st, ok := origins[origin]; ok {
+			wrote := false
+			if len(st.Local) > 0 && !restored[origin] {
+				if err := bridge.SetWebStorageItems(tCtx, origin, true, st.Local, false); err != nil {
+					return fail(fmt.Errorf("restore localStorage: %w", err))
+				}
+				wrote = true
+			}
+			if len(st.Session) > 0 {
+				if err := bridge.SetWebStorageItems(tCtx, origin, false, st.Session, false); err != nil {
+					return fail(fmt.Errorf("restore sessionStorage: %w", err))
+				}
+				wrote = true
+			}
+			restored[origin] = true
+			if wrote {
+				if err := chromedp.Run(tCtx, chromedp.Reload()); err != nil {
+					return fail(fmt.Errorf("reload: %w", err))
+				}
+			}
+		}
+	}
+
+	if tab.ScrollX != 0 || tab.ScrollY != 0 {
+		_ = bridge.ScrollTab(tCtx, tab.ScrollX, tab.ScrollY)
+	}
+	h.recordResolvedTab(r, tabID)
+	return tabID, nil
+}
+
+// cookieURL builds a URL for a cookie's domain so it can be checked against
+// the IDPI domain policy.
+func cookieURL(c bridge.StateCookie) string {
+	return "https://" + strings.TrimPrefix(c.Domain, ".") + "/"
+}
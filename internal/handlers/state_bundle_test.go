@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func signedStateBundle(t *testing.T, key string) string {
+	t.Helper()
+	b := bridge.StateBundle{
+		Version:   bridge.StateBundleVersion,
+		CreatedAt: time.Now().UTC(),
+		Cookies:   []bridge.StateCookie{{Name: "sid", Value: "abc", Domain: "example.com", Path: "/"}},
+		Origins:   map[string]bridge.StateOriginStorage{},
+		Tabs:      []bridge.StateTab{},
+	}
+	if err := bridge.SignStateBundle(&b, []byte(key)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestHandleStateImport_Validation(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{Token: "tok"}, nil, nil, nil)
+	tests := []struct {
+		name string
+		body string
+		code int
+		want string
+	}{
+		{"bad json", `{`, 400, "decode"},
+		{"bad version", `{"version":99}`, 400, "unsupported state bundle version"},
+		{"unsigned", `{"version":1,"cookies":[]}`, 403, "state_bundle_signature_invalid"},
+		{"wrong key", signedStateBundle(t, "other"), 403, "state_bundle_signature_invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/state/import", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.HandleStateImport(w, req)
+			if w.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("expected %q, got %s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleStateImport_TamperedBundle(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{Token: "tok"}, nil, nil, nil)
+	body := strings.Replace(signedStateBundle(t, "tok"), `"value":"abc"`, `"value":"xyz"`, 1)
+	req := httptest.NewRequest("POST", "/state/import", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.HandleStateImport(w, req)
+	if w.Code != 403 {
+		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleStateExport_BadBody(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{Token: "tok"}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/state/export", strings.NewReader(`{"tabIds":`))
+	w := httptest.NewRecorder()
+	h.HandleStateExport(w, req)
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
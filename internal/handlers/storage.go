@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+type storageRequest struct {
+	TabID    string                  `json:"tabId"`
+	Origin   string                  `json:"origin"`
+	Type     string                  `json:"type"`
+	Items    map[string]string       `json:"items"`
+	Replace  bool                    `json:"replace"`
+	Database string                  `json:"database"`
+	Store    string                  `json:"store"`
+	Entries  []bridge.IndexedDBEntry `json:"entries"`
+}
+
+// HandleGetStorage reads web storage for an origin loaded in a tab.
+//
+// @Endpoint GET /storage
+// @Description Returns localStorage/sessionStorage items or IndexedDB contents via CDP
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+// @Param origin string query Origin to read (optional, defaults to the tab's current origin)
+// @Param type string query "local", "session" or "indexeddb" (optional, default: local and session)
+// @Param key string query Return a single key (optional, local/session only)
+// @Param database string query IndexedDB database (optional; lists databases when empty)
+// @Param store string query IndexedDB object store to read (optional)
+// @Param limit int query Maximum IndexedDB records (optional, default 100)
+// @Param skip int query IndexedDB records to skip (optional)
+//
+// @Response 200 application/json Storage contents
+// @Response 400 application/json Invalid origin or type
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleGetStorage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	kind := strings.ToLower(q.Get("type"))
+	switch kind {
+	case "", bridge.StorageLocal, bridge.StorageSession, bridge.StorageIndexedDB:
+	default:
+		httpx.Error(w, 400, fmt.Errorf("invalid type %q (want local, session or indexeddb)", kind))
+		return
+	}
+
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, q.Get("tabId"))
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	origin, _, err := resolveStorageOrigin(tCtx, q.Get("origin"))
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	result := map[string]any{"origin": origin, "tabId": resolvedTabID}
+
+	if kind == bridge.StorageIndexedDB {
+		database, store := q.Get("database"), q.Get("store")
+		if database == "" || store == "" {
+			dbs, err := bridge.IndexedDBDatabases(tCtx, origin)
+			if err != nil {
+				httpx.Error(w, 500, fmt.Errorf("indexeddb: %w", err))
+				return
+			}
+			result["databases"] = dbs
+			httpx.JSON(w, 200, result)
+			return
+		}
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		skip, _ := strconv.Atoi(q.Get("skip"))
+		entries, hasMore, err := bridge.IndexedDBEntries(tCtx, origin, database, store, skip, limit)
+		if err != nil {
+			httpx.Error(w, 500, fmt.Errorf("indexeddb: %w", err))
+			return
+		}
+		result["database"] = database
+		result["store"] = store
+		result["entries"] = entries
+		result["count"] = len(entries)
+		result["hasMore"] = hasMore
+		httpx.JSON(w, 200, result)
+		return
+	}
+
+	key := q.Get("key")
+	for _, area := range []string{bridge.StorageLocal, bridge.StorageSession} {
+		if kind != "" && kind != area {
+			continue
+		}
+		items, err := bridge.WebStorageItems(tCtx, origin, area == bridge.StorageLocal)
+		if err != nil {
+			httpx.Error(w, 500, fmt.Errorf("%s storage: %w", area, err))
+			return
+		}
+		if key != "" {
+			filtered := map[string]string{}
+			if v, ok := items[key]; ok {
+				filtered[key] = v
+			}
+			items = filtered
+		}
+		result[area] = items
+	}
+	httpx.JSON(w, 200, result)
+}
+
+// HandleTabGetStorage reads web storage for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/storage
+func (h *Handlers) HandleTabGetStorage(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.HandleGetStorage(w, withTabIDQuery(r, tabID))
+}
+
+// HandleSetStorage writes web storage for an origin loaded in a tab.
+//
+// @Endpoint POST /storage
+// @Description Writes localStorage/sessionStorage items or IndexedDB records
+//
+// @Param tabId string body Tab ID (optional, uses current tab if empty)
+// @Param origin string body Origin to write (optional, defaults to the tab's current origin)
+// @Param type string body "local" (default), "session" or "indexeddb"
+// @Param items object body Key/value pairs for local/session storage
+// @Param replace bool body Clear the storage area before writing (optional)
+// @Param database string body IndexedDB database (indexeddb only)
+// @Param store string body IndexedDB object store (indexeddb only)
+// @Param entries array body IndexedDB records as {key, value} (indexeddb only)
+//
+// @Response 200 application/json Write result
+// @Response 400 application/json Invalid request
+// @Response 403 application/json Origin blocked by IDPI
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleSetStorage(w http.ResponseWriter, r *http.Request) {
+	var req storageRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	h.handleSetStorage(w, r, req)
+}
+
+// HandleTabSetStorage writes web storage for a tab identified by path ID.
+//
+// @Endpoint POST /tabs/{id}/storage
+func (h *Handlers) HandleTabSetStorage(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	var req storageRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.TabID != "" && req.TabID != tabID {
+		httpx.Error(w, 400, fmt.Errorf("tabId in body does not match path id"))
+		return
+	}
+	req.TabID = tabID
+	h.handleSetStorage(w, r, req)
+}
+
+func (h *Handlers) handleSetStorage(w http.ResponseWriter, r *http.Request, req storageRequest) {
+	kind := strings.ToLower(req.Type)
+	if kind == "" {
+		kind = bridge.StorageLocal
+	}
+	switch kind {
+	case bridge.StorageLocal, bridge.StorageSession:
+		if len(req.Items) == 0 && !req.Replace {
+			httpx.Error(w, 400, fmt.Errorf("items required"))
+			return
+		}
+	case bridge.StorageIndexedDB:
+		if req.Database == "" || req.Store == "" {
+			httpx.Error(w, 400, fmt.Errorf("database and store required for indexeddb"))
+			return
+		}
+		if len(req.Entries) == 0 {
+			httpx.Error(w, 400, fmt.Errorf("entries required"))
+			return
+		}
+	default:
+		httpx.Error(w, 400, fmt.Errorf("invalid type %q (want local, session or indexeddb)", req.Type))
+		return
+	}
+
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	origin, currentOrigin, err := resolveStorageOrigin(tCtx, req.Origin)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	if !h.storageOriginAllowed(w, origin) {
+		return
+	}
+
+	if kind == bridge.StorageIndexedDB {
+		// The CDP IndexedDB domain cannot write, so records go through the
+		// page, which only has access to its own origin.
+		if origin != currentOrigin {
+			httpx.Error(w, 400, fmt.Errorf("indexeddb writes require the tab to be on %s (currently %s)", origin, currentOrigin))
+			return
+		}
+		n, err := bridge.PutIndexedDBEntries(tCtx, req.Database, req.Store, req.Entries)
+		if err != nil {
+			httpx.Error(w, 500, fmt.Errorf("indexeddb: %w", err))
+			return
+		}
+		httpx.JSON(w, 200, map[string]any{"origin": origin, "type": kind, "database": req.Database, "store": req.Store, "written": n, "tabId": resolvedTabID})
+		return
+	}
+
+	if err := bridge.SetWebStorageItems(tCtx, origin, kind == bridge.StorageLocal, req.Items, req.Replace); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("%s storage: %w", kind, err))
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"origin": origin, "type": kind, "written": len(req.Items), "replaced": req.Replace, "tabId": resolvedTabID})
+}
+
+// HandleDeleteStorage removes web storage entries for an origin.
+//
+// @Endpoint DELETE /storage
+// @Description Removes keys, clears a storage area, or deletes IndexedDB data
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+// @Param origin string query Origin (optional, defaults to the tab's current origin)
+// @Param type string query "local", "session" or "indexeddb" (required)
+// @Param key string query Key to remove (repeatable; clears the area when omitted)
+// @Param database string query IndexedDB database (indexeddb only; deleted when store is omitted)
+// @Param store string query IndexedDB object store (cleared when key is omitted)
+//
+// @Response 200 application/json Delete result
+// @Response 400 application/json Invalid request
+// @Response 403 application/json Origin blocked by IDPI
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleDeleteStorage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	kind := strings.ToLower(q.Get("type"))
+	switch kind {
+	case bridge.StorageLocal, bridge.StorageSession:
+	case bridge.StorageIndexedDB:
+		if q.Get("database") == "" {
+			httpx.Error(w, 400, fmt.Errorf("database required for indexeddb"))
+			return
+		}
+	default:
+		httpx.Error(w, 400, fmt.Errorf("type required (local, session or indexeddb)"))
+		return
+	}
+
+	if err := h.ensureChrome(); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, q.Get("tabId"))
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	origin, _, err := resolveStorageOrigin(tCtx, q.Get("origin"))
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	if !h.storageOriginAllowed(w, origin) {
+		return
+	}
+
+	result := map[string]any{"origin": origin, "type": kind, "tabId": resolvedTabID}
+	if kind == bridge.StorageIndexedDB {
+		var key any
+		if v := q.Get("key"); v != "" {
+			key = v
+		}
+		if err := bridge.DeleteIndexedDB(tCtx, origin, q.Get("database"), q.Get("store"), key); err != nil {
+			httpx.Error(w, 500, fmt.Errorf("indexeddb: %w", err))
+			return
+		}
+		result["database"] = q.Get("database")
+		if s := q.Get("store"); s != "" {
+			result["store"] = s
+		}
+		result["deleted"] = true
+		httpx.JSON(w, 200, result)
+		return
+	}
+
+	keys := q["key"]
+	if err := bridge.RemoveWebStorageItems(tCtx, origin, kind == bridge.StorageLocal, keys); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("%s storage: %w", kind, err))
+		return
+	}
+	if len(keys) == 0 {
+		result["cleared"] = true
+	} else {
+		result["removed"] = len(keys)
+	}
+	httpx.JSON(w, 200, result)
+}
+
+// HandleTabDeleteStorage removes web storage entries for a tab identified by path ID.
+//
+// @Endpoint DELETE /tabs/{id}/storage
+func (h *Handlers) HandleTabDeleteStorage(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.HandleDeleteStorage(w, withTabIDQuery(r, tabID))
+}
+
+// resolveStorageOrigin returns the requested origin (or the tab's current
+// origin when empty) along with the tab's current origin.
+func resolveStorageOrigin(ctx context.Context, requested string) (string, string, error) {
+	var currentURL string
+	if err := chromedp.Run(ctx, chromedp.Location(&currentURL)); err != nil {
+		return "", "", fmt.Errorf("resolve current tab url: %w", err)
+	}
+	current, curErr := bridge.StorageOrigin(currentURL)
+	if requested == "" {
+		if curErr != nil {
+			return "", "", fmt.Errorf("tab is not on a web origin; pass origin explicitly")
+		}
+		return current, current, nil
+	}
+	origin, err := bridge.StorageOrigin(requested)
+	if err != nil {
+		return "", "", err
+	}
+	return origin, current, nil
+}
+
+// storageOriginAllowed applies the IDPI domain policy to the origin being
+// modified, which may differ from the tab's current URL.
+func (h *Handlers) storageOriginAllowed(w http.ResponseWriter, origin string) bool {
+	if h.IDPIGuard == nil {
+		return true
+	}
+	result := h.IDPIGuard.CheckDomain(origin)
+	if result.Blocked {
+		httpx.ErrorCode(w, http.StatusForbidden, "idpi_domain_blocked",
+			fmt.Sprintf("storage write blocked by IDPI: %s", result.Reason), false, map[string]any{
+				"origin": origin,
+			})
+		return false
+	}
+	if result.Threat {
+		w.Header().Set("X-IDPI-Warning", result.Reason)
+	}
+	return true
+}
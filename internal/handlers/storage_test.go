@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestHandleGetStorage_InvalidType(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("GET", "/storage?type=cookies", nil)
+	w := httptest.NewRecorder()
+	h.HandleGetStorage(w, req)
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleGetStorage_TabNotFound(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("GET", "/storage?tabId=missing", nil)
+	w := httptest.NewRecorder()
+	h.HandleGetStorage(w, req)
+	if w.Code != 404 {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleSetStorage_Validation(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"no items", `{"type":"local"}`, "items required"},
+		{"bad type", `{"type":"cache","items":{"a":"b"}}`, "invalid type"},
+		{"indexeddb without store", `{"type":"indexeddb","database":"db","entries":[{"key":"a","value":1}]}`, "database and store required"},
+		{"indexeddb without entries", `{"type":"indexeddb","database":"db","store":"s"}`, "entries required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/storage", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.HandleSetStorage(w, req)
+			if w.Code != 400 {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("expected %q, got %s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleTabSetStorage_TabIDMismatch(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/tabs/tab1/storage", strings.NewReader(`{"tabId":"other","items":{"a":"b"}}`))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabSetStorage(w, req)
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleDeleteStorage_RequiresType(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	for _, q := range []string{"", "?type=indexeddb"} {
+		req := httptest.NewRequest("DELETE", "/storage"+q, nil)
+		w := httptest.NewRecorder()
+		h.HandleDeleteStorage(w, req)
+		if w.Code != 400 {
+			t.Errorf("%q: expected 400, got %d: %s", q, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestStorageOriginAllowed_IDPIBlocked(t *testing.T) {
+	cfg := &config.RuntimeConfig{IDPI: config.IDPIConfig{
+		Enabled:        true,
+		AllowedDomains: []string{"example.com"},
+		StrictMode:     true,
+	}}
+	h := New(&mockBridge{}, cfg, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	if h.storageOriginAllowed(w, "https://evil.test") {
+		t.Fatal("expected evil.test to be blocked")
+	}
+	if w.Code != 403 || !strings.Contains(w.Body.String(), "idpi_domain_blocked") {
+		t.Errorf("expected 403 idpi_domain_blocked, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	if !h.storageOriginAllowed(w, "https://example.com") {
+		t.Errorf("expected example.com to be allowed: %s", w.Body.String())
+	}
+}
@@ -14,7 +14,9 @@ import (
 	"github.com/chromedp/cdproto/dom"
 	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/selector"
 )
 
 type uploadRequest struct {
@@ -144,6 +146,27 @@ func (h *Handlers) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	defer tCancel()
 	go httpx.CancelOnClientDone(r.Context(), tCancel)
 
+	// Inputs inside frames or shadow trees are resolved by backend node ID,
+	// in the frame's own target for cross-origin iframes.
+	if sel := selector.Parse(req.Selector); sel.Scoped() {
+		frameCtx, nid, err := bridge.ResolveScopedSelector(tCtx, sel)
+		if err != nil {
+			httpx.Error(w, 400, fmt.Errorf("selector %q: %w", req.Selector, err))
+			return
+		}
+		if err := chromedp.Run(frameCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return dom.SetFileInputFiles(allPaths).WithBackendNodeID(cdp.BackendNodeID(nid)).Do(ctx)
+		})); err != nil {
+			httpx.Error(w, 500, fmt.Errorf("upload: %w", err))
+			return
+		}
+		httpx.JSON(w, 200, map[string]any{
+			"status": "ok",
+			"files":  len(allPaths),
+		})
+		return
+	}
+
 	// Find the file input node and set files via CDP.
 	if err := chromedp.Run(tCtx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/selector"
 )
 
 const (
@@ -189,10 +191,20 @@ func (h *Handlers) handleWaitCore(w http.ResponseWriter, r *http.Request, req wa
 
 	var js string
 	var matchLabel string
+	var check func() (bool, error)
 
 	switch mode {
 	case "selector":
-		js, matchLabel = buildSelectorJS(req.Selector, req.State)
+		if sel := selector.Parse(req.Selector); sel.Scoped() {
+			if err := sel.Validate(); err != nil {
+				httpx.Error(w, 400, fmt.Errorf("selector: %w", err))
+				return
+			}
+			check = scopedSelectorCheck(tCtx, sel, req.State == "hidden")
+			matchLabel = req.Selector
+		} else {
+			js, matchLabel = buildSelectorJS(req.Selector, req.State)
+		}
 	case "text":
 		js = fmt.Sprintf(`document.body && document.body.innerText.includes(%s)`, jsonStr(req.Text))
 		matchLabel = req.Text
@@ -215,7 +227,12 @@ func (h *Handlers) handleWaitCore(w http.ResponseWriter, r *http.Request, req wa
 	// Poll loop
 	for {
 		var result bool
-		evalErr := chromedp.Run(tCtx, chromedp.Evaluate(js, &result))
+		var evalErr error
+		if check != nil {
+			result, evalErr = check()
+		} else {
+			evalErr = chromedp.Run(tCtx, chromedp.Evaluate(js, &result))
+		}
 		if evalErr == nil && result {
 			httpx.JSON(w, 200, waitResponse{
 				Waited:  true,
@@ -240,6 +257,25 @@ func (h *Handlers) handleWaitCore(w http.ResponseWriter, r *http.Request, req wa
 	}
 }
 
+// scopedSelectorCheck polls a frame: or >>> selector. A frame or element
+// that is not present yet counts as hidden.
+func scopedSelectorCheck(ctx context.Context, sel selector.Selector, hidden bool) func() (bool, error) {
+	return func() (bool, error) {
+		// Each poll gets its own context so a cross-origin frame session is
+		// detached before the next one.
+		pollCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		_, _, err := bridge.ResolveScopedSelector(pollCtx, sel)
+		if errors.Is(err, bridge.ErrScopedNoMatch) {
+			return hidden, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return !hidden, nil
+	}
+}
+
 // buildSelectorJS builds a JS expression for selector wait.
 // Supports css:, xpath:, text: prefixes and bare CSS selectors.
 func buildSelectorJS(sel, state string) (string, string) {
@@ -166,6 +166,16 @@ func TestHandleWait_SelectorNoTab(t *testing.T) {
 	}
 }
 
+func TestHandleWait_ScopedSelectorInvalid(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/wait", bytes.NewReader([]byte(`{"selector":"frame:login e5"}`)))
+	w := httptest.NewRecorder()
+	h.HandleWait(w, req)
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
 func TestHandleWait_TextNoTab(t *testing.T) {
 	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
 	req := httptest.NewRequest("POST", "/wait", bytes.NewReader([]byte(`{"text":"Order confirmed"}`)))
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/selector"
+	"github.com/pinchtab/pinchtab/internal/workflow"
+)
+
+// workflowProbeTimeout bounds the presence, text and URL checks behind
+// if/unless, loop conditions and asserts.
+const workflowProbeTimeout = 5 * time.Second
+
+// HandleWorkflowRun runs a declarative workflow against one tab.
+//
+// @Endpoint POST /workflows/run
+// @Description Runs a YAML or JSON workflow (navigate, action, wait, extract, assert, set, loop steps with variables and if/unless guards) and returns a per-step trace
+//
+// @Param tabId string query Tab to run in, overrides the workflow's tabId (optional)
+// @Param body object body Workflow document (application/json or application/yaml)
+//
+// @Response 200 application/json Run result with status, variables and trace
+// @Response 400 application/json Invalid workflow
+// @Response 403 application/json Macros (or evaluate, for eval steps) disabled
+func (h *Handlers) HandleWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	if !h.Config.AllowMacro {
+		httpx.ErrorCode(w, 403, "macro_disabled", httpx.DisabledEndpointMessage("workflows", "security.allowMacro"), false, map[string]any{
+			"setting": "security.allowMacro",
+		})
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		httpx.ErrorCode(w, 400, "bad_request", fmt.Sprintf("read body: %v", err), false, nil)
+		return
+	}
+	wf, err := workflow.Parse(body)
+	if err != nil {
+		httpx.ErrorCode(w, 400, "bad_request", err.Error(), false, nil)
+		return
+	}
+	if tabID := r.URL.Query().Get("tabId"); tabID != "" {
+		wf.TabID = tabID
+	}
+	if wf.UsesEval() && !h.evaluateEnabled() {
+		httpx.ErrorCode(w, 403, "evaluate_disabled", httpx.DisabledEndpointMessage("evaluate", "security.allowEvaluate"), false, map[string]any{
+			"setting": "security.allowEvaluate",
+		})
+		return
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, wf.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if err := h.enforceTabLease(resolvedTabID, resolveOwner(r, "")); err != nil {
+		httpx.ErrorCode(w, 423, "tab_locked", err.Error(), false, nil)
+		return
+	}
+	h.recordResolvedTab(r, resolvedTabID)
+
+	exec := &workflowExecutor{h: h, r: r, tabID: resolvedTabID, tabCtx: ctx}
+	res := workflow.Run(r.Context(), wf, exec)
+	httpx.JSON(w, 200, struct {
+		TabID string `json:"tabId"`
+		*workflow.Result
+	}{resolvedTabID, res})
+}
+
+// workflowExecutor runs workflow steps on one tab. Navigate, action, wait
+// and eval steps go through the regular handlers so they get the same
+// validation, IDPI checks and tab policy as direct API calls. Extracts and
+// conditions read the page directly, after the same IDPI domain check.
+type workflowExecutor struct {
+	h      *Handlers
+	r      *http.Request
+	tabID  string
+	tabCtx context.Context
+}
+
+func (e *workflowExecutor) Navigate(ctx context.Context, n workflow.Navigate) (any, error) {
+	return e.dispatch(ctx, "/navigate", map[string]any{
+		"tabId":        e.tabID,
+		"url":          n.URL,
+		"waitFor":      n.WaitFor,
+		"waitSelector": n.WaitSelector,
+		"timeout":      n.Timeout,
+	}, e.h.HandleNavigate)
+}
+
+func (e *workflowExecutor) Action(ctx context.Context, a workflow.Action) (any, error) {
+	body := make(map[string]any, len(a)+1)
+	for k, v := range a {
+		body[k] = v
+	}
+	body["tabId"] = e.tabID
+	return e.dispatch(ctx, "/action", body, e.h.HandleAction)
+}
+
+func (e *workflowExecutor) Wait(ctx context.Context, wt workflow.Wait) (any, error) {
+	req := waitRequest{
+		TabID:    e.tabID,
+		Selector: wt.Selector,
+		State:    wt.State,
+		Text:     wt.Text,
+		URL:      wt.URL,
+		Load:     wt.Load,
+		Fn:       wt.Fn,
+		Ms:       wt.Ms,
+		Timeout:  wt.Timeout,
+	}
+	out, err := e.dispatch(ctx, "/wait", req, func(w http.ResponseWriter, r *http.Request) {
+		e.h.handleWaitCore(w, r, req)
+	})
+	if err != nil {
+		return out, err
+	}
+	if waited, _ := out["waited"].(bool); !waited {
+		msg, _ := out["error"].(string)
+		if msg == "" {
+			msg = "wait condition not met"
+		}
+		return out, errors.New(msg)
+	}
+	return out, nil
+}
+
+func (e *workflowExecutor) Extract(ctx context.Context, x workflow.Extract) (any, error) {
+	if x.Eval != "" {
+		out, err := e.dispatch(ctx, "/evaluate", map[string]any{
+			"tabId":      e.tabID,
+			"expression": x.Eval,
+		}, e.h.HandleEvaluate)
+		if err != nil {
+			return nil, err
+		}
+		return out["result"], nil
+	}
+
+	sel := selector.Parse(x.Selector)
+	if err := sel.Validate(); err != nil {
+		return nil, err
+	}
+	if sel.Kind == selector.KindSemantic {
+		return nil, fmt.Errorf("extract does not support find: selectors")
+	}
+	tCtx, cancel := e.tabContext(ctx, e.h.Config.ActionTimeout)
+	defer cancel()
+	if err := e.checkDomainPolicy(tCtx); err != nil {
+		return nil, err
+	}
+
+	nodeCtx := tCtx
+	var nodeID int64
+	var err error
+	if sel.Scoped() {
+		nodeCtx, nodeID, err = bridge.ResolveScopedSelector(tCtx, sel)
+	} else {
+		nodeID, err = bridge.ResolveUnifiedSelector(tCtx, sel, e.h.Bridge.GetRefCache(e.tabID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", workflow.ErrNotFound, err)
+	}
+	value, ok, err := bridge.ElementValue(nodeCtx, nodeID, x.Attr)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no attribute %q", workflow.ErrNotFound, x.Selector, x.Attr)
+	}
+	return value, nil
+}
+
+func (e *workflowExecutor) Present(ctx context.Context, raw string) (bool, error) {
+	sel := selector.Parse(raw)
+	if err := sel.Validate(); err != nil {
+		return false, err
+	}
+	tCtx, cancel := e.tabContext(ctx, workflowProbeTimeout)
+	defer cancel()
+	if err := e.checkDomainPolicy(tCtx); err != nil {
+		return false, err
+	}
+
+	switch {
+	case sel.Scoped():
+		_, _, err := bridge.ResolveScopedSelector(tCtx, sel)
+		if errors.Is(err, bridge.ErrScopedNoMatch) {
+			return false, nil
+		}
+		return err == nil, err
+	case sel.Kind == selector.KindRef:
+		cache := e.h.Bridge.GetRefCache(e.tabID)
+		if cache == nil {
+			return false, nil
+		}
+		_, ok := cache.Refs[sel.Value]
+		return ok, nil
+	case sel.Kind == selector.KindSemantic:
+		return false, fmt.Errorf("find: selectors cannot be used as conditions")
+	}
+	js, _ := buildSelectorJS(raw, "visible")
+	return e.evalBool(tCtx, js)
+}
+
+func (e *workflowExecutor) TextPresent(ctx context.Context, text string) (bool, error) {
+	tCtx, cancel := e.tabContext(ctx, workflowProbeTimeout)
+	defer cancel()
+	if err := e.checkDomainPolicy(tCtx); err != nil {
+		return false, err
+	}
+	return e.evalBool(tCtx, fmt.Sprintf(`!!document.body && document.body.innerText.includes(%s)`, jsonStr(text)))
+}
+
+func (e *workflowExecutor) URLMatches(ctx context.Context, pattern string) (bool, error) {
+	tCtx, cancel := e.tabContext(ctx, workflowProbeTimeout)
+	defer cancel()
+	if err := e.checkDomainPolicy(tCtx); err != nil {
+		return false, err
+	}
+	return e.evalBool(tCtx, buildURLMatchJS(pattern))
+}
+
+// checkDomainPolicy applies the IDPI domain policy of the tab's current
+// page, as the read endpoints do, before a step reads the page directly.
+func (e *workflowExecutor) checkDomainPolicy(tCtx context.Context) error {
+	rec := newResponseCapture()
+	if _, ok := e.h.enforceCurrentTabDomainPolicy(rec, e.r, tCtx, e.tabID); ok {
+		return nil
+	}
+	_, err := rec.result()
+	return err
+}
+
+func (e *workflowExecutor) evalBool(ctx context.Context, js string) (bool, error) {
+	var ok bool
+	if err := chromedp.Run(ctx, chromedp.Evaluate(js, &ok)); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// tabContext derives a context from the tab that is also cancelled with ctx.
+func (e *workflowExecutor) tabContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	tCtx, cancel := context.WithTimeout(e.tabCtx, timeout)
+	stop := context.AfterFunc(ctx, cancel)
+	return tCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// dispatch invokes a handler in-process with a JSON body and decodes its
+// JSON response. Error statuses become errors carrying the handler's message.
+func (e *workflowExecutor) dispatch(ctx context.Context, path string, body any, handler http.HandlerFunc) (map[string]any, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, path, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = e.r.Header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = e.r.RemoteAddr
+
+	rec := newResponseCapture()
+	handler(rec, req)
+	return rec.result()
+}
+
+// responseCapture is a minimal http.ResponseWriter for in-process handler
+// calls.
+type responseCapture struct {
+	header http.Header
+	status int
+	wrote  bool
+	body   bytes.Buffer
+}
+
+func newResponseCapture() *responseCapture {
+	return &responseCapture{header: http.Header{}, status: http.StatusOK}
+}
+
+// result decodes the captured JSON response. Error statuses become errors
+// carrying the handler's message.
+func (c *responseCapture) result() (map[string]any, error) {
+	var out map[string]any
+	if err := json.Unmarshal(c.body.Bytes(), &out); err != nil {
+		out = map[string]any{"body": strings.TrimSpace(c.body.String())}
+	}
+	if c.status >= 400 {
+		msg, _ := out["error"].(string)
+		if msg == "" {
+			msg = http.StatusText(c.status)
+		}
+		return out, fmt.Errorf("%s (HTTP %d)", msg, c.status)
+	}
+	return out, nil
+}
+
+func (c *responseCapture) Header() http.Header { return c.header }
+
+func (c *responseCapture) WriteHeader(status int) {
+	if !c.wrote {
+		c.status = status
+		c.wrote = true
+	}
+}
+
+func (c *responseCapture) Write(p []byte) (int, error) {
+	c.wrote = true
+	return c.body.Write(p)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/workflow"
+)
+
+func TestHandleWorkflowRun_Disabled(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/workflows/run", bytes.NewReader([]byte(`steps: [{wait: {ms: 1}}]`)))
+	w := httptest.NewRecorder()
+	h.HandleWorkflowRun(w, req)
+	if w.Code != 403 {
+		t.Errorf("expected 403 when macro disabled, got %d", w.Code)
+	}
+}
+
+func TestHandleWorkflowRun_InvalidWorkflow(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{AllowMacro: true}, nil, nil, nil)
+	for _, body := range []string{`steps: []`, `{"steps": [{"action": {}}]}`, `steps: [`} {
+		req := httptest.NewRequest("POST", "/workflows/run", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+		h.HandleWorkflowRun(w, req)
+		if w.Code != 400 {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestHandleWorkflowRun_EvalRequiresEvaluate(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{AllowMacro: true}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/workflows/run", bytes.NewReader([]byte(`steps: [{extract: {var: t, eval: "document.title"}}]`)))
+	w := httptest.NewRecorder()
+	h.HandleWorkflowRun(w, req)
+	if w.Code != 403 {
+		t.Errorf("expected 403 for eval step with evaluate disabled, got %d", w.Code)
+	}
+}
+
+func TestWorkflowExecutor_DispatchError(t *testing.T) {
+	e := &workflowExecutor{r: httptest.NewRequest("POST", "/workflows/run", nil)}
+	out, err := e.dispatch(t.Context(), "/x", map[string]any{}, func(w http.ResponseWriter, r *http.Request) {
+		httpx.ErrorCode(w, 409, "conflict", "tab busy", false, nil)
+	})
+	if err == nil || err.Error() != "tab busy (HTTP 409)" {
+		t.Fatalf("dispatch error = %v", err)
+	}
+	if out["code"] != "conflict" {
+		t.Errorf("response body not decoded: %v", out)
+	}
+}
+
+func TestHandleWorkflowRun_ReadsRespectDomainPolicy(t *testing.T) {
+	for name, body := range map[string]string{
+		"extract":    `steps: [{extract: {var: title, selector: "h1"}}]`,
+		"if present": `steps: [{if: "#login", set: {seen: true}}]`,
+		"assert url": `steps: [{assert: {url: "*evil*"}}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			b := &policyMockBridge{
+				state: bridge.TabPolicyState{
+					CurrentURL: "https://evil.example.net",
+					Threat:     true,
+					Blocked:    true,
+					Reason:     `domain "evil.example.net" is not in the allowed list`,
+					UpdatedAt:  time.Now(),
+				},
+				hasState: true,
+			}
+			h := New(b, &config.RuntimeConfig{
+				AllowMacro:    true,
+				ActionTimeout: time.Second,
+				IDPI: config.IDPIConfig{
+					Enabled:        true,
+					AllowedDomains: []string{"example.com"},
+					StrictMode:     true,
+				},
+			}, nil, nil, nil)
+
+			req := httptest.NewRequest("POST", "/workflows/run?tabId=tab1", bytes.NewReader([]byte(body)))
+			w := httptest.NewRecorder()
+			h.HandleWorkflowRun(w, req)
+			if w.Code != 200 {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var res workflow.Result
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != "failed" || !strings.Contains(res.Error, "blocked by IDPI") {
+				t.Fatalf("a read on a blocked tab should fail the run, got %+v", res)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package imagediff compares screenshots pixel by pixel and manages the
+// named baselines they are compared against.
+package imagediff
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// DefaultTolerance is the per-pixel color difference (0-1) below which
+// pixels are considered equal. It absorbs anti-aliasing and compression
+// noise.
+const DefaultTolerance = 0.1
+
+// Region is a rectangle in image pixels.
+type Region struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func (r Region) contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// Options controls Compare.
+type Options struct {
+	// Tolerance is the largest per-channel difference, as a fraction of
+	// the channel range, at which two pixels still match.
+	Tolerance float64
+	// Ignore lists regions excluded from the comparison.
+	Ignore []Region
+}
+
+// Result describes the difference between two images.
+type Result struct {
+	Width           int     `json:"width"`
+	Height          int     `json:"height"`
+	DiffPixels      int     `json:"diffPixels"`
+	ComparedPixels  int     `json:"comparedPixels"`
+	MismatchPercent float64 `json:"mismatchPercent"`
+	// SizeMismatch is set when the images have different dimensions.
+	// Pixels outside either image count as different.
+	SizeMismatch bool `json:"sizeMismatch,omitempty"`
+	// Diff shows the baseline faded, with changed pixels in red and
+	// ignored regions tinted blue.
+	Diff *image.RGBA `json:"-"`
+}
+
+var (
+	diffColor   = color.RGBA{R: 255, A: 255}
+	ignoreColor = color.RGBA{R: 170, G: 200, B: 255, A: 255}
+)
+
+// Compare compares actual against baseline.
+func Compare(baseline, actual image.Image, opts Options) *Result {
+	bb, ab := baseline.Bounds(), actual.Bounds()
+	w, h := max(bb.Dx(), ab.Dx()), max(bb.Dy(), ab.Dy())
+	res := &Result{
+		Width:        w,
+		Height:       h,
+		SizeMismatch: bb.Dx() != ab.Dx() || bb.Dy() != ab.Dy(),
+		Diff:         image.NewRGBA(image.Rect(0, 0, w, h)),
+	}
+	limit := uint32(math.Round(math.Max(0, opts.Tolerance) * 0xffff))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if ignored(opts.Ignore, x, y) {
+				res.Diff.SetRGBA(x, y, ignoreColor)
+				continue
+			}
+			res.ComparedPixels++
+			inBase := x < bb.Dx() && y < bb.Dy()
+			inActual := x < ab.Dx() && y < ab.Dy()
+			if !inBase || !inActual {
+				res.DiffPixels++
+				res.Diff.SetRGBA(x, y, diffColor)
+				continue
+			}
+			bc := baseline.At(bb.Min.X+x, bb.Min.Y+y)
+			if channelDelta(bc, actual.At(ab.Min.X+x, ab.Min.Y+y)) > limit {
+				res.DiffPixels++
+				res.Diff.SetRGBA(x, y, diffColor)
+				continue
+			}
+			res.Diff.SetRGBA(x, y, faded(bc))
+		}
+	}
+	if res.ComparedPixels > 0 {
+		pct := float64(res.DiffPixels) / float64(res.ComparedPixels) * 100
+		res.MismatchPercent = math.Round(pct*10000) / 10000
+	}
+	return res
+}
+
+func ignored(regions []Region, x, y int) bool {
+	for _, r := range regions {
+		if r.contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// channelDelta returns the largest difference between the 16-bit
+// alpha-premultiplied channels of a and b.
+func channelDelta(a, b color.Color) uint32 {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return max(absDiff(ar, br), absDiff(ag, bg), absDiff(ab, bb), absDiff(aa, ba))
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// faded renders c as light gray so changed pixels stand out.
+func faded(c color.Color) color.RGBA {
+	g := color.GrayModel.Convert(c).(color.Gray)
+	v := uint8(255 - (255-int(g.Y))/4)
+	return color.RGBA{R: v, G: v, B: v, A: 255}
+}
@@ -0,0 +1,152 @@
+package imagediff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encode(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompare(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	base := solid(10, 10, white)
+
+	res := Compare(base, solid(10, 10, white), Options{Tolerance: DefaultTolerance})
+	if res.DiffPixels != 0 || res.MismatchPercent != 0 {
+		t.Errorf("identical images: %+v", res)
+	}
+
+	actual := solid(10, 10, white)
+	// Within tolerance: slight anti-aliasing noise.
+	actual.SetRGBA(0, 0, color.RGBA{250, 250, 250, 255})
+	// Real changes.
+	for x := 0; x < 5; x++ {
+		actual.SetRGBA(x, 5, color.RGBA{0, 0, 0, 255})
+	}
+	res = Compare(base, actual, Options{Tolerance: DefaultTolerance})
+	if res.DiffPixels != 5 || res.MismatchPercent != 5 {
+		t.Errorf("diff = %d (%v%%), want 5 (5%%)", res.DiffPixels, res.MismatchPercent)
+	}
+	if got := res.Diff.RGBAAt(2, 5); got != diffColor {
+		t.Errorf("changed pixel not highlighted: %v", got)
+	}
+
+	res = Compare(base, actual, Options{Ignore: []Region{{X: 0, Y: 5, Width: 10, Height: 1}}})
+	if res.ComparedPixels != 90 || res.DiffPixels != 1 {
+		t.Errorf("ignore region: compared=%d diff=%d", res.ComparedPixels, res.DiffPixels)
+	}
+	if got := res.Diff.RGBAAt(2, 5); got != ignoreColor {
+		t.Errorf("ignored pixel not tinted: %v", got)
+	}
+
+	res = Compare(base, solid(10, 12, white), Options{})
+	if !res.SizeMismatch || res.DiffPixels != 20 || res.Height != 12 {
+		t.Errorf("size mismatch: %+v", res)
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := NewStore(t.TempDir())
+	img := solid(4, 3, color.RGBA{1, 2, 3, 255})
+
+	if _, err := s.Load("home"); !errors.Is(err, ErrNoBaseline) {
+		t.Fatalf("Load missing = %v", err)
+	}
+	for _, bad := range []string{"", "../x", "a/b", "x.png", "-lead"} {
+		if err := s.Save(bad, encode(t, img)); err == nil {
+			t.Errorf("Save(%q) should fail", bad)
+		}
+	}
+	if err := s.Save("home", []byte("not a png")); err == nil {
+		t.Error("Save should reject non-PNG data")
+	}
+	if err := s.Save("home", encode(t, img)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Load("home"); err != nil || got.Bounds().Dx() != 4 {
+		t.Fatalf("Load = %v, %v", got, err)
+	}
+
+	if err := s.Accept("home"); !errors.Is(err, ErrNoActual) {
+		t.Errorf("Accept without result = %v", err)
+	}
+	changed := solid(5, 5, color.RGBA{9, 9, 9, 255})
+	if err := s.SaveResult("home", encode(t, changed), Compare(img, changed, Options{}).Diff); err != nil {
+		t.Fatal(err)
+	}
+	list, err := s.List()
+	if err != nil || len(list) != 1 || !list[0].HasResult || list[0].Width != 4 {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+	if _, err := s.ReadDiff("home"); err != nil {
+		t.Errorf("ReadDiff: %v", err)
+	}
+	if err := s.Accept("home"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Load("home"); got.Bounds().Dx() != 5 {
+		t.Error("Accept should promote the actual screenshot")
+	}
+	if list, _ := s.List(); list[0].HasResult {
+		t.Error("Accept should clear the pending result")
+	}
+
+	if err := s.Delete("home"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("home"); !errors.Is(err, ErrNoBaseline) {
+		t.Errorf("second Delete = %v", err)
+	}
+}
+
+// withSize rewrites the dimensions in a PNG's header, leaving the pixel
+// data as is.
+func withSize(t *testing.T, data []byte, w, h uint32) []byte {
+	t.Helper()
+	out := bytes.Clone(data)
+	// 8-byte signature, then the IHDR chunk: length, type, data, CRC.
+	ihdr := out[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:4], w)
+	binary.BigEndian.PutUint32(ihdr[4:8], h)
+	binary.BigEndian.PutUint32(out[8+8+13:], crc32.ChecksumIEEE(out[8+4:8+8+13]))
+	return out
+}
+
+func TestStoreRejectsOversizedImages(t *testing.T) {
+	s := NewStore(t.TempDir())
+	small := encode(t, solid(4, 3, color.RGBA{1, 2, 3, 255}))
+
+	if _, err := CheckPNG(withSize(t, small, 8000, 5000)); err != nil {
+		t.Fatalf("an image at MaxPixels should pass: %v", err)
+	}
+	huge := withSize(t, small, 1<<16, 1<<16)
+	if err := s.Save("huge", huge); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Save oversized = %v, want ErrTooLarge", err)
+	}
+	if list, _ := s.List(); len(list) != 0 {
+		t.Errorf("an oversized baseline should not be stored, got %+v", list)
+	}
+}
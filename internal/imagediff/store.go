@@ -0,0 +1,250 @@
+package imagediff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNoBaseline is returned when a named baseline does not exist.
+var ErrNoBaseline = errors.New("baseline not found")
+
+// ErrNoActual is returned by Accept when no comparison has been recorded
+// for the baseline.
+var ErrNoActual = errors.New("no comparison result to accept")
+
+// ErrInvalidName is returned for baseline names that ValidName rejects.
+var ErrInvalidName = errors.New("invalid baseline name")
+
+// ErrTooLarge is returned for images over MaxPixels.
+var ErrTooLarge = errors.New("image too large")
+
+// MaxPixels bounds the width×height of a baseline or compared screenshot.
+// A decoded image takes four bytes per pixel and a comparison holds three
+// of them, so this keeps one comparison under about half a gigabyte.
+const MaxPixels = 40_000_000
+
+// CheckPNG reports whether data is a PNG within MaxPixels, reading only
+// its header.
+func CheckPNG(data []byte) (image.Config, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err == nil && format != "png" {
+		err = fmt.Errorf("got %s", format)
+	}
+	if err != nil {
+		return image.Config{}, fmt.Errorf("image must be a PNG: %w", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return image.Config{}, fmt.Errorf("%w: %dx%d is over %d pixels", ErrTooLarge, cfg.Width, cfg.Height, MaxPixels)
+	}
+	return cfg, nil
+}
+
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,127}$`)
+
+// ValidName reports whether name can be used as a baseline name. Names
+// are limited to letters, digits, '-' and '_' so they map to plain file
+// names.
+func ValidName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("%w %q: use letters, digits, '-' and '_' (max 128)", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// Store keeps baselines as PNG files in one directory. The last compared
+// screenshot and its diff are kept next to each baseline as
+// <name>.actual.png and <name>.diff.png.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store rooted at dir. The directory is created on the
+// first write.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the store directory.
+func (s *Store) Dir() string { return s.dir }
+
+// Baseline describes a stored baseline.
+type Baseline struct {
+	Name      string    `json:"name"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// HasResult is set when an unaccepted comparison result is stored.
+	HasResult bool `json:"hasResult,omitempty"`
+}
+
+func (s *Store) path(name, suffix string) string {
+	return filepath.Join(s.dir, name+suffix+".png")
+}
+
+// Read returns the baseline's PNG bytes.
+func (s *Store) Read(name string) ([]byte, error) {
+	return s.read(name, "")
+}
+
+// ReadDiff returns the PNG diff from the last comparison.
+func (s *Store) ReadDiff(name string) ([]byte, error) {
+	return s.read(name, ".diff")
+}
+
+func (s *Store) read(name, suffix string) ([]byte, error) {
+	if err := ValidName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(name, suffix))
+	if errors.Is(err, os.ErrNotExist) {
+		if suffix == "" {
+			return nil, fmt.Errorf("%w: %s", ErrNoBaseline, name)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNoActual, name)
+	}
+	return data, err
+}
+
+// Load returns the decoded baseline image.
+func (s *Store) Load(name string) (image.Image, error) {
+	data, err := s.Read(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := CheckPNG(data); err != nil {
+		return nil, fmt.Errorf("baseline %s: %w", name, err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode baseline %s: %w", name, err)
+	}
+	return img, nil
+}
+
+// Save stores data as the named baseline. data must be a PNG within
+// MaxPixels. Any pending comparison result for the name is discarded.
+func (s *Store) Save(name string, data []byte) error {
+	if err := ValidName(name); err != nil {
+		return err
+	}
+	if _, err := CheckPNG(data); err != nil {
+		return fmt.Errorf("baseline: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path(name, ""), data); err != nil {
+		return err
+	}
+	s.clearResult(name)
+	return nil
+}
+
+// SaveResult records the screenshot and diff of a failed comparison so it
+// can be reviewed and accepted later.
+func (s *Store) SaveResult(name string, actual []byte, diff image.Image) error {
+	if err := ValidName(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, diff); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path(name, ".actual"), actual); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path(name, ".diff"), buf.Bytes())
+}
+
+// clearResult removes any stored comparison result for name.
+func (s *Store) clearResult(name string) {
+	_ = os.Remove(s.path(name, ".actual"))
+	_ = os.Remove(s.path(name, ".diff"))
+}
+
+// Accept promotes the last compared screenshot to be the baseline.
+func (s *Store) Accept(name string) error {
+	data, err := s.read(name, ".actual")
+	if err != nil {
+		return err
+	}
+	return s.Save(name, data)
+}
+
+// Delete removes a baseline and its comparison result.
+func (s *Store) Delete(name string) error {
+	if err := ValidName(name); err != nil {
+		return err
+	}
+	err := os.Remove(s.path(name, ""))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNoBaseline, name)
+	}
+	s.clearResult(name)
+	return err
+}
+
+// List returns all baselines sorted by name.
+func (s *Store) List() ([]Baseline, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Baseline{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := map[string]bool{}
+	var names []string
+	for _, e := range entries {
+		base, ok := strings.CutSuffix(e.Name(), ".png")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if n, ok := strings.CutSuffix(base, ".actual"); ok {
+			results[n] = true
+			continue
+		}
+		if ValidName(base) == nil {
+			names = append(names, base)
+		}
+	}
+	sort.Strings(names)
+
+	out := make([]Baseline, 0, len(names))
+	for _, name := range names {
+		b := Baseline{Name: name, HasResult: results[name]}
+		if info, err := os.Stat(s.path(name, "")); err == nil {
+			b.Size = info.Size()
+			b.UpdatedAt = info.ModTime().UTC()
+		}
+		if f, err := os.Open(s.path(name, "")); err == nil {
+			if cfg, err := png.DecodeConfig(f); err == nil {
+				b.Width, b.Height = cfg.Width, cfg.Height
+			}
+			_ = f.Close()
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -8,9 +8,9 @@ type FCFS struct{}
 
 func (f *FCFS) Name() string { return "fcfs" }
 
-func (f *FCFS) Select(candidates []bridge.Instance) (bridge.Instance, error) {
+func (f *FCFS) Select(_ Request, candidates []Candidate) (bridge.Instance, error) {
 	if len(candidates) == 0 {
 		return bridge.Instance{}, ErrNoCandidates
 	}
-	return candidates[0], nil
+	return candidates[0].Instance, nil
 }
@@ -0,0 +1,36 @@
+package allocation
+
+import "github.com/pinchtab/pinchtab/internal/bridge"
+
+// LeastLoaded picks the candidate with the fewest open tabs, breaking ties
+// by JS heap in use and then by candidate order. Candidates whose load is
+// unknown rank after every measured one.
+type LeastLoaded struct{}
+
+func (l *LeastLoaded) Name() string { return "least_loaded" }
+
+func (l *LeastLoaded) NeedsLoad() bool { return true }
+
+func (l *LeastLoaded) Select(_ Request, candidates []Candidate) (bridge.Instance, error) {
+	if len(candidates) == 0 {
+		return bridge.Instance{}, ErrNoCandidates
+	}
+	best := 0
+	for i := 1; i < len(candidates); i++ {
+		if lighter(candidates[i].Load, candidates[best].Load) {
+			best = i
+		}
+	}
+	return candidates[best].Instance, nil
+}
+
+// lighter reports whether load a ranks strictly before load b.
+func lighter(a, b Load) bool {
+	if a.Known != b.Known {
+		return a.Known
+	}
+	if a.Tabs != b.Tabs {
+		return a.Tabs < b.Tabs
+	}
+	return a.MemoryMB < b.MemoryMB
+}
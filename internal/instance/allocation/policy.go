@@ -8,15 +8,60 @@ import (
 	"github.com/pinchtab/pinchtab/internal/bridge"
 )
 
+// Request describes who an instance is allocated for. Empty fields are
+// unknown.
+type Request struct {
+	// AgentID is the calling agent, from X-Agent-Id or a scheduler task.
+	AgentID string
+	// SessionID identifies the agent's session, when it has one.
+	SessionID string
+	// Profile is the profile name or ID the caller prefers.
+	Profile string
+	// Labels must all be present with equal values on the chosen instance.
+	Labels map[string]string
+}
+
+// MatchesLabels reports whether labels carry every label req asks for.
+func (req Request) MatchesLabels(labels map[string]string) bool {
+	for k, v := range req.Labels {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Load is an instance's current load. Known is false when it could not be
+// measured.
+type Load struct {
+	Tabs     int
+	MemoryMB float64
+	Known    bool
+}
+
+// Candidate is a running instance with its load. Load is only measured
+// for policies that implement LoadAware.
+type Candidate struct {
+	bridge.Instance
+	Load Load
+}
+
 // Policy selects an instance from a list of running candidates.
 // Implementations must be safe for concurrent use.
 type Policy interface {
 	// Name returns the policy identifier (for config/logging).
 	Name() string
 
-	// Select picks the best instance from the given candidates.
+	// Select picks the best instance from the given candidates for req.
 	// Returns an error if candidates is empty or no suitable instance exists.
-	Select(candidates []bridge.Instance) (bridge.Instance, error)
+	Select(req Request, candidates []Candidate) (bridge.Instance, error)
+}
+
+// LoadAware is implemented by policies that rank candidates by load.
+// Allocators measure load only when NeedsLoad reports true, since it
+// costs a request to every instance.
+type LoadAware interface {
+	NeedsLoad() bool
 }
 
 // ErrNoCandidates is returned when Select receives an empty slice.
@@ -31,7 +76,13 @@ func New(name string) (Policy, error) {
 		return NewRoundRobin(), nil
 	case "random":
 		return &Random{}, nil
+	case "least_loaded":
+		return &LeastLoaded{}, nil
+	case "sticky":
+		return NewSticky(&LeastLoaded{}), nil
+	case "profile_affinity":
+		return &ProfileAffinity{}, nil
 	default:
-		return nil, fmt.Errorf("unknown allocation policy: %q (available: fcfs, round_robin, random)", name)
+		return nil, fmt.Errorf("unknown allocation policy: %q (available: fcfs, round_robin, random, least_loaded, sticky, profile_affinity)", name)
 	}
 }
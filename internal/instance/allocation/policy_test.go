@@ -7,17 +7,24 @@ import (
 	"github.com/pinchtab/pinchtab/internal/instance/allocation"
 )
 
-func candidates(ids ...string) []bridge.Instance {
-	out := make([]bridge.Instance, len(ids))
+func candidates(ids ...string) []allocation.Candidate {
+	out := make([]allocation.Candidate, len(ids))
 	for i, id := range ids {
-		out[i] = bridge.Instance{ID: id, Status: "running"}
+		out[i] = allocation.Candidate{Instance: bridge.Instance{ID: id, Status: "running"}}
 	}
 	return out
 }
 
+func loaded(id, profile string, tabs int, memMB float64) allocation.Candidate {
+	return allocation.Candidate{
+		Instance: bridge.Instance{ID: id, ProfileName: profile, Status: "running"},
+		Load:     allocation.Load{Tabs: tabs, MemoryMB: memMB, Known: true},
+	}
+}
+
 func TestFCFS_SelectsFirst(t *testing.T) {
 	p := &allocation.FCFS{}
-	got, err := p.Select(candidates("a", "b", "c"))
+	got, err := p.Select(allocation.Request{}, candidates("a", "b", "c"))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -28,7 +35,7 @@ func TestFCFS_SelectsFirst(t *testing.T) {
 
 func TestFCFS_EmptyReturnsError(t *testing.T) {
 	p := &allocation.FCFS{}
-	_, err := p.Select(nil)
+	_, err := p.Select(allocation.Request{}, nil)
 	if err != allocation.ErrNoCandidates {
 		t.Errorf("expected ErrNoCandidates, got %v", err)
 	}
@@ -40,7 +47,7 @@ func TestRoundRobin_Cycles(t *testing.T) {
 
 	expected := []string{"a", "b", "c", "a", "b", "c"}
 	for i, want := range expected {
-		got, err := p.Select(c)
+		got, err := p.Select(allocation.Request{}, c)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -52,7 +59,7 @@ func TestRoundRobin_Cycles(t *testing.T) {
 
 func TestRoundRobin_EmptyReturnsError(t *testing.T) {
 	p := allocation.NewRoundRobin()
-	_, err := p.Select(nil)
+	_, err := p.Select(allocation.Request{}, nil)
 	if err != allocation.ErrNoCandidates {
 		t.Errorf("expected ErrNoCandidates, got %v", err)
 	}
@@ -65,7 +72,7 @@ func TestRandom_SelectsFromCandidates(t *testing.T) {
 	// Run enough times to verify it doesn't panic and returns valid candidates.
 	seen := map[string]bool{}
 	for range 100 {
-		got, err := p.Select(c)
+		got, err := p.Select(allocation.Request{}, c)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -79,7 +86,7 @@ func TestRandom_SelectsFromCandidates(t *testing.T) {
 
 func TestRandom_EmptyReturnsError(t *testing.T) {
 	p := &allocation.Random{}
-	_, err := p.Select(nil)
+	_, err := p.Select(allocation.Request{}, nil)
 	if err != allocation.ErrNoCandidates {
 		t.Errorf("expected ErrNoCandidates, got %v", err)
 	}
@@ -94,6 +101,9 @@ func TestNew_KnownPolicies(t *testing.T) {
 		{"", "fcfs"},
 		{"round_robin", "round_robin"},
 		{"random", "random"},
+		{"least_loaded", "least_loaded"},
+		{"sticky", "sticky"},
+		{"profile_affinity", "profile_affinity"},
 	}
 	for _, tt := range tests {
 		p, err := allocation.New(tt.name)
@@ -113,3 +123,76 @@ func TestNew_UnknownPolicy(t *testing.T) {
 		t.Error("expected error for unknown policy")
 	}
 }
+
+func TestLeastLoaded_FewestTabsThenMemory(t *testing.T) {
+	p := &allocation.LeastLoaded{}
+	unknown := candidates("unknown")[0]
+	got, err := p.Select(allocation.Request{}, []allocation.Candidate{
+		unknown,
+		loaded("busy", "", 5, 100),
+		loaded("heavy", "", 2, 900),
+		loaded("light", "", 2, 300),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "light" {
+		t.Errorf("expected light, got %s", got.ID)
+	}
+	if _, err := p.Select(allocation.Request{}, nil); err != allocation.ErrNoCandidates {
+		t.Errorf("expected ErrNoCandidates, got %v", err)
+	}
+}
+
+func TestSticky_PinsAgentAndSession(t *testing.T) {
+	p := allocation.NewSticky(allocation.NewRoundRobin())
+	c := candidates("a", "b", "c")
+
+	first, _ := p.Select(allocation.Request{AgentID: "agent-1"}, c)
+	for range 5 {
+		got, err := p.Select(allocation.Request{AgentID: "agent-1"}, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != first.ID {
+			t.Fatalf("agent should stay on %s, got %s", first.ID, got.ID)
+		}
+	}
+	other, _ := p.Select(allocation.Request{AgentID: "agent-2"}, c)
+	if other.ID == first.ID {
+		t.Errorf("a new agent should be placed by the fallback, got %s again", other.ID)
+	}
+	session, _ := p.Select(allocation.Request{AgentID: "agent-1", SessionID: "s1"}, c)
+	if again, _ := p.Select(allocation.Request{AgentID: "agent-1", SessionID: "s1"}, c); again.ID != session.ID {
+		t.Errorf("session should stay on %s, got %s", session.ID, again.ID)
+	}
+
+	var rest []allocation.Candidate
+	for _, cand := range c {
+		if cand.ID != first.ID {
+			rest = append(rest, cand)
+		}
+	}
+	moved, err := p.Select(allocation.Request{AgentID: "agent-1"}, rest)
+	if err != nil || moved.ID == first.ID {
+		t.Fatalf("agent should move when its instance is gone, got %s %v", moved.ID, err)
+	}
+	if got, _ := p.Select(allocation.Request{AgentID: "agent-1"}, c); got.ID != moved.ID {
+		t.Errorf("agent should be pinned to its new instance %s, got %s", moved.ID, got.ID)
+	}
+}
+
+func TestProfileAffinity_PrefersProfile(t *testing.T) {
+	p := &allocation.ProfileAffinity{}
+	c := []allocation.Candidate{
+		loaded("idle-default", "default", 0, 0),
+		loaded("busy-work", "work", 4, 0),
+		loaded("work", "work", 1, 0),
+	}
+	if got, _ := p.Select(allocation.Request{Profile: "work"}, c); got.ID != "work" {
+		t.Errorf("expected the least loaded work instance, got %s", got.ID)
+	}
+	if got, _ := p.Select(allocation.Request{Profile: "missing"}, c); got.ID != "idle-default" {
+		t.Errorf("expected the least loaded instance overall, got %s", got.ID)
+	}
+}
@@ -0,0 +1,30 @@
+package allocation
+
+import "github.com/pinchtab/pinchtab/internal/bridge"
+
+// ProfileAffinity prefers instances running the requested profile, matched
+// by name or ID, and picks the least loaded of them. Without a requested
+// profile, or when no candidate runs it, it picks the least loaded of all
+// candidates.
+type ProfileAffinity struct {
+	loaded LeastLoaded
+}
+
+func (p *ProfileAffinity) Name() string { return "profile_affinity" }
+
+func (p *ProfileAffinity) NeedsLoad() bool { return true }
+
+func (p *ProfileAffinity) Select(req Request, candidates []Candidate) (bridge.Instance, error) {
+	if req.Profile != "" {
+		var matching []Candidate
+		for _, c := range candidates {
+			if c.ProfileName == req.Profile || c.ProfileID == req.Profile {
+				matching = append(matching, c)
+			}
+		}
+		if len(matching) > 0 {
+			return p.loaded.Select(req, matching)
+		}
+	}
+	return p.loaded.Select(req, candidates)
+}
@@ -11,9 +11,9 @@ type Random struct{}
 
 func (r *Random) Name() string { return "random" }
 
-func (r *Random) Select(candidates []bridge.Instance) (bridge.Instance, error) {
+func (r *Random) Select(_ Request, candidates []Candidate) (bridge.Instance, error) {
 	if len(candidates) == 0 {
 		return bridge.Instance{}, ErrNoCandidates
 	}
-	return candidates[rand.IntN(len(candidates))], nil
+	return candidates[rand.IntN(len(candidates))].Instance, nil
 }
@@ -19,10 +19,10 @@ func NewRoundRobin() *RoundRobin {
 
 func (rr *RoundRobin) Name() string { return "round_robin" }
 
-func (rr *RoundRobin) Select(candidates []bridge.Instance) (bridge.Instance, error) {
+func (rr *RoundRobin) Select(_ Request, candidates []Candidate) (bridge.Instance, error) {
 	if len(candidates) == 0 {
 		return bridge.Instance{}, ErrNoCandidates
 	}
 	idx := rr.counter.Add(1) - 1
-	return candidates[idx%uint64(len(candidates))], nil
+	return candidates[idx%uint64(len(candidates))].Instance, nil
 }
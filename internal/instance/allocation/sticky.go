@@ -0,0 +1,85 @@
+package allocation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+const (
+	// stickyIdle is how long an unused pin is kept once the table is full.
+	stickyIdle = time.Hour
+	// maxStickyPins is the size above which idle pins are pruned.
+	maxStickyPins = 10000
+)
+
+// Sticky pins each session, or each agent when there is no session, to
+// the instance it was first given, for as long as that instance is a
+// candidate. New and unidentified callers, and callers whose instance has
+// gone, are placed by the fallback policy.
+type Sticky struct {
+	fallback Policy
+
+	mu   sync.Mutex
+	pins map[string]stickyPin
+}
+
+type stickyPin struct {
+	instanceID string
+	used       time.Time
+}
+
+// NewSticky creates a Sticky policy that places new callers with fallback.
+func NewSticky(fallback Policy) *Sticky {
+	return &Sticky{fallback: fallback, pins: make(map[string]stickyPin)}
+}
+
+func (s *Sticky) Name() string { return "sticky" }
+
+func (s *Sticky) NeedsLoad() bool {
+	la, ok := s.fallback.(LoadAware)
+	return ok && la.NeedsLoad()
+}
+
+func (s *Sticky) Select(req Request, candidates []Candidate) (bridge.Instance, error) {
+	key := stickyKey(req)
+	if key == "" {
+		return s.fallback.Select(req, candidates)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if pin, ok := s.pins[key]; ok {
+		for _, c := range candidates {
+			if c.ID == pin.instanceID {
+				s.pins[key] = stickyPin{instanceID: c.ID, used: now}
+				return c.Instance, nil
+			}
+		}
+	}
+	selected, err := s.fallback.Select(req, candidates)
+	if err != nil {
+		return bridge.Instance{}, err
+	}
+	if len(s.pins) >= maxStickyPins {
+		for k, pin := range s.pins {
+			if now.Sub(pin.used) > stickyIdle {
+				delete(s.pins, k)
+			}
+		}
+	}
+	s.pins[key] = stickyPin{instanceID: selected.ID, used: now}
+	return selected, nil
+}
+
+func stickyKey(req Request) string {
+	switch {
+	case req.SessionID != "":
+		return "session:" + req.SessionID
+	case req.AgentID != "":
+		return "agent:" + req.AgentID
+	}
+	return ""
+}
@@ -2,16 +2,38 @@ package instance
 
 import (
 	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/instance/allocation"
 )
 
+// loadTTL is how long measured instance loads are reused, so a burst of
+// allocations does not query every instance each time.
+const loadTTL = 2 * time.Second
+
+// LoadSource measures the load of running instances, keyed by instance ID.
+// Instances missing from the result have unknown load.
+type LoadSource func() map[string]allocation.Load
+
 // Allocator selects an instance using the configured AllocationPolicy.
 // It reads candidates from the Repository and delegates selection to the policy.
 type Allocator struct {
-	repo   *Repository
+	repo *Repository
+
+	mu     sync.Mutex
 	policy allocation.Policy
+
+	// loadMu is held while loads are measured, so a slow instance does not
+	// block policy reads.
+	loadMu  sync.Mutex
+	load    LoadSource
+	loads   map[string]allocation.Load
+	loadsAt time.Time
 }
 
 // NewAllocator creates an Allocator with the given policy.
@@ -21,23 +43,143 @@ func NewAllocator(repo *Repository, policy allocation.Policy) *Allocator {
 
 // Allocate selects a running instance using the configured policy.
 func (a *Allocator) Allocate() (*bridge.Instance, error) {
-	candidates := a.repo.Running()
-	if len(candidates) == 0 {
+	return a.AllocateFor(allocation.Request{})
+}
+
+// AllocateFor selects a running instance for req using the configured
+// policy. req.Profile is only a preference, for policies that use it;
+// req.Labels must match.
+func (a *Allocator) AllocateFor(req allocation.Request) (*bridge.Instance, error) {
+	running := a.repo.Running()
+	if len(running) == 0 {
 		return nil, fmt.Errorf("no running instances available")
 	}
-	selected, err := a.policy.Select(candidates)
+	candidates := matchingLabels(req, running)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no running instances with labels %s", formatLabels(req.Labels))
+	}
+	return a.selectFrom(req, candidates)
+}
+
+// AllocateProfile selects a running instance of req.Profile, matched by
+// name or ID, using the configured policy. An empty profile matches every
+// running instance. req.Labels must match as well.
+func (a *Allocator) AllocateProfile(req allocation.Request) (*bridge.Instance, error) {
+	if req.Profile == "" {
+		return a.AllocateFor(req)
+	}
+	var candidates []bridge.Instance
+	for _, inst := range a.repo.Running() {
+		if inst.ProfileName == req.Profile || inst.ProfileID == req.Profile {
+			candidates = append(candidates, inst)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no running instances for profile %q", req.Profile)
+	}
+	if candidates = matchingLabels(req, candidates); len(candidates) == 0 {
+		return nil, fmt.Errorf("no running instances for profile %q with labels %s", req.Profile, formatLabels(req.Labels))
+	}
+	return a.selectFrom(req, candidates)
+}
+
+// matchingLabels keeps the instances carrying every label req asks for.
+func matchingLabels(req allocation.Request, instances []bridge.Instance) []bridge.Instance {
+	if len(req.Labels) == 0 {
+		return instances
+	}
+	var out []bridge.Instance
+	for _, inst := range instances {
+		if req.MatchesLabels(inst.Labels) {
+			out = append(out, inst)
+		}
+	}
+	return out
+}
+
+// formatLabels renders labels as sorted k=v pairs for error messages.
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// selectFrom orders the instances by start time, so "first" is stable,
+// attaches their load if the policy needs it, and lets the policy choose.
+func (a *Allocator) selectFrom(req allocation.Request, instances []bridge.Instance) (*bridge.Instance, error) {
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].StartTime.Equal(instances[j].StartTime) {
+			return instances[i].ID < instances[j].ID
+		}
+		return instances[i].StartTime.Before(instances[j].StartTime)
+	})
+	policy := a.Policy()
+	var loads map[string]allocation.Load
+	if la, ok := policy.(allocation.LoadAware); ok && la.NeedsLoad() {
+		loads = a.currentLoads()
+	}
+	candidates := make([]allocation.Candidate, len(instances))
+	for i, inst := range instances {
+		candidates[i] = allocation.Candidate{Instance: inst, Load: loads[inst.ID]}
+	}
+	selected, err := policy.Select(req, candidates)
 	if err != nil {
-		return nil, fmt.Errorf("allocation policy %q failed: %w", a.policy.Name(), err)
+		return nil, fmt.Errorf("allocation policy %q failed: %w", policy.Name(), err)
+	}
+	if loads != nil {
+		a.countAllocation(selected.ID)
 	}
 	return &selected, nil
 }
 
+// currentLoads returns a copy of the measured loads, refreshing them once
+// they are older than loadTTL.
+func (a *Allocator) currentLoads() map[string]allocation.Load {
+	a.loadMu.Lock()
+	defer a.loadMu.Unlock()
+	if a.load == nil {
+		return nil
+	}
+	if a.loads == nil || time.Since(a.loadsAt) > loadTTL {
+		a.loads = a.load()
+		a.loadsAt = time.Now()
+	}
+	return maps.Clone(a.loads)
+}
+
+// countAllocation adds a tab to the cached load of an instance that was
+// just allocated, so allocations before the next measurement spread out.
+func (a *Allocator) countAllocation(instanceID string) {
+	a.loadMu.Lock()
+	defer a.loadMu.Unlock()
+	if l, ok := a.loads[instanceID]; ok {
+		l.Tabs++
+		a.loads[instanceID] = l
+	}
+}
+
 // Policy returns the current allocation policy.
 func (a *Allocator) Policy() allocation.Policy {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	return a.policy
 }
 
 // SetPolicy swaps the allocation policy at runtime.
 func (a *Allocator) SetPolicy(p allocation.Policy) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.policy = p
 }
+
+// SetLoadSource sets how instance load is measured for load-aware
+// policies. Without one, every candidate's load is unknown.
+func (a *Allocator) SetLoadSource(load LoadSource) {
+	a.loadMu.Lock()
+	defer a.loadMu.Unlock()
+	a.load = load
+	a.loads = nil
+}
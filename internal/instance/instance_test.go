@@ -316,6 +316,89 @@ func TestAllocator_NoRunningInstances(t *testing.T) {
 	}
 }
 
+func TestAllocator_AllocateProfile(t *testing.T) {
+	launcher := newMockLauncher()
+	repo := instance.NewRepository(launcher)
+	alloc := instance.NewAllocator(repo, allocation.NewRoundRobin())
+
+	_, _ = repo.Launch("prof1", "9868", true)
+	_, _ = repo.Launch("prof2", "9869", true)
+	_, _ = repo.Launch("prof2", "9870", true)
+
+	for range 4 {
+		got, err := alloc.AllocateProfile(allocation.Request{Profile: "prof2"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ProfileName != "prof2" {
+			t.Errorf("expected a prof2 instance, got %s", got.ProfileName)
+		}
+	}
+	if _, err := alloc.AllocateProfile(allocation.Request{Profile: "missing"}); err == nil {
+		t.Error("expected error for a profile with no running instances")
+	}
+	if _, err := alloc.AllocateProfile(allocation.Request{}); err != nil {
+		t.Errorf("empty profile should match any instance: %v", err)
+	}
+}
+
+func TestAllocator_FiltersByLabels(t *testing.T) {
+	repo := instance.NewRepository(newMockLauncher())
+	repo.Add(&bridgepkg.Instance{ID: "inst_eu", ProfileName: "edge-eu", Status: "running", Labels: map[string]string{"region": "eu", "gpu": "false"}})
+	repo.Add(&bridgepkg.Instance{ID: "inst_us", ProfileName: "edge-us", Status: "running", Labels: map[string]string{"region": "us"}})
+	repo.Add(&bridgepkg.Instance{ID: "inst_local", ProfileName: "default", Status: "running"})
+	alloc := instance.NewAllocator(repo, allocation.NewRoundRobin())
+
+	for range 3 {
+		got, err := alloc.AllocateFor(allocation.Request{Labels: map[string]string{"region": "eu"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != "inst_eu" {
+			t.Fatalf("expected the eu instance, got %s", got.ID)
+		}
+	}
+	if _, err := alloc.AllocateFor(allocation.Request{Labels: map[string]string{"region": "eu", "gpu": "true"}}); err == nil {
+		t.Error("every label must match")
+	}
+	if _, err := alloc.AllocateProfile(allocation.Request{Profile: "edge-us", Labels: map[string]string{"region": "eu"}}); err == nil {
+		t.Error("profile and labels must both match")
+	}
+}
+
+func TestAllocator_LeastLoadedUsesLoadSource(t *testing.T) {
+	launcher := newMockLauncher()
+	repo := instance.NewRepository(launcher)
+	alloc := instance.NewAllocator(repo, &allocation.LeastLoaded{})
+
+	a, _ := repo.Launch("prof1", "9868", true)
+	b, _ := repo.Launch("prof2", "9869", true)
+	calls := 0
+	alloc.SetLoadSource(func() map[string]allocation.Load {
+		calls++
+		return map[string]allocation.Load{
+			a.ID: {Tabs: 3, Known: true},
+			b.ID: {Tabs: 1, Known: true},
+		}
+	})
+
+	// b starts lighter; each allocation counts as a tab until the next
+	// measurement, so the third allocation goes to a.
+	want := []string{b.ID, b.ID, a.ID}
+	for i, id := range want {
+		got, err := alloc.Allocate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != id {
+			t.Errorf("allocation %d: expected %s, got %s", i, id, got.ID)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loads should be measured once within the TTL, got %d", calls)
+	}
+}
+
 // --- Manager facade tests ---
 
 func TestManager_DelegatesToComponents(t *testing.T) {
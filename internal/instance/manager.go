@@ -1,6 +1,8 @@
 package instance
 
 import (
+	"time"
+
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/instance/allocation"
 )
@@ -55,6 +57,16 @@ func (m *Manager) Running() []bridge.Instance {
 	return m.Repo.Running()
 }
 
+// Touch records that an instance is serving a request now.
+func (m *Manager) Touch(id string) {
+	m.Repo.Touch(id)
+}
+
+// LastActive returns when each instance last served a request.
+func (m *Manager) LastActive() map[string]time.Time {
+	return m.Repo.LastActive()
+}
+
 // --- Discovery (delegates to Locator) ---
 
 // FindInstanceByTabID returns the instance that owns a tab.
@@ -79,6 +91,18 @@ func (m *Manager) Allocate() (*bridge.Instance, error) {
 	return m.Allocator.Allocate()
 }
 
+// AllocateFor selects a running instance for a request using the
+// configured policy.
+func (m *Manager) AllocateFor(req allocation.Request) (*bridge.Instance, error) {
+	return m.Allocator.AllocateFor(req)
+}
+
+// AllocateProfile selects a running instance of the request's profile
+// using the configured policy.
+func (m *Manager) AllocateProfile(req allocation.Request) (*bridge.Instance, error) {
+	return m.Allocator.AllocateProfile(req)
+}
+
 // SetAllocationPolicy swaps the allocation policy at runtime by name.
 func (m *Manager) SetAllocationPolicy(name string) error {
 	policy, err := allocation.New(name)
@@ -2,7 +2,9 @@ package instance
 
 import (
 	"fmt"
+	"maps"
 	"sync"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
 )
@@ -13,13 +15,16 @@ type Repository struct {
 	mu        sync.RWMutex
 	instances map[string]*bridge.Instance
 	launcher  InstanceLauncher
+	// lastActive is when each instance last served a request.
+	lastActive map[string]time.Time
 }
 
 // NewRepository creates a Repository backed by the given launcher.
 func NewRepository(launcher InstanceLauncher) *Repository {
 	return &Repository{
-		instances: make(map[string]*bridge.Instance),
-		launcher:  launcher,
+		instances:  make(map[string]*bridge.Instance),
+		launcher:   launcher,
+		lastActive: make(map[string]time.Time),
 	}
 }
 
@@ -42,6 +47,7 @@ func (r *Repository) Stop(id string) error {
 	}
 	r.mu.Lock()
 	delete(r.instances, id)
+	delete(r.lastActive, id)
 	r.mu.Unlock()
 	return nil
 }
@@ -93,9 +99,27 @@ func (r *Repository) Add(inst *bridge.Instance) {
 func (r *Repository) Remove(id string) {
 	r.mu.Lock()
 	delete(r.instances, id)
+	delete(r.lastActive, id)
 	r.mu.Unlock()
 }
 
+// Touch records that an instance is serving a request now.
+func (r *Repository) Touch(id string) {
+	r.mu.Lock()
+	if _, ok := r.instances[id]; ok {
+		r.lastActive[id] = time.Now()
+	}
+	r.mu.Unlock()
+}
+
+// LastActive returns when each instance last served a request. Instances
+// that have not served one are missing.
+func (r *Repository) LastActive() map[string]time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.lastActive)
+}
+
 // Count returns the number of tracked instances.
 func (r *Repository) Count() int {
 	r.mu.RLock()
@@ -96,3 +96,16 @@ func (c *Client) Post(ctx context.Context, path string, payload any) ([]byte, in
 	}
 	return c.do(req)
 }
+
+// Delete performs a DELETE request and returns the response body.
+func (c *Client) Delete(ctx context.Context, path string, query url.Values) ([]byte, int, error) {
+	u := c.url(path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.do(req)
+}
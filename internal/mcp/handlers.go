@@ -44,6 +44,9 @@ func handlerMap(c *Client) map[string]func(context.Context, mcp.CallToolRequest)
 		"pinchtab_close_tab":       handleCloseTab(c),
 		"pinchtab_health":          handleHealth(c),
 		"pinchtab_cookies":         handleCookies(c),
+		"pinchtab_storage_get":     handleStorageGet(c),
+		"pinchtab_storage_set":     handleStorageSet(c),
+		"pinchtab_storage_delete":  handleStorageDelete(c),
 		"pinchtab_connect_profile": handleConnectProfile(c),
 
 		// Utility
@@ -59,6 +62,11 @@ func handlerMap(c *Client) map[string]func(context.Context, mcp.CallToolRequest)
 		"pinchtab_network_detail": handleNetworkDetail(c),
 		"pinchtab_network_clear":  handleNetworkClear(c),
 
+		// Request interception
+		"pinchtab_intercept_add":    handleInterceptAdd(c),
+		"pinchtab_intercept_list":   handleInterceptList(c),
+		"pinchtab_intercept_remove": handleInterceptRemove(c),
+
 		// Dialog
 		"pinchtab_dialog": handleDialog(c),
 	}
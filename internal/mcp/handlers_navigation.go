@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -96,6 +97,18 @@ func handleScreenshot(c *Client) func(context.Context, mcp.CallToolRequest) (*mc
 		if quality, ok := optFloat(r, "quality"); ok {
 			q.Set("quality", fmt.Sprintf("%d", int(quality)))
 		}
+		if sel := optString(r, "selector"); sel != "" {
+			q.Set("selector", sel)
+		}
+		if v, ok := optBool(r, "fullPage"); ok && v {
+			q.Set("fullPage", "true")
+		}
+		if clip := optString(r, "clip"); clip != "" {
+			q.Set("clip", clip)
+		}
+		if scale, ok := optFloat(r, "scale"); ok {
+			q.Set("scale", strconv.FormatFloat(scale, 'f', -1, 64))
+		}
 		body, code, err := c.Get(ctx, "/screenshot", q)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
@@ -154,6 +154,24 @@ func TestHandleScreenshot(t *testing.T) {
 	}
 }
 
+func TestHandleScreenshotOptions(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_screenshot", map[string]any{
+		"format":   "webp",
+		"selector": "#hero",
+		"scale":    float64(2),
+	}, srv)
+
+	text := resultText(t, r)
+	for _, want := range []string{`"selector":["#hero"]`, `"scale":["2"]`, `"format":["webp"]`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %s in query, got %s", want, text)
+		}
+	}
+}
+
 func TestHandleGetText(t *testing.T) {
 	srv := mockPinchTab()
 	defer srv.Close()
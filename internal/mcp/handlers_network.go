@@ -75,3 +75,63 @@ func handleNetworkClear(c *Client) func(context.Context, mcp.CallToolRequest) (*
 		return resultFromBytes(body, code)
 	}
 }
+
+func handleInterceptAdd(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		payload := map[string]any{}
+		for _, key := range []string{"tabId", "id", "url", "method", "resourceType", "action", "body", "errorReason"} {
+			if v := optString(r, key); v != "" {
+				payload[key] = v
+			}
+		}
+		for _, key := range []string{"status", "delayMs"} {
+			if v, ok := optFloat(r, key); ok {
+				payload[key] = int(v)
+			}
+		}
+		for _, key := range []string{"headers", "requestHeaders"} {
+			if v, ok := r.GetArguments()[key].(map[string]any); ok {
+				payload[key] = v
+			}
+		}
+		if _, ok := payload["url"]; !ok {
+			payload["url"] = "*"
+		}
+		body, code, err := c.Post(ctx, "/intercept", payload)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
+
+func handleInterceptList(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		q := url.Values{}
+		if tabID := optString(r, "tabId"); tabID != "" {
+			q.Set("tabId", tabID)
+		}
+		body, code, err := c.Get(ctx, "/intercept", q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
+
+func handleInterceptRemove(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		q := url.Values{}
+		if tabID := optString(r, "tabId"); tabID != "" {
+			q.Set("tabId", tabID)
+		}
+		if id := optString(r, "id"); id != "" {
+			q.Set("id", id)
+		}
+		body, code, err := c.Delete(ctx, "/intercept", q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
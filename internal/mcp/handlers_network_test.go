@@ -91,3 +91,46 @@ func TestHandleNetworkClearWithTab(t *testing.T) {
 		t.Errorf("expected /network/clear path, got %s", text)
 	}
 }
+
+func TestHandleInterceptAdd(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_intercept_add", map[string]any{
+		"tabId":   "t1",
+		"url":     "*/api/*",
+		"status":  float64(503),
+		"body":    "{}",
+		"headers": map[string]any{"Content-Type": "application/json"},
+	}, srv)
+
+	text := resultText(t, r)
+	if !strings.Contains(text, "/intercept") {
+		t.Errorf("expected /intercept path, got %s", text)
+	}
+	if !strings.Contains(text, "*/api/*") || !strings.Contains(text, "503") {
+		t.Errorf("expected rule fields in body, got %s", text)
+	}
+}
+
+func TestHandleInterceptList(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_intercept_list", map[string]any{"tabId": "t1"}, srv)
+	text := resultText(t, r)
+	if !strings.Contains(text, "/intercept") || !strings.Contains(text, "t1") {
+		t.Errorf("expected /intercept with tabId, got %s", text)
+	}
+}
+
+func TestHandleInterceptRemove(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_intercept_remove", map[string]any{"id": "rule_1"}, srv)
+	text := resultText(t, r)
+	if !strings.Contains(text, "DELETE") || !strings.Contains(text, "rule_1") {
+		t.Errorf("expected DELETE with rule id, got %s", text)
+	}
+}
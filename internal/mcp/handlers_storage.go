@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func handleStorageGet(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		q := url.Values{}
+		for _, key := range []string{"tabId", "origin", "type", "key", "database", "store"} {
+			if v := optString(r, key); v != "" {
+				q.Set(key, v)
+			}
+		}
+		if v, ok := optFloat(r, "limit"); ok {
+			q.Set("limit", strconv.Itoa(int(v)))
+		}
+		body, code, err := c.Get(ctx, "/storage", q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
+
+func handleStorageSet(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		payload := map[string]any{}
+		for _, key := range []string{"tabId", "origin", "type", "database", "store"} {
+			if v := optString(r, key); v != "" {
+				payload[key] = v
+			}
+		}
+		args := r.GetArguments()
+		if v, ok := args["items"].(map[string]any); ok {
+			payload["items"] = v
+		}
+		if v, ok := args["entries"].([]any); ok {
+			payload["entries"] = v
+		}
+		if v, ok := optBool(r, "replace"); ok && v {
+			payload["replace"] = true
+		}
+		body, code, err := c.Post(ctx, "/storage", payload)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
+
+func handleStorageDelete(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		kind, err := r.RequireString("type")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		q := url.Values{"type": {kind}}
+		for _, key := range []string{"tabId", "origin", "key", "database", "store"} {
+			if v := optString(r, key); v != "" {
+				q.Set(key, v)
+			}
+		}
+		body, code, err := c.Delete(ctx, "/storage", q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandleStorageGet(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_storage_get", map[string]any{
+		"tabId": "t1",
+		"type":  "local",
+		"key":   "flag",
+	}, srv)
+	text := resultText(t, r)
+	if !strings.Contains(text, "/storage") || !strings.Contains(text, "flag") {
+		t.Errorf("expected /storage with key, got %s", text)
+	}
+}
+
+func TestHandleStorageSet(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_storage_set", map[string]any{
+		"items":   map[string]any{"featureX": "on"},
+		"replace": true,
+	}, srv)
+	text := resultText(t, r)
+	if !strings.Contains(text, "featureX") || !strings.Contains(text, `"replace":true`) {
+		t.Errorf("expected items and replace in body, got %s", text)
+	}
+}
+
+func TestHandleStorageDeleteRequiresType(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_storage_delete", map[string]any{}, srv)
+	if !r.IsError {
+		t.Error("expected error when type is missing")
+	}
+
+	r = callTool(t, "pinchtab_storage_delete", map[string]any{"type": "session", "key": "k"}, srv)
+	text := resultText(t, r)
+	if !strings.Contains(text, "DELETE") || !strings.Contains(text, "session") {
+		t.Errorf("expected DELETE with type, got %s", text)
+	}
+}
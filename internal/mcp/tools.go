@@ -24,10 +24,14 @@ func allTools() []mcp.Tool {
 			mcp.WithBoolean("noAnimations", mcp.Description("Disable animations before capturing the snapshot")),
 		),
 		mcp.NewTool("pinchtab_screenshot",
-			mcp.WithDescription("Take a screenshot of the current page"),
+			mcp.WithDescription("Take a screenshot of the current page, the full page, one element or a clip rectangle"),
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
-			mcp.WithString("format", mcp.Description("Image format: 'jpeg' (default) or 'png'")),
-			mcp.WithNumber("quality", mcp.Description("JPEG quality 0-100 (only for JPEG format)")),
+			mcp.WithString("format", mcp.Description("Image format: 'jpeg' (default), 'png' or 'webp'")),
+			mcp.WithNumber("quality", mcp.Description("JPEG/WebP quality 0-100")),
+			mcp.WithString("selector", mcp.Description("Capture only this element (CSS, XPath, text, or ref)")),
+			mcp.WithBoolean("fullPage", mcp.Description("Capture the whole scrollable page")),
+			mcp.WithString("clip", mcp.Description("Document rectangle 'x,y,width,height' in CSS pixels")),
+			mcp.WithNumber("scale", mcp.Description("Output scale factor, up to 4 (e.g. 2 for high-DPI)")),
 		),
 		mcp.NewTool("pinchtab_get_text",
 			mcp.WithDescription("Extract readable text content from the current page"),
@@ -147,6 +151,36 @@ func allTools() []mcp.Tool {
 			mcp.WithDescription("Get cookies for the current page"),
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 		),
+		mcp.NewTool("pinchtab_storage_get",
+			mcp.WithDescription("Read localStorage/sessionStorage items or IndexedDB data for an origin loaded in a tab"),
+			mcp.WithString("tabId", mcp.Description("Target tab ID")),
+			mcp.WithString("origin", mcp.Description("Origin (defaults to the tab's current origin)")),
+			mcp.WithString("type", mcp.Description("'local', 'session' or 'indexeddb' (default: local and session)")),
+			mcp.WithString("key", mcp.Description("Single key to read (local/session)")),
+			mcp.WithString("database", mcp.Description("IndexedDB database (lists databases when omitted)")),
+			mcp.WithString("store", mcp.Description("IndexedDB object store to read")),
+			mcp.WithNumber("limit", mcp.Description("Maximum IndexedDB records (default 100)")),
+		),
+		mcp.NewTool("pinchtab_storage_set",
+			mcp.WithDescription("Write localStorage/sessionStorage items or IndexedDB records. Subject to the IDPI domain policy."),
+			mcp.WithString("tabId", mcp.Description("Target tab ID")),
+			mcp.WithString("origin", mcp.Description("Origin (defaults to the tab's current origin)")),
+			mcp.WithString("type", mcp.Description("'local' (default), 'session' or 'indexeddb'")),
+			mcp.WithObject("items", mcp.Description("Key/value string pairs for local/session storage")),
+			mcp.WithBoolean("replace", mcp.Description("Clear the storage area before writing")),
+			mcp.WithString("database", mcp.Description("IndexedDB database")),
+			mcp.WithString("store", mcp.Description("IndexedDB object store")),
+			mcp.WithArray("entries", mcp.Description("IndexedDB records as {key, value} objects")),
+		),
+		mcp.NewTool("pinchtab_storage_delete",
+			mcp.WithDescription("Remove storage keys, clear a storage area, or delete IndexedDB data. Subject to the IDPI domain policy."),
+			mcp.WithString("type", mcp.Required(), mcp.Description("'local', 'session' or 'indexeddb'")),
+			mcp.WithString("tabId", mcp.Description("Target tab ID")),
+			mcp.WithString("origin", mcp.Description("Origin (defaults to the tab's current origin)")),
+			mcp.WithString("key", mcp.Description("Key to remove (clears the area or store when omitted)")),
+			mcp.WithString("database", mcp.Description("IndexedDB database (deleted when store is omitted)")),
+			mcp.WithString("store", mcp.Description("IndexedDB object store")),
+		),
 		mcp.NewTool("pinchtab_connect_profile",
 			mcp.WithDescription("Get the user-facing connect URL and instance status for a profile"),
 			mcp.WithString("profile", mcp.Required(), mcp.Description("Profile name or profile ID")),
@@ -211,6 +245,32 @@ func allTools() []mcp.Tool {
 			mcp.WithString("tabId", mcp.Description("Target tab ID (optional, clears all if empty)")),
 		),
 
+		// ── Request Interception ────────────────────────────────────
+		mcp.NewTool("pinchtab_intercept_add",
+			mcp.WithDescription("Add a request interception rule to a tab. Matching requests can be fulfilled with a canned response, continued with modified request headers, delayed, or aborted."),
+			mcp.WithString("tabId", mcp.Description("Target tab ID (optional, uses current tab if empty)")),
+			mcp.WithString("url", mcp.Description("URL glob: '*' matches any run of characters, '?' one character (default '*')")),
+			mcp.WithString("method", mcp.Description("HTTP method matcher (GET, POST, etc)")),
+			mcp.WithString("resourceType", mcp.Description("Resource type matcher (xhr, fetch, document, script, image, etc)")),
+			mcp.WithString("action", mcp.Description("'fulfill', 'continue' or 'abort' (inferred from other fields when omitted)")),
+			mcp.WithNumber("status", mcp.Description("Response status code for fulfill (default 200)")),
+			mcp.WithObject("headers", mcp.Description("Response headers for fulfill")),
+			mcp.WithString("body", mcp.Description("Response body for fulfill")),
+			mcp.WithObject("requestHeaders", mcp.Description("Request header overrides for continue (empty value removes a header)")),
+			mcp.WithString("errorReason", mcp.Description("Network error reason for abort (e.g. 'Failed', 'TimedOut', 'BlockedByClient')")),
+			mcp.WithNumber("delayMs", mcp.Description("Delay in milliseconds before applying the action (max 60000)")),
+			mcp.WithString("id", mcp.Description("Rule ID (optional; reusing an ID replaces that rule)")),
+		),
+		mcp.NewTool("pinchtab_intercept_list",
+			mcp.WithDescription("List request interception rules on a tab, with hit counts"),
+			mcp.WithString("tabId", mcp.Description("Target tab ID (optional, uses current tab if empty)")),
+		),
+		mcp.NewTool("pinchtab_intercept_remove",
+			mcp.WithDescription("Remove a request interception rule, or all rules on the tab when no id is given"),
+			mcp.WithString("id", mcp.Description("Rule ID to remove (optional, removes all if empty)")),
+			mcp.WithString("tabId", mcp.Description("Target tab ID (optional, uses current tab if empty)")),
+		),
+
 		// ── Dialog ──────────────────────────────────────────────────
 		mcp.NewTool("pinchtab_dialog",
 			mcp.WithDescription("Handle a JavaScript dialog (alert, confirm, prompt). Accept or dismiss the currently open dialog."),
@@ -0,0 +1,261 @@
+//go:build linux
+
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+const (
+	cgroupMount = "/sys/fs/cgroup"
+	// cgroupServerLeaf holds the server process itself. cgroup v2 only
+	// lets a cgroup hand controllers to its children while it has no
+	// processes of its own, so the server moves out of the way first.
+	cgroupServerLeaf = "pinchtab-server"
+)
+
+var cgroupParent struct {
+	once sync.Once
+	dir  string
+	err  error
+}
+
+// instanceCgroupParent returns the cgroup under which instance cgroups are
+// created, with the memory, cpu and pids controllers enabled for its
+// children. It is set up once per process.
+func instanceCgroupParent() (string, error) {
+	cgroupParent.once.Do(func() {
+		cgroupParent.dir, cgroupParent.err = setupCgroupParent()
+	})
+	return cgroupParent.dir, cgroupParent.err
+}
+
+func setupCgroupParent() (string, error) {
+	if _, err := os.Stat(filepath.Join(cgroupMount, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("cgroup v2 is not mounted at %s", cgroupMount)
+	}
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	rel, ok := "", false
+	for _, line := range strings.Split(string(data), "\n") {
+		if p, found := strings.CutPrefix(line, "0::"); found {
+			rel, ok = p, true
+			break
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("process is not in a cgroup v2 hierarchy")
+	}
+	dir := filepath.Join(cgroupMount, filepath.Clean("/"+rel))
+	if filepath.Base(dir) == cgroupServerLeaf {
+		dir = filepath.Dir(dir)
+	}
+	return prepareCgroupParent(dir, os.Getpid())
+}
+
+// prepareCgroupParent enables the memory, cpu and pids controllers of dir
+// for its children. When they are not enabled yet and dir holds the server
+// process pid, the server first moves into the cgroupServerLeaf child. If
+// the controllers still cannot be enabled it is moved back, so a failed
+// setup leaves the server where it started.
+func prepareCgroupParent(dir string, pid int) (string, error) {
+	available, err := os.ReadFile(filepath.Join(dir, "cgroup.controllers"))
+	if err != nil {
+		return "", err
+	}
+	var want []string
+	for _, c := range []string{"memory", "cpu", "pids"} {
+		if containsField(string(available), c) {
+			want = append(want, "+"+c)
+		}
+	}
+	if len(want) == 0 {
+		return "", fmt.Errorf("no memory, cpu or pids controller delegated to %s", dir)
+	}
+
+	enabled, _ := os.ReadFile(filepath.Join(dir, "cgroup.subtree_control"))
+	if allEnabled(string(enabled), want) {
+		return dir, nil
+	}
+	inDir, err := checkCgroupDelegation(dir, pid)
+	if err != nil {
+		return "", err
+	}
+
+	leaf := filepath.Join(dir, cgroupServerLeaf)
+	self := strconv.Itoa(pid)
+	createdLeaf := false
+	if inDir {
+		if _, err := os.Stat(leaf); errors.Is(err, os.ErrNotExist) {
+			if err := os.Mkdir(leaf, 0o755); err != nil {
+				return "", fmt.Errorf("create %s: %w", leaf, err)
+			}
+			createdLeaf = true
+		}
+		if err := writeCgroupFileFunc(leaf, "cgroup.procs", self); err != nil {
+			if createdLeaf {
+				_ = os.Remove(leaf)
+			}
+			return "", fmt.Errorf("move server into %s: %w", leaf, err)
+		}
+	}
+	if err := writeCgroupFileFunc(dir, "cgroup.subtree_control", strings.Join(want, " ")); err != nil {
+		if inDir {
+			if rerr := writeCgroupFileFunc(dir, "cgroup.procs", self); rerr != nil {
+				slog.Warn("failed to move server back after cgroup setup failed", "cgroup", dir, "err", rerr)
+			} else if createdLeaf {
+				_ = os.Remove(leaf)
+			}
+		}
+		return "", fmt.Errorf("enable controllers in %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
+// checkCgroupDelegation checks, before anything is moved, that the server
+// may manage dir: it must be able to write dir's cgroup.procs and
+// cgroup.subtree_control, and dir may hold no process but pid, since
+// controllers cannot be handed to children of a cgroup with processes of
+// its own. It reports whether pid is in dir.
+func checkCgroupDelegation(dir string, pid int) (bool, error) {
+	for _, name := range []string{"cgroup.procs", "cgroup.subtree_control"} {
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY, 0)
+		if err != nil {
+			return false, fmt.Errorf("%s is not delegated to this process: %w", dir, err)
+		}
+		_ = f.Close()
+	}
+	procs, err := os.ReadFile(filepath.Join(dir, "cgroup.procs"))
+	if err != nil {
+		return false, err
+	}
+	self := strconv.Itoa(pid)
+	inDir := false
+	for _, p := range strings.Fields(string(procs)) {
+		if p != self {
+			return false, fmt.Errorf("%s also holds process %s; run the server in a cgroup of its own to apply resource limits", dir, p)
+		}
+		inDir = true
+	}
+	return inDir, nil
+}
+
+func containsField(s, field string) bool {
+	for _, f := range strings.Fields(s) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func allEnabled(enabled string, want []string) bool {
+	for _, w := range want {
+		if !containsField(enabled, strings.TrimPrefix(w, "+")) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeCgroupFileFunc is overridden in tests to make cgroup writes fail.
+var writeCgroupFileFunc = writeCgroupFile
+
+func writeCgroupFile(dir, name, value string) error {
+	return os.WriteFile(filepath.Join(dir, name), []byte(value), 0o644)
+}
+
+// cgroupLimiter enforces limits through a per-instance cgroup. The kernel
+// does the enforcing; the limiter only reads back OOM kills.
+type cgroupLimiter struct {
+	dir         string
+	memoryMaxMB int
+}
+
+// newCgroupLimiter creates the instance cgroup, writes its limits and moves
+// pid into it. Chrome is started lazily by the bridge, so it lands in the
+// same cgroup.
+func newCgroupLimiter(id string, limits bridge.ResourceLimits, pid int) (limiter, error) {
+	parent, err := instanceCgroupParent()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(parent, fmt.Sprintf("pinchtab-%s-%d", id, pid))
+	if err := os.Mkdir(dir, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("create %s: %w", dir, err)
+	}
+	l := &cgroupLimiter{dir: dir, memoryMaxMB: limits.MemoryMaxMB}
+
+	if limits.MemoryMaxMB > 0 {
+		if err := writeCgroupFile(dir, "memory.max", strconv.FormatInt(int64(limits.MemoryMaxMB)*1024*1024, 10)); err != nil {
+			l.release()
+			return nil, fmt.Errorf("set memory.max: %w", err)
+		}
+		// Without this the limit is first absorbed by swap.
+		_ = writeCgroupFile(dir, "memory.swap.max", "0")
+		// Kill the whole instance on OOM rather than one renderer, so the
+		// failure is visible instead of leaving a half-dead browser.
+		_ = writeCgroupFile(dir, "memory.oom.group", "1")
+	}
+	if limits.CPUQuota > 0 {
+		if err := writeCgroupFile(dir, "cpu.max", formatCPUMax(limits.CPUQuota)); err != nil {
+			l.release()
+			return nil, fmt.Errorf("set cpu.max: %w", err)
+		}
+	}
+	if limits.PidsMax > 0 {
+		if err := writeCgroupFile(dir, "pids.max", strconv.Itoa(limits.PidsMax)); err != nil {
+			l.release()
+			return nil, fmt.Errorf("set pids.max: %w", err)
+		}
+	}
+	if err := writeCgroupFile(dir, "cgroup.procs", strconv.Itoa(pid)); err != nil {
+		l.release()
+		return nil, fmt.Errorf("move instance into %s: %w", dir, err)
+	}
+
+	slog.Info("instance resource limits applied", "id", id, "cgroup", dir,
+		"memoryMaxMB", limits.MemoryMaxMB, "cpuQuota", limits.CPUQuota, "pidsMax", limits.PidsMax)
+	return l, nil
+}
+
+func (l *cgroupLimiter) exceeded() string {
+	data, err := os.ReadFile(filepath.Join(l.dir, "memory.events"))
+	if err != nil {
+		return ""
+	}
+	if kills := parseMemoryEvents(string(data))["oom_kill"]; kills > 0 {
+		return oomReason(l.memoryMaxMB, kills)
+	}
+	return ""
+}
+
+// release kills anything left in the cgroup and removes it. A cgroup can
+// only be removed once empty, so it retries briefly.
+func (l *cgroupLimiter) release() {
+	_ = writeCgroupFile(l.dir, "cgroup.kill", "1")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		err := os.Remove(l.dir)
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		if time.Now().After(deadline) {
+			slog.Warn("failed to remove instance cgroup", "cgroup", l.dir, "err", err)
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
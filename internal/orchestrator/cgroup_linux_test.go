@@ -0,0 +1,88 @@
+//go:build linux
+
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCgroupDir lays out the cgroup files prepareCgroupParent reads. Plain
+// files accept any write, so the tests only cover the checks and the order
+// of the moves, not the kernel's own rules.
+func fakeCgroupDir(t *testing.T, procs string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"cgroup.controllers":     "cpuset cpu io memory pids",
+		"cgroup.subtree_control": "",
+		"cgroup.procs":           procs,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func readCgroupFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestPrepareCgroupParent_MovesServerAndEnablesControllers(t *testing.T) {
+	dir := fakeCgroupDir(t, "4242\n")
+
+	got, err := prepareCgroupParent(dir, 4242)
+	if err != nil || got != dir {
+		t.Fatalf("prepareCgroupParent() = %q, %v", got, err)
+	}
+	if procs := readCgroupFile(t, filepath.Join(dir, cgroupServerLeaf), "cgroup.procs"); procs != "4242" {
+		t.Errorf("server should move into %s, got procs %q", cgroupServerLeaf, procs)
+	}
+	if ctl := readCgroupFile(t, dir, "cgroup.subtree_control"); ctl != "+memory +cpu +pids" {
+		t.Errorf("subtree_control = %q", ctl)
+	}
+}
+
+func TestPrepareCgroupParent_SharedCgroupIsLeftAlone(t *testing.T) {
+	dir := fakeCgroupDir(t, "4242\n777\n")
+
+	if _, err := prepareCgroupParent(dir, 4242); err == nil || !strings.Contains(err.Error(), "777") {
+		t.Fatalf("expected an error naming the other process, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, cgroupServerLeaf)); !os.IsNotExist(err) {
+		t.Error("the server must not be moved when the cgroup is shared")
+	}
+}
+
+func TestPrepareCgroupParent_MovesServerBackOnFailure(t *testing.T) {
+	dir := fakeCgroupDir(t, "4242\n")
+	var moves []string
+	old := writeCgroupFileFunc
+	writeCgroupFileFunc = func(d, name, value string) error {
+		switch name {
+		case "cgroup.subtree_control":
+			return errors.New("device or resource busy")
+		case "cgroup.procs":
+			rel, _ := filepath.Rel(dir, d)
+			moves = append(moves, rel)
+		}
+		return writeCgroupFile(d, name, value)
+	}
+	defer func() { writeCgroupFileFunc = old }()
+
+	if _, err := prepareCgroupParent(dir, 4242); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(moves) != 2 || moves[0] != cgroupServerLeaf || moves[1] != "." {
+		t.Errorf("server should move into %s and back, got moves %v", cgroupServerLeaf, moves)
+	}
+}
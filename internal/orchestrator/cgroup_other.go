@@ -0,0 +1,13 @@
+//go:build !linux
+
+package orchestrator
+
+import (
+	"errors"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+func newCgroupLimiter(string, bridge.ResourceLimits, int) (limiter, error) {
+	return nil, errors.New("cgroup v2 is only available on Linux")
+}
@@ -0,0 +1,286 @@
+package orchestrator
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/authn"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/fleet"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	internalurls "github.com/pinchtab/pinchtab/internal/urls"
+)
+
+const (
+	defaultFleetHeartbeatInterval = 10 * time.Second
+	defaultFleetMissedHeartbeats  = 3
+)
+
+// fleetSettings returns the heartbeat interval and how many heartbeats a
+// registered bridge may miss before it is removed.
+func (o *Orchestrator) fleetSettings() (time.Duration, int) {
+	interval := defaultFleetHeartbeatInterval
+	missed := defaultFleetMissedHeartbeats
+	if o.runtimeCfg != nil {
+		if o.runtimeCfg.FleetHeartbeatInterval > 0 {
+			interval = o.runtimeCfg.FleetHeartbeatInterval
+		}
+		if o.runtimeCfg.FleetMissedHeartbeats > 0 {
+			missed = o.runtimeCfg.FleetMissedHeartbeats
+		}
+	}
+	return interval, missed
+}
+
+// checkJoinToken authorizes a fleet request. Registration is disabled
+// until a join token is configured.
+func (o *Orchestrator) checkJoinToken(r *http.Request) (int, error) {
+	want := ""
+	if o.runtimeCfg != nil {
+		want = strings.TrimSpace(o.runtimeCfg.FleetJoinToken)
+	}
+	if want == "" {
+		return 403, fmt.Errorf("fleet registration is disabled")
+	}
+	got := strings.TrimSpace(r.Header.Get(fleet.HeaderJoinToken))
+	if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		return 401, fmt.Errorf("invalid join token")
+	}
+	return 0, nil
+}
+
+func (o *Orchestrator) handleFleetRegister(w http.ResponseWriter, r *http.Request) {
+	if code, err := o.checkJoinToken(r); err != nil {
+		httpx.Error(w, code, err)
+		return
+	}
+	var req fleet.Registration
+	if err := httpx.DecodeJSONBody(w, r, 0, &req); err != nil {
+		httpx.Error(w, httpx.StatusForJSONDecodeError(err), fmt.Errorf("invalid JSON"))
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.BaseURL == "" {
+		httpx.Error(w, 400, fmt.Errorf("name and baseUrl are required"))
+		return
+	}
+	if req.Token == "" {
+		httpx.Error(w, 400, fmt.Errorf("token is required: the bridge needs server.token set to register"))
+		return
+	}
+	if err := fleet.ValidateLabels(req.Labels); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	if err := o.validateAttachURL(req.BaseURL); err != nil {
+		httpx.Error(w, 403, err)
+		return
+	}
+	if err := o.probeAttachBridge(req.BaseURL, req.Token); err != nil {
+		httpx.Error(w, 502, err)
+		return
+	}
+
+	inst, created, err := o.RegisterBridge(req)
+	if err != nil {
+		httpx.Error(w, classifyLaunchError(err), err)
+		return
+	}
+	authn.AuditLog(r, "instance.registered", "instanceId", inst.ID, "instanceName", inst.ProfileName, "created", created)
+
+	interval, missed := o.fleetSettings()
+	status := 200
+	if created {
+		status = 201
+	}
+	httpx.JSON(w, status, fleet.Registered{
+		ID:                   inst.ID,
+		HeartbeatIntervalSec: int(interval / time.Second),
+		MissedHeartbeats:     missed,
+	})
+}
+
+func (o *Orchestrator) handleFleetHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if code, err := o.checkJoinToken(r); err != nil {
+		httpx.Error(w, code, err)
+		return
+	}
+	var req fleet.Heartbeat
+	if err := httpx.DecodeJSONBody(w, r, 0, &req); err != nil {
+		httpx.Error(w, httpx.StatusForJSONDecodeError(err), fmt.Errorf("invalid JSON"))
+		return
+	}
+	if err := fleet.ValidateLabels(req.Labels); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	if err := o.Heartbeat(req); err != nil {
+		httpx.Error(w, fleetErrorStatus(err), err)
+		return
+	}
+
+	interval, missed := o.fleetSettings()
+	httpx.JSON(w, 200, fleet.Registered{
+		ID:                   req.ID,
+		HeartbeatIntervalSec: int(interval / time.Second),
+		MissedHeartbeats:     missed,
+	})
+}
+
+func (o *Orchestrator) handleFleetDeregister(w http.ResponseWriter, r *http.Request) {
+	if code, err := o.checkJoinToken(r); err != nil {
+		httpx.Error(w, code, err)
+		return
+	}
+	var req fleet.Deregistration
+	if err := httpx.DecodeJSONBody(w, r, 0, &req); err != nil {
+		httpx.Error(w, httpx.StatusForJSONDecodeError(err), fmt.Errorf("invalid JSON"))
+		return
+	}
+	o.mu.RLock()
+	inst, err := o.registeredLocked(req.ID, req.Token)
+	o.mu.RUnlock()
+	if err != nil {
+		httpx.Error(w, fleetErrorStatus(err), err)
+		return
+	}
+	o.markStopped(req.ID)
+	o.emitEvent("instance.stopped", &inst)
+	authn.AuditLog(r, "instance.deregistered", "instanceId", inst.ID, "instanceName", inst.ProfileName)
+	httpx.JSON(w, 200, map[string]string{"status": "deregistered", "id": inst.ID})
+}
+
+// RegisterBridge adds a self-registered remote bridge, or refreshes it when
+// a bridge with the same name registers again. Registered bridges are kept
+// alive by heartbeats rather than health polling.
+func (o *Orchestrator) RegisterBridge(reg fleet.Registration) (*bridge.Instance, bool, error) {
+	capacity := reg.Capacity
+	inst, created, err := o.attachExternalInstance(reg.Name, bridge.Instance{
+		Attached:   true,
+		AttachType: "bridge",
+		URL:        normalizeBridgeBaseURL(reg.BaseURL),
+		Registered: true,
+		Labels:     reg.Labels,
+		Capacity:   &capacity,
+	}, reg.Token)
+	if err != nil {
+		return nil, false, err
+	}
+
+	o.mu.Lock()
+	if internal, ok := o.instances[inst.ID]; ok {
+		internal.lastHeartbeat = time.Now()
+	}
+	startReaper := !o.fleetReaping
+	o.fleetReaping = true
+	o.mu.Unlock()
+	if startReaper {
+		go o.runFleetReaper()
+	}
+
+	slog.Info("registered remote bridge", "id", inst.ID, "name", reg.Name, "url", internalurls.RedactForLog(inst.URL), "labels", reg.Labels)
+	o.emitEvent("instance.attached", inst)
+	return inst, created, nil
+}
+
+// Heartbeat records that a registered bridge is alive and updates its
+// capacity, and its labels when the heartbeat carries any. The heartbeat
+// must carry the bridge token the instance registered with.
+func (o *Orchestrator) Heartbeat(hb fleet.Heartbeat) error {
+	o.mu.Lock()
+	if _, err := o.registeredLocked(hb.ID, hb.Token); err != nil {
+		o.mu.Unlock()
+		return err
+	}
+	internal := o.instances[hb.ID]
+	internal.lastHeartbeat = time.Now()
+	capacity := hb.Capacity
+	internal.Capacity = &capacity
+	if hb.Labels != nil {
+		internal.Labels = hb.Labels
+	}
+	result := internal.Instance
+	o.mu.Unlock()
+
+	o.syncInstanceToManager(&result)
+	return nil
+}
+
+// errBridgeToken is returned when a fleet request for a registered
+// instance carries a different bridge token than it registered with.
+var errBridgeToken = errors.New("bridge token does not match the registration")
+
+// registeredLocked returns the active registered instance id, provided
+// token is the bridge token it registered with. o.mu must be held.
+func (o *Orchestrator) registeredLocked(id, token string) (bridge.Instance, error) {
+	internal, ok := o.instances[id]
+	if !ok || !internal.Registered || !instanceIsActive(internal) {
+		return bridge.Instance{}, fmt.Errorf("registered instance %q not found", id)
+	}
+	if internal.authToken == "" || subtle.ConstantTimeCompare([]byte(internal.authToken), []byte(token)) != 1 {
+		return bridge.Instance{}, fmt.Errorf("instance %q: %w", id, errBridgeToken)
+	}
+	return internal.Instance, nil
+}
+
+// fleetErrorStatus maps heartbeat and deregistration errors to a status:
+// 403 for a wrong bridge token, 404 when the bridge is not registered, which
+// tells it to register again.
+func fleetErrorStatus(err error) int {
+	if errors.Is(err, errBridgeToken) {
+		return 403
+	}
+	return 404
+}
+
+// runFleetReaper removes registered bridges that stopped sending
+// heartbeats. It exits once no registered bridges remain; the next
+// registration starts it again.
+func (o *Orchestrator) runFleetReaper() {
+	for {
+		interval, _ := o.fleetSettings()
+		time.Sleep(interval)
+		if !o.expireFleet(time.Now()) {
+			return
+		}
+	}
+}
+
+// expireFleet removes registered bridges whose last heartbeat is older than
+// the allowed number of missed intervals. It reports whether any
+// registered bridges remain.
+func (o *Orchestrator) expireFleet(now time.Time) bool {
+	interval, missed := o.fleetSettings()
+	deadline := time.Duration(missed) * interval
+
+	o.mu.Lock()
+	var expired []bridge.Instance
+	remaining := 0
+	for _, inst := range o.instances {
+		if !inst.Registered || !instanceIsActive(inst) {
+			continue
+		}
+		if now.Sub(inst.lastHeartbeat) > deadline {
+			expired = append(expired, inst.Instance)
+			continue
+		}
+		remaining++
+	}
+	if remaining == 0 {
+		o.fleetReaping = false
+	}
+	o.mu.Unlock()
+
+	for i := range expired {
+		inst := expired[i]
+		slog.Warn("registered bridge missed heartbeats, removing", "id", inst.ID, "name", inst.ProfileName, "missed", missed)
+		o.markStopped(inst.ID)
+		o.emitEvent("instance.stopped", &inst)
+	}
+	return remaining > 0
+}
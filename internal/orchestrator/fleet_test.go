@@ -0,0 +1,217 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/fleet"
+)
+
+func newFleetOrchestrator(t *testing.T) (*Orchestrator, string) {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(backend.Close)
+	backendURL, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	o.client = backend.Client()
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{
+		AttachEnabled:          true,
+		AttachAllowHosts:       []string{backendURL.Hostname()},
+		AttachAllowSchemes:     []string{"http"},
+		FleetJoinToken:         "join-secret",
+		FleetHeartbeatInterval: time.Second,
+		FleetMissedHeartbeats:  3,
+	})
+	return o, backend.URL
+}
+
+func postFleet(t *testing.T, o *Orchestrator, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	o.RegisterHandlers(mux)
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(fleet.HeaderJoinToken, token)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestFleet_RegisterHeartbeatAndExpire(t *testing.T) {
+	o, baseURL := newFleetOrchestrator(t)
+	var mu sync.Mutex
+	var events []string
+	o.OnEvent(func(evt InstanceEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, evt.Type)
+	})
+
+	reg := fleet.Registration{
+		Name:     "edge-eu",
+		BaseURL:  baseURL,
+		Token:    "bridge-secret",
+		Labels:   map[string]string{"region": "eu"},
+		Capacity: bridge.InstanceCapacity{MaxTabs: 10},
+	}
+	if w := postFleet(t, o, fleet.RegisterPath, "wrong", reg); w.Code != 401 {
+		t.Fatalf("wrong join token: expected 401, got %d", w.Code)
+	}
+
+	w := postFleet(t, o, fleet.RegisterPath, "join-secret", reg)
+	if w.Code != 201 {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var registered fleet.Registered
+	if err := json.Unmarshal(w.Body.Bytes(), &registered); err != nil {
+		t.Fatal(err)
+	}
+	if registered.ID == "" || registered.HeartbeatIntervalSec != 1 || registered.MissedHeartbeats != 3 {
+		t.Fatalf("unexpected registration %+v", registered)
+	}
+	if w := postFleet(t, o, fleet.RegisterPath, "join-secret", reg); w.Code != 200 {
+		t.Fatalf("registering again should update in place, got %d", w.Code)
+	}
+	hijack := reg
+	hijack.Token = "other"
+	if w := postFleet(t, o, fleet.RegisterPath, "join-secret", hijack); w.Code != 409 {
+		t.Fatalf("registering over a bridge with another token: expected 409, got %d", w.Code)
+	}
+
+	hb := fleet.Heartbeat{ID: registered.ID, Token: "other", Capacity: bridge.InstanceCapacity{MaxTabs: 10, Tabs: 4}}
+	if w := postFleet(t, o, fleet.HeartbeatPath, "join-secret", hb); w.Code != 403 {
+		t.Fatalf("heartbeat with another bridge token: expected 403, got %d", w.Code)
+	}
+	hb.Token = "bridge-secret"
+	if w := postFleet(t, o, fleet.HeartbeatPath, "join-secret", hb); w.Code != 200 {
+		t.Fatalf("heartbeat: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	list := o.List()
+	if len(list) != 1 || !list[0].Registered || list[0].Labels["region"] != "eu" || list[0].Capacity == nil || list[0].Capacity.Tabs != 4 {
+		t.Fatalf("unexpected instances %+v", list)
+	}
+
+	if !o.expireFleet(time.Now().Add(2 * time.Second)) {
+		t.Fatal("a bridge within its missed-heartbeat window should stay")
+	}
+	if o.expireFleet(time.Now().Add(5 * time.Second)) {
+		t.Fatal("expected no registered bridges to remain")
+	}
+	if len(o.List()) != 0 {
+		t.Fatalf("expected the silent bridge to be removed, got %+v", o.List())
+	}
+	if w := postFleet(t, o, fleet.HeartbeatPath, "join-secret", hb); w.Code != 404 {
+		t.Fatalf("heartbeat for a removed bridge: expected 404, got %d", w.Code)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) == 0 || events[len(events)-1] != "instance.stopped" {
+		t.Errorf("expected an instance.stopped event, got %v", events)
+	}
+}
+
+func TestFleet_Deregister(t *testing.T) {
+	o, baseURL := newFleetOrchestrator(t)
+	inst, _, err := o.RegisterBridge(fleet.Registration{Name: "edge", BaseURL: baseURL, Token: "bridge-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := postFleet(t, o, fleet.DeregisterPath, "join-secret", fleet.Deregistration{ID: inst.ID}); w.Code != 403 {
+		t.Fatalf("deregistering without the bridge token: expected 403, got %d", w.Code)
+	}
+	if w := postFleet(t, o, fleet.DeregisterPath, "join-secret", fleet.Deregistration{ID: inst.ID, Token: "bridge-secret"}); w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(o.List()) != 0 {
+		t.Fatal("expected the bridge to be removed")
+	}
+	if w := postFleet(t, o, fleet.DeregisterPath, "join-secret", fleet.Deregistration{ID: inst.ID, Token: "bridge-secret"}); w.Code != 404 {
+		t.Fatalf("expected 404 for an unknown bridge, got %d", w.Code)
+	}
+}
+
+func TestFleet_RegisterNeedsBridgeToken(t *testing.T) {
+	o, baseURL := newFleetOrchestrator(t)
+	if w := postFleet(t, o, fleet.RegisterPath, "join-secret", fleet.Registration{Name: "edge", BaseURL: baseURL}); w.Code != 400 {
+		t.Fatalf("registering without a bridge token: expected 400, got %d", w.Code)
+	}
+
+	if _, _, err := o.AttachBridge("manual", baseURL, ""); err != nil {
+		t.Fatal(err)
+	}
+	w := postFleet(t, o, fleet.RegisterPath, "join-secret", fleet.Registration{Name: "manual", BaseURL: baseURL, Token: "bridge-secret"})
+	if w.Code != 409 {
+		t.Fatalf("registering over a bridge attached without a token: expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+	if list := o.List(); len(list) != 1 || list[0].Registered {
+		t.Fatalf("the attached bridge should be left as is, got %+v", list)
+	}
+}
+
+func TestFleet_DisabledWithoutJoinToken(t *testing.T) {
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{AttachEnabled: true})
+	w := postFleet(t, o, fleet.RegisterPath, "anything", fleet.Registration{Name: "edge", BaseURL: "http://10.0.0.8:9868"})
+	if w.Code != 403 {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+}
+
+func TestTargetURL_RoutesByLabels(t *testing.T) {
+	o, baseURL := newFleetOrchestrator(t)
+	if _, _, err := o.RegisterBridge(fleet.Registration{
+		Name:    "edge-eu",
+		BaseURL: baseURL,
+		Labels:  map[string]string{"region": "eu", "gpu": "false"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	local := &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_local", Status: "running", StartTime: time.Unix(0, 0)},
+		URL:      "http://127.0.0.1:1",
+		cmd:      &mockCmd{pid: 1, isAlive: true},
+	}
+	o.instances[local.ID] = local
+	o.syncInstanceToManager(&local.Instance)
+	orig := processAliveFunc
+	processAliveFunc = func(pid int) bool { return true }
+	defer func() { processAliveFunc = orig }()
+
+	req := func(labels string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/snapshot", nil)
+		if labels != "" {
+			r.Header.Set(fleet.HeaderLabels, labels)
+		}
+		return r
+	}
+	if got := o.TargetURL(req("")); got != local.URL {
+		t.Fatalf("without labels fcfs should pick the oldest instance, got %s", got)
+	}
+	if got := o.TargetURL(req("region=eu,gpu=false")); got != baseURL {
+		t.Fatalf("expected the labelled bridge, got %s", got)
+	}
+	if got := o.TargetURL(req("region=us")); got != "" {
+		t.Fatalf("unmatched labels must not fall back, got %s", got)
+	}
+	if got := o.TargetURL(req("region")); got != "" {
+		t.Fatalf("malformed labels must not fall back, got %s", got)
+	}
+}
@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"net/http"
 
+	"github.com/pinchtab/pinchtab/internal/fleet"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
@@ -43,6 +44,11 @@ func (o *Orchestrator) registerHandlers(mux *http.ServeMux, skipLaunch bool) {
 	}
 	mux.HandleFunc("POST /instances/attach", o.handleAttachInstance)
 	mux.HandleFunc("POST /instances/attach-bridge", o.handleAttachBridge)
+
+	// Fleet registration (authorized by the join token, not the server token)
+	mux.HandleFunc("POST "+fleet.RegisterPath, o.handleFleetRegister)
+	mux.HandleFunc("POST "+fleet.HeartbeatPath, o.handleFleetHeartbeat)
+	mux.HandleFunc("POST "+fleet.DeregisterPath, o.handleFleetDeregister)
 	if !skipLaunch {
 		mux.HandleFunc("POST /instances/{id}/start", o.handleStartByInstanceID)
 	}
@@ -52,6 +58,9 @@ func (o *Orchestrator) registerHandlers(mux *http.ServeMux, skipLaunch bool) {
 	mux.HandleFunc("GET /instances/{id}/tabs", o.handleInstanceTabs)
 	mux.HandleFunc("POST /instances/{id}/tabs/open", o.handleInstanceTabOpen)
 	mux.HandleFunc("POST /instances/{id}/tab", o.proxyToInstance)
+	mux.HandleFunc("POST /instances/{id}/state/export", o.proxyToInstance)
+	mux.HandleFunc("POST /instances/{id}/state/import", o.proxyToInstance)
+	mux.HandleFunc("POST /instances/{id}/workflows/run", o.proxyToInstance)
 	registerCapabilityRoute(mux, "GET /instances/{id}/proxy/screencast", o.AllowsScreencast(), "screencast", "security.allowScreencast", "screencast_disabled", o.handleProxyScreencast)
 	registerCapabilityRoute(mux, "GET /instances/{id}/screencast", o.AllowsScreencast(), "screencast", "security.allowScreencast", "screencast_disabled", o.proxyToInstance)
 
@@ -72,12 +81,26 @@ func (o *Orchestrator) registerHandlers(mux *http.ServeMux, skipLaunch bool) {
 		"POST /tabs/{id}/unlock",
 		"GET /tabs/{id}/cookies",
 		"POST /tabs/{id}/cookies",
+		"GET /tabs/{id}/storage",
+		"POST /tabs/{id}/storage",
+		"DELETE /tabs/{id}/storage",
 		"GET /tabs/{id}/metrics",
 		"POST /tabs/{id}/find",
+		"POST /tabs/{id}/extract",
+		"POST /tabs/{id}/screenshot/compare",
+		"POST /tabs/{id}/emulate",
 		"POST /tabs/{id}/back",
 		"POST /tabs/{id}/forward",
 		"POST /tabs/{id}/reload",
 		"POST /tabs/{id}/wait",
+		"GET /tabs/{id}/intercept",
+		"POST /tabs/{id}/intercept",
+		"DELETE /tabs/{id}/intercept",
+		"DELETE /tabs/{id}/intercept/{ruleId}",
+		"GET /tabs/{id}/network/har",
+		"GET /tabs/{id}/network/har/replay",
+		"POST /tabs/{id}/network/har/replay",
+		"DELETE /tabs/{id}/network/har/replay",
 	} {
 		mux.HandleFunc(route, o.proxyTabRequest)
 	}
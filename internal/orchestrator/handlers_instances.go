@@ -9,14 +9,27 @@ import (
 	"time"
 
 	"github.com/pinchtab/pinchtab/internal/authn"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/devices"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
 type startInstanceRequest struct {
-	ProfileID      string   `json:"profileId,omitempty"`
-	Mode           string   `json:"mode,omitempty"`
-	Port           string   `json:"port,omitempty"`
-	ExtensionPaths []string `json:"extensionPaths,omitempty"`
+	ProfileID      string                `json:"profileId,omitempty"`
+	Mode           string                `json:"mode,omitempty"`
+	Port           string                `json:"port,omitempty"`
+	ExtensionPaths []string              `json:"extensionPaths,omitempty"`
+	Limits         bridge.ResourceLimits `json:"limits"`
+	Device         string                `json:"device,omitempty"`
+}
+
+// validateDevice accepts an empty device, which keeps the configured
+// default, or a known preset name.
+func validateDevice(device string) error {
+	if device != "" && !devices.Valid(device) {
+		return fmt.Errorf("unknown device %q (must be one of %s)", device, strings.Join(devices.Names(), ", "))
+	}
+	return nil
 }
 
 func (o *Orchestrator) handleGetInstance(w http.ResponseWriter, r *http.Request) {
@@ -89,6 +102,11 @@ func (o *Orchestrator) handleStartByInstanceID(w http.ResponseWriter, r *http.Re
 	port := inst.Port
 	profileName := inst.ProfileName
 	headless := inst.Headless
+	var limits bridge.ResourceLimits
+	if inst.Limits != nil {
+		limits = *inst.Limits
+	}
+	device := inst.Device
 	o.mu.RUnlock()
 
 	if inst.Attached && inst.AttachType != "bridge" {
@@ -111,7 +129,7 @@ func (o *Orchestrator) handleStartByInstanceID(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	started, err := o.Launch(profileName, port, headless, nil)
+	started, err := o.launch(profileName, port, headless, nil, limits, device, "")
 	if err != nil {
 		statusCode := classifyLaunchError(err)
 		httpx.Error(w, statusCode, err)
@@ -229,7 +247,16 @@ func (o *Orchestrator) startInstanceWithRequest(w http.ResponseWriter, r *http.R
 
 	headless := req.Mode != "headed"
 
-	inst, err := o.Launch(profileName, req.Port, headless, req.ExtensionPaths)
+	if err := validateLimits(req.Limits); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	if err := validateDevice(req.Device); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+
+	inst, err := o.launch(profileName, req.Port, headless, req.ExtensionPaths, req.Limits, req.Device, "")
 	if err != nil {
 		statusCode := classifyLaunchError(err)
 		httpx.Error(w, statusCode, err)
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/profiles"
 )
 
@@ -64,3 +66,61 @@ func TestHandleLaunchByNameAliasesStartSemantics(t *testing.T) {
 		t.Fatal("Headless = true, want false for mode=headed")
 	}
 }
+
+func TestStartInstance_DeviceOverridesDefault(t *testing.T) {
+	old := processAliveFunc
+	processAliveFunc = func(pid int) bool { return pid > 0 }
+	defer func() { processAliveFunc = old }()
+	stubPortAvailability(t, func(int) bool { return true })
+
+	runner := &mockRunner{portAvail: true}
+	o := NewOrchestratorWithRunner(t.TempDir(), runner)
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{StateDir: t.TempDir(), InstancePortStart: 9920, InstancePortEnd: 9929, Device: "iphone-15"})
+	mux := http.NewServeMux()
+	o.RegisterHandlers(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/instances/start", strings.NewReader(`{"device":"pixel-8"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var inst bridge.Instance
+	if err := json.NewDecoder(w.Body).Decode(&inst); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if inst.Device != "pixel-8" {
+		t.Errorf("Device = %q, want pixel-8", inst.Device)
+	}
+
+	data, err := os.ReadFile(envMap(runner.env)["PINCHTAB_CONFIG"])
+	if err != nil {
+		t.Fatalf("read child config: %v", err)
+	}
+	var fc config.FileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatalf("decode child config: %v", err)
+	}
+	if fc.InstanceDefaults.Device != "pixel-8" {
+		t.Errorf("child instanceDefaults.device = %q, want pixel-8", fc.InstanceDefaults.Device)
+	}
+}
+
+func TestStartInstance_RejectsUnknownDevice(t *testing.T) {
+	runner := &mockRunner{portAvail: true}
+	o := NewOrchestratorWithRunner(t.TempDir(), runner)
+	mux := http.NewServeMux()
+	o.RegisterHandlers(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/instances/start", strings.NewReader(`{"device":"nokia-3310"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+	if runner.runCalled {
+		t.Fatal("an unknown device should not launch an instance")
+	}
+}
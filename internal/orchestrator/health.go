@@ -101,7 +101,10 @@ func (o *Orchestrator) monitor(inst *InstanceInternal) {
 			slog.Info("instance ready", "id", inst.ID, "port", inst.Port)
 		} else if exitedEarly {
 			inst.Status = "error"
-			if waitErr != nil {
+			if reason := limitExceeded(inst); reason != "" {
+				inst.Error = reason
+				inst.Instance.Error = reason
+			} else if waitErr != nil {
 				inst.Error = "process exited before health check: " + waitErr.Error()
 			} else {
 				inst.Error = "process exited before health check succeeded"
@@ -126,24 +129,49 @@ func (o *Orchestrator) monitor(inst *InstanceInternal) {
 	if eventType != "" {
 		o.emitEvent(eventType, &instCopy)
 	}
+	if eventType == "instance.started" {
+		o.startMemoryWatch(inst)
+	}
 
 	if !exitedEarly {
 		<-waitCh
 	}
+	reason := limitExceeded(inst)
 	o.mu.Lock()
-	wasStopped := false
+	eventType = ""
 	if inst.Status == "running" || inst.Status == "stopping" {
-		inst.Status = "stopped"
-		wasStopped = true
+		if reason != "" {
+			inst.Status = "error"
+			inst.Error = reason
+			inst.Instance.Error = reason
+			eventType = "instance.error"
+			slog.Error("instance killed for exceeding its resource limits", "id", inst.ID, "reason", reason)
+		} else {
+			inst.Status = "stopped"
+			// A recycle is reported once the instance is back, so strategies
+			// don't mistake the stop for a crash.
+			if !inst.recycling {
+				eventType = "instance.stopped"
+			}
+		}
 	}
 	instCopy = inst.Instance
 	o.mu.Unlock()
-	if wasStopped {
-		o.emitEvent("instance.stopped", &instCopy)
+	if eventType != "" {
+		o.emitEvent(eventType, &instCopy)
 	}
 	slog.Info("instance exited", "id", inst.ID)
 }
 
+// limitExceeded returns why a launched instance was killed for breaching
+// its resource limits, or "" when it was not.
+func limitExceeded(inst *InstanceInternal) string {
+	if inst.limiter == nil {
+		return ""
+	}
+	return inst.limiter.exceeded()
+}
+
 func (o *Orchestrator) monitorAttachedBridge(inst *InstanceInternal) {
 	ticker := time.NewTicker(attachedBridgeHealthPollInterval)
 	defer ticker.Stop()
@@ -238,6 +266,7 @@ type remoteMetrics struct {
 }
 
 type memoryMetrics struct {
+	MemoryMB      float64 `json:"memoryMB"`
 	JSHeapUsedMB  float64 `json:"jsHeapUsedMB"`
 	JSHeapTotalMB float64 `json:"jsHeapTotalMB"`
 	Documents     int64   `json:"documents"`
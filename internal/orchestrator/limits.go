@@ -0,0 +1,231 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+const (
+	minLimitMemoryMaxMB = 128
+	minLimitCPUQuota    = 0.01
+
+	// cpuMaxPeriod is the cgroup cpu.max period in microseconds.
+	cpuMaxPeriod = 100000
+
+	rssPollInterval = 2 * time.Second
+)
+
+// limiter enforces resource limits on one launched instance.
+type limiter interface {
+	// exceeded returns why the instance was killed for breaching a limit,
+	// or "" when it was not.
+	exceeded() string
+	// release stops enforcement once the instance is gone.
+	release()
+}
+
+// effectiveLimits fills unset fields in override from the configured
+// instance defaults.
+func (o *Orchestrator) effectiveLimits(override bridge.ResourceLimits) bridge.ResourceLimits {
+	limits := override
+	if o.runtimeCfg == nil {
+		return limits
+	}
+	if limits.MemoryMaxMB <= 0 {
+		limits.MemoryMaxMB = o.runtimeCfg.InstanceMemoryMaxMB
+	}
+	if limits.CPUQuota <= 0 {
+		limits.CPUQuota = o.runtimeCfg.InstanceCPUQuota
+	}
+	if limits.PidsMax <= 0 {
+		limits.PidsMax = o.runtimeCfg.InstancePidsMax
+	}
+	return limits
+}
+
+func validateLimits(limits bridge.ResourceLimits) error {
+	if limits.MemoryMaxMB < 0 || (limits.MemoryMaxMB > 0 && limits.MemoryMaxMB < minLimitMemoryMaxMB) {
+		return fmt.Errorf("limits.memoryMaxMB must be 0 (default) or >= %d", minLimitMemoryMaxMB)
+	}
+	if limits.CPUQuota < 0 || (limits.CPUQuota > 0 && limits.CPUQuota < minLimitCPUQuota) {
+		return fmt.Errorf("limits.cpuQuota must be 0 (default) or >= %g", minLimitCPUQuota)
+	}
+	if limits.PidsMax < 0 {
+		return fmt.Errorf("limits.pidsMax must be >= 0")
+	}
+	return nil
+}
+
+// newInstanceLimiter puts pid under limits. It prefers a cgroup v2 subtree,
+// which also covers the Chrome processes the bridge starts later. Without
+// cgroups only memoryMaxMB can be enforced, by polling RSS and killing the
+// instance. It returns nil when nothing is enforced.
+func newInstanceLimiter(id string, limits bridge.ResourceLimits, pid int) limiter {
+	if limits.IsZero() || pid <= 0 {
+		return nil
+	}
+	cg, err := newCgroupLimiter(id, limits, pid)
+	if err == nil {
+		return cg
+	}
+	slog.Warn("cgroup v2 unavailable for instance limits, falling back to RSS polling", "id", id, "err", err)
+	if limits.CPUQuota > 0 || limits.PidsMax > 0 {
+		slog.Warn("cpuQuota and pidsMax are not enforced without cgroup v2", "id", id)
+	}
+	if limits.MemoryMaxMB <= 0 {
+		return nil
+	}
+	return newRSSLimiter(id, limits.MemoryMaxMB, pid, processTreeRSS, killProcessTree)
+}
+
+// formatCPUMax renders a quota in cores as a cgroup cpu.max value.
+func formatCPUMax(cores float64) string {
+	if cores <= 0 {
+		return "max " + strconv.Itoa(cpuMaxPeriod)
+	}
+	quota := int64(cores * cpuMaxPeriod)
+	if quota < 1000 {
+		quota = 1000
+	}
+	return fmt.Sprintf("%d %d", quota, cpuMaxPeriod)
+}
+
+// parseMemoryEvents reads the counters of a cgroup memory.events file.
+func parseMemoryEvents(data string) map[string]int64 {
+	events := make(map[string]int64)
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if n, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			events[fields[0]] = n
+		}
+	}
+	return events
+}
+
+func oomReason(memoryMaxMB int, oomKills int64) string {
+	if memoryMaxMB <= 0 {
+		return fmt.Sprintf("out of memory: instance processes were OOM-killed (cgroup oom_kill=%d)", oomKills)
+	}
+	return fmt.Sprintf("out of memory: instance exceeded memoryMaxMB=%d (cgroup oom_kill=%d)", memoryMaxMB, oomKills)
+}
+
+// rssLimiter polls the resident memory of the bridge and its descendants
+// and kills the whole tree once it passes the limit.
+type rssLimiter struct {
+	id       string
+	maxBytes uint64
+	pid      int
+	rss      func(pid int) (uint64, error)
+	kill     func(pid int)
+
+	mu     sync.Mutex
+	reason string
+	stop   chan struct{}
+	once   sync.Once
+}
+
+func newRSSLimiter(id string, memoryMaxMB, pid int, rss func(int) (uint64, error), kill func(int)) *rssLimiter {
+	l := &rssLimiter{
+		id:       id,
+		maxBytes: uint64(memoryMaxMB) * 1024 * 1024,
+		pid:      pid,
+		rss:      rss,
+		kill:     kill,
+		stop:     make(chan struct{}),
+	}
+	go l.run(rssPollInterval)
+	return l
+}
+
+func (l *rssLimiter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+		if l.check() {
+			return
+		}
+	}
+}
+
+// check kills the instance when it is over the limit and reports whether
+// it did.
+func (l *rssLimiter) check() bool {
+	used, err := l.rss(l.pid)
+	if err != nil || used <= l.maxBytes {
+		return false
+	}
+	reason := fmt.Sprintf("out of memory: instance RSS %d MB exceeded memoryMaxMB=%d", used/(1024*1024), l.maxBytes/(1024*1024))
+	l.mu.Lock()
+	l.reason = reason
+	l.mu.Unlock()
+	slog.Error("instance over memory limit, killing", "id", l.id, "pid", l.pid, "reason", reason)
+	l.kill(l.pid)
+	return true
+}
+
+func (l *rssLimiter) exceeded() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.reason
+}
+
+func (l *rssLimiter) release() {
+	l.once.Do(func() { close(l.stop) })
+}
+
+// processTreeRSS sums the resident memory of pid and all its descendants.
+func processTreeRSS(pid int) (uint64, error) {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return 0, err
+	}
+	var total uint64
+	for _, proc := range append([]*process.Process{p}, descendants(p)...) {
+		if mem, err := proc.MemoryInfo(); err == nil && mem != nil {
+			total += mem.RSS
+		}
+	}
+	return total, nil
+}
+
+// killProcessTree kills pid's descendants, then its process group. Chrome
+// helpers run in their own process groups, so the group kill alone would
+// miss them.
+func killProcessTree(pid int) {
+	if p, err := process.NewProcess(int32(pid)); err == nil {
+		for _, child := range descendants(p) {
+			_ = child.Kill()
+		}
+	}
+	if err := killProcessGroup(pid, sigKILL); err != nil {
+		slog.Warn("failed to kill instance process group", "pid", pid, "err", err)
+	}
+}
+
+func descendants(p *process.Process) []*process.Process {
+	children, err := p.Children()
+	if err != nil {
+		return nil
+	}
+	out := make([]*process.Process, 0, len(children))
+	for _, child := range children {
+		out = append(out, child)
+		out = append(out, descendants(child)...)
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package orchestrator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestFormatCPUMax(t *testing.T) {
+	tests := []struct {
+		cores float64
+		want  string
+	}{
+		{0, "max 100000"},
+		{1, "100000 100000"},
+		{1.5, "150000 100000"},
+		{0.001, "1000 100000"},
+	}
+	for _, tt := range tests {
+		if got := formatCPUMax(tt.cores); got != tt.want {
+			t.Errorf("formatCPUMax(%g) = %q, want %q", tt.cores, got, tt.want)
+		}
+	}
+}
+
+func TestParseMemoryEvents(t *testing.T) {
+	events := parseMemoryEvents("low 0\nhigh 12\nmax 40\noom 2\noom_kill 1\noom_group_kill 1\n")
+	if events["oom_kill"] != 1 || events["max"] != 40 || events["low"] != 0 {
+		t.Fatalf("unexpected events %v", events)
+	}
+	if _, ok := parseMemoryEvents("garbage")["garbage"]; ok {
+		t.Fatal("lines without a counter should be skipped")
+	}
+}
+
+func TestEffectiveLimits(t *testing.T) {
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{InstanceMemoryMaxMB: 2048, InstanceCPUQuota: 2, InstancePidsMax: 256})
+
+	got := o.effectiveLimits(bridge.ResourceLimits{MemoryMaxMB: 512})
+	want := bridge.ResourceLimits{MemoryMaxMB: 512, CPUQuota: 2, PidsMax: 256}
+	if got != want {
+		t.Fatalf("effectiveLimits() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRSSLimiter_KillsOverLimit(t *testing.T) {
+	var mu sync.Mutex
+	rss := uint64(100 * 1024 * 1024)
+	var killed []int
+	l := &rssLimiter{
+		id:       "inst_test",
+		maxBytes: 256 * 1024 * 1024,
+		pid:      4242,
+		rss: func(int) (uint64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return rss, nil
+		},
+		kill: func(pid int) {
+			mu.Lock()
+			defer mu.Unlock()
+			killed = append(killed, pid)
+		},
+		stop: make(chan struct{}),
+	}
+
+	if l.check() || l.exceeded() != "" {
+		t.Fatal("an instance under its limit must not be killed")
+	}
+	mu.Lock()
+	rss = 300 * 1024 * 1024
+	mu.Unlock()
+	if !l.check() {
+		t.Fatal("expected the instance to be killed over its limit")
+	}
+	if len(killed) != 1 || killed[0] != 4242 {
+		t.Fatalf("expected pid 4242 to be killed, got %v", killed)
+	}
+	if reason := l.exceeded(); !strings.Contains(reason, "RSS 300 MB exceeded memoryMaxMB=256") {
+		t.Fatalf("unexpected reason %q", reason)
+	}
+	l.release()
+	l.release()
+}
+
+type fakeLimiter struct{ reason string }
+
+func (f *fakeLimiter) exceeded() string { return f.reason }
+func (f *fakeLimiter) release()         {}
+
+func TestMonitor_ReportsLimitExceeded(t *testing.T) {
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	events := make(chan InstanceEvent, 4)
+	o.OnEvent(func(evt InstanceEvent) { events <- evt })
+
+	inst := &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_oom", ProfileName: "oom", Port: "9999", Status: "starting"},
+		cmd:      &mockCmd{pid: 1234},
+		logBuf:   newRingBuffer(1024),
+		limiter:  &fakeLimiter{reason: oomReason(512, 1)},
+	}
+	o.instances[inst.ID] = inst
+	o.monitor(inst)
+
+	select {
+	case evt := <-events:
+		if evt.Type != "instance.error" || !strings.Contains(evt.Instance.Error, "memoryMaxMB=512") {
+			t.Fatalf("unexpected event %s: %+v", evt.Type, evt.Instance)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an instance.error event")
+	}
+	if inst.Status != "error" || !strings.HasPrefix(inst.Error, "out of memory") {
+		t.Fatalf("expected an out of memory error, got %s %q", inst.Status, inst.Error)
+	}
+}
+
+func TestStartInstance_RejectsInvalidLimits(t *testing.T) {
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	mux := http.NewServeMux()
+	o.RegisterHandlers(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/instances/start", strings.NewReader(`{"limits":{"memoryMaxMB":16}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -17,18 +17,21 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pinchtab/pinchtab/internal/activity"
 	"github.com/pinchtab/pinchtab/internal/api/types"
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/fleet"
 	"github.com/pinchtab/pinchtab/internal/ids"
 	"github.com/pinchtab/pinchtab/internal/instance"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
 	"github.com/pinchtab/pinchtab/internal/profiles"
 	internalurls "github.com/pinchtab/pinchtab/internal/urls"
 )
 
 // InstanceEvent is emitted when instance state changes.
 type InstanceEvent struct {
-	Type     string           `json:"type"` // "instance.started", "instance.stopped", "instance.error"
+	Type     string           `json:"type"` // "instance.started", "instance.stopped", "instance.error", "instance.recycled"
 	Instance *bridge.Instance `json:"instance"`
 }
 
@@ -50,6 +53,14 @@ type Orchestrator struct {
 	eventHandlers  []EventHandler
 	instanceMgr    *instance.Manager
 	runtimeCfg     *config.RuntimeConfig
+	fleetReaping   bool
+
+	// pins maps shorthand callers to the instance they were routed to.
+	pinMu sync.Mutex
+	pins  map[string]shorthandPin
+
+	// recycles holds the recycle backoff per instance ID, under mu.
+	recycles map[string]recycleHistory
 }
 
 // OnEvent adds an event handler for instance lifecycle events.
@@ -82,10 +93,15 @@ type InstanceInternal struct {
 	URL   string
 	Error string
 
-	authToken string
-	cdpPort   int
-	cmd       Cmd
-	logBuf    *ringBuffer
+	authToken     string
+	cdpPort       int
+	cmd           Cmd
+	logBuf        *ringBuffer
+	lastHeartbeat time.Time
+	limiter       limiter
+	// extensionPaths and recycling support restarting the instance in place.
+	extensionPaths []string
+	recycling      bool
 }
 
 func NewOrchestrator(baseDir string) *Orchestrator {
@@ -145,6 +161,7 @@ func NewOrchestratorWithRunner(baseDir string, runner HostRunner) *Orchestrator
 		&orchestratorLauncher{orch: orch},
 		bridgeClient,
 	)
+	orch.instanceMgr.Allocator.SetLoadSource(orch.InstanceLoads)
 
 	return orch
 }
@@ -241,6 +258,23 @@ func installStableBinary(src, dst string) error {
 }
 
 func (o *Orchestrator) Launch(name, port string, headless bool, extensionPaths []string) (*bridge.Instance, error) {
+	return o.LaunchWithLimits(name, port, headless, extensionPaths, bridge.ResourceLimits{})
+}
+
+// LaunchWithLimits launches an instance under resource limits. Unset limits
+// fall back to the configured instance defaults.
+func (o *Orchestrator) LaunchWithLimits(name, port string, headless bool, extensionPaths []string, limits bridge.ResourceLimits) (*bridge.Instance, error) {
+	return o.launch(name, port, headless, extensionPaths, limits, "", "")
+}
+
+// launch starts an instance process. A non-empty device overrides the
+// configured device preset for the instance's tabs. A non-empty instanceID
+// reuses that ID, which Recycle relies on; otherwise a new one is generated.
+func (o *Orchestrator) launch(name, port string, headless bool, extensionPaths []string, limits bridge.ResourceLimits, device, instanceID string) (*bridge.Instance, error) {
+	if err := validateLimits(limits); err != nil {
+		return nil, err
+	}
+	limits = o.effectiveLimits(limits)
 	// Validate profile name to prevent path traversal attacks
 	if err := profiles.ValidateProfileName(name); err != nil {
 		return nil, err
@@ -295,7 +329,9 @@ func (o *Orchestrator) Launch(name, port string, headless bool, extensionPaths [
 	}
 
 	profileID := o.idMgr.ProfileID(name)
-	instanceID := o.idMgr.InstanceID(profileID, name)
+	if instanceID == "" {
+		instanceID = o.idMgr.InstanceID(profileID, name)
+	}
 
 	if inst, ok := o.instances[instanceID]; ok && inst.Status == "running" {
 		o.mu.Unlock()
@@ -324,7 +360,7 @@ func (o *Orchestrator) Launch(name, port string, headless bool, extensionPaths [
 		return nil, fmt.Errorf("create state dir: %w", err)
 	}
 
-	childConfigPath, err := o.writeChildConfig(port, cdpPort, profilePath, instanceStateDir, headless, extensionPaths)
+	childConfigPath, err := o.writeChildConfig(port, cdpPort, profilePath, instanceStateDir, headless, extensionPaths, device)
 	if err != nil {
 		return nil, fmt.Errorf("write child config: %w", err)
 	}
@@ -333,6 +369,15 @@ func (o *Orchestrator) Launch(name, port string, headless bool, extensionPaths [
 		"PINCHTAB_PORT":   port,
 		"PINCHTAB_CONFIG": childConfigPath,
 	}
+	// Without a token each instance would sign state bundles with a key of
+	// its own; hand down the server's so bundles move between instances.
+	if o.runtimeCfg != nil && o.runtimeCfg.Token == "" {
+		if key, err := bridge.StateBundleKey(o.runtimeCfg); err == nil {
+			envOverrides["PINCHTAB_STATE_BUNDLE_KEY"] = string(key)
+		} else {
+			slog.Warn("no shared state bundle key for instance", "profile", name, "err", err)
+		}
+	}
 	env := mergeEnvWithOverrides(filterEnvWithPrefixes(os.Environ(), "PINCHTAB_"), envOverrides)
 
 	logBuf := newRingBuffer(256 * 1024)
@@ -353,24 +398,37 @@ func (o *Orchestrator) Launch(name, port string, headless bool, extensionPaths [
 			Headless:    headless,
 			Status:      "starting",
 			StartTime:   time.Now(),
+			Device:      device,
 		},
-		URL:     fmt.Sprintf("http://localhost:%s", port),
-		cdpPort: cdpPort,
-		cmd:     cmd,
-		logBuf:  logBuf,
+		URL:            fmt.Sprintf("http://localhost:%s", port),
+		cdpPort:        cdpPort,
+		cmd:            cmd,
+		logBuf:         logBuf,
+		extensionPaths: extensionPaths,
+	}
+	if !limits.IsZero() {
+		inst.Limits = &limits
+		inst.limiter = newInstanceLimiter(instanceID, limits, cmd.PID())
 	}
 
 	o.mu.Lock()
+	var replaced limiter
+	if prev, ok := o.instances[instanceID]; ok {
+		replaced = prev.limiter
+	}
 	o.instances[instanceID] = inst
 	o.mu.Unlock()
 	reservedPorts = nil
+	if replaced != nil {
+		replaced.release()
+	}
 
 	go o.monitor(inst)
 
 	return &inst.Instance, nil
 }
 
-func (o *Orchestrator) writeChildConfig(port string, cdpPort int, profilePath, instanceStateDir string, headless bool, extensionPaths []string) (string, error) {
+func (o *Orchestrator) writeChildConfig(port string, cdpPort int, profilePath, instanceStateDir string, headless bool, extensionPaths []string, device string) (string, error) {
 	fc := config.FileConfigFromRuntime(o.runtimeCfg)
 	fc.Server.Port = port
 	fc.Server.StateDir = instanceStateDir
@@ -382,6 +440,9 @@ func (o *Orchestrator) writeChildConfig(port string, cdpPort int, profilePath, i
 	} else {
 		fc.InstanceDefaults.Mode = "headed"
 	}
+	if device != "" {
+		fc.InstanceDefaults.Device = device
+	}
 
 	if len(extensionPaths) > 0 {
 		seen := make(map[string]bool)
@@ -427,6 +488,13 @@ func (o *Orchestrator) attachExternalInstance(name string, inst bridge.Instance,
 	for _, existing := range o.instances {
 		if existing.ProfileName == name && instanceIsActive(existing) {
 			if existing.Attached && inst.AttachType == "bridge" && existing.AttachType == "bridge" {
+				// Without a token there is nothing to bind a registration
+				// to, so heartbeats could not be told apart from anyone
+				// else holding the join token.
+				if inst.Registered && existing.authToken == "" {
+					o.mu.Unlock()
+					return nil, false, fmt.Errorf("bridge %q is already attached without a token and cannot be registered", name)
+				}
 				if existing.authToken != "" && subtle.ConstantTimeCompare([]byte(existing.authToken), []byte(authToken)) != 1 {
 					o.mu.Unlock()
 					return nil, false, fmt.Errorf("bridge %q already attached: token mismatch", name)
@@ -434,6 +502,11 @@ func (o *Orchestrator) attachExternalInstance(name string, inst bridge.Instance,
 				existing.URL = inst.URL
 				existing.Instance.URL = inst.URL
 				existing.authToken = authToken
+				if inst.Registered {
+					existing.Registered = true
+					existing.Labels = inst.Labels
+					existing.Capacity = inst.Capacity
+				}
 				existing.Status = "running"
 				existing.Error = ""
 				existing.StartTime = time.Now()
@@ -495,15 +568,10 @@ func (o *Orchestrator) Attach(name, cdpURL string) (*bridge.Instance, error) {
 // If a bridge with the same name is already attached, it is updated in place (upsert)
 // provided the caller presents the current bridge token.
 func (o *Orchestrator) AttachBridge(name, baseURL, token string) (*bridge.Instance, bool, error) {
-	normalizedBaseURL := strings.TrimRight(baseURL, "/")
-	if parsed, err := url.Parse(normalizedBaseURL); err == nil && parsed.Scheme != "" && parsed.Host != "" {
-		normalizedBaseURL = parsed.Scheme + "://" + parsed.Host
-	}
-
 	inst, created, err := o.attachExternalInstance(name, bridge.Instance{
 		Attached:   true,
 		AttachType: "bridge",
-		URL:        normalizedBaseURL,
+		URL:        normalizeBridgeBaseURL(baseURL),
 	}, token)
 	if err != nil {
 		return nil, false, err
@@ -522,6 +590,15 @@ func (o *Orchestrator) AttachBridge(name, baseURL, token string) (*bridge.Instan
 	return inst, created, nil
 }
 
+// normalizeBridgeBaseURL reduces a bridge base URL to scheme and host.
+func normalizeBridgeBaseURL(baseURL string) string {
+	normalized := strings.TrimRight(baseURL, "/")
+	if parsed, err := url.Parse(normalized); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		normalized = parsed.Scheme + "://" + parsed.Host
+	}
+	return normalized
+}
+
 func (o *Orchestrator) Stop(id string) error {
 	o.mu.Lock()
 	inst, ok := o.instances[id]
@@ -646,9 +723,18 @@ func (o *Orchestrator) markStopped(id string) {
 	}
 
 	profileName := inst.ProfileName
+	lim := inst.limiter
+	recycling := inst.recycling
 	delete(o.instances, id)
+	if !recycling {
+		delete(o.recycles, id)
+	}
 	o.mu.Unlock()
 
+	if lim != nil {
+		lim.release()
+	}
+
 	if o.instanceMgr != nil {
 		o.instanceMgr.Locator.InvalidateInstance(id)
 		o.instanceMgr.Repo.Remove(id)
@@ -662,7 +748,8 @@ func (o *Orchestrator) markStopped(id string) {
 	profilePath := filepath.Join(o.baseDir, profileName)
 	bridge.CleanupOrphanedChromeProcesses(profilePath)
 
-	if strings.HasPrefix(profileName, "instance-") {
+	// A recycled instance comes back on the same profile.
+	if strings.HasPrefix(profileName, "instance-") && !recycling {
 		profilePath := filepath.Join(o.baseDir, profileName)
 		if err := os.RemoveAll(profilePath); err != nil {
 			slog.Warn("failed to delete temporary profile directory", "name", profileName, "err", err)
@@ -751,6 +838,161 @@ func (o *Orchestrator) FirstRunningURL() string {
 	return candidates[0].url
 }
 
+// TargetURL returns the base URL of the instance a shorthand request should
+// go to. Under the default fcfs policy that is FirstRunningURL; other
+// allocation policies choose for the request's agent, session and profile
+// headers.
+//
+// Shorthand calls build on each other (/navigate, then /snapshot on the
+// same tab), so a caller is pinned to the instance it was first given for
+// as long as that instance runs. Callers are told apart by session, agent
+// or profile header; requests with none of them go to FirstRunningURL.
+//
+// A request carrying the labels header only goes to an instance with those
+// labels; when none matches it gets no target rather than an arbitrary one.
+func (o *Orchestrator) TargetURL(r *http.Request) string {
+	req := allocationRequest(r)
+	labelled := strings.TrimSpace(r.Header.Get(fleet.HeaderLabels)) != ""
+	if labelled && len(req.Labels) == 0 {
+		return ""
+	}
+	if !labelled && (o.instanceMgr == nil || o.instanceMgr.Allocator.Policy().Name() == "fcfs") {
+		return o.FirstRunningURL()
+	}
+	key := shorthandPinKey(req)
+	if key == "" {
+		return o.FirstRunningURL()
+	}
+	if target := o.pinnedURL(key, req); target != "" {
+		return target
+	}
+	inst := o.allocatedInstance(req)
+	if inst == nil {
+		if labelled {
+			return ""
+		}
+		return o.FirstRunningURL()
+	}
+	o.pin(key, inst.ID)
+	return inst.URL
+}
+
+const (
+	// shorthandPinIdle is how long an unused pin is kept once the table
+	// is full.
+	shorthandPinIdle = time.Hour
+	// maxShorthandPins is the size above which idle pins are pruned.
+	maxShorthandPins = 10000
+)
+
+type shorthandPin struct {
+	instanceID string
+	used       time.Time
+}
+
+// shorthandPinKey identifies the caller of a shorthand request. Labels are
+// part of the key so that one caller can use several instance groups.
+func shorthandPinKey(req allocation.Request) string {
+	var key string
+	switch {
+	case req.SessionID != "":
+		key = "session:" + req.SessionID
+	case req.AgentID != "":
+		key = "agent:" + req.AgentID
+	case req.Profile != "":
+		key = "profile:" + req.Profile
+	case len(req.Labels) > 0:
+		key = "anonymous"
+	default:
+		return ""
+	}
+	if len(req.Labels) > 0 {
+		pairs := make([]string, 0, len(req.Labels))
+		for k, v := range req.Labels {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+		key += "|" + strings.Join(pairs, ",")
+	}
+	return key
+}
+
+// pinnedURL returns the URL of the instance key is pinned to while it is
+// still running, or "".
+func (o *Orchestrator) pinnedURL(key string, req allocation.Request) string {
+	o.pinMu.Lock()
+	pin, ok := o.pins[key]
+	o.pinMu.Unlock()
+	if !ok {
+		return ""
+	}
+	o.mu.RLock()
+	inst, ok := o.instances[pin.instanceID]
+	o.mu.RUnlock()
+	if !ok || inst.Status != "running" || inst.URL == "" || !instanceIsActive(inst) || !req.MatchesLabels(inst.Labels) {
+		return ""
+	}
+	o.pin(key, pin.instanceID)
+	return inst.URL
+}
+
+func (o *Orchestrator) pin(key, instanceID string) {
+	o.pinMu.Lock()
+	defer o.pinMu.Unlock()
+	now := time.Now()
+	if o.pins == nil {
+		o.pins = make(map[string]shorthandPin)
+	}
+	if len(o.pins) >= maxShorthandPins {
+		for k, p := range o.pins {
+			if now.Sub(p.used) > shorthandPinIdle {
+				delete(o.pins, k)
+			}
+		}
+	}
+	o.pins[key] = shorthandPin{instanceID: instanceID, used: now}
+}
+
+// allocatedInstance returns the instance the allocation policy picks for
+// req, or nil when there is none.
+func (o *Orchestrator) allocatedInstance(req allocation.Request) *InstanceInternal {
+	if o.instanceMgr == nil {
+		return nil
+	}
+	selected, err := o.instanceMgr.AllocateFor(req)
+	if err != nil {
+		return nil
+	}
+	o.mu.RLock()
+	inst, ok := o.instances[selected.ID]
+	o.mu.RUnlock()
+	if !ok || inst.URL == "" || !instanceIsActive(inst) {
+		return nil
+	}
+	return inst
+}
+
+// allocationRequest describes the caller of a shorthand request.
+func allocationRequest(r *http.Request) allocation.Request {
+	profile := strings.TrimSpace(r.Header.Get(activity.HeaderPTProfile))
+	if profile == "" {
+		profile = strings.TrimSpace(r.Header.Get(activity.HeaderPTProfileID))
+	}
+	agentID := strings.TrimSpace(r.Header.Get(activity.HeaderAgentID))
+	if agentID == "" {
+		agentID = strings.TrimSpace(r.Header.Get(activity.HeaderPTAgentID))
+	}
+	// Malformed labels are dropped here; TargetURL refuses to route a
+	// request whose labels header did not parse.
+	labels, _ := fleet.ParseLabels([]string{r.Header.Get(fleet.HeaderLabels)})
+	return allocation.Request{
+		AgentID:   agentID,
+		SessionID: strings.TrimSpace(r.Header.Get(activity.HeaderPTSessionID)),
+		Profile:   profile,
+		Labels:    labels,
+	}
+}
+
 func (o *Orchestrator) AllTabs() []bridge.InstanceTab {
 	o.mu.RLock()
 	instances := make([]*InstanceInternal, 0)
@@ -809,6 +1051,44 @@ func (o *Orchestrator) AllMetrics() []types.InstanceMetrics {
 	return all
 }
 
+// InstanceLoads measures the open tabs and JS heap of every running
+// instance for load-aware allocation policies. Instances that answer
+// neither request are left out, so their load is unknown.
+func (o *Orchestrator) InstanceLoads() map[string]allocation.Load {
+	o.mu.RLock()
+	instances := make([]*InstanceInternal, 0)
+	for _, inst := range o.instances {
+		if inst.Status == "running" && instanceIsActive(inst) {
+			instances = append(instances, inst)
+		}
+	}
+	o.mu.RUnlock()
+
+	loads := make(map[string]allocation.Load, len(instances))
+	for _, inst := range instances {
+		var load allocation.Load
+		if tabs, err := o.fetchTabs(inst); err == nil {
+			load.Tabs, load.Known = len(tabs), true
+		}
+		if mem, err := o.fetchMetrics(inst); err == nil && mem != nil {
+			load.MemoryMB, load.Known = mem.JSHeapUsedMB, true
+		}
+		if load.Known {
+			loads[inst.ID] = load
+		}
+	}
+	return loads
+}
+
+// InstanceActivity returns when each instance last served a proxied or
+// scheduled request. Instances that have not served one are missing.
+func (o *Orchestrator) InstanceActivity() map[string]time.Time {
+	if o.instanceMgr == nil {
+		return nil
+	}
+	return o.instanceMgr.LastActive()
+}
+
 func (o *Orchestrator) ScreencastURL(instanceID, tabID string) string {
 	o.mu.RLock()
 	defer o.mu.RUnlock()
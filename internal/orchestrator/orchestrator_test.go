@@ -214,6 +214,49 @@ func TestOrchestrator_Launch_RejectsInvalidPort(t *testing.T) {
 	}
 }
 
+func TestOrchestrator_Launch_SharesStateBundleKey(t *testing.T) {
+	old := processAliveFunc
+	processAliveFunc = func(pid int) bool { return pid > 0 }
+	defer func() { processAliveFunc = old }()
+	stubPortAvailability(t, func(int) bool { return true })
+
+	runner := &mockRunner{portAvail: true}
+	o := NewOrchestratorWithRunner(t.TempDir(), runner)
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{StateDir: t.TempDir(), InstancePortStart: 9910, InstancePortEnd: 9919})
+
+	// Each instance gets a state dir of its own, so without the shared key
+	// each would generate a different one.
+	childConfig := func(profile string) *config.RuntimeConfig {
+		if _, err := o.Launch(profile, "", true, nil); err != nil {
+			t.Fatalf("Launch(%s) failed: %v", profile, err)
+		}
+		return &config.RuntimeConfig{
+			StateDir:       t.TempDir(),
+			StateBundleKey: envMap(runner.env)["PINCHTAB_STATE_BUNDLE_KEY"],
+		}
+	}
+	a, b := childConfig("a"), childConfig("b")
+	if a.StateBundleKey == "" {
+		t.Fatal("PINCHTAB_STATE_BUNDLE_KEY missing from child env")
+	}
+
+	keyA, err := bridge.StateBundleKey(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := bridge.StateBundle{Version: bridge.StateBundleVersion, Tabs: []bridge.StateTab{{URL: "https://example.com/"}}}
+	if err := bridge.SignStateBundle(&bundle, keyA); err != nil {
+		t.Fatal(err)
+	}
+	keyB, err := bridge.StateBundleKey(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bridge.VerifyStateBundle(&bundle, keyB); err != nil {
+		t.Fatalf("a bundle exported on one instance should import on another: %v", err)
+	}
+}
+
 func TestOrchestrator_Launch_ReservesDistinctChromeDebugPort(t *testing.T) {
 	old := processAliveFunc
 	processAliveFunc = func(pid int) bool { return pid > 0 }
@@ -120,6 +120,9 @@ func (o *Orchestrator) proxyToURL(w http.ResponseWriter, r *http.Request, target
 			activity.PropagateHeaders(r.Context(), req)
 			if inst := o.proxyTargetInstance(targetURL); inst != nil {
 				req.Header.Set(activity.HeaderPTInstance, inst.ID)
+				if o.instanceMgr != nil {
+					o.instanceMgr.Touch(inst.ID)
+				}
 				if inst.ProfileID != "" {
 					req.Header.Set(activity.HeaderPTProfileID, inst.ProfileID)
 				}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
 )
@@ -151,3 +152,66 @@ func TestProxyToTarget_InjectsChildAuthAndStripsCookie(t *testing.T) {
 		t.Fatalf("status = %d, want 200", w.Code)
 	}
 }
+
+func TestTargetURL_FollowsAllocationPolicy(t *testing.T) {
+	o := NewOrchestrator(t.TempDir())
+	for i, id := range []string{"inst_1", "inst_2"} {
+		inst := &InstanceInternal{
+			Instance: bridge.Instance{ID: id, Status: "running", StartTime: time.Unix(int64(i), 0)},
+			URL:      fmt.Sprintf("http://127.0.0.1:%d", i+1),
+			cmd:      &mockCmd{pid: i + 1, isAlive: true},
+		}
+		o.instances[id] = inst
+		o.syncInstanceToManager(&inst.Instance)
+	}
+	orig := processAliveFunc
+	processAliveFunc = func(pid int) bool { return true }
+	defer func() { processAliveFunc = orig }()
+
+	req := func(agent string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/snapshot", nil)
+		r.Header.Set("X-Agent-Id", agent)
+		return r
+	}
+	for range 3 {
+		if got := o.TargetURL(req("a1")); got != o.FirstRunningURL() {
+			t.Fatalf("fcfs should match FirstRunningURL, got %s", got)
+		}
+	}
+
+	if err := o.SetAllocationPolicy("round_robin"); err != nil {
+		t.Fatal(err)
+	}
+	a1 := o.TargetURL(req("a1"))
+	if a2 := o.TargetURL(req("a2")); a1 == a2 {
+		t.Errorf("round_robin should spread agents, got %s twice", a1)
+	}
+	for range 3 {
+		if got := o.TargetURL(req("a1")); got != a1 {
+			t.Fatalf("agent a1 should stay on %s between shorthand calls, got %s", a1, got)
+		}
+	}
+	anonymous := httptest.NewRequest(http.MethodGet, "/snapshot", nil)
+	for range 3 {
+		if got := o.TargetURL(anonymous); got != o.FirstRunningURL() {
+			t.Fatalf("requests without an identity should go to FirstRunningURL, got %s", got)
+		}
+	}
+
+	o.mu.Lock()
+	o.instances[o.pins["agent:a1"].instanceID].Status = "stopped"
+	o.mu.Unlock()
+	if got := o.TargetURL(req("a1")); got == a1 || got == "" {
+		t.Errorf("a stopped pin should be replaced, got %q", got)
+	}
+
+	if err := o.SetAllocationPolicy("sticky"); err != nil {
+		t.Fatal(err)
+	}
+	first := o.TargetURL(req("a1"))
+	for range 3 {
+		if got := o.TargetURL(req("a1")); got != first {
+			t.Fatalf("sticky should keep agent a1 on %s, got %s", first, got)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+const (
+	defaultMemoryCheckInterval = 15 * time.Second
+	// recycleBackoffMin is how long a recycled instance is left alone
+	// before it may be recycled again. It doubles for each recycle that
+	// follows the previous one within twice the backoff, up to
+	// recycleBackoffMax, so an instance that is over the limit right after
+	// restoring its tabs does not restart in a loop.
+	recycleBackoffMin = time.Minute
+	recycleBackoffMax = 30 * time.Minute
+)
+
+// recycleHistory is when an instance was last recycled and how long it is
+// left alone after that.
+type recycleHistory struct {
+	at      time.Time
+	backoff time.Duration
+}
+
+// noteRecycleLocked records a recycle of id at now. o.mu must be held.
+func (o *Orchestrator) noteRecycleLocked(id string, now time.Time) {
+	if o.recycles == nil {
+		o.recycles = make(map[string]recycleHistory)
+	}
+	h, ok := o.recycles[id]
+	if !ok || now.Sub(h.at) > 2*h.backoff {
+		h.backoff = recycleBackoffMin
+	} else {
+		h.backoff = min(2*h.backoff, recycleBackoffMax)
+	}
+	h.at = now
+	o.recycles[id] = h
+}
+
+// recycleWait returns how long id must still wait before its next recycle.
+func (o *Orchestrator) recycleWait(id string, now time.Time) time.Duration {
+	o.mu.RLock()
+	h, ok := o.recycles[id]
+	o.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+	return max(0, h.at.Add(h.backoff).Sub(now))
+}
+
+// startMemoryWatch recycles inst once its browser memory passes
+// memoryPressure.recycleAboveMB. It does nothing when recycling is off.
+func (o *Orchestrator) startMemoryWatch(inst *InstanceInternal) {
+	if o.runtimeCfg == nil || o.runtimeCfg.MemoryPressureRecycleMB <= 0 {
+		return
+	}
+	interval := o.runtimeCfg.MemoryPressureInterval
+	if interval <= 0 {
+		interval = defaultMemoryCheckInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if !o.checkMemoryRecycle(inst) {
+				return
+			}
+		}
+	}()
+}
+
+// checkMemoryRecycle recycles inst when it is over recycleAboveMB and
+// reports whether it should still be watched.
+func (o *Orchestrator) checkMemoryRecycle(inst *InstanceInternal) bool {
+	o.mu.RLock()
+	current, ok := o.instances[inst.ID]
+	watching := ok && current == inst && inst.Status == "running" && !inst.recycling
+	o.mu.RUnlock()
+	if !watching {
+		return false
+	}
+	limit := o.runtimeCfg.MemoryPressureRecycleMB
+	if limit <= 0 {
+		return true
+	}
+
+	mem, err := o.fetchMetrics(inst)
+	if err != nil || mem == nil || mem.MemoryMB <= float64(limit) {
+		return true
+	}
+	if wait := o.recycleWait(inst.ID, time.Now()); wait > 0 {
+		slog.Info("instance over recycleAboveMB but recycled recently", "id", inst.ID, "memoryMB", mem.MemoryMB, "retryIn", wait.Round(time.Second))
+		return true
+	}
+	reason := fmt.Sprintf("browser memory %.0f MB over recycleAboveMB=%d", mem.MemoryMB, limit)
+	if _, err := o.Recycle(inst.ID, reason); err != nil {
+		slog.Error("instance recycle failed", "id", inst.ID, "err", err)
+	}
+	return false
+}
+
+// Recycle restarts a launched instance in place: the bridge saves its open
+// tabs on shutdown and restores them on the next start, with the same
+// instance ID, profile, port, limits and device. Tab IDs change across a recycle.
+// When the relaunch fails the instance is gone and instance.error is
+// emitted in place of the suppressed instance.stopped.
+func (o *Orchestrator) Recycle(id, reason string) (*bridge.Instance, error) {
+	o.mu.Lock()
+	inst, ok := o.instances[id]
+	if !ok {
+		o.mu.Unlock()
+		return nil, fmt.Errorf("instance %q not found", id)
+	}
+	if inst.cmd == nil {
+		o.mu.Unlock()
+		return nil, fmt.Errorf("instance %q was not launched by this server", id)
+	}
+	inst.recycling = true
+	o.noteRecycleLocked(id, time.Now())
+	last := inst.Instance
+	name, port, headless := inst.ProfileName, inst.Port, inst.Headless
+	extensionPaths, device := inst.extensionPaths, inst.Device
+	var limits bridge.ResourceLimits
+	if inst.Limits != nil {
+		limits = *inst.Limits
+	}
+	o.mu.Unlock()
+
+	slog.Warn("recycling instance under memory pressure", "id", id, "profile", name, "reason", reason)
+	if err := o.Stop(id); err != nil {
+		o.mu.Lock()
+		if current, ok := o.instances[id]; ok && current == inst {
+			inst.recycling = false
+		}
+		o.mu.Unlock()
+		return nil, fmt.Errorf("stop: %w", err)
+	}
+	started, err := o.launch(name, port, headless, extensionPaths, limits, device, id)
+	if err != nil {
+		last.Status = "error"
+		last.Error = "recycle failed: relaunch: " + err.Error()
+		o.emitEvent("instance.error", &last)
+		return nil, fmt.Errorf("relaunch: %w", err)
+	}
+	evt := *started
+	o.emitEvent("instance.recycled", &evt)
+	return started, nil
+}
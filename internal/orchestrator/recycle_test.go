@@ -0,0 +1,172 @@
+package orchestrator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestCheckMemoryRecycle_RelaunchesOverLimit(t *testing.T) {
+	oldPort, oldAlive := portAvailableFunc, processAliveFunc
+	portAvailableFunc = func(int) bool { return true }
+	processAliveFunc = func(int) bool { return false }
+	defer func() { portAvailableFunc, processAliveFunc = oldPort, oldAlive }()
+
+	var mu sync.Mutex
+	var shutdowns int
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metrics":
+			_, _ = w.Write([]byte(`{"memory":{"memoryMB":2500}}`))
+		case "/shutdown":
+			mu.Lock()
+			shutdowns++
+			mu.Unlock()
+		}
+	}))
+	defer backend.Close()
+
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	o.client = backend.Client()
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{MemoryPressureRecycleMB: 2048})
+	var events []string
+	o.OnEvent(func(evt InstanceEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, evt.Type)
+	})
+
+	limits := bridge.ResourceLimits{MemoryMaxMB: 4096}
+	inst := &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_work", ProfileName: "work", Port: "9977", Status: "running", Limits: &limits},
+		URL:      backend.URL,
+		cmd:      &mockCmd{pid: 4321},
+		logBuf:   newRingBuffer(1024),
+	}
+	inst.Instance.URL = backend.URL
+	o.instances[inst.ID] = inst
+
+	if o.checkMemoryRecycle(inst) {
+		t.Fatal("the old instance should no longer be watched after a recycle")
+	}
+
+	o.mu.RLock()
+	relaunched, ok := o.instances[inst.ID]
+	o.mu.RUnlock()
+	if !ok || relaunched == inst {
+		t.Fatal("expected the instance to be relaunched under the same ID")
+	}
+	if relaunched.Port != "9977" || relaunched.ProfileName != "work" || relaunched.Limits == nil || relaunched.Limits.MemoryMaxMB != 4096 {
+		t.Fatalf("relaunch should keep profile, port and limits, got %+v", relaunched.Instance)
+	}
+
+	// Still over the limit right after the restart: the backoff holds.
+	o.mu.Lock()
+	relaunched.Status = "running"
+	relaunched.URL = backend.URL
+	relaunched.Instance.URL = backend.URL
+	o.mu.Unlock()
+	if !o.checkMemoryRecycle(relaunched) {
+		t.Fatal("an instance within its recycle backoff should stay watched")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if shutdowns != 1 {
+		t.Fatalf("expected the bridge to be shut down once, got %d", shutdowns)
+	}
+	if len(events) != 1 || events[0] != "instance.recycled" {
+		t.Fatalf("expected a single instance.recycled event, got %v", events)
+	}
+}
+
+func TestRecycle_RelaunchFailureEmitsError(t *testing.T) {
+	oldPort, oldAlive := portAvailableFunc, processAliveFunc
+	portAvailableFunc = func(int) bool { return false }
+	processAliveFunc = func(int) bool { return false }
+	defer func() { portAvailableFunc, processAliveFunc = oldPort, oldAlive }()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	o.client = backend.Client()
+	var mu sync.Mutex
+	var events []InstanceEvent
+	o.OnEvent(func(evt InstanceEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, evt)
+	})
+
+	inst := &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_work", ProfileName: "work", Port: "9979", Status: "running"},
+		URL:      backend.URL,
+		cmd:      &mockCmd{pid: 4323},
+		logBuf:   newRingBuffer(1024),
+	}
+	inst.Instance.URL = backend.URL
+	o.instances[inst.ID] = inst
+
+	if _, err := o.Recycle(inst.ID, "test"); err == nil {
+		t.Fatal("expected the relaunch to fail while the port is taken")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 1 || events[0].Type != "instance.error" || events[0].Instance.ID != inst.ID || events[0].Instance.Error == "" {
+		t.Fatalf("expected a single instance.error event for the lost instance, got %+v", events)
+	}
+}
+
+func TestNoteRecycle_Backoff(t *testing.T) {
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	o.noteRecycleLocked("inst_a", now)
+	if wait := o.recycleWait("inst_a", now.Add(30*time.Second)); wait != 30*time.Second {
+		t.Fatalf("recycleWait after one recycle = %v, want 30s", wait)
+	}
+	now = now.Add(90 * time.Second)
+	o.noteRecycleLocked("inst_a", now)
+	if wait := o.recycleWait("inst_a", now); wait != 2*recycleBackoffMin {
+		t.Fatalf("a quick second recycle should double the backoff, got %v", wait)
+	}
+	now = now.Add(time.Hour)
+	o.noteRecycleLocked("inst_a", now)
+	if wait := o.recycleWait("inst_a", now); wait != recycleBackoffMin {
+		t.Fatalf("the backoff should reset after a quiet period, got %v", wait)
+	}
+	if wait := o.recycleWait("inst_b", now); wait != 0 {
+		t.Fatalf("an instance never recycled should not wait, got %v", wait)
+	}
+}
+
+func TestCheckMemoryRecycle_UnderLimit(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"memory":{"memoryMB":900}}`))
+	}))
+	defer backend.Close()
+
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	o.client = backend.Client()
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{MemoryPressureRecycleMB: 2048})
+	inst := &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_ok", ProfileName: "ok", Port: "9978", Status: "running"},
+		URL:      backend.URL,
+		cmd:      &mockCmd{pid: 4322},
+	}
+	o.instances[inst.ID] = inst
+
+	if !o.checkMemoryRecycle(inst) {
+		t.Fatal("an instance under the threshold should stay watched")
+	}
+	if o.instances[inst.ID] != inst {
+		t.Fatal("an instance under the threshold must not be recycled")
+	}
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 
@@ -18,24 +19,39 @@ type BatchRequest struct {
 	AgentID     string         `json:"agentId"`
 	CallbackURL string         `json:"callbackUrl,omitempty"`
 	Tasks       []BatchTaskDef `json:"tasks"`
+	// OnDependencyFailure is the default for tasks that set dependsOn.
+	OnDependencyFailure string `json:"onDependencyFailure,omitempty"`
 }
 
 // BatchTaskDef defines a single task inside a batch.
 type BatchTaskDef struct {
-	Action   string         `json:"action"`
-	TabID    string         `json:"tabId,omitempty"`
-	Ref      string         `json:"ref,omitempty"`
-	Params   map[string]any `json:"params,omitempty"`
-	Priority int            `json:"priority,omitempty"`
-	Deadline string         `json:"deadline,omitempty"`
+	Action   string            `json:"action"`
+	TabID    string            `json:"tabId,omitempty"`
+	Ref      string            `json:"ref,omitempty"`
+	Params   map[string]any    `json:"params,omitempty"`
+	Priority int               `json:"priority,omitempty"`
+	Deadline string            `json:"deadline,omitempty"`
+	Retry    *RetryPolicy      `json:"retry,omitempty"`
+	Profile  string            `json:"profile,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	CloseTab bool              `json:"closeTab,omitempty"`
+
+	// Key names the task within the batch. DependsOn may list keys of
+	// other tasks in the batch or IDs of existing tasks.
+	Key                 string   `json:"key,omitempty"`
+	DependsOn           []string `json:"dependsOn,omitempty"`
+	OnDependencyFailure string   `json:"onDependencyFailure,omitempty"`
 }
 
 // BatchResponseItem is the result for each submitted task in the batch.
 type BatchResponseItem struct {
 	TaskID   string    `json:"taskId"`
+	Key      string    `json:"key,omitempty"`
 	State    TaskState `json:"state"`
 	Position int       `json:"position,omitempty"`
 	Error    string    `json:"error,omitempty"`
+	// Reason is the machine-readable rejection reason, if any.
+	Reason string `json:"reason,omitempty"`
 }
 
 func (s *Scheduler) handleBatch(w http.ResponseWriter, r *http.Request) {
@@ -64,6 +80,11 @@ func (s *Scheduler) handleBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.isDag() {
+		s.handleDagBatch(w, req)
+		return
+	}
+
 	results := make([]BatchResponseItem, 0, len(req.Tasks))
 	for _, td := range req.Tasks {
 		sr := SubmitRequest{
@@ -75,6 +96,10 @@ func (s *Scheduler) handleBatch(w http.ResponseWriter, r *http.Request) {
 			Priority:    td.Priority,
 			Deadline:    td.Deadline,
 			CallbackURL: req.CallbackURL,
+			Retry:       td.Retry,
+			Profile:     td.Profile,
+			Labels:      td.Labels,
+			CloseTab:    td.CloseTab,
 		}
 
 		task, err := s.Submit(sr)
@@ -82,6 +107,7 @@ func (s *Scheduler) handleBatch(w http.ResponseWriter, r *http.Request) {
 			item := BatchResponseItem{State: StateRejected, Error: err.Error()}
 			if task != nil {
 				item.TaskID = task.ID
+				item.Reason = task.Snapshot().RejectReason
 			}
 			results = append(results, item)
 			slog.Warn("batch: task rejected", "agent", req.AgentID, "action", td.Action, "err", err)
@@ -100,3 +126,160 @@ func (s *Scheduler) handleBatch(w http.ResponseWriter, r *http.Request) {
 		"submitted": len(results),
 	})
 }
+
+// isDag reports whether any task in the batch names or depends on another.
+func (req *BatchRequest) isDag() bool {
+	for _, td := range req.Tasks {
+		if td.Key != "" || len(td.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// handleDagBatch submits a batch whose tasks depend on each other as one
+// DAG. Unlike a plain batch it is validated as a whole: an unknown
+// dependency or a cycle rejects every task.
+func (s *Scheduler) handleDagBatch(w http.ResponseWriter, req BatchRequest) {
+	order, err := s.dagOrder(req.Tasks)
+	if err != nil {
+		httpx.ErrorCode(w, 400, "invalid_dag", err.Error(), false, nil)
+		return
+	}
+
+	tasks := make([]*Task, len(req.Tasks))
+	byKey := make(map[string]string)
+	for i, td := range req.Tasks {
+		policy := td.OnDependencyFailure
+		if policy == "" {
+			policy = req.OnDependencyFailure
+		}
+		t, err := s.newTask(SubmitRequest{
+			AgentID:             req.AgentID,
+			Action:              td.Action,
+			TabID:               td.TabID,
+			Ref:                 td.Ref,
+			Params:              td.Params,
+			Priority:            td.Priority,
+			Deadline:            td.Deadline,
+			CallbackURL:         req.CallbackURL,
+			Retry:               td.Retry,
+			Profile:             td.Profile,
+			Labels:              td.Labels,
+			CloseTab:            td.CloseTab,
+			DependsOn:           td.DependsOn,
+			OnDependencyFailure: policy,
+		})
+		if err != nil {
+			httpx.ErrorCode(w, 400, "invalid_dag", fmt.Sprintf("task %d: %v", i, err), false, nil)
+			return
+		}
+		t.Key = td.Key
+		tasks[i] = t
+		if td.Key != "" {
+			byKey[td.Key] = t.ID
+		}
+	}
+
+	dagID := generateDagID()
+	for _, t := range tasks {
+		t.DagID = dagID
+		for j, dep := range t.DependsOn {
+			if id, ok := byKey[dep]; ok {
+				t.DependsOn[j] = id
+			}
+		}
+	}
+
+	// A DAG is admitted against quotas as a whole.
+	actions := make([]string, len(tasks))
+	for i, t := range tasks {
+		actions[i] = t.Action
+	}
+	if rerr := s.quotas.take(req.AgentID, actions...); rerr != nil {
+		writeQuotaRejection(w, req.AgentID, rerr)
+		return
+	}
+
+	results := make([]BatchResponseItem, len(tasks))
+	for _, i := range order {
+		t := tasks[i]
+		var err error
+		if len(t.DependsOn) > 0 {
+			err = s.admitDependent(t)
+		} else {
+			s.joinDag(t)
+			s.events.publish(t, "")
+			_, err = s.enqueue(t)
+		}
+		item := BatchResponseItem{TaskID: t.ID, Key: t.Key}
+		if err != nil {
+			s.quotas.refund(req.AgentID, t.Action)
+			item.Error = err.Error()
+			item.Reason = t.Snapshot().RejectReason
+			slog.Warn("batch: task rejected", "agent", req.AgentID, "action", t.Action, "err", err)
+		} else {
+			s.metrics.recordSubmit(req.AgentID)
+		}
+		snap := t.Snapshot()
+		item.State = snap.State
+		item.Position = snap.Position
+		results[i] = item
+	}
+	slog.Info("dag submitted", "dag", dagID, "agent", req.AgentID, "tasks", len(tasks))
+
+	httpx.JSON(w, 202, map[string]any{
+		"dagId":     dagID,
+		"tasks":     results,
+		"submitted": len(results),
+	})
+}
+
+// dagOrder checks that every dependency names a batch key or an existing
+// task and returns the batch indices in dependency order.
+func (s *Scheduler) dagOrder(defs []BatchTaskDef) ([]int, error) {
+	index := make(map[string]int, len(defs))
+	for i, td := range defs {
+		if td.Key == "" {
+			continue
+		}
+		if _, dup := index[td.Key]; dup {
+			return nil, fmt.Errorf("duplicate key %q", td.Key)
+		}
+		index[td.Key] = i
+	}
+
+	indegree := make([]int, len(defs))
+	dependents := make([][]int, len(defs))
+	for i, td := range defs {
+		for _, dep := range td.DependsOn {
+			if j, ok := index[dep]; ok {
+				indegree[i]++
+				dependents[j] = append(dependents[j], i)
+				continue
+			}
+			if s.GetTask(dep) == nil {
+				return nil, fmt.Errorf("task %d depends on unknown key or task %q", i, dep)
+			}
+		}
+	}
+
+	order := make([]int, 0, len(defs))
+	for i := range defs {
+		if indegree[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for n := 0; n < len(order); n++ {
+		for _, d := range dependents[order[n]] {
+			indegree[d]--
+			if indegree[d] == 0 {
+				order = append(order, d)
+			}
+		}
+	}
+	if len(order) != len(defs) {
+		return nil, errors.New("dependsOn contains a cycle")
+	}
+	return order, nil
+}
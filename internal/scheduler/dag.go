@@ -0,0 +1,474 @@
+package scheduler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dependency failure policies. With cancel, a task whose dependency ends in
+// any state other than done is cancelled, and the cancellation cascades to
+// its own dependents. With continue, it runs anyway.
+const (
+	DepFailureCancel   = "cancel"
+	DepFailureContinue = "continue"
+)
+
+// dagRecord groups the tasks connected through dependsOn.
+type dagRecord struct {
+	ID        string
+	CreatedAt time.Time
+	TaskIDs   []string
+}
+
+// DagStatus is the response for GET /dags/{id}.
+type DagStatus struct {
+	ID        string            `json:"dagId"`
+	State     string            `json:"state"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Counts    map[TaskState]int `json:"counts"`
+	Tasks     []*Task           `json:"tasks"`
+}
+
+// Overall DAG states reported by GET /dags/{id}.
+const (
+	DagRunning   = "running"
+	DagDone      = "done"
+	DagFailed    = "failed"
+	DagCancelled = "cancelled"
+)
+
+// templatePattern matches {{<ref>.result}} and {{<ref>.result.a.0.b}}.
+var templatePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_\-]+)\.result((?:\.[A-Za-z0-9_\-]+)*)\s*\}\}`)
+
+// templateRefs returns the dependency references used in params templates.
+func templateRefs(params map[string]any) []string {
+	var refs []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch x := v.(type) {
+		case string:
+			for _, m := range templatePattern.FindAllStringSubmatch(x, -1) {
+				refs = append(refs, m[1])
+			}
+		case map[string]any:
+			for _, e := range x {
+				walk(e)
+			}
+		case []any:
+			for _, e := range x {
+				walk(e)
+			}
+		}
+	}
+	walk(map[string]any(params))
+	return refs
+}
+
+// renderParams substitutes dependency results into params. A string that
+// is exactly one template takes the referenced value with its JSON type;
+// templates embedded in longer strings are formatted as text.
+func renderParams(params map[string]any, deps map[string]*Task) (map[string]any, error) {
+	out, err := renderValue(params, deps)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := out.(map[string]any)
+	return m, nil
+}
+
+func renderValue(v any, deps map[string]*Task) (any, error) {
+	switch x := v.(type) {
+	case string:
+		return renderString(x, deps)
+	case map[string]any:
+		if x == nil {
+			return x, nil
+		}
+		out := make(map[string]any, len(x))
+		for k, e := range x {
+			r, err := renderValue(e, deps)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(x))
+		for i, e := range x {
+			r, err := renderValue(e, deps)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func renderString(s string, deps map[string]*Task) (any, error) {
+	if m := templatePattern.FindStringSubmatchIndex(s); m != nil && m[0] == 0 && m[1] == len(s) {
+		return lookupResult(s[m[2]:m[3]], s[m[4]:m[5]], deps)
+	}
+	var firstErr error
+	out := templatePattern.ReplaceAllStringFunc(s, func(match string) string {
+		sub := templatePattern.FindStringSubmatch(match)
+		v, err := lookupResult(sub[1], sub[2], deps)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return match
+		}
+		if str, ok := v.(string); ok {
+			return str
+		}
+		b, _ := json.Marshal(v)
+		return string(b)
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+func lookupResult(ref, path string, deps map[string]*Task) (any, error) {
+	dep, ok := deps[ref]
+	if !ok {
+		return nil, fmt.Errorf("template references unknown dependency %q", ref)
+	}
+	cur := dep.Result
+	for _, seg := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		if seg == "" {
+			continue
+		}
+		switch x := cur.(type) {
+		case map[string]any:
+			v, ok := x[seg]
+			if !ok {
+				return nil, fmt.Errorf("result of %q has no field %q", ref, seg)
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, fmt.Errorf("result of %q has no index %q", ref, seg)
+			}
+			cur = x[i]
+		default:
+			return nil, fmt.Errorf("result of %q has no field %q", ref, seg)
+		}
+	}
+	return cur, nil
+}
+
+// admitDependent registers a task that has dependencies. It joins the DAG
+// of its dependencies, merging DAGs when they differ, and waits until they
+// finish.
+func (s *Scheduler) admitDependent(t *Task) error {
+	deps := make([]*Task, 0, len(t.DependsOn))
+	for _, id := range t.DependsOn {
+		dep := s.GetTask(id)
+		if dep == nil {
+			return fmt.Errorf("dependency %q not found", id)
+		}
+		deps = append(deps, dep)
+	}
+
+	s.dagMu.Lock()
+	dagID := t.DagID
+	for _, dep := range deps {
+		dep.mu.RLock()
+		id := dep.DagID
+		dep.mu.RUnlock()
+		if id == "" {
+			continue
+		}
+		if dagID == "" {
+			dagID = id
+		} else if id != dagID {
+			s.mergeDagLocked(dagID, id)
+		}
+	}
+	if dagID == "" {
+		dagID = generateDagID()
+	}
+	rec := s.dagLocked(dagID)
+	for _, dep := range deps {
+		dep.mu.Lock()
+		joined := dep.DagID == ""
+		if joined {
+			dep.DagID = dagID
+		}
+		dep.mu.Unlock()
+		if joined {
+			rec.TaskIDs = append(rec.TaskIDs, dep.ID)
+			s.results.Store(dep)
+		}
+	}
+	t.DagID = dagID
+	t.State = StateWaiting
+	rec.TaskIDs = append(rec.TaskIDs, t.ID)
+	s.waiting[t.ID] = t
+	s.dagMu.Unlock()
+
+	s.liveMu.Lock()
+	s.live[t.ID] = t
+	s.liveMu.Unlock()
+	s.results.Store(t)
+	s.events.publish(t, "")
+
+	s.checkDependencies(t)
+	return nil
+}
+
+// joinDag adds a task without dependencies to its DAG record.
+func (s *Scheduler) joinDag(t *Task) {
+	s.dagMu.Lock()
+	rec := s.dagLocked(t.DagID)
+	rec.TaskIDs = append(rec.TaskIDs, t.ID)
+	s.dagMu.Unlock()
+}
+
+// dagLocked returns the record for id, creating it if needed.
+func (s *Scheduler) dagLocked(id string) *dagRecord {
+	rec, ok := s.dags[id]
+	if !ok {
+		rec = &dagRecord{ID: id, CreatedAt: timeNow()}
+		s.dags[id] = rec
+	}
+	return rec
+}
+
+// mergeDagLocked moves every task of DAG from into DAG into.
+func (s *Scheduler) mergeDagLocked(into, from string) {
+	src, ok := s.dags[from]
+	if !ok {
+		return
+	}
+	dst := s.dagLocked(into)
+	for _, id := range src.TaskIDs {
+		if t := s.GetTask(id); t != nil {
+			t.mu.Lock()
+			t.DagID = into
+			t.mu.Unlock()
+			s.results.Store(t)
+		}
+		dst.TaskIDs = append(dst.TaskIDs, id)
+	}
+	delete(s.dags, from)
+}
+
+// releaseDependents re-checks every waiting task that depends on taskID.
+func (s *Scheduler) releaseDependents(taskID string) {
+	s.dagMu.Lock()
+	var ready []*Task
+	for _, w := range s.waiting {
+		for _, dep := range w.DependsOn {
+			if dep == taskID {
+				ready = append(ready, w)
+				break
+			}
+		}
+	}
+	s.dagMu.Unlock()
+
+	for _, w := range ready {
+		s.checkDependencies(w)
+	}
+}
+
+// checkDependencies queues t once all its dependencies are terminal, or
+// cancels it if one failed under the cancel policy. It is a no-op while
+// any dependency is still pending.
+func (s *Scheduler) checkDependencies(t *Task) {
+	deps := make(map[string]*Task, len(t.DependsOn)*2)
+	failed := ""
+	for _, id := range t.DependsOn {
+		dep := s.GetTask(id)
+		if dep == nil {
+			if failed == "" {
+				failed = fmt.Sprintf("dependency %s is no longer available", id)
+			}
+			continue
+		}
+		snap := dep.Snapshot()
+		if !snap.State.IsTerminal() {
+			return
+		}
+		if snap.State != StateDone && failed == "" {
+			failed = fmt.Sprintf("dependency %s %s", id, snap.State)
+		}
+		deps[snap.ID] = snap
+		if snap.Key != "" {
+			deps[snap.Key] = snap
+		}
+	}
+
+	s.dagMu.Lock()
+	_, ok := s.waiting[t.ID]
+	delete(s.waiting, t.ID)
+	s.dagMu.Unlock()
+	if !ok {
+		return
+	}
+
+	if failed != "" && t.OnDependencyFailure != DepFailureContinue {
+		t.mu.Lock()
+		t.Error = failed
+		t.mu.Unlock()
+		if err := t.SetState(StateCancelled); err != nil {
+			slog.Warn("task state transition failed", "task", t.ID, "err", err)
+		}
+		s.metrics.recordCancel(t.AgentID)
+		slog.Info("task cancelled", "task", t.ID, "agent", t.AgentID, "reason", failed)
+		s.finishTask(t)
+		return
+	}
+
+	params, err := renderParams(t.Params, deps)
+	if err != nil {
+		t.mu.Lock()
+		t.Error = "template: " + err.Error()
+		t.mu.Unlock()
+		if stateErr := t.SetState(StateFailed); stateErr != nil {
+			slog.Warn("task state transition failed", "task", t.ID, "err", stateErr)
+		}
+		s.metrics.recordFail(t.AgentID)
+		slog.Info("task failed", "task", t.ID, "agent", t.AgentID, "err", err)
+		s.finishTask(t)
+		return
+	}
+
+	t.mu.Lock()
+	t.Params = params
+	if t.Deadline.IsZero() {
+		t.Deadline = timeNow().Add(defaultTaskTimeout)
+	}
+	t.mu.Unlock()
+	if err := t.SetState(StateQueued); err != nil {
+		slog.Warn("task state transition failed", "task", t.ID, "err", err)
+		return
+	}
+	if _, err := s.enqueue(t); err == nil {
+		slog.Info("task released", "task", t.ID, "agent", t.AgentID, "dag", t.DagID)
+	}
+}
+
+// expireWaiting fails waiting tasks whose explicit deadline has passed.
+func (s *Scheduler) expireWaiting() {
+	now := timeNow()
+	s.dagMu.Lock()
+	var expired []*Task
+	for id, t := range s.waiting {
+		if !t.Deadline.IsZero() && t.Deadline.Before(now) {
+			expired = append(expired, t)
+			delete(s.waiting, id)
+		}
+	}
+	s.dagMu.Unlock()
+
+	for _, t := range expired {
+		t.mu.Lock()
+		t.Error = "deadline exceeded while waiting for dependencies"
+		t.mu.Unlock()
+		if err := t.SetState(StateFailed); err != nil {
+			slog.Warn("deadline reaper state transition failed", "task", t.ID, "err", err)
+		}
+		s.metrics.recordExpire()
+		s.metrics.recordFail(t.AgentID)
+		slog.Info("task expired", "task", t.ID, "agent", t.AgentID)
+		s.finishTask(t)
+	}
+}
+
+// GetDag returns the status of a DAG, or nil if none of its tasks are
+// still known.
+func (s *Scheduler) GetDag(id string) *DagStatus {
+	s.dagMu.Lock()
+	rec, ok := s.dags[id]
+	var ids []string
+	var created time.Time
+	if ok {
+		ids = append(ids, rec.TaskIDs...)
+		created = rec.CreatedAt
+	}
+	s.dagMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	st := &DagStatus{ID: id, CreatedAt: created, Counts: make(map[TaskState]int)}
+	for _, tid := range ids {
+		t := s.GetTask(tid)
+		if t == nil {
+			continue
+		}
+		snap := t.Snapshot()
+		st.Tasks = append(st.Tasks, snap)
+		st.Counts[snap.State]++
+	}
+	if len(st.Tasks) == 0 {
+		return nil
+	}
+	st.State = dagState(st.Counts, len(st.Tasks))
+	return st
+}
+
+func dagState(counts map[TaskState]int, total int) string {
+	terminal := counts[StateDone] + counts[StateFailed] + counts[StateCancelled] + counts[StateRejected]
+	switch {
+	case terminal < total:
+		return DagRunning
+	case counts[StateDone] == total:
+		return DagDone
+	case counts[StateFailed]+counts[StateRejected] > 0:
+		return DagFailed
+	default:
+		return DagCancelled
+	}
+}
+
+// pruneDags drops DAG records whose tasks have all been evicted.
+func (s *Scheduler) pruneDags() {
+	s.dagMu.Lock()
+	ids := make(map[string][]string, len(s.dags))
+	for id, rec := range s.dags {
+		ids[id] = append([]string(nil), rec.TaskIDs...)
+	}
+	s.dagMu.Unlock()
+
+	for id, taskIDs := range ids {
+		gone := true
+		for _, tid := range taskIDs {
+			if s.GetTask(tid) != nil {
+				gone = false
+				break
+			}
+		}
+		if gone {
+			s.dagMu.Lock()
+			if rec, ok := s.dags[id]; ok && len(rec.TaskIDs) == len(taskIDs) {
+				delete(s.dags, id)
+			}
+			s.dagMu.Unlock()
+		}
+	}
+}
+
+// generateDagID produces a random DAG ID in the format dag_XXXXXXXX.
+func generateDagID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("dag_%08x", time.Now().UnixNano()&0xFFFFFFFF)
+	}
+	return "dag_" + hex.EncodeToString(b)
+}
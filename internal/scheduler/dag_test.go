@@ -0,0 +1,252 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderParams(t *testing.T) {
+	deps := map[string]*Task{
+		"login": {ID: "tsk_1", Result: map[string]any{"user": "ada", "ids": []any{float64(7), float64(8)}}},
+	}
+	got, err := renderParams(map[string]any{
+		"whole":  "{{login.result.ids}}",
+		"index":  "{{ login.result.ids.1 }}",
+		"text":   "hello {{login.result.user}}!",
+		"nested": []any{map[string]any{"u": "{{login.result.user}}"}},
+		"plain":  42,
+	}, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids, ok := got["whole"].([]any); !ok || len(ids) != 2 {
+		t.Errorf("whole-string template should keep type, got %#v", got["whole"])
+	}
+	if got["index"] != float64(8) {
+		t.Errorf("index = %#v", got["index"])
+	}
+	if got["text"] != "hello ada!" {
+		t.Errorf("text = %#v", got["text"])
+	}
+	if got["nested"].([]any)[0].(map[string]any)["u"] != "ada" {
+		t.Errorf("nested = %#v", got["nested"])
+	}
+	if got["plain"] != 42 {
+		t.Errorf("plain = %#v", got["plain"])
+	}
+
+	for _, tmpl := range []string{"{{login.result.missing}}", "{{login.result.ids.5}}", "x {{other.result}}"} {
+		if _, err := renderParams(map[string]any{"v": tmpl}, deps); err == nil {
+			t.Errorf("%q should fail", tmpl)
+		}
+	}
+}
+
+func TestSubmitValidatesTemplateRefs(t *testing.T) {
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	dep, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click"})
+
+	if _, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "fill", DependsOn: []string{dep.ID},
+		Params: map[string]any{"text": "{{tsk_other.result}}"}}); err == nil {
+		t.Error("template outside dependsOn should be rejected")
+	}
+	if _, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "fill", DependsOn: []string{"tsk_missing"}}); err == nil {
+		t.Error("unknown dependency should be rejected")
+	}
+	if _, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "fill", DependsOn: []string{dep.ID}, OnDependencyFailure: "ignore"}); err == nil {
+		t.Error("unknown failure policy should be rejected")
+	}
+
+	child, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "fill", DependsOn: []string{dep.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.GetState() != StateWaiting || child.DagID == "" {
+		t.Fatalf("expected waiting task in a DAG, got %+v", child.Snapshot())
+	}
+	if got := s.GetTask(dep.ID); got.DagID != child.DagID {
+		t.Errorf("dependency should join the DAG, got %q want %q", got.DagID, child.DagID)
+	}
+	if s.QueueStats().TotalQueued != 1 {
+		t.Errorf("waiting task must not be queued, got %d", s.QueueStats().TotalQueued)
+	}
+}
+
+// dagExecutor returns {"value": "<kind>-out"} and fails any "fail" action.
+func dagExecutor(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	executor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		if body["kind"] == "fail" {
+			http.Error(w, "boom", 500)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"value": body["kind"].(string) + "-out", "echo": body})
+	}))
+	parts := strings.Split(executor.URL, ":")
+	return executor, parts[len(parts)-1]
+}
+
+func waitDag(t *testing.T, s *Scheduler, id string) *DagStatus {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		if st := s.GetDag(id); st != nil && st.State != DagRunning {
+			return st
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("dag %s did not finish: %+v", id, s.GetDag(id))
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+func postDagBatch(t *testing.T, mux *http.ServeMux, body string) (int, map[string]any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/tasks/batch", strings.NewReader(body)))
+	var resp map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestDagBatchRunsInOrderWithTemplates(t *testing.T) {
+	executor, port := dagExecutor(t)
+	defer executor.Close()
+	s := New(DefaultConfig(), &mockResolver{port: port})
+	mux := http.NewServeMux()
+	s.RegisterHandlers(mux)
+	s.Start()
+	defer s.Stop()
+
+	code, resp := postDagBatch(t, mux, `{"agentId":"a1","tasks":[
+		{"key":"fill","action":"fill","tabId":"t1","dependsOn":["read"],"params":{"text":"got {{read.result.value}}"}},
+		{"key":"read","action":"text","tabId":"t1"}
+	]}`)
+	if code != 202 || resp["dagId"] == nil {
+		t.Fatalf("expected 202 with dagId, got %d: %v", code, resp)
+	}
+	items := resp["tasks"].([]any)
+	if items[0].(map[string]any)["state"] != string(StateWaiting) {
+		t.Errorf("dependent should be waiting, got %v", items[0])
+	}
+
+	st := waitDag(t, s, resp["dagId"].(string))
+	if st.State != DagDone || len(st.Tasks) != 2 {
+		t.Fatalf("unexpected dag status: %+v", st)
+	}
+	fill := s.GetTask(items[0].(map[string]any)["taskId"].(string))
+	if fill.Params["text"] != "got text-out" {
+		t.Errorf("template not rendered: %#v", fill.Params)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/dags/"+st.ID, nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"state":"done"`) {
+		t.Errorf("GET /dags: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDagFailurePropagation(t *testing.T) {
+	executor, port := dagExecutor(t)
+	defer executor.Close()
+	s := New(DefaultConfig(), &mockResolver{port: port})
+	mux := http.NewServeMux()
+	s.RegisterHandlers(mux)
+	s.Start()
+	defer s.Stop()
+
+	_, resp := postDagBatch(t, mux, `{"agentId":"a1","tasks":[
+		{"key":"root","action":"fail","tabId":"t1"},
+		{"key":"child","action":"click","tabId":"t1","dependsOn":["root"]},
+		{"key":"grandchild","action":"click","tabId":"t1","dependsOn":["child"]},
+		{"key":"cleanup","action":"click","tabId":"t1","dependsOn":["root"],"onDependencyFailure":"continue"}
+	]}`)
+	st := waitDag(t, s, resp["dagId"].(string))
+
+	states := map[string]TaskState{}
+	for _, task := range st.Tasks {
+		states[task.Key] = task.State
+	}
+	want := map[string]TaskState{"root": StateFailed, "child": StateCancelled, "grandchild": StateCancelled, "cleanup": StateDone}
+	for k, v := range want {
+		if states[k] != v {
+			t.Errorf("%s: state %s, want %s", k, states[k], v)
+		}
+	}
+	if st.State != DagFailed {
+		t.Errorf("dag state = %s, want failed", st.State)
+	}
+}
+
+func TestDagBatchValidation(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+
+	for name, body := range map[string]string{
+		"cycle":        `{"agentId":"a1","tasks":[{"key":"a","action":"click","dependsOn":["b"]},{"key":"b","action":"click","dependsOn":["a"]}]}`,
+		"unknown":      `{"agentId":"a1","tasks":[{"key":"a","action":"click","dependsOn":["nope"]}]}`,
+		"duplicate":    `{"agentId":"a1","tasks":[{"key":"a","action":"click"},{"key":"a","action":"click"}]}`,
+		"bad template": `{"agentId":"a1","tasks":[{"key":"a","action":"click"},{"key":"b","action":"fill","params":{"v":"{{a.result}}"}}]}`,
+	} {
+		if code, resp := postDagBatch(t, mux, body); code != 400 {
+			t.Errorf("%s: expected 400, got %d: %v", name, code, resp)
+		}
+	}
+	if s.QueueStats().TotalQueued != 0 {
+		t.Error("rejected DAG must not queue any task")
+	}
+}
+
+func TestCancelWaitingTaskCascades(t *testing.T) {
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	root, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click"})
+	mid, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", DependsOn: []string{root.ID}})
+	leaf, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", DependsOn: []string{mid.ID}})
+
+	if err := s.Cancel(mid.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetTask(leaf.ID); got.State != StateCancelled || !strings.Contains(got.Error, mid.ID) {
+		t.Errorf("leaf should be cancelled by its dependency, got %+v", got)
+	}
+	if got := s.GetTask(root.ID); got.GetState() != StateQueued {
+		t.Errorf("root should be unaffected, got %s", got.GetState())
+	}
+	if st := s.GetDag(root.DagID); st == nil || st.State != DagRunning || len(st.Tasks) != 3 {
+		t.Errorf("unexpected dag status: %+v", st)
+	}
+}
+
+func TestRestoreWaitingTask(t *testing.T) {
+	dir := t.TempDir()
+	st, _ := NewTaskStore(StoreFile, dir)
+	now := time.Now()
+	_ = st.Put(&Task{ID: "root", AgentID: "a1", State: StateDone, DagID: "dag_1", CreatedAt: now, CompletedAt: now, Result: map[string]any{"v": "x"}})
+	_ = st.Put(&Task{ID: "child", AgentID: "a1", Action: "fill", State: StateWaiting, DagID: "dag_1", DependsOn: []string{"root"},
+		OnDependencyFailure: DepFailureCancel, Params: map[string]any{"text": "{{root.result.v}}"}, CreatedAt: now.Add(time.Millisecond)})
+	_ = st.Close()
+
+	st, _ = NewTaskStore(StoreFile, dir)
+	defer func() { _ = st.Close() }()
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	s.SetStore(st)
+	s.restore()
+
+	child := s.GetTask("child")
+	if child == nil || child.GetState() != StateQueued || child.Params["text"] != "x" {
+		t.Fatalf("waiting task should be released on restore: %+v", child)
+	}
+	if dag := s.GetDag("dag_1"); dag == nil || len(dag.Tasks) != 2 {
+		t.Errorf("dag not rebuilt: %+v", dag)
+	}
+}
@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// eventHistory is how many task events are kept for Last-Event-ID
+	// replay.
+	eventHistory = 1000
+	// eventSubscriberBuffer is the per-subscriber channel size. A
+	// subscriber that falls further behind is disconnected and can resume
+	// from its last event ID.
+	eventSubscriberBuffer = 256
+)
+
+// TaskEvent is one task lifecycle change. From is empty for the event that
+// announces a newly admitted task.
+type TaskEvent struct {
+	ID      uint64    `json:"id"`
+	Time    time.Time `json:"time"`
+	TaskID  string    `json:"taskId"`
+	AgentID string    `json:"agentId"`
+	From    TaskState `json:"from,omitempty"`
+	State   TaskState `json:"state"`
+	Task    *Task     `json:"task"`
+}
+
+// EventFilter selects the events a subscriber receives. Empty fields match
+// everything.
+type EventFilter struct {
+	AgentID string
+	States  []TaskState
+	TaskIDs []string
+}
+
+// Match reports whether e passes the filter.
+func (f EventFilter) Match(e TaskEvent) bool {
+	if f.AgentID != "" && e.AgentID != f.AgentID {
+		return false
+	}
+	if len(f.States) > 0 && !slices.Contains(f.States, e.State) {
+		return false
+	}
+	if len(f.TaskIDs) > 0 && !slices.Contains(f.TaskIDs, e.TaskID) {
+		return false
+	}
+	return true
+}
+
+// eventLog fans task events out to subscribers and keeps a bounded history
+// for replay.
+type eventLog struct {
+	mu      sync.Mutex
+	next    uint64
+	history []TaskEvent
+	subs    map[chan TaskEvent]struct{}
+	closed  bool
+}
+
+// newEventLog starts event IDs at the current time in microseconds, so IDs
+// from before a restart are always older than anything retained.
+func newEventLog() *eventLog {
+	return &eventLog{
+		next: uint64(time.Now().UnixMicro()),
+		subs: make(map[chan TaskEvent]struct{}),
+	}
+}
+
+// publish records a transition of t from the given state.
+func (l *eventLog) publish(t *Task, from TaskState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	snap := t.Snapshot()
+	l.next++
+	e := TaskEvent{
+		ID:      l.next,
+		Time:    timeNow(),
+		TaskID:  snap.ID,
+		AgentID: snap.AgentID,
+		From:    from,
+		State:   snap.State,
+		Task:    snap,
+	}
+	if len(l.history) >= eventHistory {
+		l.history = slices.Delete(l.history, 0, len(l.history)-eventHistory+1)
+	}
+	l.history = append(l.history, e)
+
+	for ch := range l.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(l.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe registers a subscriber. With after > 0 it also returns the
+// retained events newer than after, and reports a gap when events between
+// after and the oldest retained one were dropped. The channel is closed on
+// unsubscribe, on shutdown, or when the subscriber falls behind.
+func (l *eventLog) subscribe(after uint64) (replay []TaskEvent, gap bool, ch chan TaskEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ch = make(chan TaskEvent, eventSubscriberBuffer)
+	if l.closed {
+		close(ch)
+		return nil, false, ch
+	}
+	l.subs[ch] = struct{}{}
+
+	if after == 0 {
+		return nil, false, ch
+	}
+	if len(l.history) > 0 {
+		gap = after+1 < l.history[0].ID
+	} else {
+		gap = after < l.next
+	}
+	i, _ := slices.BinarySearchFunc(l.history, after+1, func(e TaskEvent, id uint64) int {
+		switch {
+		case e.ID < id:
+			return -1
+		case e.ID > id:
+			return 1
+		}
+		return 0
+	})
+	return slices.Clone(l.history[i:]), gap, ch
+}
+
+func (l *eventLog) unsubscribe(ch chan TaskEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.subs[ch]; ok {
+		delete(l.subs, ch)
+		close(ch)
+	}
+}
+
+// close disconnects all subscribers.
+func (l *eventLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	for ch := range l.subs {
+		delete(l.subs, ch)
+		close(ch)
+	}
+}
+
+// observe is the Task.observer of every task the scheduler owns.
+func (s *Scheduler) observe(t *Task, from TaskState) {
+	s.events.publish(t, from)
+}
+
+// SubscribeEvents returns the task events after the given event ID, a
+// channel of new ones, and a function that ends the subscription. gap
+// reports that some events after the ID are no longer retained.
+func (s *Scheduler) SubscribeEvents(after uint64) (replay []TaskEvent, gap bool, events <-chan TaskEvent, cancel func()) {
+	replay, gap, ch := s.events.subscribe(after)
+	return replay, gap, ch, func() { s.events.unsubscribe(ch) }
+}
@@ -0,0 +1,215 @@
+package scheduler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventLogReplay(t *testing.T) {
+	l := newEventLog()
+	task := &Task{ID: "t1", AgentID: "a1", State: StateQueued}
+	for range 3 {
+		l.publish(task, "")
+	}
+	base := l.history[0].ID
+
+	replay, gap, ch := l.subscribe(base)
+	defer l.unsubscribe(ch)
+	if gap || len(replay) != 2 || replay[0].ID != base+1 {
+		t.Fatalf("expected the 2 events after %d, got %d (gap=%v)", base, len(replay), gap)
+	}
+
+	if _, gap, ch := l.subscribe(base - 5); !gap {
+		t.Error("an ID older than the history should report a gap")
+	} else {
+		l.unsubscribe(ch)
+	}
+
+	l.publish(task, StateQueued)
+	select {
+	case e := <-ch:
+		if e.ID != base+3 || e.From != StateQueued {
+			t.Errorf("unexpected live event: %+v", e)
+		}
+	default:
+		t.Error("subscriber should receive new events")
+	}
+}
+
+func TestEventLogDropsSlowSubscriber(t *testing.T) {
+	l := newEventLog()
+	_, _, ch := l.subscribe(0)
+	task := &Task{ID: "t1", State: StateQueued}
+	for range eventSubscriberBuffer + 1 {
+		l.publish(task, "")
+	}
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != eventSubscriberBuffer {
+		t.Errorf("expected %d buffered events before close, got %d", eventSubscriberBuffer, n)
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	e := TaskEvent{TaskID: "t1", AgentID: "a1", State: StateDone}
+	for _, f := range []EventFilter{{}, {AgentID: "a1"}, {States: []TaskState{StateFailed, StateDone}}, {TaskIDs: []string{"t0", "t1"}}} {
+		if !f.Match(e) {
+			t.Errorf("%+v should match", f)
+		}
+	}
+	for _, f := range []EventFilter{{AgentID: "a2"}, {States: []TaskState{StateQueued}}, {TaskIDs: []string{"t2"}}} {
+		if f.Match(e) {
+			t.Errorf("%+v should not match", f)
+		}
+	}
+}
+
+type sseEvent struct {
+	id, event, data string
+}
+
+// readSSE reads events from an SSE response until n have arrived.
+func readSSE(t *testing.T, resp *http.Response, n int) []sseEvent {
+	t.Helper()
+	events := make(chan sseEvent, 64)
+	go func() {
+		defer close(events)
+		sc := bufio.NewScanner(resp.Body)
+		sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		var cur sseEvent
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case line == "":
+				if cur.event != "" {
+					events <- cur
+				}
+				cur = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				cur.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				cur.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				cur.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	var got []sseEvent
+	timeout := time.After(5 * time.Second)
+	for len(got) < n {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatalf("stream ended after %d events", len(got))
+			}
+			got = append(got, e)
+		case <-timeout:
+			t.Fatalf("timed out after %d of %d events", len(got), n)
+		}
+	}
+	return got
+}
+
+func openStream(t *testing.T, url, lastID string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected stream response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return resp
+}
+
+func TestHandlerStreamLifecycle(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	task, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := s.Submit(SubmitRequest{AgentID: "a2", Action: "click", TabID: "t2"})
+
+	resp := openStream(t, fmt.Sprintf("%s/tasks/stream?agentId=a1&taskId=%s", srv.URL, task.ID), "")
+	s.Start()
+	defer s.Stop()
+
+	events := readSSE(t, resp, 3)
+	var states []string
+	for _, e := range events {
+		var te TaskEvent
+		if err := json.Unmarshal([]byte(e.data), &te); err != nil {
+			t.Fatal(err)
+		}
+		if e.event != "task" || e.id != fmt.Sprint(te.ID) || te.TaskID != task.ID || te.Task == nil {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+		states = append(states, string(te.From)+">"+string(te.State))
+	}
+	if got := strings.Join(states, " "); got != "queued>assigned assigned>running running>done" {
+		t.Errorf("transitions = %q", got)
+	}
+
+	waitTerminal(t, s, other.ID)
+}
+
+func TestHandlerStreamReplay(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	first, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1"})
+	cursor := s.events.history[0].ID // the submission event
+
+	s.Start()
+	defer s.Stop()
+	waitTerminal(t, s, first.ID)
+
+	resp := openStream(t, srv.URL+"/tasks/stream?state=done", fmt.Sprint(cursor))
+	events := readSSE(t, resp, 1)
+	var te TaskEvent
+	_ = json.Unmarshal([]byte(events[0].data), &te)
+	if te.TaskID != first.ID || te.State != StateDone {
+		t.Fatalf("expected the missed completion, got %+v", te)
+	}
+
+	gap := openStream(t, srv.URL+"/tasks/stream", "1")
+	if e := readSSE(t, gap, 1)[0]; e.event != "gap" {
+		t.Errorf("expected a gap event for an unknown cursor, got %+v", e)
+	}
+}
+
+func TestHandlerStreamBadCursor(t *testing.T) {
+	_, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/tasks/stream?lastEventId=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != 400 {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
@@ -1,8 +1,14 @@
 package scheduler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
@@ -11,10 +17,21 @@ import (
 func (s *Scheduler) RegisterHandlers(mux *http.ServeMux) {
 	mux.HandleFunc("POST /tasks", s.handleSubmit)
 	mux.HandleFunc("GET /tasks", s.handleList)
+	mux.HandleFunc("GET /tasks/stream", s.handleStream)
 	mux.HandleFunc("GET /tasks/{id}", s.handleGet)
 	mux.HandleFunc("POST /tasks/{id}/cancel", s.handleCancel)
 	mux.HandleFunc("GET /scheduler/stats", s.handleStats)
+	mux.HandleFunc("GET /scheduler/agents/{id}/usage", s.handleAgentUsage)
+	mux.HandleFunc("GET /webhooks/deliveries", s.handleDeliveries)
+	mux.HandleFunc("GET /webhooks/dead-letter", s.handleDeadLetters)
 	mux.HandleFunc("POST /tasks/batch", s.handleBatch)
+	mux.HandleFunc("GET /dags/{id}", s.handleGetDag)
+	mux.HandleFunc("POST /schedules", s.handleCreateSchedule)
+	mux.HandleFunc("GET /schedules", s.handleListSchedules)
+	mux.HandleFunc("GET /schedules/{id}", s.handleGetSchedule)
+	mux.HandleFunc("DELETE /schedules/{id}", s.handleDeleteSchedule)
+	mux.HandleFunc("POST /schedules/{id}/pause", s.handlePauseSchedule)
+	mux.HandleFunc("POST /schedules/{id}/resume", s.handleResumeSchedule)
 }
 
 func (s *Scheduler) handleSubmit(w http.ResponseWriter, r *http.Request) {
@@ -24,15 +41,30 @@ func (s *Scheduler) handleSubmit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := s.Submit(req)
+	task, existing, err := s.submit(req)
 	if err != nil {
+		if errors.Is(err, ErrIdempotencyConflict) {
+			httpx.ErrorCode(w, 409, "idempotency_conflict", err.Error(), false, map[string]any{
+				"idempotencyKey": req.IdempotencyKey,
+			})
+			return
+		}
+		var rerr *RejectError
+		if task != nil && errors.As(err, &rerr) && !rerr.queueFull() {
+			writeQuotaRejection(w, req.AgentID, rerr)
+			return
+		}
 		if task != nil && task.State == StateRejected {
 			stats := s.QueueStats()
+			s.cfgMu.RLock()
+			maxQueue, maxPerAgent := s.cfg.MaxQueueSize, s.cfg.MaxPerAgent
+			s.cfgMu.RUnlock()
 			httpx.ErrorCode(w, 429, "queue_full", err.Error(), true, map[string]any{
 				"agentId":     req.AgentID,
+				"reason":      task.Snapshot().RejectReason,
 				"queued":      stats.TotalQueued,
-				"maxQueue":    s.cfg.MaxQueueSize,
-				"maxPerAgent": s.cfg.MaxPerAgent,
+				"maxQueue":    maxQueue,
+				"maxPerAgent": maxPerAgent,
 			})
 			return
 		}
@@ -41,12 +73,31 @@ func (s *Scheduler) handleSubmit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	snap := task.Snapshot()
-	httpx.JSON(w, 202, map[string]any{
+	resp := map[string]any{
 		"taskId":    snap.ID,
 		"state":     snap.State,
 		"position":  snap.Position,
 		"createdAt": snap.CreatedAt,
-	})
+	}
+	if snap.DagID != "" {
+		resp["dagId"] = snap.DagID
+	}
+	if existing {
+		// Replayed submission: report the original task as-is.
+		resp["duplicate"] = true
+		httpx.JSON(w, 200, resp)
+		return
+	}
+	httpx.JSON(w, 202, resp)
+}
+
+func (s *Scheduler) handleGetDag(w http.ResponseWriter, r *http.Request) {
+	dag := s.GetDag(r.PathValue("id"))
+	if dag == nil {
+		httpx.ErrorCode(w, 404, "not_found", "dag not found", false, nil)
+		return
+	}
+	httpx.JSON(w, 200, dag)
 }
 
 func (s *Scheduler) handleGet(w http.ResponseWriter, r *http.Request) {
@@ -109,20 +160,228 @@ func (s *Scheduler) handleList(w http.ResponseWriter, r *http.Request) {
 	httpx.JSON(w, 200, map[string]any{"tasks": tasks, "count": len(tasks)})
 }
 
+// handleStream serves task events as server-sent events. A client that
+// reconnects with Last-Event-ID (or ?lastEventId=) first receives the
+// retained events it missed, and a "gap" event if some are gone.
+func (s *Scheduler) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		http.Error(w, "streaming deadline unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := EventFilter{AgentID: q.Get("agentId"), TaskIDs: splitList(q.Get("taskId"))}
+	for _, st := range splitList(q.Get("state")) {
+		filter.States = append(filter.States, TaskState(st))
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = q.Get("lastEventId")
+	}
+	var after uint64
+	if lastID != "" {
+		n, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			httpx.ErrorCode(w, 400, "bad_event_id", fmt.Sprintf("invalid Last-Event-ID %q", lastID), false, nil)
+			return
+		}
+		after = n
+	}
+
+	replay, gap, events, cancel := s.SubscribeEvents(after)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if gap {
+		if _, err := fmt.Fprintf(w, "event: gap\ndata: {\"lastEventId\":%d}\n\n", after); err != nil {
+			return
+		}
+	}
+	for _, e := range replay {
+		if filter.Match(e) && !writeTaskEvent(w, e) {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if !filter.Match(e) {
+				continue
+			}
+			if !writeTaskEvent(w, e) {
+				return
+			}
+			flusher.Flush()
+		case <-keepalive.C:
+			if _, err := fmt.Fprintf(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeTaskEvent(w http.ResponseWriter, e TaskEvent) bool {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return true
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: task\ndata: %s\n\n", e.ID, data)
+	return err == nil
+}
+
+// splitList splits a comma-separated query value, dropping empty items.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// writeQuotaRejection answers a submission refused by an agent quota with
+// 429, the rejection reason as error code, and Retry-After.
+func writeQuotaRejection(w http.ResponseWriter, agentID string, rerr *RejectError) {
+	details := map[string]any{"agentId": agentID, "reason": rerr.Reason}
+	if rerr.RetryAfter > 0 {
+		secs := int(math.Ceil(rerr.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+		details["retryAfterSec"] = secs
+	}
+	httpx.ErrorCode(w, 429, rerr.Reason, rerr.Error(), true, details)
+}
+
+func (s *Scheduler) handleAgentUsage(w http.ResponseWriter, r *http.Request) {
+	agentID := r.PathValue("id")
+	if agentID == "" {
+		httpx.Error(w, 400, errMissingAgentID)
+		return
+	}
+	httpx.JSON(w, 200, s.AgentUsage(agentID))
+}
+
+func deliveryFilter(r *http.Request) DeliveryFilter {
+	q := r.URL.Query()
+	return DeliveryFilter{TaskID: q.Get("taskId"), State: q.Get("state")}
+}
+
+func (s *Scheduler) handleDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries := s.Deliveries(deliveryFilter(r))
+	httpx.JSON(w, 200, map[string]any{"deliveries": deliveries, "count": len(deliveries)})
+}
+
+func (s *Scheduler) handleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	deliveries := s.DeadLetters(deliveryFilter(r))
+	httpx.JSON(w, 200, map[string]any{"deliveries": deliveries, "count": len(deliveries)})
+}
+
 func (s *Scheduler) handleStats(w http.ResponseWriter, _ *http.Request) {
 	queue := s.QueueStats()
 	metrics := s.GetMetrics()
+	s.cfgMu.RLock()
+	cfg := s.cfg
+	s.cfgMu.RUnlock()
 	httpx.JSON(w, 200, map[string]any{
 		"queue":   queue,
 		"metrics": metrics,
+		"tabPool": s.tabs.stats(),
 		"config": map[string]any{
-			"strategy":          s.cfg.Strategy,
-			"maxQueueSize":      s.cfg.MaxQueueSize,
-			"maxPerAgent":       s.cfg.MaxPerAgent,
-			"maxInflight":       s.cfg.MaxInflight,
-			"maxPerAgentFlight": s.cfg.MaxPerAgentFlight,
-			"workerCount":       s.cfg.WorkerCount,
-			"resultTTL":         s.cfg.ResultTTL.String(),
+			"strategy":           cfg.Strategy,
+			"agentWeights":       cfg.AgentWeights,
+			"starvationAfter":    cfg.StarvationAfter.String(),
+			"serializeTabs":      cfg.SerializeTabs,
+			"limits":             s.quotas.config(),
+			"webhookSigned":      cfg.WebhookSecret != "",
+			"webhookMaxAttempts": cfg.WebhookMaxAttempts,
+			"webhookBackoff":     cfg.WebhookBackoff.String(),
+			"tabPoolSize":        cfg.TabPoolSize,
+			"maxQueueSize":       cfg.MaxQueueSize,
+			"maxPerAgent":        cfg.MaxPerAgent,
+			"maxInflight":        cfg.MaxInflight,
+			"maxPerAgentFlight":  cfg.MaxPerAgentFlight,
+			"workerCount":        cfg.WorkerCount,
+			"resultTTL":          cfg.ResultTTL.String(),
+			"store":              cfg.Store,
+			"inflightPolicy":     cfg.InflightPolicy,
 		},
 	})
 }
+
+func (s *Scheduler) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleRequest
+	if err := httpx.DecodeJSONBody(w, r, 0, &req); err != nil {
+		httpx.Error(w, httpx.StatusForJSONDecodeError(err), err)
+		return
+	}
+
+	sc, err := s.CreateSchedule(req)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	httpx.JSON(w, 201, sc)
+}
+
+func (s *Scheduler) handleListSchedules(w http.ResponseWriter, _ *http.Request) {
+	schedules := s.ListSchedules()
+	httpx.JSON(w, 200, map[string]any{"schedules": schedules, "count": len(schedules)})
+}
+
+func (s *Scheduler) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	sc := s.GetSchedule(r.PathValue("id"))
+	if sc == nil {
+		httpx.ErrorCode(w, 404, "not_found", "schedule not found", false, nil)
+		return
+	}
+	httpx.JSON(w, 200, sc)
+}
+
+func (s *Scheduler) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.DeleteSchedule(id); err != nil {
+		httpx.ErrorCode(w, 404, "not_found", err.Error(), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, map[string]string{"status": "deleted", "id": id})
+}
+
+func (s *Scheduler) handlePauseSchedule(w http.ResponseWriter, r *http.Request) {
+	sc, err := s.PauseSchedule(r.PathValue("id"))
+	if err != nil {
+		httpx.ErrorCode(w, 404, "not_found", err.Error(), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, sc)
+}
+
+func (s *Scheduler) handleResumeSchedule(w http.ResponseWriter, r *http.Request) {
+	sc, err := s.ResumeSchedule(r.PathValue("id"))
+	if err != nil {
+		httpx.ErrorCode(w, 404, "not_found", err.Error(), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, sc)
+}
@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+)
+
+// maxIdempotencyKeyLen bounds SubmitRequest.IdempotencyKey.
+const maxIdempotencyKeyLen = 255
+
+// ErrIdempotencyConflict is returned when an idempotency key is reused
+// with a different request body.
+var ErrIdempotencyConflict = errors.New("idempotencyKey was already used with a different request")
+
+// idemEntry maps an agent's idempotency key to the task it created.
+type idemEntry struct {
+	taskID string
+	// fingerprint is empty for entries rebuilt from the task store, which
+	// then match any request.
+	fingerprint string
+}
+
+func idemIndexKey(agentID, key string) string {
+	return agentID + "\x00" + key
+}
+
+// requestFingerprint hashes the request without its key, so a retry of the
+// same request matches and a different request under the same key does not.
+func requestFingerprint(req SubmitRequest) string {
+	req.IdempotencyKey = ""
+	b, _ := json.Marshal(req)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// submit is Submit with idempotency. It reports whether the returned task
+// was created by an earlier request with the same key. A key is held for
+// as long as its task is live or its result is retained; rejected
+// submissions do not hold one.
+func (s *Scheduler) submit(req SubmitRequest) (*Task, bool, error) {
+	if req.IdempotencyKey == "" {
+		t, err := s.submitNew(req)
+		return t, false, err
+	}
+
+	key := idemIndexKey(req.AgentID, req.IdempotencyKey)
+	fp := requestFingerprint(req)
+
+	s.idemMu.Lock()
+	defer s.idemMu.Unlock()
+	if e, ok := s.idem[key]; ok {
+		if t := s.GetTask(e.taskID); t != nil {
+			if e.fingerprint != "" && e.fingerprint != fp {
+				return nil, false, ErrIdempotencyConflict
+			}
+			return t, true, nil
+		}
+		delete(s.idem, key)
+	}
+
+	t, err := s.submitNew(req)
+	if err == nil {
+		s.idem[key] = idemEntry{taskID: t.ID, fingerprint: fp}
+	}
+	return t, false, err
+}
+
+// indexIdempotencyKey records a restored task's key.
+func (s *Scheduler) indexIdempotencyKey(t *Task) {
+	if t.IdempotencyKey == "" || t.State == StateRejected {
+		return
+	}
+	s.idemMu.Lock()
+	s.idem[idemIndexKey(t.AgentID, t.IdempotencyKey)] = idemEntry{taskID: t.ID}
+	s.idemMu.Unlock()
+}
+
+// pruneIdempotency drops keys whose task has been evicted.
+func (s *Scheduler) pruneIdempotency() {
+	s.idemMu.Lock()
+	defer s.idemMu.Unlock()
+	for key, e := range s.idem {
+		if s.GetTask(e.taskID) == nil {
+			delete(s.idem, key)
+		}
+	}
+}
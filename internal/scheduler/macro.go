@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"time"
+)
+
+// ActionMacro is the task action that runs a list of steps on one tab as a
+// single task.
+const ActionMacro = "macro"
+
+const (
+	// maxMacroSteps bounds the steps of one macro task.
+	maxMacroSteps = 100
+	// macroUnlockTimeout bounds releasing the tab lease after a macro,
+	// which runs even when the task's own deadline has passed.
+	macroUnlockTimeout = 5 * time.Second
+)
+
+// validateMacroParams checks the params of a macro task: a non-empty
+// "steps" list of action objects, each with a "kind". Steps cannot name a
+// tab other than the task's, or any tab when the scheduler places the task.
+func validateMacroParams(tabID string, params map[string]any) error {
+	steps, ok := params["steps"].([]any)
+	if !ok || len(steps) == 0 {
+		return fmt.Errorf("macro tasks require a non-empty 'params.steps' list")
+	}
+	if len(steps) > maxMacroSteps {
+		return fmt.Errorf("macro has %d steps (max %d)", len(steps), maxMacroSteps)
+	}
+	for i, raw := range steps {
+		step, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("params.steps[%d] must be an object", i)
+		}
+		kind, _ := step["kind"].(string)
+		switch kind {
+		case "":
+			return fmt.Errorf("params.steps[%d] is missing 'kind'", i)
+		case ActionMacro:
+			return fmt.Errorf("params.steps[%d]: macros cannot be nested", i)
+		}
+		if tab, ok := step["tabId"].(string); ok && tab != "" && tab != tabID {
+			return fmt.Errorf("params.steps[%d] targets tab %q; all steps run on the task's tab", i, tab)
+		}
+	}
+	if v, ok := params["stopOnError"]; ok {
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("params.stopOnError must be a boolean")
+		}
+	}
+	return nil
+}
+
+// macroStepError reports the first failed step of a macro.
+type macroStepError struct {
+	step int
+	msg  string
+}
+
+func (e *macroStepError) Error() string {
+	return fmt.Sprintf("macro step %d failed: %s", e.step, e.msg)
+}
+
+// macroFailure finds the first failed step in a macro response.
+func macroFailure(result any) *macroStepError {
+	m, _ := result.(map[string]any)
+	steps, _ := m["results"].([]any)
+	for _, raw := range steps {
+		step, _ := raw.(map[string]any)
+		if ok, _ := step["success"].(bool); ok {
+			continue
+		}
+		idx, _ := step["index"].(float64)
+		msg, _ := step["error"].(string)
+		return &macroStepError{step: int(idx), msg: msg}
+	}
+	return nil
+}
+
+// executeMacro runs a macro task on its tab. The tab is leased to the task
+// for the whole run, so requests from other owners get 423 until the last
+// step has finished, and the steps go to the instance in one request so
+// nothing else the scheduler dispatches can interleave. The lease expires
+// with the task deadline in case it cannot be released.
+//
+// The result is the instance's macro response with per-step results. With
+// stopOnError (the default) a failed step fails the task, and the result
+// is returned with the error so the task still shows which steps ran.
+func (s *Scheduler) executeMacro(ctx context.Context, t *Task, tabID, port string) (any, error) {
+	owner := "scheduler:" + t.ID
+	lockPath := fmt.Sprintf("/tabs/%s/lock", tabID)
+	lease := map[string]any{"owner": owner}
+	if deadline, ok := ctx.Deadline(); ok {
+		lease["timeoutSec"] = max(1, int(math.Ceil(time.Until(deadline).Seconds())))
+	}
+	if _, err := s.postInstance(ctx, t, port, lockPath, lease, ""); err != nil {
+		return nil, fmt.Errorf("tab lease: %w", err)
+	}
+	defer func() {
+		uctx, cancel := context.WithTimeout(context.Background(), macroUnlockTimeout)
+		defer cancel()
+		unlockPath := fmt.Sprintf("/tabs/%s/unlock", tabID)
+		if _, err := s.postInstance(uctx, t, port, unlockPath, map[string]any{"owner": owner}, ""); err != nil {
+			slog.Warn("macro: tab unlock failed", "task", t.ID, "tab", tabID, "err", err)
+		}
+	}()
+
+	body := map[string]any{"stopOnError": true}
+	maps.Copy(body, t.Params)
+	body["tabId"] = tabID
+	body["owner"] = owner
+	result, err := s.postInstance(ctx, t, port, "/macro", body, owner)
+	if err != nil {
+		return nil, err
+	}
+	if stop, _ := body["stopOnError"].(bool); stop {
+		if stepErr := macroFailure(result); stepErr != nil {
+			return result, stepErr
+		}
+	}
+	return result, nil
+}
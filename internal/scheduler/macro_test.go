@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// macroInstance fakes the instance endpoints a macro task uses and records
+// the requests it receives.
+type macroInstance struct {
+	mu       sync.Mutex
+	calls    []string
+	owners   []string
+	lease    map[string]any
+	macro    map[string]any
+	lockCode int
+	results  []map[string]any
+}
+
+func (m *macroInstance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, r.URL.Path)
+	m.owners = append(m.owners, r.Header.Get("X-Owner"))
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/lock"):
+		m.lease = body
+		if m.lockCode != 0 {
+			w.WriteHeader(m.lockCode)
+			_, _ = w.Write([]byte(`{"error":"tab tab-1 is locked by someone"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"locked":true}`))
+	case r.URL.Path == "/macro":
+		m.macro = body
+		_ = json.NewEncoder(w).Encode(map[string]any{"kind": "macro", "results": m.results, "total": len(m.results)})
+	default:
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}
+}
+
+func runMacroTask(t *testing.T, inst *macroInstance, params map[string]any) *Task {
+	t.Helper()
+	srv := httptest.NewServer(inst)
+	t.Cleanup(srv.Close)
+	parts := strings.Split(srv.URL, ":")
+
+	s := New(DefaultConfig(), &mockResolver{port: parts[len(parts)-1]})
+	task, err := s.Submit(SubmitRequest{AgentID: "a1", Action: ActionMacro, TabID: "tab-1", Params: params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	t.Cleanup(s.Stop)
+	return waitTerminal(t, s, task.ID)
+}
+
+func macroSteps(kinds ...string) map[string]any {
+	steps := make([]any, len(kinds))
+	for i, k := range kinds {
+		steps[i] = map[string]any{"kind": k, "selector": "#x"}
+	}
+	return map[string]any{"steps": steps}
+}
+
+func TestValidateMacroParams(t *testing.T) {
+	tooMany := make([]any, maxMacroSteps+1)
+	for i := range tooMany {
+		tooMany[i] = map[string]any{"kind": "click"}
+	}
+	tests := []struct {
+		name   string
+		tabID  string
+		params map[string]any
+		ok     bool
+	}{
+		{"valid", "tab-1", macroSteps("click", "type"), true},
+		{"placed by scheduler", "", macroSteps("click"), true},
+		{"step names a tab of a placed macro", "", map[string]any{"steps": []any{map[string]any{"kind": "click", "tabId": "tab-2"}}}, false},
+		{"no steps", "tab-1", map[string]any{}, false},
+		{"step not an object", "tab-1", map[string]any{"steps": []any{"click"}}, false},
+		{"missing kind", "tab-1", map[string]any{"steps": []any{map[string]any{"selector": "#x"}}}, false},
+		{"nested macro", "tab-1", macroSteps("click", ActionMacro), false},
+		{"other tab", "tab-1", map[string]any{"steps": []any{map[string]any{"kind": "click", "tabId": "tab-2"}}}, false},
+		{"too many steps", "tab-1", map[string]any{"steps": tooMany}, false},
+		{"bad stopOnError", "tab-1", map[string]any{"steps": []any{map[string]any{"kind": "click"}}, "stopOnError": "yes"}, false},
+	}
+	for _, tt := range tests {
+		req := SubmitRequest{AgentID: "a1", Action: ActionMacro, TabID: tt.tabID, Params: tt.params}
+		if err := req.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: got err=%v, want ok=%v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestMacroTaskHoldsTabLease(t *testing.T) {
+	inst := &macroInstance{results: []map[string]any{
+		{"index": 0, "success": true},
+		{"index": 1, "success": true},
+	}}
+	task := runMacroTask(t, inst, macroSteps("click", "type"))
+
+	if task.State != StateDone {
+		t.Fatalf("expected done, got %s (%s)", task.State, task.Error)
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if got := strings.Join(inst.calls, " "); got != "/tabs/tab-1/lock /macro /tabs/tab-1/unlock" {
+		t.Fatalf("unexpected call sequence %q", got)
+	}
+	owner := "scheduler:" + task.ID
+	if inst.lease["owner"] != owner || inst.lease["timeoutSec"] == nil {
+		t.Errorf("lease should be taken for the task with a timeout, got %v", inst.lease)
+	}
+	if inst.owners[1] != owner || inst.macro["owner"] != owner || inst.macro["tabId"] != "tab-1" || inst.macro["stopOnError"] != true {
+		t.Errorf("macro should run as the lease owner, got header %q body %v", inst.owners[1], inst.macro)
+	}
+	if steps, _ := inst.macro["steps"].([]any); len(steps) != 2 {
+		t.Errorf("expected 2 steps forwarded, got %v", inst.macro["steps"])
+	}
+	result, _ := task.Result.(map[string]any)
+	if results, _ := result["results"].([]any); len(results) != 2 {
+		t.Errorf("task result should carry per-step results, got %v", task.Result)
+	}
+}
+
+func TestMacroTaskStepFailure(t *testing.T) {
+	inst := &macroInstance{results: []map[string]any{
+		{"index": 0, "success": true},
+		{"index": 1, "success": false, "error": "element not found"},
+	}}
+	task := runMacroTask(t, inst, macroSteps("click", "click"))
+
+	if task.State != StateFailed || !strings.Contains(task.Error, "macro step 1 failed: element not found") {
+		t.Fatalf("expected step 1 failure, got %s: %q", task.State, task.Error)
+	}
+	if task.Result == nil {
+		t.Error("a failed macro should keep its per-step results")
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if inst.calls[len(inst.calls)-1] != "/tabs/tab-1/unlock" {
+		t.Errorf("lease should be released after a failure, got %v", inst.calls)
+	}
+}
+
+func TestMacroTaskContinueOnError(t *testing.T) {
+	inst := &macroInstance{results: []map[string]any{
+		{"index": 0, "success": false, "error": "boom"},
+		{"index": 1, "success": true},
+	}}
+	params := macroSteps("click", "click")
+	params["stopOnError"] = false
+	if task := runMacroTask(t, inst, params); task.State != StateDone {
+		t.Fatalf("stopOnError=false should not fail the task, got %s: %q", task.State, task.Error)
+	}
+}
+
+func TestMacroTaskTabLocked(t *testing.T) {
+	inst := &macroInstance{lockCode: 409}
+	task := runMacroTask(t, inst, macroSteps("click"))
+
+	if task.State != StateFailed || !strings.Contains(task.Error, "tab lease") {
+		t.Fatalf("expected a lease failure, got %s: %q", task.State, task.Error)
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	for _, c := range inst.calls {
+		if c == "/macro" {
+			t.Fatal("steps must not run without the lease")
+		}
+	}
+}
+
+func TestQueueMacroExclusiveTab(t *testing.T) {
+	q := newPolicyQueue(t, QueuePolicy{Strategy: StrategyFairFIFO})
+	mustEnqueue(t, q, &Task{ID: "click", AgentID: "a1", TabID: "tab1"})
+	first := q.Dequeue(100, 100)
+	if first.ID != "click" {
+		t.Fatalf("expected click first, got %s", first.ID)
+	}
+
+	mustEnqueue(t, q, &Task{ID: "macro", AgentID: "a2", TabID: "tab1", Action: ActionMacro})
+	mustEnqueue(t, q, &Task{ID: "after", AgentID: "a1", TabID: "tab1"})
+	mustEnqueue(t, q, &Task{ID: "other", AgentID: "a2", TabID: "tab2"})
+	if got := q.Dequeue(100, 100); got == nil || got.ID != "other" {
+		t.Fatalf("macro should wait for tab1 and hold back later tasks, got %+v", got)
+	}
+	if got := q.Dequeue(100, 100); got != nil {
+		t.Fatalf("tab1 tasks must wait behind the queued macro, got %s", got.ID)
+	}
+
+	q.Complete(first.AgentID, first.TabID)
+	macro := q.Dequeue(100, 100)
+	if macro == nil || macro.ID != "macro" {
+		t.Fatalf("expected the macro once tab1 is idle, got %+v", macro)
+	}
+	if got := q.Dequeue(100, 100); got != nil {
+		t.Fatalf("nothing may run on tab1 during the macro, got %s", got.ID)
+	}
+	q.Complete(macro.AgentID, macro.TabID)
+	if got := q.Dequeue(100, 100); got == nil || got.ID != "after" {
+		t.Fatalf("expected the queued click after the macro, got %+v", got)
+	}
+}
+
+func TestQueueRemovedMacroReleasesTab(t *testing.T) {
+	q := newPolicyQueue(t, QueuePolicy{Strategy: StrategyFairFIFO})
+	mustEnqueue(t, q, &Task{ID: "busy", AgentID: "a1", TabID: "tab1"})
+	busy := q.Dequeue(100, 100)
+	mustEnqueue(t, q, &Task{ID: "macro", AgentID: "a2", TabID: "tab1", Action: ActionMacro})
+	mustEnqueue(t, q, &Task{ID: "click", AgentID: "a1", TabID: "tab1"})
+	q.Complete(busy.AgentID, busy.TabID)
+
+	if !q.Remove("macro", "a2") {
+		t.Fatal("macro should be removable")
+	}
+	if got := q.Dequeue(100, 100); got == nil || got.ID != "click" {
+		t.Fatalf("a cancelled macro must not keep holding its tab, got %+v", got)
+	}
+}
@@ -14,6 +14,7 @@ type Metrics struct {
 	TasksCancelled  atomic.Uint64
 	TasksRejected   atomic.Uint64
 	TasksExpired    atomic.Uint64
+	TasksRetried    atomic.Uint64
 	DispatchTotal   atomic.Uint64
 	DispatchLatency atomic.Uint64 // cumulative milliseconds
 
@@ -29,6 +30,7 @@ type AgentMetrics struct {
 	Failed    uint64 `json:"failed"`
 	Cancelled uint64 `json:"cancelled"`
 	Rejected  uint64 `json:"rejected"`
+	Retried   uint64 `json:"retried"`
 }
 
 func newMetrics() *Metrics {
@@ -62,6 +64,11 @@ func (m *Metrics) recordCancel(agentID string) {
 	m.agentMetric(agentID).add(func(a *AgentMetrics) { a.Cancelled++ })
 }
 
+func (m *Metrics) recordRetry(agentID string) {
+	m.TasksRetried.Add(1)
+	m.agentMetric(agentID).add(func(a *AgentMetrics) { a.Retried++ })
+}
+
 func (m *Metrics) recordExpire() {
 	m.TasksExpired.Add(1)
 }
@@ -93,6 +100,7 @@ func (m *Metrics) Snapshot() MetricsSnapshot {
 			Failed:    a.Failed,
 			Cancelled: a.Cancelled,
 			Rejected:  a.Rejected,
+			Retried:   a.Retried,
 		}
 		a.mu.RUnlock()
 	}
@@ -105,6 +113,7 @@ func (m *Metrics) Snapshot() MetricsSnapshot {
 		TasksCancelled:     m.TasksCancelled.Load(),
 		TasksRejected:      m.TasksRejected.Load(),
 		TasksExpired:       m.TasksExpired.Load(),
+		TasksRetried:       m.TasksRetried.Load(),
 		DispatchCount:      dispatched,
 		AvgDispatchLatency: avgMs,
 		Agents:             agents,
@@ -119,6 +128,7 @@ type MetricsSnapshot struct {
 	TasksCancelled     uint64                  `json:"tasksCancelled"`
 	TasksRejected      uint64                  `json:"tasksRejected"`
 	TasksExpired       uint64                  `json:"tasksExpired"`
+	TasksRetried       uint64                  `json:"tasksRetried"`
 	DispatchCount      uint64                  `json:"dispatchCount"`
 	AvgDispatchLatency float64                 `json:"avgDispatchLatencyMs"`
 	Agents             map[string]AgentMetrics `json:"agents"`
@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+)
+
+const (
+	// defaultTabPoolSize is how many idle tabs are kept per pool key.
+	defaultTabPoolSize = 4
+	// maxPooledTabs bounds the idle tabs across all pool keys, since every
+	// agent has pools of its own.
+	maxPooledTabs = 64
+	// tabCloseTimeout bounds closing a placed tab, which runs even when
+	// the task's own deadline has passed.
+	tabCloseTimeout = 5 * time.Second
+)
+
+// TabPlacement records where the scheduler ran a task submitted without a
+// tabId: an instance chosen by the allocation policy and a tab on it.
+type TabPlacement struct {
+	InstanceID string            `json:"instanceId"`
+	TabID      string            `json:"tabId"`
+	Profile    string            `json:"profile,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	// Reused is set when the tab came from the pool rather than being
+	// opened for the task.
+	Reused bool `json:"reused,omitempty"`
+	// Port is not persisted; a restored task is placed again.
+	Port string `json:"-"`
+
+	agentID string
+}
+
+// TabProvisioner opens and closes tabs for tasks submitted without a
+// tabId. The scheduler places such tasks only when its InstanceResolver
+// also implements TabProvisioner.
+type TabProvisioner interface {
+	// OpenTab picks a running instance for req, which names the agent, the
+	// profile ("" for any) and the labels the instance must carry, and
+	// opens a blank tab on it.
+	OpenTab(ctx context.Context, req allocation.Request) (TabPlacement, error)
+	// CloseTab closes a tab opened by OpenTab.
+	CloseTab(ctx context.Context, p TabPlacement) error
+	// ResetTab navigates a tab opened by OpenTab back to about:blank
+	// before it is pooled.
+	ResetTab(ctx context.Context, p TabPlacement) error
+}
+
+// tabPool keeps idle tabs the scheduler opened, keyed by agent, profile
+// and labels, so later tasks of the same agent asking for the same skip
+// opening a tab. Tabs are never shared between agents: the instance was
+// allocated for the agent that opened the tab.
+type tabPool struct {
+	mu   sync.Mutex
+	size int
+	idle map[string][]TabPlacement
+}
+
+func newTabPool(size int) *tabPool {
+	if size <= 0 {
+		size = defaultTabPoolSize
+	}
+	return &tabPool{size: size, idle: make(map[string][]TabPlacement)}
+}
+
+func (p *tabPool) setSize(n int) {
+	p.mu.Lock()
+	p.size = n
+	p.mu.Unlock()
+}
+
+// tabPoolKey is the agent and the poolKey, e.g. "crawler/work{region=eu}".
+func tabPoolKey(agentID, profile string, labels map[string]string) string {
+	return agentID + "/" + poolKey(profile, labels)
+}
+
+// poolKey is the profile, followed by the sorted labels when there are
+// any, e.g. "work{region=eu}".
+func poolKey(profile string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return profile
+	}
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return profile + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// take pops the most recently released idle tab under a pool key.
+func (p *tabPool) take(key string) (TabPlacement, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tabs := p.idle[key]
+	if len(tabs) == 0 {
+		return TabPlacement{}, false
+	}
+	tp := tabs[len(tabs)-1]
+	if len(tabs) == 1 {
+		delete(p.idle, key)
+	} else {
+		p.idle[key] = tabs[:len(tabs)-1]
+	}
+	return tp, true
+}
+
+// put returns a tab to the pool for its agent, profile and labels. It
+// reports false when that pool is full and the tab should be closed
+// instead.
+func (p *tabPool) put(tp TabPlacement) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := tabPoolKey(tp.agentID, tp.Profile, tp.Labels)
+	if len(p.idle[key]) >= p.size || p.countLocked() >= max(p.size, maxPooledTabs) {
+		return false
+	}
+	tp.Reused = false
+	p.idle[key] = append(p.idle[key], tp)
+	return true
+}
+
+func (p *tabPool) countLocked() int {
+	n := 0
+	for _, tabs := range p.idle {
+		n += len(tabs)
+	}
+	return n
+}
+
+// drain empties the pool and returns the tabs it held.
+func (p *tabPool) drain() []TabPlacement {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []TabPlacement
+	for _, tabs := range p.idle {
+		out = append(out, tabs...)
+	}
+	clear(p.idle)
+	return out
+}
+
+// stats returns the number of idle tabs per pool key.
+func (p *tabPool) stats() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make(map[string]int, len(p.idle))
+	for key, tabs := range p.idle {
+		out[key] = len(tabs)
+	}
+	return out
+}
+
+// placeTask gives a task submitted without a tabId a tab: an idle one from
+// the agent's pool for its profile and labels while the instance still
+// owns it, otherwise a new one opened by the provisioner.
+func (s *Scheduler) placeTask(ctx context.Context, t *Task) (TabPlacement, error) {
+	prov, ok := s.resolver.(TabProvisioner)
+	if !ok {
+		return TabPlacement{}, fmt.Errorf("tabId is required: tab placement is not available")
+	}
+	for {
+		tp, ok := s.tabs.take(tabPoolKey(t.AgentID, t.Profile, t.Labels))
+		if !ok {
+			break
+		}
+		port, err := s.resolver.ResolveTabInstance(tp.TabID)
+		if err != nil {
+			slog.Info("scheduler: dropping pooled tab", "tab", tp.TabID, "instance", tp.InstanceID, "err", err)
+			continue
+		}
+		tp.Port, tp.Reused = port, true
+		return tp, nil
+	}
+	tp, err := prov.OpenTab(ctx, allocation.Request{AgentID: t.AgentID, Profile: t.Profile, Labels: t.Labels})
+	if err != nil {
+		return TabPlacement{}, fmt.Errorf("tab placement: %w", err)
+	}
+	tp.Profile, tp.Labels, tp.agentID = t.Profile, t.Labels, t.AgentID
+	slog.Info("scheduler: opened tab", "task", t.ID, "tab", tp.TabID, "instance", tp.InstanceID, "profile", tp.Profile, "labels", tp.Labels)
+	return tp, nil
+}
+
+// releaseTab resets a placed task's tab to about:blank and returns it to
+// its pool, or closes it when asked to, when the pool is full or when the
+// reset fails.
+func (s *Scheduler) releaseTab(tp TabPlacement, closeTab bool) {
+	if !closeTab && s.resetTab(tp) && s.tabs.put(tp) {
+		return
+	}
+	s.closeTab(tp)
+}
+
+// resetTab clears the page a task left in its tab so the next task does
+// not see it.
+func (s *Scheduler) resetTab(tp TabPlacement) bool {
+	prov, ok := s.resolver.(TabProvisioner)
+	if !ok {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tabCloseTimeout)
+	defer cancel()
+	if err := prov.ResetTab(ctx, tp); err != nil {
+		slog.Info("scheduler: tab reset failed, closing it", "tab", tp.TabID, "instance", tp.InstanceID, "err", err)
+		return false
+	}
+	return true
+}
+
+func (s *Scheduler) closeTab(tp TabPlacement) {
+	prov, ok := s.resolver.(TabProvisioner)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tabCloseTimeout)
+	defer cancel()
+	if err := prov.CloseTab(ctx, tp); err != nil {
+		slog.Warn("scheduler: tab close failed", "tab", tp.TabID, "instance", tp.InstanceID, "err", err)
+	}
+}
@@ -0,0 +1,350 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/instance"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+)
+
+// fakeProvisioner opens numbered tabs on one executor and records what it
+// was asked to do.
+type fakeProvisioner struct {
+	port string
+
+	mu       sync.Mutex
+	opened   int
+	profiles []string
+	closed   []string
+	gone     map[string]bool
+	openErr  error
+	// pages is the page each tab shows: tasks of kind "navigate" load
+	// their url and ResetTab blanks it.
+	pages map[string]string
+	seen  []string
+}
+
+func (f *fakeProvisioner) ResolveTabInstance(tabID string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.gone[tabID] {
+		return "", fmt.Errorf("tab %q not found", tabID)
+	}
+	return f.port, nil
+}
+
+func (f *fakeProvisioner) OpenTab(_ context.Context, req allocation.Request) (TabPlacement, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.openErr != nil {
+		return TabPlacement{}, f.openErr
+	}
+	f.opened++
+	f.profiles = append(f.profiles, poolKey(req.Profile, req.Labels))
+	tabID := fmt.Sprintf("tab-%d", f.opened)
+	f.pages[tabID] = "about:blank"
+	return TabPlacement{InstanceID: "inst_1", TabID: tabID, Port: f.port}, nil
+}
+
+func (f *fakeProvisioner) ResetTab(_ context.Context, p TabPlacement) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pages[p.TabID] = "about:blank"
+	return nil
+}
+
+func (f *fakeProvisioner) CloseTab(_ context.Context, p TabPlacement) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = append(f.closed, p.TabID)
+	return nil
+}
+
+// newPlacementScheduler starts a scheduler whose executor records action
+// paths, with the page the tab showed, and fails actions of kind "fail".
+func newPlacementScheduler(t *testing.T) (*Scheduler, *fakeProvisioner, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var paths []string
+	prov := &fakeProvisioner{gone: map[string]bool{}, pages: map[string]string{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		tabID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/tabs/"), "/action")
+		prov.mu.Lock()
+		prov.seen = append(prov.seen, prov.pages[tabID])
+		if body["kind"] == "navigate" {
+			prov.pages[tabID], _ = body["url"].(string)
+		}
+		prov.mu.Unlock()
+		if body["kind"] == "fail" {
+			w.WriteHeader(400)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	t.Cleanup(srv.Close)
+	parts := strings.Split(srv.URL, ":")
+
+	prov.port = parts[len(parts)-1]
+	s := New(DefaultConfig(), prov)
+	s.Start()
+	t.Cleanup(s.Stop)
+	return s, prov, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func runPlaced(t *testing.T, s *Scheduler, req SubmitRequest) *Task {
+	t.Helper()
+	req.AgentID = "a1"
+	task, err := s.Submit(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return waitTerminal(t, s, task.ID)
+}
+
+func TestPlacedTaskOpensAndPoolsTab(t *testing.T) {
+	s, prov, paths := newPlacementScheduler(t)
+
+	first := runPlaced(t, s, SubmitRequest{Action: "click", Profile: "work"})
+	if first.State != StateDone {
+		t.Fatalf("expected done, got %s (%s)", first.State, first.Error)
+	}
+	if p := first.Placement; p == nil || p.TabID != "tab-1" || p.InstanceID != "inst_1" || p.Profile != "work" || p.Reused {
+		t.Fatalf("unexpected placement %+v", first.Placement)
+	}
+	if got := paths(); len(got) != 1 || got[0] != "/tabs/tab-1/action" {
+		t.Fatalf("action should run on the opened tab, got %v", got)
+	}
+
+	second := runPlaced(t, s, SubmitRequest{Action: "click", Profile: "work"})
+	if p := second.Placement; p == nil || p.TabID != "tab-1" || !p.Reused {
+		t.Fatalf("second task should reuse the pooled tab, got %+v", second.Placement)
+	}
+	other := runPlaced(t, s, SubmitRequest{Action: "click", Profile: "personal"})
+	if p := other.Placement; p == nil || p.TabID != "tab-2" || p.Reused {
+		t.Fatalf("pools are per profile, got %+v", other.Placement)
+	}
+
+	prov.mu.Lock()
+	defer prov.mu.Unlock()
+	if prov.opened != 2 || strings.Join(prov.profiles, ",") != "work,personal" || len(prov.closed) != 0 {
+		t.Errorf("expected two tabs opened and none closed, got opened=%d profiles=%v closed=%v", prov.opened, prov.profiles, prov.closed)
+	}
+	if got := s.tabs.stats(); got["a1/work"] != 1 || got["a1/personal"] != 1 {
+		t.Errorf("unexpected pool %v", got)
+	}
+}
+
+func TestPlacedTaskReusedTabStartsBlank(t *testing.T) {
+	s, prov, _ := newPlacementScheduler(t)
+
+	first := runPlaced(t, s, SubmitRequest{Action: "navigate", Params: map[string]any{"url": "https://mail.example.com/inbox"}})
+	if first.State != StateDone {
+		t.Fatalf("expected done, got %s (%s)", first.State, first.Error)
+	}
+	second := runPlaced(t, s, SubmitRequest{Action: "click"})
+	if p := second.Placement; p == nil || p.TabID != "tab-1" || !p.Reused {
+		t.Fatalf("expected the pooled tab to be reused, got %+v", second.Placement)
+	}
+
+	prov.mu.Lock()
+	defer prov.mu.Unlock()
+	if len(prov.seen) != 2 || prov.seen[1] != "about:blank" {
+		t.Fatalf("a reused tab must start blank, pages seen: %v", prov.seen)
+	}
+}
+
+func TestPlacedTaskPoolsPerAgent(t *testing.T) {
+	s, _, _ := newPlacementScheduler(t)
+	runPlaced(t, s, SubmitRequest{Action: "click", Profile: "work"})
+
+	task, err := s.Submit(SubmitRequest{AgentID: "a2", Action: "click", Profile: "work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := waitTerminal(t, s, task.ID)
+	if p := other.Placement; p == nil || p.TabID != "tab-2" || p.Reused {
+		t.Fatalf("another agent must not get a1's pooled tab, got %+v", other.Placement)
+	}
+	if got := s.tabs.stats(); got["a1/work"] != 1 || got["a2/work"] != 1 {
+		t.Errorf("unexpected pool %v", got)
+	}
+}
+
+func TestPlacedTaskPoolsByLabels(t *testing.T) {
+	s, prov, _ := newPlacementScheduler(t)
+
+	eu := runPlaced(t, s, SubmitRequest{Action: "click", Labels: map[string]string{"region": "eu"}})
+	if p := eu.Placement; p == nil || p.Labels["region"] != "eu" || p.Reused {
+		t.Fatalf("unexpected placement %+v", eu.Placement)
+	}
+	if p := runPlaced(t, s, SubmitRequest{Action: "click"}).Placement; p == nil || p.Reused {
+		t.Fatalf("a task without labels must not reuse a labelled tab, got %+v", p)
+	}
+	if p := runPlaced(t, s, SubmitRequest{Action: "click", Labels: map[string]string{"region": "eu"}}).Placement; p == nil || p.TabID != "tab-1" || !p.Reused {
+		t.Fatalf("same labels should reuse the pooled tab, got %+v", p)
+	}
+
+	prov.mu.Lock()
+	defer prov.mu.Unlock()
+	if got := strings.Join(prov.profiles, ";"); got != "{region=eu};" {
+		t.Errorf("labels should reach the provisioner, got %q", got)
+	}
+}
+
+func TestPlacedTaskCloseTab(t *testing.T) {
+	s, prov, _ := newPlacementScheduler(t)
+
+	if task := runPlaced(t, s, SubmitRequest{Action: "click", CloseTab: true}); task.State != StateDone {
+		t.Fatalf("expected done, got %s (%s)", task.State, task.Error)
+	}
+	if task := runPlaced(t, s, SubmitRequest{Action: "fail"}); task.State != StateFailed {
+		t.Fatalf("expected failed, got %s", task.State)
+	}
+
+	prov.mu.Lock()
+	defer prov.mu.Unlock()
+	if strings.Join(prov.closed, ",") != "tab-1,tab-2" {
+		t.Errorf("closeTab and failed tasks should close their tabs, got %v", prov.closed)
+	}
+	if got := s.tabs.stats(); len(got) != 0 {
+		t.Errorf("closed tabs must not be pooled, got %v", got)
+	}
+}
+
+func TestPlacedTaskDropsStalePooledTab(t *testing.T) {
+	s, prov, _ := newPlacementScheduler(t)
+	runPlaced(t, s, SubmitRequest{Action: "click"})
+
+	prov.mu.Lock()
+	prov.gone["tab-1"] = true
+	prov.mu.Unlock()
+
+	task := runPlaced(t, s, SubmitRequest{Action: "click"})
+	if p := task.Placement; p == nil || p.TabID != "tab-2" || p.Reused {
+		t.Fatalf("a pooled tab whose instance is gone should be replaced, got %+v", task.Placement)
+	}
+}
+
+func TestPlacedTaskErrors(t *testing.T) {
+	s, prov, _ := newPlacementScheduler(t)
+	prov.openErr = fmt.Errorf("no running instances for profile %q", "work")
+	task := runPlaced(t, s, SubmitRequest{Action: "click", Profile: "work"})
+	if task.State != StateFailed || !strings.Contains(task.Error, "tab placement: no running instances") {
+		t.Fatalf("expected a placement failure, got %s: %q", task.State, task.Error)
+	}
+
+	plain, executor := newTestScheduler(t)
+	defer executor.Close()
+	plain.Start()
+	defer plain.Stop()
+	task = runPlaced(t, plain, SubmitRequest{Action: "click"})
+	if task.State != StateFailed || !strings.Contains(task.Error, "tabId is required") {
+		t.Fatalf("resolvers that cannot open tabs need a tabId, got %s: %q", task.State, task.Error)
+	}
+}
+
+func TestSubmitPlacementFieldsRequireNoTab(t *testing.T) {
+	for _, req := range []SubmitRequest{
+		{AgentID: "a1", Action: "click", TabID: "tab-1", Profile: "work"},
+		{AgentID: "a1", Action: "click", TabID: "tab-1", CloseTab: true},
+		{AgentID: "a1", Action: "click", TabID: "tab-1", Labels: map[string]string{"region": "eu"}},
+		{AgentID: "a1", Action: "click", Labels: map[string]string{"region": "e u"}},
+	} {
+		if err := req.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestTabPoolBounded(t *testing.T) {
+	p := newTabPool(1)
+	if !p.put(TabPlacement{TabID: "a", Profile: "work"}) || p.put(TabPlacement{TabID: "b", Profile: "work"}) {
+		t.Fatal("pool should hold one idle tab per profile")
+	}
+	if !p.put(TabPlacement{TabID: "c"}) {
+		t.Fatal("profiles are pooled separately")
+	}
+	if got := p.drain(); len(got) != 2 {
+		t.Errorf("expected 2 drained tabs, got %v", got)
+	}
+}
+
+type noTabs struct{}
+
+func (noTabs) FetchTabs(string) ([]bridge.InstanceTab, error) { return nil, nil }
+
+type fakeTabClient struct {
+	closed    []string
+	navigated []string
+}
+
+func (c *fakeTabClient) NavigateTab(_ context.Context, _, tabID, url string) error {
+	c.navigated = append(c.navigated, tabID+"="+url)
+	return nil
+}
+
+func (c *fakeTabClient) CreateTab(_ context.Context, port, _ string) (string, error) {
+	return "tab-on-" + port, nil
+}
+
+func (c *fakeTabClient) CloseTab(_ context.Context, _, tabID string) error {
+	c.closed = append(c.closed, tabID)
+	return nil
+}
+
+func TestManagerResolverOpensTabOnProfile(t *testing.T) {
+	mgr := instance.NewManager(nil, noTabs{})
+	mgr.Repo.Add(&bridge.Instance{ID: "inst_a", ProfileName: "default", Port: "9868", Status: "running"})
+	mgr.Repo.Add(&bridge.Instance{ID: "inst_b", ProfileName: "work", Port: "9869", Status: "running"})
+	tabs := &fakeTabClient{}
+	r := &ManagerResolver{Mgr: mgr, Tabs: tabs}
+
+	p, err := r.OpenTab(context.Background(), allocation.Request{AgentID: "a1", Profile: "work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.InstanceID != "inst_b" || p.TabID != "tab-on-9869" || p.Port != "9869" {
+		t.Fatalf("unexpected placement %+v", p)
+	}
+	if port, err := r.ResolveTabInstance(p.TabID); err != nil || port != "9869" {
+		t.Fatalf("opened tab should resolve to its instance, got %q %v", port, err)
+	}
+	if _, err := r.OpenTab(context.Background(), allocation.Request{AgentID: "a1", Profile: "missing"}); err == nil {
+		t.Error("expected an error for a profile without instances")
+	}
+
+	if err := r.ResetTab(context.Background(), p); err != nil || len(tabs.navigated) != 1 || tabs.navigated[0] != "tab-on-9869=about:blank" {
+		t.Fatalf("reset should blank the tab: %v %v", err, tabs.navigated)
+	}
+	if err := r.CloseTab(context.Background(), p); err != nil || len(tabs.closed) != 1 {
+		t.Fatalf("close failed: %v %v", err, tabs.closed)
+	}
+	if _, err := r.ResolveTabInstance(p.TabID); err == nil {
+		t.Error("closed tab should no longer resolve")
+	}
+
+	if _, err := r.OpenTab(context.Background(), allocation.Request{AgentID: "a1", Labels: map[string]string{"region": "eu"}}); err == nil {
+		t.Error("expected an error when no instance carries the labels")
+	}
+	if _, err := (&ManagerResolver{Mgr: mgr}).OpenTab(context.Background(), allocation.Request{AgentID: "a1"}); err == nil {
+		t.Error("expected an error without a tab client")
+	}
+}
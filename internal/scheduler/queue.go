@@ -4,6 +4,7 @@ import (
 	"container/heap"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // TaskQueue is an in-memory priority queue with per-agent fairness.
@@ -13,11 +14,26 @@ type TaskQueue struct {
 	totalCount  int
 	maxTotal    int
 	maxPerAgent int
+	policy      QueuePolicy
+
+	// busyTabs counts in-flight tasks per tab ID.
+	busyTabs map[string]int
+	// macroTabs holds the tabs with a macro in flight, and queuedMacros
+	// counts queued macros per tab. Other tasks for those tabs are held
+	// back so a macro neither interleaves nor starves.
+	macroTabs    map[string]bool
+	queuedMacros map[string]int
+	// vclock is the weighted-fair virtual time: the pass of the agent
+	// served last. Agents that become backlogged start from it.
+	vclock float64
 }
 
 type agentQueue struct {
 	tasks    taskHeap
 	inflight int
+	// pass is the agent's virtual time under weighted-fair; it advances by
+	// 1/weight for every task dequeued.
+	pass float64
 }
 
 // NewTaskQueue creates a queue with the given global and per-agent limits.
@@ -26,9 +42,29 @@ func NewTaskQueue(maxTotal, maxPerAgent int) *TaskQueue {
 		agents:      make(map[string]*agentQueue),
 		maxTotal:    maxTotal,
 		maxPerAgent: maxPerAgent,
+		policy:      QueuePolicy{Strategy: StrategyFairFIFO, StarvationAfter: defaultStarvationAfter},
+		busyTabs:    make(map[string]int),
+		macroTabs:   make(map[string]bool),
+
+		queuedMacros: make(map[string]int),
 	}
 }
 
+// SetPolicy switches the dequeue strategy at runtime. Queued tasks keep
+// their place; only the order in which they are picked changes.
+func (q *TaskQueue) SetPolicy(p QueuePolicy) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.policy = p
+}
+
+// Policy returns the active dequeue policy.
+func (q *TaskQueue) Policy() QueuePolicy {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.policy
+}
+
 // SetLimits updates queue capacity at runtime.
 func (q *TaskQueue) SetLimits(maxTotal, maxPerAgent int) {
 	q.mu.Lock()
@@ -47,7 +83,7 @@ func (q *TaskQueue) Enqueue(t *Task) (int, error) {
 	defer q.mu.Unlock()
 
 	if q.totalCount >= q.maxTotal {
-		return 0, fmt.Errorf("global queue full (%d/%d)", q.totalCount, q.maxTotal)
+		return 0, &RejectError{Reason: RejectQueueFull, Msg: fmt.Sprintf("global queue full (%d/%d)", q.totalCount, q.maxTotal)}
 	}
 
 	aq, ok := q.agents[t.AgentID]
@@ -58,17 +94,52 @@ func (q *TaskQueue) Enqueue(t *Task) (int, error) {
 	}
 
 	if aq.tasks.Len() >= q.maxPerAgent {
-		return 0, fmt.Errorf("agent queue full for %q (%d/%d)", t.AgentID, aq.tasks.Len(), q.maxPerAgent)
+		return 0, &RejectError{Reason: RejectAgentQueueFull, Msg: fmt.Sprintf("agent queue full for %q (%d/%d)", t.AgentID, aq.tasks.Len(), q.maxPerAgent)}
 	}
 
+	// An agent that was idle must not bank credit for the time it had
+	// nothing queued.
+	if aq.tasks.Len() == 0 {
+		aq.pass = max(aq.pass, q.vclock)
+	}
+	t.queuedAt = timeNow()
 	heap.Push(&aq.tasks, t)
 	q.totalCount++
+	if isMacro(t) {
+		q.queuedMacros[t.TabID]++
+	}
 	return q.totalCount, nil
 }
 
-// Dequeue picks the next task using fair round-robin: the agent with the
-// fewest in-flight tasks gets served first. Among tasks for that agent,
-// the heap ordering (priority then creation time) decides.
+func isMacro(t *Task) bool { return t.Action == ActionMacro && t.TabID != "" }
+
+// unqueued updates the macro counts for a task leaving the queue.
+func (q *TaskQueue) unqueued(t *Task) {
+	if !isMacro(t) {
+		return
+	}
+	if n := q.queuedMacros[t.TabID]; n > 1 {
+		q.queuedMacros[t.TabID] = n - 1
+	} else {
+		delete(q.queuedMacros, t.TabID)
+	}
+}
+
+// Dequeue picks the next task according to the queue policy:
+//
+//   - fair-fifo: the agent with the fewest in-flight tasks is served
+//     first, and the heap ordering (priority then creation time) picks
+//     among that agent's tasks.
+//   - weighted-fair: the agent with the lowest virtual pass is served
+//     first, so agents get dispatches in proportion to their weights.
+//   - priority: the lowest priority value across all agents is served
+//     first, except that tasks queued longer than StarvationAfter go
+//     ahead of everything else, oldest first.
+//
+// With SerializeTabs, tasks for a tab that has a task in flight are
+// skipped until it completes. Macros always get their tab to themselves:
+// once one is queued, other tasks for the tab are held back until it has
+// run.
 func (q *TaskQueue) Dequeue(maxPerAgentInflight, maxGlobalInflight int) *Task {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -81,9 +152,12 @@ func (q *TaskQueue) Dequeue(maxPerAgentInflight, maxGlobalInflight int) *Task {
 		return nil
 	}
 
-	var bestAgent string
-	bestInflight := int(^uint(0) >> 1) // max int
-
+	now := timeNow()
+	var (
+		bestAgent string
+		best      *agentQueue
+		bestIdx   int
+	)
 	for agentID, aq := range q.agents {
 		if aq.tasks.Len() == 0 {
 			continue
@@ -91,27 +165,81 @@ func (q *TaskQueue) Dequeue(maxPerAgentInflight, maxGlobalInflight int) *Task {
 		if aq.inflight >= maxPerAgentInflight {
 			continue
 		}
-		if aq.inflight < bestInflight {
-			bestInflight = aq.inflight
-			bestAgent = agentID
+		idx := q.next(aq, now)
+		if idx < 0 {
+			continue
+		}
+		if best == nil || q.policy.agentBefore(aq, best) ||
+			(!q.policy.agentBefore(best, aq) && q.policy.taskBefore(aq.tasks[idx], best.tasks[bestIdx], now)) {
+			bestAgent, best, bestIdx = agentID, aq, idx
 		}
 	}
 
-	if bestAgent == "" {
+	if best == nil {
 		return nil
 	}
 
-	aq := q.agents[bestAgent]
-	t := heap.Pop(&aq.tasks).(*Task)
-	aq.inflight++
+	t := heap.Remove(&best.tasks, bestIdx).(*Task)
+	best.inflight++
 	q.totalCount--
+	if q.policy.Strategy == StrategyWeightedFair {
+		q.vclock = max(q.vclock, best.pass)
+		best.pass += 1 / q.policy.weight(bestAgent)
+	}
+	q.unqueued(t)
+	if t.TabID != "" {
+		q.busyTabs[t.TabID]++
+		if isMacro(t) {
+			q.macroTabs[t.TabID] = true
+		}
+	}
 	return t
 }
 
-// Complete marks a task as no longer in-flight for its agent.
-func (q *TaskQueue) Complete(agentID string) {
+// held reports whether t must wait for its tab. A macro waits for the tab
+// to be idle; other tasks wait while a macro for the tab is queued or in
+// flight, and, with SerializeTabs, while anything for it is in flight.
+func (q *TaskQueue) held(t *Task) bool {
+	if t.TabID == "" {
+		return false
+	}
+	if isMacro(t) {
+		return q.busyTabs[t.TabID] > 0
+	}
+	if q.macroTabs[t.TabID] || q.queuedMacros[t.TabID] > 0 {
+		return true
+	}
+	return q.policy.SerializeTabs && q.busyTabs[t.TabID] > 0
+}
+
+// next returns the index of the agent's next runnable task, or -1 if all
+// of its tasks are held back by busy tabs.
+func (q *TaskQueue) next(aq *agentQueue, now time.Time) int {
+	if !q.policy.scans() && !q.held(aq.tasks[0]) {
+		return 0
+	}
+	idx := -1
+	for i, t := range aq.tasks {
+		if q.held(t) {
+			continue
+		}
+		if idx < 0 || q.policy.taskBefore(t, aq.tasks[idx], now) {
+			idx = i
+		}
+	}
+	return idx
+}
+
+// Complete marks a task as no longer in-flight for its agent and tab.
+func (q *TaskQueue) Complete(agentID, tabID string) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	if n := q.busyTabs[tabID]; n > 1 {
+		q.busyTabs[tabID] = n - 1
+	} else {
+		delete(q.busyTabs, tabID)
+		delete(q.macroTabs, tabID)
+	}
 	if aq, ok := q.agents[agentID]; ok {
 		if aq.inflight > 0 {
 			aq.inflight--
@@ -137,6 +265,7 @@ func (q *TaskQueue) Remove(taskID, agentID string) bool {
 		if t.ID == taskID {
 			heap.Remove(&aq.tasks, i)
 			q.totalCount--
+			q.unqueued(t)
 			if aq.tasks.Len() == 0 && aq.inflight == 0 {
 				delete(q.agents, agentID)
 			}
@@ -159,6 +288,7 @@ func (q *TaskQueue) ExpireDeadlined() []*Task {
 			if !t.Deadline.IsZero() && t.Deadline.Before(timeNow()) {
 				expired = append(expired, t)
 				q.totalCount--
+				q.unqueued(t)
 			} else {
 				remaining = append(remaining, t)
 			}
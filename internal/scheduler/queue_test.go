@@ -125,7 +125,7 @@ func TestQueueInflightLimit(t *testing.T) {
 	}
 
 	// Complete the first, now second should work.
-	q.Complete("a1")
+	q.Complete("a1", "")
 	got3 := q.Dequeue(1, 2)
 	if got3 == nil {
 		t.Error("should dequeue after completing")
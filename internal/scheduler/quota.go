@@ -0,0 +1,369 @@
+package scheduler
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"sync"
+	"time"
+)
+
+// Rejection reasons reported in Task.RejectReason.
+const (
+	RejectQueueFull         = "queue_full"
+	RejectAgentQueueFull    = "agent_queue_full"
+	RejectRateLimited       = "rate_limited"
+	RejectDailyQuota        = "daily_quota_exceeded"
+	RejectActionRateLimited = "action_rate_limited"
+	RejectActionDailyQuota  = "action_daily_quota_exceeded"
+)
+
+// RejectError is returned when a task is refused at admission.
+type RejectError struct {
+	Reason string
+	Msg    string
+	// RetryAfter is how long until the same request can be admitted, when
+	// known.
+	RetryAfter time.Duration
+}
+
+func (e *RejectError) Error() string { return e.Msg }
+
+// queueFull reports whether the rejection came from queue capacity rather
+// than a quota.
+func (e *RejectError) queueFull() bool {
+	return e.Reason == RejectQueueFull || e.Reason == RejectAgentQueueFull
+}
+
+// RateLimit caps submissions with a token bucket refilled at PerSecond and
+// holding up to Burst tokens, and with a count per UTC day. Zero fields are
+// unlimited.
+type RateLimit struct {
+	PerSecond float64 `json:"perSecond,omitempty"`
+	Burst     int     `json:"burst,omitempty"`
+	Daily     int     `json:"daily,omitempty"`
+}
+
+func (l RateLimit) validate(field string) error {
+	if l.PerSecond < 0 || math.IsNaN(l.PerSecond) || math.IsInf(l.PerSecond, 0) {
+		return fmt.Errorf("%s.perSecond must be a non-negative number", field)
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("%s.burst must be >= 0", field)
+	}
+	if l.Daily < 0 {
+		return fmt.Errorf("%s.daily must be >= 0", field)
+	}
+	return nil
+}
+
+// burst is the bucket size: Burst, or one second of tokens (at least one).
+func (l RateLimit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return max(1, math.Ceil(l.PerSecond))
+}
+
+// AgentLimits are the limits of one agent, with extra limits per action
+// kind (e.g. "evaluate").
+type AgentLimits struct {
+	RateLimit
+	Actions map[string]RateLimit `json:"actions,omitempty"`
+}
+
+// QuotaConfig holds the limits applied to every agent, and per-agent
+// overrides that replace them.
+type QuotaConfig struct {
+	Default AgentLimits            `json:"default"`
+	Agents  map[string]AgentLimits `json:"agents,omitempty"`
+}
+
+// Validate checks every limit in the config.
+func (c QuotaConfig) Validate() error {
+	check := func(field string, l AgentLimits) error {
+		if err := l.validate(field); err != nil {
+			return err
+		}
+		for action, al := range l.Actions {
+			if err := al.validate(field + ".actions." + action); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := check("limits.default", c.Default); err != nil {
+		return err
+	}
+	for agentID, l := range c.Agents {
+		if err := check("limits.agents."+agentID, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c QuotaConfig) limitsFor(agentID string) AgentLimits {
+	if l, ok := c.Agents[agentID]; ok {
+		return l
+	}
+	return c.Default
+}
+
+// meter tracks one token bucket and one daily count.
+type meter struct {
+	tokens float64
+	last   time.Time
+	day    string
+	used   int
+}
+
+func utcDay(t time.Time) string { return t.UTC().Format(time.DateOnly) }
+
+// nextUTCMidnight returns the start of the UTC day after t.
+func nextUTCMidnight(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// sync refills the bucket and rolls the daily count over.
+func (m *meter) sync(l RateLimit, now time.Time) {
+	if m.last.IsZero() {
+		m.tokens = l.burst()
+	} else if l.PerSecond > 0 {
+		m.tokens = min(l.burst(), m.tokens+now.Sub(m.last).Seconds()*l.PerSecond)
+	}
+	m.last = now
+	if day := utcDay(now); m.day != day {
+		m.day = day
+		m.used = 0
+	}
+}
+
+// check reports whether n more submissions fit, and if not whether the
+// rate or the daily limit is hit and when to retry.
+func (m *meter) check(l RateLimit, n int, now time.Time) (daily bool, retryAfter time.Duration, ok bool) {
+	if l.Daily > 0 && m.used+n > l.Daily {
+		return true, nextUTCMidnight(now).Sub(now), false
+	}
+	if l.PerSecond > 0 && m.tokens < float64(n) {
+		wait := (float64(n) - m.tokens) / l.PerSecond
+		return false, time.Duration(math.Ceil(wait * float64(time.Second))), false
+	}
+	return false, 0, true
+}
+
+func (m *meter) take(l RateLimit, n int) {
+	if l.PerSecond > 0 {
+		m.tokens -= float64(n)
+	}
+	m.used += n
+}
+
+func (m *meter) refund(l RateLimit) {
+	if l.PerSecond > 0 {
+		m.tokens = min(l.burst(), m.tokens+1)
+	}
+	if m.used > 0 {
+		m.used--
+	}
+}
+
+type agentUsage struct {
+	meter
+	actions  map[string]*meter
+	rejected map[string]uint64
+}
+
+// quotas enforces QuotaConfig at admission. Usage is kept in memory, so
+// daily counts start over when the server restarts.
+type quotas struct {
+	mu     sync.Mutex
+	cfg    QuotaConfig
+	agents map[string]*agentUsage
+}
+
+func newQuotas(cfg QuotaConfig) *quotas {
+	return &quotas{cfg: cfg, agents: make(map[string]*agentUsage)}
+}
+
+func (q *quotas) setConfig(cfg QuotaConfig) {
+	q.mu.Lock()
+	q.cfg = cfg
+	q.mu.Unlock()
+}
+
+func (q *quotas) config() QuotaConfig {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.cfg
+}
+
+func (q *quotas) usageLocked(agentID string) *agentUsage {
+	u, ok := q.agents[agentID]
+	if !ok {
+		u = &agentUsage{actions: make(map[string]*meter), rejected: make(map[string]uint64)}
+		q.agents[agentID] = u
+	}
+	return u
+}
+
+func (u *agentUsage) action(kind string) *meter {
+	m, ok := u.actions[kind]
+	if !ok {
+		m = &meter{}
+		u.actions[kind] = m
+	}
+	return m
+}
+
+// take admits one submission per action for the agent, all or none.
+func (q *quotas) take(agentID string, actions ...string) *RejectError {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := timeNow()
+	lim := q.cfg.limitsFor(agentID)
+	u := q.usageLocked(agentID)
+
+	u.sync(lim.RateLimit, now)
+	if daily, wait, ok := u.check(lim.RateLimit, len(actions), now); !ok {
+		if daily {
+			return u.reject(&RejectError{Reason: RejectDailyQuota, RetryAfter: wait,
+				Msg: fmt.Sprintf("agent %q daily quota exceeded (%d/%d)", agentID, u.used, lim.Daily)})
+		}
+		return u.reject(&RejectError{Reason: RejectRateLimited, RetryAfter: wait,
+			Msg: fmt.Sprintf("agent %q rate limit exceeded (%g/s, burst %g)", agentID, lim.PerSecond, lim.burst())})
+	}
+
+	need := make(map[string]int)
+	for _, a := range actions {
+		need[a]++
+	}
+	for kind, n := range need {
+		al := lim.Actions[kind]
+		m := u.action(kind)
+		m.sync(al, now)
+		if daily, wait, ok := m.check(al, n, now); !ok {
+			if daily {
+				return u.reject(&RejectError{Reason: RejectActionDailyQuota, RetryAfter: wait,
+					Msg: fmt.Sprintf("agent %q daily quota for %q exceeded (%d/%d)", agentID, kind, m.used, al.Daily)})
+			}
+			return u.reject(&RejectError{Reason: RejectActionRateLimited, RetryAfter: wait,
+				Msg: fmt.Sprintf("agent %q rate limit for %q exceeded (%g/s, burst %g)", agentID, kind, al.PerSecond, al.burst())})
+		}
+	}
+
+	u.take(lim.RateLimit, len(actions))
+	for kind, n := range need {
+		u.action(kind).take(lim.Actions[kind], n)
+	}
+	return nil
+}
+
+func (u *agentUsage) reject(err *RejectError) *RejectError {
+	u.rejected[err.Reason]++
+	return err
+}
+
+// refund returns the quota taken for a task that was not admitted after
+// all, e.g. because the queue was full.
+func (q *quotas) refund(agentID, action string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	lim := q.cfg.limitsFor(agentID)
+	u := q.usageLocked(agentID)
+	u.refund(lim.RateLimit)
+	u.action(action).refund(lim.Actions[action])
+}
+
+// recordReject counts a rejection that did not come from take.
+func (q *quotas) recordReject(agentID, reason string) {
+	q.mu.Lock()
+	q.usageLocked(agentID).rejected[reason]++
+	q.mu.Unlock()
+}
+
+// MeterUsage reports one limit and the usage against it. Tokens and
+// Remaining are set only when the matching limit is configured.
+type MeterUsage struct {
+	RateLimit
+	Tokens    *float64 `json:"tokens,omitempty"`
+	Used      int      `json:"used"`
+	Remaining *int     `json:"remaining,omitempty"`
+}
+
+// AgentUsage is the response of GET /scheduler/agents/{id}/usage.
+type AgentUsage struct {
+	AgentID string `json:"agentId"`
+	MeterUsage
+	Actions  map[string]MeterUsage `json:"actions,omitempty"`
+	Rejected map[string]uint64     `json:"rejected,omitempty"`
+	Day      string                `json:"day"`
+	ResetsAt time.Time             `json:"resetsAt"`
+	Queued   int                   `json:"queued"`
+	Inflight int                   `json:"inflight"`
+}
+
+func meterUsage(m meter, l RateLimit) MeterUsage {
+	mu := MeterUsage{RateLimit: l, Used: m.used}
+	if l.PerSecond > 0 {
+		tokens := math.Floor(m.tokens*100) / 100
+		mu.Tokens = &tokens
+	}
+	if l.Daily > 0 {
+		remaining := max(0, l.Daily-m.used)
+		mu.Remaining = &remaining
+	}
+	return mu
+}
+
+// usage reports an agent's current limits and usage for today. It only
+// reads: agents and actions with no usage yet are reported as unused
+// without being tracked, so looking up arbitrary agent IDs costs nothing.
+func (q *quotas) usage(agentID string) AgentUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := timeNow()
+	lim := q.cfg.limitsFor(agentID)
+	u, ok := q.agents[agentID]
+	if !ok {
+		u = &agentUsage{}
+	}
+	m := u.meter
+	m.sync(lim.RateLimit, now)
+
+	out := AgentUsage{
+		AgentID:    agentID,
+		MeterUsage: meterUsage(m, lim.RateLimit),
+		Actions:    make(map[string]MeterUsage),
+		Rejected:   maps.Clone(u.rejected),
+		Day:        utcDay(now),
+		ResetsAt:   nextUTCMidnight(now),
+	}
+	report := func(kind string) {
+		var am meter
+		if cur, ok := u.actions[kind]; ok {
+			am = *cur
+		}
+		am.sync(lim.Actions[kind], now)
+		out.Actions[kind] = meterUsage(am, lim.Actions[kind])
+	}
+	for kind := range lim.Actions {
+		report(kind)
+	}
+	for kind := range u.actions {
+		report(kind)
+	}
+	return out
+}
+
+// AgentUsage reports an agent's quota usage and queue occupancy.
+func (s *Scheduler) AgentUsage(agentID string) AgentUsage {
+	u := s.quotas.usage(agentID)
+	if st, ok := s.QueueStats().Agents[agentID]; ok {
+		u.Queued, u.Inflight = st.Queued, st.Inflight
+	}
+	return u
+}
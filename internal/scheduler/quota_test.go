@@ -0,0 +1,192 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuotaRateLimit(t *testing.T) {
+	now := withClock(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	q := newQuotas(QuotaConfig{Default: AgentLimits{RateLimit: RateLimit{PerSecond: 1, Burst: 2}}})
+
+	for range 2 {
+		if err := q.take("a1", "click"); err != nil {
+			t.Fatalf("burst should be admitted: %v", err)
+		}
+	}
+	err := q.take("a1", "click")
+	if err == nil || err.Reason != RejectRateLimited || err.RetryAfter != time.Second {
+		t.Fatalf("expected rate_limited with 1s retry, got %+v", err)
+	}
+	if err := q.take("a2", "click"); err != nil {
+		t.Errorf("agents have separate buckets: %v", err)
+	}
+
+	*now = now.Add(time.Second)
+	if err := q.take("a1", "click"); err != nil {
+		t.Errorf("bucket should refill: %v", err)
+	}
+}
+
+func TestQuotaDaily(t *testing.T) {
+	now := withClock(t, time.Date(2026, 1, 1, 22, 0, 0, 0, time.UTC))
+	q := newQuotas(QuotaConfig{Default: AgentLimits{RateLimit: RateLimit{Daily: 3}}})
+
+	if err := q.take("a1", "click", "click"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.take("a1", "click", "click"); err == nil || err.Reason != RejectDailyQuota {
+		t.Fatalf("expected daily_quota_exceeded, got %+v", err)
+	} else if err.RetryAfter != 2*time.Hour {
+		t.Errorf("retry should point at UTC midnight, got %v", err.RetryAfter)
+	}
+	if u := q.usage("a1"); u.Used != 2 || *u.Remaining != 1 || u.Rejected[RejectDailyQuota] != 1 {
+		t.Errorf("a rejected batch should take nothing, got %+v", u)
+	}
+
+	*now = now.Add(2 * time.Hour)
+	if err := q.take("a1", "click", "click", "click"); err != nil {
+		t.Errorf("quota should reset at midnight: %v", err)
+	}
+}
+
+func TestQuotaActionLimits(t *testing.T) {
+	withClock(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	q := newQuotas(QuotaConfig{
+		Default: AgentLimits{Actions: map[string]RateLimit{"evaluate": {Daily: 1}}},
+		Agents:  map[string]AgentLimits{"trusted": {}},
+	})
+
+	if err := q.take("a1", "evaluate"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.take("a1", "evaluate"); err == nil || err.Reason != RejectActionDailyQuota {
+		t.Fatalf("expected action_daily_quota_exceeded, got %+v", err)
+	}
+	if err := q.take("a1", "click"); err != nil {
+		t.Errorf("other actions are not limited: %v", err)
+	}
+	for range 3 {
+		if err := q.take("trusted", "evaluate"); err != nil {
+			t.Fatalf("an agent override replaces the defaults: %v", err)
+		}
+	}
+
+	u := q.usage("a1")
+	if ev := u.Actions["evaluate"]; ev.Used != 1 || ev.Remaining == nil || *ev.Remaining != 0 {
+		t.Errorf("unexpected evaluate usage: %+v", ev)
+	}
+	if u.Used != 2 {
+		t.Errorf("agent usage should count every action, got %d", u.Used)
+	}
+}
+
+func TestQuotaUsageDoesNotTrackUnknownAgents(t *testing.T) {
+	withClock(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	q := newQuotas(QuotaConfig{Default: AgentLimits{RateLimit: RateLimit{Daily: 3}, Actions: map[string]RateLimit{"evaluate": {Daily: 1}}}})
+
+	for i := range 100 {
+		u := q.usage("probe-" + strconv.Itoa(i))
+		if u.Used != 0 || *u.Remaining != 3 || *u.Actions["evaluate"].Remaining != 1 {
+			t.Fatalf("an unknown agent should report unused limits, got %+v", u)
+		}
+	}
+	if len(q.agents) != 0 {
+		t.Fatalf("reading usage should not allocate, tracking %d agents", len(q.agents))
+	}
+}
+
+func TestSubmitRejectedByQuota(t *testing.T) {
+	withClock(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	cfg := DefaultConfig()
+	cfg.MaxPerAgent = 1
+	cfg.Quotas = &QuotaConfig{Default: AgentLimits{RateLimit: RateLimit{Daily: 5}}}
+	s := New(cfg, &mockResolver{port: "1"})
+
+	if _, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1"})
+	if err == nil || task.Snapshot().RejectReason != RejectAgentQueueFull {
+		t.Fatalf("expected agent_queue_full, got %v", err)
+	}
+	if u := s.AgentUsage("a1"); u.Used != 1 || u.Queued != 1 || u.Rejected[RejectAgentQueueFull] != 1 {
+		t.Errorf("a queue-full rejection should be refunded, got %+v", u)
+	}
+
+	s.ReloadConfig(Config{Quotas: &QuotaConfig{Default: AgentLimits{RateLimit: RateLimit{Daily: 1}}}})
+	task, err = s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1"})
+	var rerr *RejectError
+	if !errors.As(err, &rerr) || rerr.Reason != RejectDailyQuota {
+		t.Fatalf("expected daily_quota_exceeded after reload, got %v", err)
+	}
+	if snap := task.Snapshot(); snap.State != StateRejected || snap.RejectReason != RejectDailyQuota {
+		t.Errorf("unexpected rejected task: %+v", snap)
+	}
+}
+
+func TestHandlerQuotaRejection(t *testing.T) {
+	withClock(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+	s.ReloadConfig(Config{Quotas: &QuotaConfig{Default: AgentLimits{RateLimit: RateLimit{PerSecond: 0.5, Burst: 1}}}})
+
+	submit := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/tasks", strings.NewReader(`{"agentId":"a1","action":"click","tabId":"t1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	if w := submit(); w.Code != 202 {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	w := submit()
+	if w.Code != 429 || w.Header().Get("Retry-After") != "2" {
+		t.Fatalf("expected 429 with Retry-After 2, got %d %q: %s", w.Code, w.Header().Get("Retry-After"), w.Body.String())
+	}
+	var body map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if body["code"] != RejectRateLimited {
+		t.Errorf("expected code rate_limited, got %v", body["code"])
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/scheduler/agents/a1/usage", nil))
+	var usage AgentUsage
+	if err := json.Unmarshal(w.Body.Bytes(), &usage); err != nil {
+		t.Fatal(err)
+	}
+	if usage.AgentID != "a1" || usage.Used != 1 || usage.Queued != 1 || usage.Rejected[RejectRateLimited] != 1 {
+		t.Errorf("unexpected usage: %s", w.Body.String())
+	}
+}
+
+func TestRejectedTaskEvictedAfterResultTTL(t *testing.T) {
+	now := withClock(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	cfg := DefaultConfig()
+	cfg.Quotas = &QuotaConfig{Default: AgentLimits{RateLimit: RateLimit{Daily: 1}}}
+	s := New(cfg, &mockResolver{port: "1"})
+
+	if _, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1"})
+	if err == nil {
+		t.Fatal("expected the second submission to be rejected")
+	}
+	if snap := task.Snapshot(); snap.State != StateRejected || !snap.CompletedAt.Equal(*now) {
+		t.Fatalf("a rejected task should be completed at rejection, got %+v", snap)
+	}
+
+	*now = now.Add(cfg.ResultTTL + time.Second)
+	s.results.evict()
+	if s.GetTask(task.ID) != nil {
+		t.Error("a rejected task should be evicted after ResultTTL")
+	}
+}
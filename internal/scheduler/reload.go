@@ -2,34 +2,154 @@ package scheduler
 
 import (
 	"log/slog"
+	"maps"
+	"reflect"
 	"sync"
 	"time"
 )
 
-// ReloadConfig applies new queue limits without restarting the scheduler.
-// Only tuning knobs that are safe to change at runtime are updated.
+// ReloadConfig applies new queue limits and dequeue strategy without
+// restarting the scheduler. Only tuning knobs that are safe to change at
+// runtime are updated, and only changes are logged.
 func (s *Scheduler) ReloadConfig(cfg Config) {
+	if cfg.Strategy != "" {
+		s.reloadPolicy(cfg)
+	}
+	if cfg.Quotas != nil {
+		s.reloadQuotas(*cfg.Quotas)
+	}
+	if cfg.WebhookSecret != "" || cfg.WebhookMaxAttempts > 0 || cfg.WebhookBackoff > 0 {
+		s.reloadWebhooks(cfg)
+	}
 	if cfg.MaxQueueSize > 0 {
 		s.queue.SetLimits(cfg.MaxQueueSize, cfg.MaxPerAgent)
-		slog.Info("scheduler: queue limits reloaded",
-			"maxQueueSize", cfg.MaxQueueSize,
-			"maxPerAgent", cfg.MaxPerAgent,
-		)
+		s.cfgMu.Lock()
+		changed := s.cfg.MaxQueueSize != cfg.MaxQueueSize || (cfg.MaxPerAgent > 0 && s.cfg.MaxPerAgent != cfg.MaxPerAgent)
+		s.cfg.MaxQueueSize = cfg.MaxQueueSize
+		if cfg.MaxPerAgent > 0 {
+			s.cfg.MaxPerAgent = cfg.MaxPerAgent
+		}
+		s.cfgMu.Unlock()
+		if changed {
+			slog.Info("scheduler: queue limits reloaded",
+				"maxQueueSize", cfg.MaxQueueSize,
+				"maxPerAgent", cfg.MaxPerAgent,
+			)
+		}
 	}
 	if cfg.MaxInflight > 0 {
 		s.cfgMu.Lock()
+		changed := s.cfg.MaxInflight != cfg.MaxInflight || s.cfg.MaxPerAgentFlight != cfg.MaxPerAgentFlight
 		s.cfg.MaxInflight = cfg.MaxInflight
 		s.cfg.MaxPerAgentFlight = cfg.MaxPerAgentFlight
 		s.cfgMu.Unlock()
-		slog.Info("scheduler: inflight limits reloaded",
-			"maxInflight", cfg.MaxInflight,
-			"maxPerAgentFlight", cfg.MaxPerAgentFlight,
-		)
+		if changed {
+			slog.Info("scheduler: inflight limits reloaded",
+				"maxInflight", cfg.MaxInflight,
+				"maxPerAgentFlight", cfg.MaxPerAgentFlight,
+			)
+		}
+	}
+	if cfg.TabPoolSize > 0 {
+		s.tabs.setSize(cfg.TabPoolSize)
+		s.cfgMu.Lock()
+		changed := s.cfg.TabPoolSize != cfg.TabPoolSize
+		s.cfg.TabPoolSize = cfg.TabPoolSize
+		s.cfgMu.Unlock()
+		if changed {
+			slog.Info("scheduler: tab pool size reloaded", "tabPoolSize", cfg.TabPoolSize)
+		}
 	}
 	if cfg.ResultTTL > 0 {
 		s.results.SetTTL(cfg.ResultTTL)
-		slog.Info("scheduler: result TTL reloaded", "ttl", cfg.ResultTTL)
+		s.cfgMu.Lock()
+		changed := s.cfg.ResultTTL != cfg.ResultTTL
+		s.cfg.ResultTTL = cfg.ResultTTL
+		s.cfgMu.Unlock()
+		if changed {
+			slog.Info("scheduler: result TTL reloaded", "ttl", cfg.ResultTTL)
+		}
+	}
+}
+
+// reloadQuotas replaces the agent limits. Usage counted so far is kept, so
+// lowering a daily quota takes effect immediately.
+func (s *Scheduler) reloadQuotas(qc QuotaConfig) {
+	if err := qc.Validate(); err != nil {
+		slog.Warn("scheduler: limits not reloaded", "err", err)
+		return
+	}
+	if reflect.DeepEqual(s.quotas.config(), qc) {
+		return
+	}
+	s.quotas.setConfig(qc)
+	s.cfgMu.Lock()
+	s.cfg.Quotas = &qc
+	s.cfgMu.Unlock()
+	slog.Info("scheduler: limits reloaded", "agents", len(qc.Agents))
+}
+
+// reloadWebhooks updates the webhook secret and retry policy. Zero fields
+// keep their current value, so a secret can be rotated but not removed
+// without a restart.
+func (s *Scheduler) reloadWebhooks(cfg Config) {
+	cur := s.webhooks.currentPolicy()
+	next := cur
+	if cfg.WebhookSecret != "" {
+		next.Secret = cfg.WebhookSecret
+	}
+	if cfg.WebhookMaxAttempts > 0 {
+		next.MaxAttempts = cfg.WebhookMaxAttempts
+	}
+	if cfg.WebhookBackoff > 0 {
+		next.Backoff = cfg.WebhookBackoff
+	}
+	next = next.normalize()
+	if next == cur {
+		return
+	}
+	s.webhooks.setPolicy(next)
+	s.cfgMu.Lock()
+	s.cfg.WebhookSecret = next.Secret
+	s.cfg.WebhookMaxAttempts = next.MaxAttempts
+	s.cfg.WebhookBackoff = next.Backoff
+	s.cfgMu.Unlock()
+	slog.Info("scheduler: webhook policy reloaded",
+		"signed", next.Secret != "",
+		"secretRotated", next.Secret != cur.Secret,
+		"maxAttempts", next.MaxAttempts,
+		"backoff", next.Backoff,
+	)
+}
+
+// reloadPolicy switches the queue strategy, agent weights and tab
+// serialization. An invalid strategy is logged and the current one kept.
+func (s *Scheduler) reloadPolicy(cfg Config) {
+	policy, err := policyFromConfig(cfg)
+	if err != nil {
+		slog.Warn("scheduler: strategy not reloaded", "err", err)
+		return
+	}
+	s.cfgMu.Lock()
+	changed := s.cfg.Strategy != policy.Strategy ||
+		!maps.Equal(s.cfg.AgentWeights, policy.Weights) ||
+		s.cfg.StarvationAfter != policy.StarvationAfter ||
+		s.cfg.SerializeTabs != policy.SerializeTabs
+	s.cfg.Strategy = policy.Strategy
+	s.cfg.AgentWeights = maps.Clone(policy.Weights)
+	s.cfg.StarvationAfter = policy.StarvationAfter
+	s.cfg.SerializeTabs = policy.SerializeTabs
+	s.cfgMu.Unlock()
+	if !changed {
+		return
 	}
+	s.queue.SetPolicy(policy)
+	slog.Info("scheduler: strategy reloaded",
+		"strategy", policy.Strategy,
+		"agentWeights", policy.Weights,
+		"starvationAfter", policy.StarvationAfter,
+		"serializeTabs", policy.SerializeTabs,
+	)
 }
 
 // ConfigWatcher periodically re-reads the scheduler config and applies changes.
@@ -265,3 +265,23 @@ func TestMetricsWiredInDispatch(t *testing.T) {
 		t.Error("avg dispatch latency should be > 0")
 	}
 }
+
+func TestReloadSwitchesStrategy(t *testing.T) {
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+
+	cfg := DefaultConfig()
+	cfg.Strategy = StrategyWeightedFair
+	cfg.AgentWeights = map[string]int{"a1": 2}
+	cfg.SerializeTabs = true
+	s.ReloadConfig(cfg)
+	p := s.queue.Policy()
+	if p.Strategy != StrategyWeightedFair || p.Weights["a1"] != 2 || !p.SerializeTabs {
+		t.Fatalf("policy not reloaded: %+v", p)
+	}
+
+	cfg.Strategy = "nope"
+	s.ReloadConfig(cfg)
+	if got := s.queue.Policy().Strategy; got != StrategyWeightedFair {
+		t.Errorf("invalid reload should keep the current strategy, got %q", got)
+	}
+}
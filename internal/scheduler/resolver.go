@@ -1,14 +1,28 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pinchtab/pinchtab/internal/instance"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
 )
 
-// ManagerResolver adapts instance.Manager to the InstanceResolver interface.
+// TabClient opens, navigates and closes tabs on an instance.
+// instance.BridgeClient implements it.
+type TabClient interface {
+	CreateTab(ctx context.Context, port, url string) (string, error)
+	NavigateTab(ctx context.Context, port, tabID, url string) error
+	CloseTab(ctx context.Context, port, tabID string) error
+}
+
+// ManagerResolver adapts instance.Manager to the InstanceResolver and
+// TabProvisioner interfaces.
 type ManagerResolver struct {
 	Mgr *instance.Manager
+	// Tabs opens and closes the tabs of tasks the scheduler places.
+	// Without it, tasks must name a tabId.
+	Tabs TabClient
 }
 
 func (r *ManagerResolver) ResolveTabInstance(tabID string) (string, error) {
@@ -16,5 +30,42 @@ func (r *ManagerResolver) ResolveTabInstance(tabID string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("tab %q not found: %w", tabID, err)
 	}
+	r.Mgr.Touch(inst.ID)
 	return inst.Port, nil
 }
+
+// OpenTab picks an instance for req with the manager's allocation policy
+// and opens a blank tab on it.
+func (r *ManagerResolver) OpenTab(ctx context.Context, req allocation.Request) (TabPlacement, error) {
+	if r.Tabs == nil {
+		return TabPlacement{}, fmt.Errorf("tab provisioning is not configured")
+	}
+	inst, err := r.Mgr.AllocateProfile(req)
+	if err != nil {
+		return TabPlacement{}, err
+	}
+	tabID, err := r.Tabs.CreateTab(ctx, inst.Port, "")
+	if err != nil {
+		return TabPlacement{}, fmt.Errorf("open tab on %s: %w", inst.ID, err)
+	}
+	r.Mgr.RegisterTab(tabID, inst.ID)
+	r.Mgr.Touch(inst.ID)
+	return TabPlacement{InstanceID: inst.ID, TabID: tabID, Port: inst.Port}, nil
+}
+
+// CloseTab closes a tab opened by OpenTab.
+func (r *ManagerResolver) CloseTab(ctx context.Context, p TabPlacement) error {
+	if r.Tabs == nil {
+		return fmt.Errorf("tab provisioning is not configured")
+	}
+	r.Mgr.InvalidateTab(p.TabID)
+	return r.Tabs.CloseTab(ctx, p.Port, p.TabID)
+}
+
+// ResetTab navigates a tab opened by OpenTab to about:blank.
+func (r *ManagerResolver) ResetTab(ctx context.Context, p TabPlacement) error {
+	if r.Tabs == nil {
+		return fmt.Errorf("tab provisioning is not configured")
+	}
+	return r.Tabs.NavigateTab(ctx, p.Port, p.TabID, "about:blank")
+}
@@ -1,15 +1,18 @@
 package scheduler
 
 import (
+	"log/slog"
 	"sync"
 	"time"
 )
 
 // ResultStore holds completed task results in memory with TTL-based expiry.
+// Every change is written through to the backing TaskStore.
 type ResultStore struct {
 	mu      sync.RWMutex
 	tasks   map[string]*Task
 	ttl     time.Duration
+	persist TaskStore
 	closeCh chan struct{}
 }
 
@@ -18,6 +21,7 @@ func NewResultStore(ttl time.Duration) *ResultStore {
 	return &ResultStore{
 		tasks:   make(map[string]*Task),
 		ttl:     ttl,
+		persist: memoryTaskStore{},
 		closeCh: make(chan struct{}),
 	}
 }
@@ -55,11 +59,29 @@ func (rs *ResultStore) SetTTL(ttl time.Duration) {
 	rs.mu.Unlock()
 }
 
+// SetPersistence sets the backing store. Call before any task is stored.
+func (rs *ResultStore) SetPersistence(st TaskStore) {
+	rs.mu.Lock()
+	rs.persist = st
+	rs.mu.Unlock()
+}
+
 // Store saves a task snapshot into the result store.
 func (rs *ResultStore) Store(t *Task) {
 	snap := t.Snapshot()
 	rs.mu.Lock()
 	rs.tasks[snap.ID] = snap
+	persist := rs.persist
+	rs.mu.Unlock()
+	if err := persist.Put(snap); err != nil {
+		slog.Warn("scheduler store: persist failed", "task", snap.ID, "err", err)
+	}
+}
+
+// restore loads a snapshot without writing it back to the backing store.
+func (rs *ResultStore) restore(t *Task) {
+	rs.mu.Lock()
+	rs.tasks[t.ID] = t
 	rs.mu.Unlock()
 }
 
@@ -97,7 +119,11 @@ func (rs *ResultStore) List(agentID string, states []TaskState) []*Task {
 func (rs *ResultStore) Delete(taskID string) {
 	rs.mu.Lock()
 	delete(rs.tasks, taskID)
+	persist := rs.persist
 	rs.mu.Unlock()
+	if err := persist.Delete(taskID); err != nil {
+		slog.Warn("scheduler store: delete failed", "task", taskID, "err", err)
+	}
 }
 
 func (rs *ResultStore) evict() {
@@ -106,8 +132,15 @@ func (rs *ResultStore) evict() {
 
 	cutoff := timeNow().Add(-rs.ttl)
 	for id, t := range rs.tasks {
-		if t.State.IsTerminal() && !t.CompletedAt.IsZero() && t.CompletedAt.Before(cutoff) {
+		if rs.expired(t, cutoff) {
 			delete(rs.tasks, id)
+			if err := rs.persist.Delete(id); err != nil {
+				slog.Warn("scheduler store: delete failed", "task", id, "err", err)
+			}
 		}
 	}
 }
+
+func (rs *ResultStore) expired(t *Task, cutoff time.Time) bool {
+	return t.State.IsTerminal() && !t.CompletedAt.IsZero() && t.CompletedAt.Before(cutoff)
+}
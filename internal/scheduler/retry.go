@@ -0,0 +1,208 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+)
+
+// Failure classes a RetryPolicy can retry on.
+const (
+	RetryOnTimeout  = "timeout"
+	RetryOn5xx      = "5xx"
+	RetryOnStaleRef = "stale_ref"
+)
+
+const (
+	// maxRetries bounds RetryPolicy.Max.
+	maxRetries = 10
+	// defaultRetryBackoff is the first retry delay when none is given.
+	defaultRetryBackoff = time.Second
+	// maxRetryBackoff caps the exponential backoff.
+	maxRetryBackoff = time.Minute
+)
+
+// RetryPolicy retries a failed task up to Max more times. The delay before
+// retry n is Backoff * 2^(n-1), capped at one minute. Only failures whose
+// class is listed in RetryOn are retried; the default is timeout and 5xx.
+type RetryPolicy struct {
+	Max     int      `json:"max"`
+	Backoff string   `json:"backoff,omitempty"`
+	RetryOn []string `json:"retryOn,omitempty"`
+}
+
+// Validate checks the policy fields.
+func (p *RetryPolicy) Validate() error {
+	if p.Max < 0 || p.Max > maxRetries {
+		return fmt.Errorf("retry.max must be between 0 and %d", maxRetries)
+	}
+	if p.Backoff != "" {
+		d, err := time.ParseDuration(p.Backoff)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid retry.backoff %q", p.Backoff)
+		}
+	}
+	for _, on := range p.RetryOn {
+		switch on {
+		case RetryOnTimeout, RetryOn5xx, RetryOnStaleRef:
+		default:
+			return fmt.Errorf("invalid retry.retryOn %q (want timeout, 5xx or stale_ref)", on)
+		}
+	}
+	return nil
+}
+
+func (p *RetryPolicy) retries(class string) bool {
+	if len(p.RetryOn) == 0 {
+		return class == RetryOnTimeout || class == RetryOn5xx
+	}
+	for _, on := range p.RetryOn {
+		if on == class {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the wait before the given retry (1-based).
+func (p *RetryPolicy) delay(retry int) time.Duration {
+	base := defaultRetryBackoff
+	if p.Backoff != "" {
+		base, _ = time.ParseDuration(p.Backoff)
+	}
+	d := base
+	for i := 1; i < retry && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryBackoff)
+}
+
+// Attempt records one execution of a task.
+type Attempt struct {
+	Attempt     int       `json:"attempt"`
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+	LatencyMs   int64     `json:"latencyMs"`
+	StatusCode  int       `json:"statusCode,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	// Class is the failure class (timeout, 5xx, stale_ref) when known.
+	Class string `json:"class,omitempty"`
+}
+
+// executorError is returned when the executor answers with an error status.
+type executorError struct {
+	status int
+	body   string
+}
+
+func (e *executorError) Error() string {
+	return fmt.Sprintf("executor returned %d: %s", e.status, e.body)
+}
+
+// classifyFailure maps an execution error to a retry class, or "" if it
+// is not one a policy can retry on.
+func classifyFailure(err error) (class string, status int) {
+	var exe *executorError
+	if errors.As(err, &exe) {
+		body := strings.ToLower(exe.body)
+		switch {
+		case strings.Contains(body, "take a /snapshot first"),
+			strings.Contains(body, "not found and recovery failed"),
+			strings.Contains(body, "could not find node"),
+			strings.Contains(body, "node with given id"),
+			strings.Contains(body, "no node"):
+			return RetryOnStaleRef, exe.status
+		case exe.status == 408 || exe.status == 504:
+			return RetryOnTimeout, exe.status
+		case exe.status >= 500:
+			return RetryOn5xx, exe.status
+		}
+		return "", exe.status
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return RetryOnTimeout, 0
+	}
+	return "", 0
+}
+
+// retryDelay reports whether t should be retried after a failure of the
+// given class, and how long to wait. A retry that could not start before
+// the task deadline is not attempted.
+func (s *Scheduler) retryDelay(t *Task, class string) (time.Duration, bool) {
+	t.mu.RLock()
+	policy := t.Retry
+	failures := len(t.Attempts)
+	deadline := t.Deadline
+	t.mu.RUnlock()
+
+	if policy == nil || class == "" || !policy.retries(class) || failures > policy.Max {
+		return 0, false
+	}
+	d := policy.delay(failures)
+	if !deadline.IsZero() && timeNow().Add(d).After(deadline) {
+		return 0, false
+	}
+	return d, true
+}
+
+// scheduleRetry parks a failed running task in StateRetrying, frees its
+// in-flight slot and re-queues it after delay.
+func (s *Scheduler) scheduleRetry(t *Task, delay time.Duration, cause error) {
+	t.mu.Lock()
+	t.Error = cause.Error()
+	t.NextAttemptAt = timeNow().Add(delay)
+	attempt := len(t.Attempts)
+	t.mu.Unlock()
+	if err := t.SetState(StateRetrying); err != nil {
+		slog.Warn("task state transition failed", "task", t.ID, "err", err)
+		s.finishTask(t)
+		return
+	}
+	t.mu.Lock()
+	t.dispatched = false
+	t.mu.Unlock()
+	s.queue.Complete(t.AgentID, t.TabID)
+	s.results.Store(t)
+	s.metrics.recordRetry(t.AgentID)
+	slog.Info("task retrying", "task", t.ID, "agent", t.AgentID, "attempt", attempt, "delay", delay, "err", cause)
+
+	s.retryMu.Lock()
+	s.retryTimers[t.ID] = time.AfterFunc(delay, func() { s.requeueRetry(t) })
+	s.retryMu.Unlock()
+}
+
+// requeueRetry moves a retrying task back to the queue.
+func (s *Scheduler) requeueRetry(t *Task) {
+	s.retryMu.Lock()
+	delete(s.retryTimers, t.ID)
+	s.retryMu.Unlock()
+
+	select {
+	case <-s.stopCh:
+		return
+	default:
+	}
+	t.mu.Lock()
+	t.NextAttemptAt = time.Time{}
+	t.mu.Unlock()
+	if err := t.SetState(StateQueued); err != nil {
+		// Cancelled while waiting.
+		return
+	}
+	_, _ = s.enqueue(t)
+}
+
+// stopRetry cancels the pending retry timer of a task.
+func (s *Scheduler) stopRetry(taskID string) {
+	s.retryMu.Lock()
+	if timer, ok := s.retryTimers[taskID]; ok {
+		timer.Stop()
+		delete(s.retryTimers, taskID)
+	}
+	s.retryMu.Unlock()
+}
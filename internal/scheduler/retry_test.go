@@ -0,0 +1,218 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		err   error
+		class string
+	}{
+		{&executorError{status: 503, body: "unavailable"}, RetryOn5xx},
+		{&executorError{status: 504, body: "gateway timeout"}, RetryOnTimeout},
+		{&executorError{status: 404, body: `{"error":"ref e5 not found - take a /snapshot first"}`}, RetryOnStaleRef},
+		{&executorError{status: 500, body: `{"error":"action click: could not find node with given id"}`}, RetryOnStaleRef},
+		{&executorError{status: 400, body: "bad request"}, ""},
+		{fmt.Errorf("executor request failed: %w", context.DeadlineExceeded), RetryOnTimeout},
+		{errors.New("tabId is required"), ""},
+	}
+	for _, tt := range tests {
+		if got, _ := classifyFailure(tt.err); got != tt.class {
+			t.Errorf("classifyFailure(%v) = %q, want %q", tt.err, got, tt.class)
+		}
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	for _, p := range []RetryPolicy{{Max: -1}, {Max: maxRetries + 1}, {Max: 1, Backoff: "soon"}, {Max: 1, RetryOn: []string{"4xx"}}} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%+v should be invalid", p)
+		}
+	}
+
+	p := RetryPolicy{Max: 5, Backoff: "10s"}
+	for retry, want := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute} {
+		if got := p.delay(retry); got != want {
+			t.Errorf("delay(%d) = %s, want %s", retry, got, want)
+		}
+	}
+	if !p.retries(RetryOn5xx) || p.retries(RetryOnStaleRef) {
+		t.Error("default retryOn should be timeout and 5xx")
+	}
+}
+
+// flakyScheduler runs tasks against an executor that answers with the
+// given status codes in order, then 200.
+func flakyScheduler(t *testing.T, statuses ...int) (*Scheduler, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	executor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			http.Error(w, "flaky", statuses[n-1])
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	t.Cleanup(executor.Close)
+	parts := strings.Split(executor.URL, ":")
+
+	cfg := DefaultConfig()
+	cfg.WorkerCount = 1
+	s := New(cfg, &mockResolver{port: parts[len(parts)-1]})
+	s.Start()
+	t.Cleanup(s.Stop)
+	return s, &calls
+}
+
+func waitTerminal(t *testing.T, s *Scheduler, id string) *Task {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		if got := s.GetTask(id); got != nil && got.GetState().IsTerminal() {
+			return got.Snapshot()
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("task %s did not finish: %+v", id, s.GetTask(id).Snapshot())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestRetrySucceedsAfterTransientFailures(t *testing.T) {
+	s, calls := flakyScheduler(t, 503, 502)
+	task, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1",
+		Retry: &RetryPolicy{Max: 3, Backoff: "10ms"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := waitTerminal(t, s, task.ID)
+	if got.State != StateDone || got.Error != "" {
+		t.Fatalf("expected done after retries, got %s: %s", got.State, got.Error)
+	}
+	if len(got.Attempts) != 3 || calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d (%d calls)", len(got.Attempts), calls.Load())
+	}
+	if a := got.Attempts[0]; a.Attempt != 1 || a.Class != RetryOn5xx || a.StatusCode != 503 {
+		t.Errorf("unexpected first attempt: %+v", a)
+	}
+	if got.Attempts[2].Error != "" {
+		t.Errorf("final attempt should succeed: %+v", got.Attempts[2])
+	}
+	if m := s.GetMetrics(); m.TasksRetried != 2 || m.Agents["a1"].Retried != 2 {
+		t.Errorf("expected 2 retries in metrics, got %+v", m)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	s, calls := flakyScheduler(t, 500, 500, 500, 500)
+	task, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1",
+		Retry: &RetryPolicy{Max: 2, Backoff: "5ms"}})
+
+	got := waitTerminal(t, s, task.ID)
+	if got.State != StateFailed || len(got.Attempts) != 3 || calls.Load() != 3 {
+		t.Fatalf("expected failure after 3 attempts, got %s with %d attempts", got.State, len(got.Attempts))
+	}
+}
+
+func TestRetrySkipsUnlistedFailures(t *testing.T) {
+	s, calls := flakyScheduler(t, 400)
+	task, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1",
+		Retry: &RetryPolicy{Max: 3, Backoff: "5ms", RetryOn: []string{RetryOnStaleRef}}})
+
+	got := waitTerminal(t, s, task.ID)
+	if got.State != StateFailed || calls.Load() != 1 {
+		t.Fatalf("400 must not be retried, got %s after %d calls", got.State, calls.Load())
+	}
+}
+
+func TestCancelRetryingTask(t *testing.T) {
+	s, calls := flakyScheduler(t, 503)
+	task, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1",
+		Retry: &RetryPolicy{Max: 1, Backoff: "1s"}})
+
+	deadline := time.After(5 * time.Second)
+	for s.GetTask(task.ID).GetState() != StateRetrying {
+		select {
+		case <-deadline:
+			t.Fatal("task never entered retrying")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if next := s.GetTask(task.ID).Snapshot().NextAttemptAt; next.IsZero() {
+		t.Error("retrying task should report nextAttemptAt")
+	}
+	if err := s.Cancel(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetTask(task.ID); got.GetState() != StateCancelled {
+		t.Fatalf("expected cancelled, got %s", got.GetState())
+	}
+	if s.QueueStats().TotalInflight != 0 {
+		t.Error("retrying task must not hold an in-flight slot")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("cancelled retry must not run again, got %d calls", calls.Load())
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	req := SubmitRequest{AgentID: "a1", Action: "click", TabID: "t1", IdempotencyKey: "order-42"}
+
+	first, err := s.Submit(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, existing, err := s.submit(req)
+	if err != nil || !existing || again.ID != first.ID {
+		t.Fatalf("resubmission should return the original task: %v %v %v", again, existing, err)
+	}
+	if s.QueueStats().TotalQueued != 1 {
+		t.Errorf("expected 1 queued task, got %d", s.QueueStats().TotalQueued)
+	}
+
+	changed := req
+	changed.TabID = "t2"
+	if _, err := s.Submit(changed); !errors.Is(err, ErrIdempotencyConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+
+	other := req
+	other.AgentID = "a2"
+	if t2, err := s.Submit(other); err != nil || t2.ID == first.ID {
+		t.Errorf("keys are scoped per agent: %v %v", t2, err)
+	}
+}
+
+func TestHandlerIdempotentSubmit(t *testing.T) {
+	_, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/tasks", strings.NewReader(body)))
+		return w
+	}
+	body := `{"agentId":"a1","action":"click","tabId":"t1","idempotencyKey":"k1"}`
+	if w := post(body); w.Code != 202 {
+		t.Fatalf("first submit: %d %s", w.Code, w.Body.String())
+	}
+	if w := post(body); w.Code != 200 || !strings.Contains(w.Body.String(), `"duplicate":true`) {
+		t.Errorf("replay: %d %s", w.Code, w.Body.String())
+	}
+	if w := post(`{"agentId":"a1","action":"hover","tabId":"t1","idempotencyKey":"k1"}`); w.Code != 409 {
+		t.Errorf("conflict: %d %s", w.Code, w.Body.String())
+	}
+}
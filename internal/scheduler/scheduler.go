@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -33,12 +34,31 @@ type Config struct {
 	WorkerCount       int           `json:"workerCount"`
 	MaxBatchSize      int           `json:"maxBatchSize"`
 	WatcherInterval   time.Duration `json:"watcherInterval"`
+	Store             string        `json:"store"`
+	InflightPolicy    string        `json:"inflightPolicy"`
+	// AgentWeights are the agents' shares under the weighted-fair strategy.
+	AgentWeights map[string]int `json:"agentWeights,omitempty"`
+	// StarvationAfter bounds how long the priority strategy lets a task wait.
+	StarvationAfter time.Duration `json:"starvationAfter"`
+	// SerializeTabs keeps at most one task per tab in flight.
+	SerializeTabs bool `json:"serializeTabs"`
+	// Quotas are per-agent rate limits and daily quotas. Nil means none,
+	// and leaves the current quotas alone on reload.
+	Quotas *QuotaConfig `json:"quotas,omitempty"`
+	// WebhookSecret signs callback payloads with HMAC-SHA256 when set.
+	WebhookSecret string `json:"-"`
+	// WebhookMaxAttempts and WebhookBackoff control callback retries.
+	WebhookMaxAttempts int           `json:"webhookMaxAttempts"`
+	WebhookBackoff     time.Duration `json:"webhookBackoff"`
+	// TabPoolSize is how many idle tabs opened for tasks without a tabId
+	// are kept per profile for reuse.
+	TabPoolSize int `json:"tabPoolSize"`
 }
 
 // DefaultConfig returns safe defaults.
 func DefaultConfig() Config {
 	return Config{
-		Strategy:          "fair-fifo",
+		Strategy:          StrategyFairFIFO,
 		MaxQueueSize:      1000,
 		MaxPerAgent:       100,
 		MaxInflight:       20,
@@ -47,6 +67,13 @@ func DefaultConfig() Config {
 		WorkerCount:       4,
 		MaxBatchSize:      50,
 		WatcherInterval:   30 * time.Second,
+		Store:             StoreMemory,
+		InflightPolicy:    InflightFail,
+		StarvationAfter:   defaultStarvationAfter,
+
+		WebhookMaxAttempts: defaultWebhookAttempts,
+		WebhookBackoff:     defaultWebhookBackoff,
+		TabPoolSize:        defaultTabPoolSize,
 	}
 }
 
@@ -57,6 +84,7 @@ type Scheduler struct {
 	queue    *TaskQueue
 	results  *ResultStore
 	resolver InstanceResolver
+	store    TaskStore
 	client   *http.Client
 	metrics  *Metrics
 
@@ -64,8 +92,34 @@ type Scheduler struct {
 	live   map[string]*Task
 	liveMu sync.RWMutex
 
-	// webhookSem bounds the number of concurrent webhook delivery goroutines.
-	webhookSem chan struct{}
+	// webhooks delivers task callbacks and logs the deliveries.
+	webhooks *webhooks
+
+	// events streams task state changes to GET /tasks/stream.
+	events *eventLog
+
+	// quotas holds per-agent rate limits and usage.
+	quotas *quotas
+
+	// tabs holds idle tabs opened for tasks submitted without a tabId.
+	tabs *tabPool
+
+	// tasks waiting on dependencies, and the DAGs they belong to.
+	waiting map[string]*Task
+	dags    map[string]*dagRecord
+	dagMu   sync.Mutex
+
+	// pending retry timers by task ID.
+	retryTimers map[string]*time.Timer
+	retryMu     sync.Mutex
+
+	// idempotency keys by agent and key.
+	idem   map[string]idemEntry
+	idemMu sync.Mutex
+
+	// recurring schedules by ID.
+	schedules map[string]*Schedule
+	schedMu   sync.Mutex
 
 	// cancellation
 	cancels   map[string]context.CancelFunc
@@ -102,23 +156,75 @@ func New(cfg Config, resolver InstanceResolver) *Scheduler {
 	if cfg.WatcherInterval <= 0 {
 		cfg.WatcherInterval = 30 * time.Second
 	}
+	if cfg.Store == "" {
+		cfg.Store = StoreMemory
+	}
+	if cfg.InflightPolicy == "" {
+		cfg.InflightPolicy = InflightFail
+	}
+	if cfg.TabPoolSize <= 0 {
+		cfg.TabPoolSize = defaultTabPoolSize
+	}
+	policy, err := policyFromConfig(cfg)
+	if err != nil {
+		slog.Warn("scheduler: falling back to fair-fifo", "err", err)
+		cfg.Strategy, cfg.AgentWeights = StrategyFairFIFO, nil
+		policy, _ = policyFromConfig(cfg)
+	}
+	cfg.Strategy = policy.Strategy
+	cfg.StarvationAfter = policy.StarvationAfter
+	var quotaCfg QuotaConfig
+	if cfg.Quotas != nil {
+		if err := cfg.Quotas.Validate(); err != nil {
+			slog.Warn("scheduler: ignoring invalid quotas", "err", err)
+			cfg.Quotas = nil
+		} else {
+			quotaCfg = *cfg.Quotas
+		}
+	}
+	webhookPolicy := cfg.webhookPolicy()
+	cfg.WebhookMaxAttempts, cfg.WebhookBackoff = webhookPolicy.MaxAttempts, webhookPolicy.Backoff
+	queue := NewTaskQueue(cfg.MaxQueueSize, cfg.MaxPerAgent)
+	queue.SetPolicy(policy)
+	stopCh := make(chan struct{})
 
 	return &Scheduler{
-		cfg:        cfg,
-		queue:      NewTaskQueue(cfg.MaxQueueSize, cfg.MaxPerAgent),
-		results:    NewResultStore(cfg.ResultTTL),
-		resolver:   resolver,
-		client:     &http.Client{Timeout: 60 * time.Second},
-		metrics:    newMetrics(),
-		live:       make(map[string]*Task),
-		cancels:    make(map[string]context.CancelFunc),
-		stopCh:     make(chan struct{}),
-		webhookSem: make(chan struct{}, 16),
+		cfg:         cfg,
+		queue:       queue,
+		results:     NewResultStore(cfg.ResultTTL),
+		resolver:    resolver,
+		store:       memoryTaskStore{},
+		client:      &http.Client{Timeout: 60 * time.Second},
+		metrics:     newMetrics(),
+		live:        make(map[string]*Task),
+		waiting:     make(map[string]*Task),
+		dags:        make(map[string]*dagRecord),
+		retryTimers: make(map[string]*time.Timer),
+		idem:        make(map[string]idemEntry),
+		schedules:   make(map[string]*Schedule),
+		cancels:     make(map[string]context.CancelFunc),
+		stopCh:      stopCh,
+		webhooks:    newWebhooks(webhookPolicy, stopCh),
+		events:      newEventLog(),
+		quotas:      newQuotas(quotaCfg),
+		tabs:        newTabPool(cfg.TabPoolSize),
 	}
 }
 
-// Start launches workers and the deadline reaper.
+// SetStore sets the backing task store. It must be called before Start,
+// which replays the stored tasks.
+func (s *Scheduler) SetStore(st TaskStore) {
+	if st == nil {
+		st = memoryTaskStore{}
+	}
+	s.store = st
+	s.results.SetPersistence(st)
+}
+
+// Start launches workers, the deadline reaper and the schedule loop.
 func (s *Scheduler) Start() {
+	s.restore()
+	s.restoreSchedules()
 	s.results.StartReaper(10 * time.Second)
 
 	for i := range s.cfg.WorkerCount {
@@ -129,6 +235,9 @@ func (s *Scheduler) Start() {
 	s.wg.Add(1)
 	go s.deadlineReaper()
 
+	s.wg.Add(1)
+	go s.scheduleLoop()
+
 	slog.Info("scheduler started", "workers", s.cfg.WorkerCount, "strategy", s.cfg.Strategy)
 }
 
@@ -139,30 +248,101 @@ func (s *Scheduler) Stop() {
 		close(s.stopCh)
 		s.wg.Wait()
 		s.results.Stop()
+		for _, tp := range s.tabs.drain() {
+			s.closeTab(tp)
+		}
+
+		s.retryMu.Lock()
+		for id, timer := range s.retryTimers {
+			timer.Stop()
+			delete(s.retryTimers, id)
+		}
+		s.retryMu.Unlock()
 
+		// A durable store keeps queued tasks for replay on the next start.
+		durable := isDurable(s.store)
 		s.liveMu.Lock()
 		for id, t := range s.live {
-			if !t.GetState().IsTerminal() {
-				_ = t.SetState(StateCancelled)
+			if !durable && !t.GetState().IsTerminal() {
+				t.mu.Lock()
 				t.Error = "scheduler shutdown"
+				t.mu.Unlock()
+				_ = t.SetState(StateCancelled)
 				s.results.Store(t)
 			}
 			delete(s.live, id)
 		}
 		s.liveMu.Unlock()
+		s.dagMu.Lock()
+		clear(s.waiting)
+		s.dagMu.Unlock()
+
+		if err := s.store.Close(); err != nil {
+			slog.Warn("scheduler store close failed", "err", err)
+		}
+		s.events.close()
 
 		slog.Info("scheduler stopped")
 	})
 }
 
-// Submit creates a new task from the request and enqueues it.
+// defaultTaskTimeout is the deadline given to tasks submitted without one.
+// For tasks with dependencies it starts when they are queued.
+const defaultTaskTimeout = 60 * time.Second
+
+// Submit creates a new task from the request and enqueues it. A task with
+// dependsOn waits until its dependencies finish. A request whose
+// idempotencyKey was already used by the same agent returns the original
+// task.
 func (s *Scheduler) Submit(req SubmitRequest) (*Task, error) {
+	t, _, err := s.submit(req)
+	return t, err
+}
+
+func (s *Scheduler) submitNew(req SubmitRequest) (*Task, error) {
+	t, err := s.newTask(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if rerr := s.quotas.take(t.AgentID, t.Action); rerr != nil {
+		s.events.publish(t, "")
+		s.reject(t, rerr)
+		return t, fmt.Errorf("rejected: %w", rerr)
+	}
+
+	if len(t.DependsOn) > 0 {
+		if err := s.admitDependent(t); err != nil {
+			s.quotas.refund(t.AgentID, t.Action)
+			return nil, fmt.Errorf("invalid task: %w", err)
+		}
+		s.metrics.recordSubmit(req.AgentID)
+		slog.Info("task submitted", "task", t.ID, "agent", req.AgentID, "action", t.Action, "dag", t.DagID, "dependsOn", t.DependsOn)
+		return t, nil
+	}
+
+	s.events.publish(t, "")
+	pos, err := s.enqueue(t)
+	if err != nil {
+		s.quotas.refund(t.AgentID, t.Action)
+		return t, fmt.Errorf("rejected: %w", err)
+	}
+	s.metrics.recordSubmit(req.AgentID)
+	slog.Info("task submitted", "task", t.ID, "agent", req.AgentID, "action", t.Action, "priority", t.Priority, "position", pos)
+	return t, nil
+}
+
+// newTask validates req and builds a task from it.
+func (s *Scheduler) newTask(req SubmitRequest) (*Task, error) {
 	if err := req.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid task: %w", err)
 	}
 
 	now := timeNow()
-	deadline := now.Add(60 * time.Second)
+	var deadline time.Time
+	if len(req.DependsOn) == 0 {
+		deadline = now.Add(defaultTaskTimeout)
+	}
 	if req.Deadline != "" {
 		parsed, err := time.Parse(time.RFC3339, req.Deadline)
 		if err != nil {
@@ -186,28 +366,67 @@ func (s *Scheduler) Submit(req SubmitRequest) (*Task, error) {
 		Deadline:    deadline,
 		CreatedAt:   now,
 		CallbackURL: req.CallbackURL,
+		DependsOn:   req.DependsOn,
+		Retry:       req.Retry,
+
+		IdempotencyKey: req.IdempotencyKey,
+		Profile:        req.Profile,
+		Labels:         req.Labels,
+		CloseTab:       req.CloseTab,
+
+		observer: s.observe,
 	}
+	if len(req.DependsOn) > 0 {
+		t.OnDependencyFailure = req.OnDependencyFailure
+		if t.OnDependencyFailure == "" {
+			t.OnDependencyFailure = DepFailureCancel
+		}
+	}
+	return t, nil
+}
 
+// enqueue admits a queued task to the queue. A task the queue refuses is
+// stored as rejected and finished, so its dependents are released.
+func (s *Scheduler) enqueue(t *Task) (int, error) {
 	pos, err := s.queue.Enqueue(t)
 	if err != nil {
-		t.State = StateRejected
-		t.Error = err.Error()
-		s.results.Store(t)
-		s.metrics.recordReject(req.AgentID)
-		slog.Warn("task rejected", "task", t.ID, "agent", req.AgentID, "err", err)
-		return t, fmt.Errorf("rejected: %w", err)
+		s.reject(t, err)
+		return 0, err
 	}
 
+	t.mu.Lock()
 	t.Position = pos
+	t.mu.Unlock()
 
 	s.liveMu.Lock()
 	s.live[t.ID] = t
 	s.liveMu.Unlock()
 
 	s.results.Store(t)
-	s.metrics.recordSubmit(req.AgentID)
-	slog.Info("task submitted", "task", t.ID, "agent", req.AgentID, "action", t.Action, "priority", t.Priority, "position", pos)
-	return t, nil
+	return pos, nil
+}
+
+// reject stores a task refused at admission as rejected, with the reason
+// taken from err, and finishes it.
+func (s *Scheduler) reject(t *Task, err error) {
+	reason := RejectQueueFull
+	var rerr *RejectError
+	if errors.As(err, &rerr) {
+		reason = rerr.Reason
+	}
+	t.mu.Lock()
+	t.Error = err.Error()
+	t.RejectReason = reason
+	t.mu.Unlock()
+	if stateErr := t.SetState(StateRejected); stateErr != nil {
+		slog.Warn("task state transition failed", "task", t.ID, "err", stateErr)
+	}
+	s.metrics.recordReject(t.AgentID)
+	if rerr == nil || rerr.queueFull() {
+		s.quotas.recordReject(t.AgentID, reason)
+	}
+	slog.Warn("task rejected", "task", t.ID, "agent", t.AgentID, "reason", reason, "err", err)
+	s.finishTask(t)
 }
 
 // GetTask retrieves a task by ID from live or completed results.
@@ -243,8 +462,15 @@ func (s *Scheduler) Cancel(taskID string) error {
 		return fmt.Errorf("task %q already in terminal state %q", taskID, state)
 	}
 
-	if state == StateQueued {
+	switch state {
+	case StateQueued:
 		s.queue.Remove(t.ID, t.AgentID)
+	case StateWaiting:
+		s.dagMu.Lock()
+		delete(s.waiting, t.ID)
+		s.dagMu.Unlock()
+	case StateRetrying:
+		s.stopRetry(t.ID)
 	}
 
 	s.cancelsMu.Lock()
@@ -312,6 +538,9 @@ func (s *Scheduler) worker(id int) {
 
 func (s *Scheduler) dispatch(t *Task) {
 	dispatchStart := timeNow()
+	t.mu.Lock()
+	t.dispatched = true
+	t.mu.Unlock()
 
 	if err := t.SetState(StateAssigned); err != nil {
 		slog.Warn("task state transition failed", "task", t.ID, "err", err)
@@ -342,20 +571,45 @@ func (s *Scheduler) dispatch(t *Task) {
 	slog.Info("task running", "task", t.ID, "agent", t.AgentID)
 	s.results.Store(t)
 
+	runStart := timeNow()
 	result, execErr := s.executeTask(ctx, t)
+	end := timeNow()
 
-	latency := timeNow().Sub(dispatchStart)
+	latency := end.Sub(dispatchStart)
 	s.metrics.recordDispatchLatency(latency)
 
+	attempt := Attempt{StartedAt: runStart, CompletedAt: end, LatencyMs: end.Sub(runStart).Milliseconds()}
+	if execErr != nil {
+		attempt.Error = execErr.Error()
+		attempt.Class, attempt.StatusCode = classifyFailure(execErr)
+	}
+	t.mu.Lock()
+	attempt.Attempt = len(t.Attempts) + 1
+	t.Attempts = append(t.Attempts, attempt)
+	t.mu.Unlock()
+
 	if execErr != nil {
+		if delay, ok := s.retryDelay(t, attempt.Class); ok {
+			s.scheduleRetry(t, delay, execErr)
+			return
+		}
+		t.mu.Lock()
 		t.Error = execErr.Error()
+		if result != nil {
+			// Partial output, such as the steps a macro ran before failing.
+			t.Result = result
+		}
+		t.mu.Unlock()
 		if stateErr := t.SetState(StateFailed); stateErr != nil {
 			slog.Warn("failed to mark task as failed", "task", t.ID, "err", stateErr)
 		}
 		s.metrics.recordFail(t.AgentID)
 		slog.Info("task failed", "task", t.ID, "agent", t.AgentID, "err", execErr, "latencyMs", latency.Milliseconds())
 	} else {
+		t.mu.Lock()
 		t.Result = result
+		t.Error = ""
+		t.mu.Unlock()
 		if stateErr := t.SetState(StateDone); stateErr != nil {
 			slog.Warn("failed to mark task as done", "task", t.ID, "err", stateErr)
 		}
@@ -366,14 +620,32 @@ func (s *Scheduler) dispatch(t *Task) {
 	s.finishTask(t)
 }
 
-func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
-	if t.TabID == "" {
-		return nil, fmt.Errorf("tabId is required for task execution")
+// executeTask runs the task on its tab. A task submitted without a tabId
+// is first placed on a tab from its profile's pool or a newly opened one;
+// afterwards the tab goes back to the pool, or is closed if the task asked
+// for that or failed.
+func (s *Scheduler) executeTask(ctx context.Context, t *Task) (result any, err error) {
+	tabID := t.TabID
+	var port string
+	if tabID == "" {
+		tp, placeErr := s.placeTask(ctx, t)
+		if placeErr != nil {
+			return nil, placeErr
+		}
+		t.mu.Lock()
+		t.Placement = &tp
+		t.mu.Unlock()
+		defer func() { s.releaseTab(tp, t.CloseTab || err != nil) }()
+		tabID, port = tp.TabID, tp.Port
+	} else {
+		port, err = s.resolver.ResolveTabInstance(tabID)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve tab %q: %w", tabID, err)
+		}
 	}
 
-	port, err := s.resolver.ResolveTabInstance(t.TabID)
-	if err != nil {
-		return nil, fmt.Errorf("could not resolve tab %q: %w", t.TabID, err)
+	if t.Action == ActionMacro {
+		return s.executeMacro(ctx, t, tabID, port)
 	}
 
 	// Build the request body matching the immediate-path action format.
@@ -386,7 +658,13 @@ func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
 	for k, v := range t.Params {
 		body[k] = v
 	}
+	return s.postInstance(ctx, t, port, fmt.Sprintf("/tabs/%s/action", tabID), body, "")
+}
 
+// postInstance sends a JSON body to a path on the instance that owns the
+// task's tab and decodes the response. owner, if set, is sent as X-Owner
+// for tab lease checks.
+func (s *Scheduler) postInstance(ctx context.Context, t *Task, port, path string, body any, owner string) (any, error) {
 	payload, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode task body: %w", err)
@@ -395,7 +673,7 @@ func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
 	targetURL := &url.URL{
 		Scheme: "http",
 		Host:   net.JoinHostPort("localhost", port),
-		Path:   fmt.Sprintf("/tabs/%s/action", t.TabID),
+		Path:   path,
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL.String(), bytes.NewReader(payload))
@@ -404,11 +682,14 @@ func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set(activity.HeaderPTSource, "scheduler")
-	req.Header.Set(activity.HeaderPTTabID, t.TabID)
+	req.Header.Set(activity.HeaderPTTabID, t.targetTab())
 	if t.AgentID != "" {
 		req.Header.Set(activity.HeaderAgentID, t.AgentID)
 		req.Header.Set(activity.HeaderPTAgentID, t.AgentID)
 	}
+	if owner != "" {
+		req.Header.Set("X-Owner", owner)
+	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -422,7 +703,7 @@ func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("executor returned %d: %s", resp.StatusCode, string(respBody))
+		return nil, &executorError{status: resp.StatusCode, body: string(respBody)}
 	}
 
 	var result any
@@ -434,24 +715,127 @@ func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
 
 func (s *Scheduler) finishTask(t *Task) {
 	s.results.Store(t)
-	s.queue.Complete(t.AgentID)
+	t.mu.RLock()
+	dispatched := t.dispatched
+	t.mu.RUnlock()
+	if dispatched {
+		s.queue.Complete(t.AgentID, t.TabID)
+	}
 
 	s.liveMu.Lock()
 	delete(s.live, t.ID)
 	s.liveMu.Unlock()
 
-	// Fire webhook asynchronously if configured.
+	s.releaseDependents(t.ID)
+
+	// Deliver the webhook asynchronously if configured.
 	if t.CallbackURL != "" && t.GetState().IsTerminal() {
-		select {
-		case s.webhookSem <- struct{}{}:
-			go func() {
-				defer func() { <-s.webhookSem }()
-				sendWebhook(t.CallbackURL, t)
-			}()
-		default:
-			slog.Warn("webhook: too many in-flight deliveries, dropping", "task", t.ID)
+		s.webhooks.dispatch(t)
+	}
+}
+
+// restore replays tasks from the backing store. Queued tasks are
+// re-enqueued, tasks that were assigned or running are failed or requeued
+// according to InflightPolicy, unexpired results are reloaded, and tasks
+// waiting on dependencies wait again.
+func (s *Scheduler) restore() {
+	tasks, err := s.store.Load()
+	if err != nil {
+		slog.Warn("scheduler store load failed", "err", err)
+		return
+	}
+	if len(tasks) == 0 {
+		return
+	}
+
+	s.cfgMu.RLock()
+	policy := s.cfg.InflightPolicy
+	cutoff := timeNow().Add(-s.cfg.ResultTTL)
+	s.cfgMu.RUnlock()
+
+	var requeued, interrupted, restored int
+	var waiting []*Task
+	for _, t := range tasks {
+		t.observer = s.observe
+		if t.DagID != "" {
+			s.dagMu.Lock()
+			rec := s.dagLocked(t.DagID)
+			rec.TaskIDs = append(rec.TaskIDs, t.ID)
+			s.dagMu.Unlock()
+		}
+
+		if t.State == StateWaiting {
+			s.dagMu.Lock()
+			s.waiting[t.ID] = t
+			s.dagMu.Unlock()
+			s.liveMu.Lock()
+			s.live[t.ID] = t
+			s.liveMu.Unlock()
+			waiting = append(waiting, t)
+			continue
+		}
+
+		s.indexIdempotencyKey(t)
+
+		// A pending retry is due again after a restart.
+		if t.State == StateRetrying {
+			t.State = StateQueued
+			t.NextAttemptAt = time.Time{}
 		}
+
+		if t.State == StateAssigned || t.State == StateRunning {
+			if policy == InflightRequeue {
+				t.State = StateQueued
+				t.StartedAt = time.Time{}
+			} else {
+				now := timeNow()
+				t.State = StateFailed
+				t.Error = "interrupted by scheduler restart"
+				t.CompletedAt = now
+				if !t.StartedAt.IsZero() {
+					t.LatencyMs = now.Sub(t.StartedAt).Milliseconds()
+				}
+				s.metrics.recordFail(t.AgentID)
+				s.finishTask(t)
+				interrupted++
+				continue
+			}
+		}
+
+		if t.State == StateQueued {
+			pos, err := s.queue.Enqueue(t)
+			if err != nil {
+				t.State = StateRejected
+				t.RejectReason = RejectQueueFull
+				t.Error = err.Error()
+				t.CompletedAt = timeNow()
+				s.results.Store(t)
+				s.metrics.recordReject(t.AgentID)
+				continue
+			}
+			t.Position = pos
+			s.liveMu.Lock()
+			s.live[t.ID] = t
+			s.liveMu.Unlock()
+			s.results.Store(t)
+			requeued++
+			continue
+		}
+
+		if s.results.expired(t, cutoff) {
+			_ = s.store.Delete(t.ID)
+			continue
+		}
+		s.results.restore(t)
+		restored++
+	}
+
+	// Dependencies may have finished or been dropped while the server was
+	// down.
+	for _, t := range waiting {
+		s.checkDependencies(t)
 	}
+	slog.Info("scheduler state restored", "requeued", requeued, "waiting", len(waiting), "interrupted", interrupted, "results", restored, "inflightPolicy", policy)
 }
 
 func (s *Scheduler) deadlineReaper() {
@@ -475,6 +859,9 @@ func (s *Scheduler) deadlineReaper() {
 				slog.Info("task expired", "task", t.ID, "agent", t.AgentID)
 				s.finishTask(t)
 			}
+			s.expireWaiting()
+			s.pruneDags()
+			s.pruneIdempotency()
 		}
 	}
 }
@@ -0,0 +1,423 @@
+package scheduler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"maps"
+	mrand "math/rand"
+	"sort"
+	"time"
+)
+
+// Overlap policies decide what happens when a schedule fires while the
+// task from its previous fire is still queued or running.
+const (
+	OverlapSkip  = "skip"
+	OverlapQueue = "queue"
+)
+
+// minScheduleInterval is the shortest accepted interval. Schedules are
+// checked once per scheduleTick, so anything shorter would not be honoured.
+const (
+	minScheduleInterval = time.Second
+	scheduleTick        = time.Second
+)
+
+// ScheduleRequest is the JSON body for POST /schedules.
+type ScheduleRequest struct {
+	Name     string        `json:"name,omitempty"`
+	Cron     string        `json:"cron,omitempty"`
+	Interval string        `json:"interval,omitempty"`
+	Timezone string        `json:"timezone,omitempty"`
+	Jitter   string        `json:"jitter,omitempty"`
+	Overlap  string        `json:"overlap,omitempty"`
+	Paused   bool          `json:"paused,omitempty"`
+	Task     SubmitRequest `json:"task"`
+}
+
+// Schedule submits a copy of its task template each time it fires.
+type Schedule struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name,omitempty"`
+	Cron     string        `json:"cron,omitempty"`
+	Interval string        `json:"interval,omitempty"`
+	Timezone string        `json:"timezone,omitempty"`
+	Jitter   string        `json:"jitter,omitempty"`
+	Overlap  string        `json:"overlap"`
+	Paused   bool          `json:"paused"`
+	Task     SubmitRequest `json:"task"`
+
+	CreatedAt  time.Time `json:"createdAt"`
+	NextRun    time.Time `json:"nextRun,omitempty"`
+	LastRun    time.Time `json:"lastRun,omitempty"`
+	LastTaskID string    `json:"lastTaskId,omitempty"`
+	LastError  string    `json:"lastError,omitempty"`
+	Runs       int       `json:"runs"`
+	Skipped    int       `json:"skipped"`
+	// Pending is set when an OverlapQueue fire is waiting for the previous
+	// task to finish. At most one fire is held back.
+	Pending bool `json:"pending,omitempty"`
+
+	cron     *cronSpec
+	interval time.Duration
+	jitter   time.Duration
+	loc      *time.Location
+	// slot is the un-jittered time of the next fire.
+	slot time.Time
+}
+
+// newSchedule validates req and returns an unscheduled Schedule.
+func newSchedule(req ScheduleRequest) (*Schedule, error) {
+	sc := &Schedule{
+		Name:     req.Name,
+		Cron:     req.Cron,
+		Interval: req.Interval,
+		Timezone: req.Timezone,
+		Jitter:   req.Jitter,
+		Overlap:  req.Overlap,
+		Paused:   req.Paused,
+		Task:     req.Task,
+	}
+	if sc.Overlap == "" {
+		sc.Overlap = OverlapSkip
+	}
+	if err := sc.compile(); err != nil {
+		return nil, err
+	}
+	if err := sc.Task.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid task: %w", err)
+	}
+	if sc.Task.Deadline != "" {
+		return nil, fmt.Errorf("task deadline is not supported for schedules; each fire gets the default deadline")
+	}
+	if sc.Task.IdempotencyKey != "" {
+		return nil, fmt.Errorf("task idempotencyKey is not supported for schedules; every fire would return the first task")
+	}
+	if len(sc.Task.DependsOn) > 0 {
+		return nil, fmt.Errorf("task dependsOn is not supported for schedules")
+	}
+	return sc, nil
+}
+
+// compile parses the textual fields into their runtime form.
+func (sc *Schedule) compile() error {
+	switch {
+	case sc.Cron != "" && sc.Interval != "":
+		return fmt.Errorf("set either cron or interval, not both")
+	case sc.Cron != "":
+		spec, err := parseCron(sc.Cron)
+		if err != nil {
+			return fmt.Errorf("invalid cron: %w", err)
+		}
+		sc.cron = spec
+	case sc.Interval != "":
+		d, err := time.ParseDuration(sc.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval: %w", err)
+		}
+		if d < minScheduleInterval {
+			return fmt.Errorf("interval must be at least %s", minScheduleInterval)
+		}
+		sc.interval = d
+	default:
+		return fmt.Errorf("missing required field 'cron' or 'interval'")
+	}
+
+	sc.loc = time.Local
+	if sc.Timezone != "" {
+		loc, err := time.LoadLocation(sc.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+		sc.loc = loc
+	}
+
+	if sc.Jitter != "" {
+		d, err := time.ParseDuration(sc.Jitter)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid jitter %q", sc.Jitter)
+		}
+		if sc.interval > 0 && d >= sc.interval {
+			return fmt.Errorf("jitter must be shorter than the interval")
+		}
+		sc.jitter = d
+	}
+
+	switch sc.Overlap {
+	case OverlapSkip, OverlapQueue:
+	default:
+		return fmt.Errorf("invalid overlap %q (want skip or queue)", sc.Overlap)
+	}
+	return nil
+}
+
+// nextSlot returns the first un-jittered fire time after t.
+func (sc *Schedule) nextSlot(t time.Time) time.Time {
+	if sc.cron != nil {
+		return sc.cron.next(t.In(sc.loc))
+	}
+	return t.Add(sc.interval)
+}
+
+// arm sets the next fire after now. Slots missed while the scheduler was
+// down or the schedule was paused are dropped rather than replayed.
+func (sc *Schedule) arm(now time.Time) {
+	if sc.slot.IsZero() || !sc.slot.After(now) {
+		if sc.interval > 0 && !sc.slot.IsZero() {
+			for !sc.slot.After(now) {
+				sc.slot = sc.slot.Add(sc.interval)
+			}
+		} else {
+			sc.slot = sc.nextSlot(now)
+		}
+	}
+	sc.NextRun = sc.slot
+	if sc.jitter > 0 && !sc.slot.IsZero() {
+		sc.NextRun = sc.slot.Add(time.Duration(mrand.Int63n(int64(sc.jitter))))
+	}
+}
+
+func (sc *Schedule) snapshot() *Schedule {
+	cp := *sc
+	cp.Task.Params = maps.Clone(sc.Task.Params)
+	return &cp
+}
+
+// CreateSchedule validates and registers a new schedule.
+func (s *Scheduler) CreateSchedule(req ScheduleRequest) (*Schedule, error) {
+	sc, err := newSchedule(req)
+	if err != nil {
+		return nil, err
+	}
+	now := timeNow()
+	sc.ID = generateScheduleID()
+	sc.CreatedAt = now
+	if !sc.Paused {
+		sc.arm(now)
+		if sc.NextRun.IsZero() {
+			return nil, fmt.Errorf("cron expression %q never fires", sc.Cron)
+		}
+	}
+
+	s.schedMu.Lock()
+	s.schedules[sc.ID] = sc
+	snap := sc.snapshot()
+	s.saveSchedulesLocked()
+	s.schedMu.Unlock()
+
+	slog.Info("schedule created", "schedule", sc.ID, "agent", sc.Task.AgentID, "action", sc.Task.Action, "next", sc.NextRun)
+	return snap, nil
+}
+
+// GetSchedule returns a copy of the schedule, or nil if it does not exist.
+func (s *Scheduler) GetSchedule(id string) *Schedule {
+	s.schedMu.Lock()
+	defer s.schedMu.Unlock()
+	sc, ok := s.schedules[id]
+	if !ok {
+		return nil
+	}
+	return sc.snapshot()
+}
+
+// ListSchedules returns copies of all schedules, oldest first.
+func (s *Scheduler) ListSchedules() []*Schedule {
+	s.schedMu.Lock()
+	out := make([]*Schedule, 0, len(s.schedules))
+	for _, sc := range s.schedules {
+		out = append(out, sc.snapshot())
+	}
+	s.schedMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// DeleteSchedule removes a schedule. Tasks it already submitted are not
+// affected.
+func (s *Scheduler) DeleteSchedule(id string) error {
+	s.schedMu.Lock()
+	defer s.schedMu.Unlock()
+	if _, ok := s.schedules[id]; !ok {
+		return fmt.Errorf("schedule %q not found", id)
+	}
+	delete(s.schedules, id)
+	s.saveSchedulesLocked()
+	slog.Info("schedule deleted", "schedule", id)
+	return nil
+}
+
+// PauseSchedule stops a schedule from firing until it is resumed. A fire
+// held back by the queue overlap policy is dropped.
+func (s *Scheduler) PauseSchedule(id string) (*Schedule, error) {
+	return s.setSchedulePaused(id, true)
+}
+
+// ResumeSchedule re-arms a paused schedule from the current time.
+func (s *Scheduler) ResumeSchedule(id string) (*Schedule, error) {
+	return s.setSchedulePaused(id, false)
+}
+
+func (s *Scheduler) setSchedulePaused(id string, paused bool) (*Schedule, error) {
+	s.schedMu.Lock()
+	defer s.schedMu.Unlock()
+	sc, ok := s.schedules[id]
+	if !ok {
+		return nil, fmt.Errorf("schedule %q not found", id)
+	}
+	if sc.Paused == paused {
+		return sc.snapshot(), nil
+	}
+	sc.Paused = paused
+	if paused {
+		sc.NextRun = time.Time{}
+		sc.slot = time.Time{}
+		sc.Pending = false
+	} else {
+		sc.arm(timeNow())
+	}
+	s.saveSchedulesLocked()
+	slog.Info("schedule updated", "schedule", id, "paused", paused)
+	return sc.snapshot(), nil
+}
+
+// tickSchedules fires every schedule that is due at now.
+func (s *Scheduler) tickSchedules(now time.Time) {
+	s.schedMu.Lock()
+	defer s.schedMu.Unlock()
+
+	changed := false
+	for _, sc := range s.schedules {
+		if sc.Paused {
+			continue
+		}
+		if sc.Pending && !s.taskActive(sc.LastTaskID) {
+			sc.Pending = false
+			s.fireSchedule(sc, now)
+			changed = true
+		}
+		if sc.NextRun.IsZero() || now.Before(sc.NextRun) {
+			continue
+		}
+		switch {
+		case !s.taskActive(sc.LastTaskID):
+			s.fireSchedule(sc, now)
+		case sc.Overlap == OverlapQueue:
+			sc.Pending = true
+		default:
+			sc.Skipped++
+			slog.Info("schedule fire skipped", "schedule", sc.ID, "running", sc.LastTaskID)
+		}
+		sc.arm(now)
+		changed = true
+	}
+	if changed {
+		s.saveSchedulesLocked()
+	}
+}
+
+// fireSchedule submits one task from the schedule's template.
+func (s *Scheduler) fireSchedule(sc *Schedule, now time.Time) {
+	req := sc.Task
+	req.Params = maps.Clone(sc.Task.Params)
+
+	sc.LastRun = now
+	sc.Runs++
+	task, err := s.Submit(req)
+	if task != nil {
+		sc.LastTaskID = task.ID
+	}
+	if err != nil {
+		sc.LastError = err.Error()
+		slog.Warn("schedule fire failed", "schedule", sc.ID, "err", err)
+		return
+	}
+	sc.LastError = ""
+	slog.Info("schedule fired", "schedule", sc.ID, "task", task.ID)
+}
+
+// taskActive reports whether the task exists and has not finished.
+func (s *Scheduler) taskActive(taskID string) bool {
+	if taskID == "" {
+		return false
+	}
+	t := s.GetTask(taskID)
+	return t != nil && !t.GetState().IsTerminal()
+}
+
+func (s *Scheduler) scheduleLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.tickSchedules(timeNow())
+		}
+	}
+}
+
+// scheduleStore is implemented by task stores that can also persist
+// schedules.
+type scheduleStore interface {
+	SaveSchedules([]*Schedule) error
+	LoadSchedules() ([]*Schedule, error)
+}
+
+func (s *Scheduler) saveSchedulesLocked() {
+	st, ok := s.store.(scheduleStore)
+	if !ok {
+		return
+	}
+	all := make([]*Schedule, 0, len(s.schedules))
+	for _, sc := range s.schedules {
+		all = append(all, sc)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].CreatedAt.Before(all[j].CreatedAt) })
+	if err := st.SaveSchedules(all); err != nil {
+		slog.Warn("scheduler store: save schedules failed", "err", err)
+	}
+}
+
+// restoreSchedules loads persisted schedules and re-arms them from now.
+func (s *Scheduler) restoreSchedules() {
+	st, ok := s.store.(scheduleStore)
+	if !ok {
+		return
+	}
+	loaded, err := st.LoadSchedules()
+	if err != nil {
+		slog.Warn("scheduler store: load schedules failed", "err", err)
+		return
+	}
+	now := timeNow()
+	s.schedMu.Lock()
+	defer s.schedMu.Unlock()
+	for _, sc := range loaded {
+		if err := sc.compile(); err != nil {
+			slog.Warn("scheduler store: dropping invalid schedule", "schedule", sc.ID, "err", err)
+			continue
+		}
+		sc.Pending = false
+		if !sc.Paused {
+			sc.arm(now)
+		}
+		s.schedules[sc.ID] = sc
+	}
+	if len(loaded) > 0 {
+		slog.Info("schedules restored", "count", len(s.schedules))
+	}
+}
+
+// generateScheduleID produces a random schedule ID in the format sch_XXXXXXXX.
+func generateScheduleID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("sch_%08x", time.Now().UnixNano()&0xFFFFFFFF)
+	}
+	return "sch_" + hex.EncodeToString(b)
+}
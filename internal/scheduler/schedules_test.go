@@ -0,0 +1,240 @@
+package scheduler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withClock(t *testing.T, now time.Time) *time.Time {
+	t.Helper()
+	old := timeNow
+	t.Cleanup(func() { timeNow = old })
+	clock := now
+	timeNow = func() time.Time { return clock }
+	return &clock
+}
+
+func testScheduleRequest() ScheduleRequest {
+	return ScheduleRequest{
+		Interval: "1m",
+		Task:     SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab1", Params: map[string]any{"x": 1}},
+	}
+}
+
+func TestCreateScheduleValidation(t *testing.T) {
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	task := SubmitRequest{AgentID: "a1", Action: "click"}
+	for name, req := range map[string]ScheduleRequest{
+		"no trigger":     {Task: task},
+		"both triggers":  {Cron: "* * * * *", Interval: "1m", Task: task},
+		"bad cron":       {Cron: "* * *", Task: task},
+		"short interval": {Interval: "10ms", Task: task},
+		"bad timezone":   {Cron: "* * * * *", Timezone: "Mars/Olympus", Task: task},
+		"jitter too big": {Interval: "1m", Jitter: "2m", Task: task},
+		"bad overlap":    {Interval: "1m", Overlap: "allow", Task: task},
+		"missing agent":  {Interval: "1m", Task: SubmitRequest{Action: "click"}},
+		"deadline":       {Interval: "1m", Task: SubmitRequest{AgentID: "a1", Action: "click", Deadline: "2030-01-01T00:00:00Z"}},
+		"never fires":    {Cron: "0 0 30 2 *", Task: task},
+	} {
+		if _, err := s.CreateSchedule(req); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestScheduleIntervalFires(t *testing.T) {
+	clock := withClock(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+
+	sc, err := s.CreateSchedule(testScheduleRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := clock.Add(time.Minute); !sc.NextRun.Equal(want) {
+		t.Fatalf("nextRun = %v, want %v", sc.NextRun, want)
+	}
+
+	s.tickSchedules(clock.Add(30 * time.Second))
+	if got := s.GetSchedule(sc.ID); got.Runs != 0 {
+		t.Fatalf("fired early: %+v", got)
+	}
+
+	*clock = clock.Add(time.Minute)
+	s.tickSchedules(*clock)
+	got := s.GetSchedule(sc.ID)
+	if got.Runs != 1 || got.LastTaskID == "" {
+		t.Fatalf("expected one fire, got %+v", got)
+	}
+	task := s.GetTask(got.LastTaskID)
+	if task == nil || task.Action != "click" || task.TabID != "tab1" || task.Params["x"] != 1 {
+		t.Fatalf("fired task does not match template: %+v", task)
+	}
+	if want := clock.Add(time.Minute); !got.NextRun.Equal(want) {
+		t.Errorf("nextRun = %v, want %v", got.NextRun, want)
+	}
+}
+
+func TestScheduleOverlapSkip(t *testing.T) {
+	clock := withClock(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	sc, _ := s.CreateSchedule(testScheduleRequest())
+
+	// Not started, so the first task stays queued.
+	*clock = clock.Add(time.Minute)
+	s.tickSchedules(*clock)
+	*clock = clock.Add(time.Minute)
+	s.tickSchedules(*clock)
+
+	got := s.GetSchedule(sc.ID)
+	if got.Runs != 1 || got.Skipped != 1 {
+		t.Fatalf("expected 1 run and 1 skip, got %+v", got)
+	}
+	if s.QueueStats().TotalQueued != 1 {
+		t.Errorf("expected 1 queued task, got %d", s.QueueStats().TotalQueued)
+	}
+}
+
+func TestScheduleOverlapQueue(t *testing.T) {
+	clock := withClock(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	req := testScheduleRequest()
+	req.Overlap = OverlapQueue
+	sc, _ := s.CreateSchedule(req)
+
+	*clock = clock.Add(time.Minute)
+	s.tickSchedules(*clock)
+	first := s.GetSchedule(sc.ID).LastTaskID
+
+	*clock = clock.Add(time.Minute)
+	s.tickSchedules(*clock)
+	got := s.GetSchedule(sc.ID)
+	if !got.Pending || got.Runs != 1 {
+		t.Fatalf("expected a held fire, got %+v", got)
+	}
+
+	if err := s.Cancel(first); err != nil {
+		t.Fatal(err)
+	}
+	*clock = clock.Add(time.Second)
+	s.tickSchedules(*clock)
+	got = s.GetSchedule(sc.ID)
+	if got.Pending || got.Runs != 2 || got.LastTaskID == first {
+		t.Fatalf("held fire should run once the previous task ends, got %+v", got)
+	}
+}
+
+func TestSchedulePauseResume(t *testing.T) {
+	clock := withClock(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	sc, _ := s.CreateSchedule(testScheduleRequest())
+
+	paused, err := s.PauseSchedule(sc.ID)
+	if err != nil || !paused.Paused || !paused.NextRun.IsZero() {
+		t.Fatalf("pause: %+v, %v", paused, err)
+	}
+	*clock = clock.Add(5 * time.Minute)
+	s.tickSchedules(*clock)
+	if s.GetSchedule(sc.ID).Runs != 0 {
+		t.Fatal("paused schedule fired")
+	}
+
+	resumed, err := s.ResumeSchedule(sc.ID)
+	if err != nil || resumed.Paused {
+		t.Fatalf("resume: %+v, %v", resumed, err)
+	}
+	if want := clock.Add(time.Minute); !resumed.NextRun.Equal(want) {
+		t.Errorf("resume should re-arm from now: nextRun = %v, want %v", resumed.NextRun, want)
+	}
+	if _, err := s.PauseSchedule("sch_missing"); err == nil {
+		t.Error("expected not found")
+	}
+}
+
+func TestScheduleJitter(t *testing.T) {
+	clock := withClock(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	req := testScheduleRequest()
+	req.Jitter = "10s"
+	for range 20 {
+		sc, err := s.CreateSchedule(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slot := clock.Add(time.Minute)
+		if sc.NextRun.Before(slot) || !sc.NextRun.Before(slot.Add(10*time.Second)) {
+			t.Fatalf("nextRun %v outside jitter window", sc.NextRun)
+		}
+	}
+}
+
+func TestSchedulesPersist(t *testing.T) {
+	dir := t.TempDir()
+	st, err := NewTaskStore(StoreFile, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	s.SetStore(st)
+	req := testScheduleRequest()
+	req.Cron = "0 * * * *"
+	req.Interval = ""
+	sc, err := s.CreateSchedule(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = s.PauseSchedule(sc.ID)
+	_ = st.Close()
+
+	st, err = NewTaskStore(StoreFile, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = st.Close() }()
+	s2 := New(DefaultConfig(), &mockResolver{port: "1"})
+	s2.SetStore(st)
+	s2.restoreSchedules()
+	got := s2.GetSchedule(sc.ID)
+	if got == nil || got.Cron != "0 * * * *" || !got.Paused {
+		t.Fatalf("schedule not restored: %+v", got)
+	}
+}
+
+func TestHandlerSchedules(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/schedules", strings.NewReader(
+		`{"interval":"5m","task":{"agentId":"a1","action":"click","tabId":"tab1"}}`)))
+	if w.Code != 201 {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	id := s.ListSchedules()[0].ID
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/schedules", strings.NewReader(`{"task":{"agentId":"a1","action":"click"}}`)))
+	if w.Code != 400 {
+		t.Errorf("invalid create: expected 400, got %d", w.Code)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		code         int
+	}{
+		{"GET", "/schedules", 200},
+		{"GET", "/schedules/" + id, 200},
+		{"POST", "/schedules/" + id + "/pause", 200},
+		{"POST", "/schedules/" + id + "/resume", 200},
+		{"DELETE", "/schedules/" + id, 200},
+		{"GET", "/schedules/" + id, 404},
+		{"DELETE", "/schedules/" + id, 404},
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.code {
+			t.Errorf("%s %s: expected %d, got %d: %s", tc.method, tc.path, tc.code, w.Code, w.Body.String())
+		}
+	}
+}
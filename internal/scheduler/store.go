@@ -0,0 +1,291 @@
+package scheduler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Task store backends.
+const (
+	StoreMemory = "memory"
+	StoreFile   = "file"
+)
+
+// In-flight recovery policies applied on startup to tasks that were
+// assigned or running when the scheduler stopped.
+const (
+	InflightFail    = "fail"
+	InflightRequeue = "requeue"
+)
+
+// TaskStore persists task snapshots so queued tasks and results survive a
+// restart. Put is called on every state change with the latest snapshot.
+type TaskStore interface {
+	Put(t *Task) error
+	Delete(taskID string) error
+	Load() ([]*Task, error)
+	Close() error
+}
+
+// NewTaskStore opens the named backend. The file backend keeps an
+// append-only log under stateDir/scheduler.
+func NewTaskStore(backend, stateDir string) (TaskStore, error) {
+	switch backend {
+	case "", StoreMemory:
+		return memoryTaskStore{}, nil
+	case StoreFile:
+		if stateDir == "" {
+			return nil, fmt.Errorf("scheduler file store requires a state dir")
+		}
+		return newFileTaskStore(filepath.Join(stateDir, "scheduler", "tasks.jsonl"))
+	default:
+		return nil, fmt.Errorf("unknown scheduler store %q (want memory or file)", backend)
+	}
+}
+
+// memoryTaskStore keeps nothing; the ResultStore map is the only copy.
+type memoryTaskStore struct{}
+
+func (memoryTaskStore) Put(*Task) error        { return nil }
+func (memoryTaskStore) Delete(string) error    { return nil }
+func (memoryTaskStore) Load() ([]*Task, error) { return nil, nil }
+func (memoryTaskStore) Close() error           { return nil }
+
+// isDurable reports whether st keeps tasks across restarts.
+func isDurable(st TaskStore) bool {
+	if st == nil {
+		return false
+	}
+	_, mem := st.(memoryTaskStore)
+	return !mem
+}
+
+// storeRecord is one line of the task log.
+type storeRecord struct {
+	Op   string `json:"op"` // "put" or "del"
+	ID   string `json:"id"`
+	Task *Task  `json:"task,omitempty"`
+}
+
+// minCompactRecords is the log length below which compaction is skipped.
+const minCompactRecords = 256
+
+// fileTaskStore appends one JSON record per change and rewrites the log
+// with only the latest snapshot per task once it holds more than twice as
+// many records as live tasks. Writes are not fsynced: the log survives a
+// process restart, not a host crash.
+type fileTaskStore struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	records int
+	latest  map[string][]byte // task ID → encoded put record
+}
+
+func newFileTaskStore(path string) (*fileTaskStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("create scheduler dir: %w", err)
+	}
+	s := &fileTaskStore{path: path, latest: make(map[string][]byte)}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := s.compactLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// replay rebuilds the latest-snapshot index from the log. Lines that fail
+// to decode, such as a record truncated by a crash, are skipped.
+func (s *fileTaskStore) replay() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open scheduler log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	skipped := 0
+	for scanner.Scan() {
+		var rec storeRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil || rec.ID == "" {
+			skipped++
+			continue
+		}
+		switch rec.Op {
+		case "put":
+			s.latest[rec.ID] = append([]byte(nil), scanner.Bytes()...)
+		case "del":
+			delete(s.latest, rec.ID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read scheduler log: %w", err)
+	}
+	if skipped > 0 {
+		slog.Warn("scheduler store: skipped unreadable records", "path", s.path, "count", skipped)
+	}
+	return nil
+}
+
+func (s *fileTaskStore) Put(t *Task) error {
+	line, err := json.Marshal(storeRecord{Op: "put", ID: t.ID, Task: t})
+	if err != nil {
+		return fmt.Errorf("marshal task %s: %w", t.ID, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest[t.ID] = line
+	return s.appendLocked(line)
+}
+
+func (s *fileTaskStore) Delete(taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.latest[taskID]; !ok {
+		return nil
+	}
+	delete(s.latest, taskID)
+	line, err := json.Marshal(storeRecord{Op: "del", ID: taskID})
+	if err != nil {
+		return err
+	}
+	return s.appendLocked(line)
+}
+
+// Load returns the latest snapshot of every stored task, oldest first.
+func (s *fileTaskStore) Load() ([]*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Task, 0, len(s.latest))
+	for id, line := range s.latest {
+		var rec storeRecord
+		if err := json.Unmarshal(line, &rec); err != nil || rec.Task == nil {
+			slog.Warn("scheduler store: dropping unreadable task", "task", id, "err", err)
+			continue
+		}
+		out = append(out, rec.Task)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *fileTaskStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+func (s *fileTaskStore) appendLocked(line []byte) error {
+	if s.f == nil {
+		f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("open scheduler log: %w", err)
+		}
+		s.f = f
+	}
+	if _, err := s.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write scheduler log: %w", err)
+	}
+	s.records++
+	if s.records > minCompactRecords && s.records > 2*len(s.latest) {
+		return s.compactLocked()
+	}
+	return nil
+}
+
+// compactLocked rewrites the log with one record per live task and swaps
+// it in atomically.
+func (s *fileTaskStore) compactLocked() error {
+	if s.f != nil {
+		_ = s.f.Close()
+		s.f = nil
+	}
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("compact scheduler log: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	for _, line := range s.latest {
+		_, _ = w.Write(line)
+		_ = w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("compact scheduler log: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("compact scheduler log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("compact scheduler log: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("compact scheduler log: %w", err)
+	}
+	s.records = len(s.latest)
+	return nil
+}
+
+// schedulesPath is the JSON file holding recurring schedules, next to the
+// task log.
+func (s *fileTaskStore) schedulesPath() string {
+	return filepath.Join(filepath.Dir(s.path), "schedules.json")
+}
+
+// SaveSchedules replaces the stored schedule set.
+func (s *fileTaskStore) SaveSchedules(schedules []*Schedule) error {
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal schedules: %w", err)
+	}
+	path := s.schedulesPath()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write schedules: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write schedules: %w", err)
+	}
+	return nil
+}
+
+// LoadSchedules returns the stored schedules, or none if the file does not
+// exist yet.
+func (s *fileTaskStore) LoadSchedules() ([]*Schedule, error) {
+	data, err := os.ReadFile(s.schedulesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schedules: %w", err)
+	}
+	var out []*Schedule
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("decode schedules: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTaskStore(t *testing.T) {
+	if st, err := NewTaskStore("", ""); err != nil || isDurable(st) {
+		t.Errorf("default store should be memory, got %T, %v", st, err)
+	}
+	if _, err := NewTaskStore(StoreFile, ""); err == nil {
+		t.Error("file store without state dir should fail")
+	}
+	if _, err := NewTaskStore("redis", t.TempDir()); err == nil {
+		t.Error("unknown backend should fail")
+	}
+}
+
+func TestFileTaskStoreReplay(t *testing.T) {
+	dir := t.TempDir()
+	st, err := NewTaskStore(StoreFile, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	_ = st.Put(&Task{ID: "t1", AgentID: "a1", State: StateQueued, CreatedAt: now})
+	_ = st.Put(&Task{ID: "t2", AgentID: "a1", State: StateQueued, CreatedAt: now.Add(time.Second)})
+	_ = st.Put(&Task{ID: "t1", AgentID: "a1", State: StateDone, CreatedAt: now, Result: map[string]any{"ok": true}})
+	_ = st.Delete("t2")
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A record truncated by a crash must not break replay.
+	path := filepath.Join(dir, "scheduler", "tasks.jsonl")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"op":"put","id":"t3","task":{"taskId":"t3"`)
+	_ = f.Close()
+
+	st, err = NewTaskStore(StoreFile, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = st.Close() }()
+	tasks, err := st.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "t1" || tasks[0].State != StateDone {
+		t.Fatalf("unexpected tasks after replay: %+v", tasks)
+	}
+
+	data, _ := os.ReadFile(path)
+	if n := strings.Count(string(data), "\n"); n != 1 {
+		t.Errorf("expected compacted log with 1 record, got %d", n)
+	}
+}
+
+func TestFileTaskStoreCompactsOnGrowth(t *testing.T) {
+	st, err := newFileTaskStore(filepath.Join(t.TempDir(), "tasks.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = st.Close() }()
+
+	task := &Task{ID: "t1", AgentID: "a1", State: StateQueued}
+	for range minCompactRecords + 10 {
+		if err := st.Put(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if st.records > minCompactRecords {
+		t.Errorf("expected compaction, log has %d records", st.records)
+	}
+}
+
+func seedRestoreStore(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	st, err := NewTaskStore(StoreFile, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	_ = st.Put(&Task{ID: "queued", AgentID: "a1", Action: "click", State: StateQueued, CreatedAt: now, Deadline: now.Add(time.Hour)})
+	_ = st.Put(&Task{ID: "running", AgentID: "a1", Action: "click", State: StateRunning, CreatedAt: now, StartedAt: now, Deadline: now.Add(time.Hour)})
+	_ = st.Put(&Task{ID: "done", AgentID: "a1", State: StateDone, CreatedAt: now, CompletedAt: now})
+	_ = st.Put(&Task{ID: "stale", AgentID: "a1", State: StateDone, CreatedAt: now.Add(-time.Hour), CompletedAt: now.Add(-time.Hour)})
+	_ = st.Close()
+	return dir
+}
+
+func TestSchedulerRestore(t *testing.T) {
+	for _, policy := range []string{InflightFail, InflightRequeue} {
+		t.Run(policy, func(t *testing.T) {
+			st, err := NewTaskStore(StoreFile, seedRestoreStore(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := DefaultConfig()
+			cfg.InflightPolicy = policy
+			s := New(cfg, &mockResolver{port: "1"})
+			s.SetStore(st)
+			s.restore()
+			defer func() { _ = st.Close() }()
+
+			if got := s.GetTask("queued"); got == nil || got.GetState() != StateQueued {
+				t.Errorf("queued task not requeued: %+v", got)
+			}
+			if got := s.GetTask("done"); got == nil || got.State != StateDone {
+				t.Errorf("result not restored: %+v", got)
+			}
+			if s.GetTask("stale") != nil {
+				t.Error("expired result should be dropped")
+			}
+
+			running := s.GetTask("running")
+			switch policy {
+			case InflightFail:
+				if running == nil || running.State != StateFailed || !strings.Contains(running.Error, "restart") {
+					t.Errorf("in-flight task should be failed: %+v", running)
+				}
+				if s.QueueStats().TotalQueued != 1 {
+					t.Errorf("expected 1 queued, got %d", s.QueueStats().TotalQueued)
+				}
+			case InflightRequeue:
+				if running == nil || running.GetState() != StateQueued {
+					t.Errorf("in-flight task should be requeued: %+v", running)
+				}
+				if s.QueueStats().TotalQueued != 2 {
+					t.Errorf("expected 2 queued, got %d", s.QueueStats().TotalQueued)
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulerStopKeepsQueuedTasksInDurableStore(t *testing.T) {
+	dir := t.TempDir()
+	st, err := NewTaskStore(StoreFile, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(DefaultConfig(), &mockResolver{port: "1"})
+	s.SetStore(st)
+	// Not started: the task stays queued until Stop.
+	task, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Stop()
+
+	st, err = NewTaskStore(StoreFile, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = st.Close() }()
+	tasks, _ := st.Load()
+	if len(tasks) != 1 || tasks[0].ID != task.ID || tasks[0].State != StateQueued {
+		t.Fatalf("expected queued task to survive stop, got %+v", tasks)
+	}
+}
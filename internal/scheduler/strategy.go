@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Queue strategies selectable through Config.Strategy.
+const (
+	// StrategyFairFIFO serves the agent with the fewest in-flight tasks,
+	// then that agent's tasks by priority and age.
+	StrategyFairFIFO = "fair-fifo"
+	// StrategyWeightedFair shares dispatches between agents in proportion
+	// to their weights.
+	StrategyWeightedFair = "weighted-fair"
+	// StrategyPriority serves the highest priority class across all agents
+	// first, with starvation protection for lower classes.
+	StrategyPriority = "priority"
+)
+
+// defaultStarvationAfter is how long a task may wait under the priority
+// strategy before it is served ahead of higher classes.
+const defaultStarvationAfter = 30 * time.Second
+
+// ValidStrategies lists the accepted Config.Strategy values.
+func ValidStrategies() []string {
+	return []string{StrategyFairFIFO, StrategyWeightedFair, StrategyPriority}
+}
+
+// QueuePolicy controls how TaskQueue.Dequeue picks the next task.
+type QueuePolicy struct {
+	Strategy string
+	// Weights are the agents' shares under weighted-fair. Unlisted agents
+	// weigh 1.
+	Weights map[string]int
+	// StarvationAfter is the queue wait after which a task is served ahead
+	// of every non-starved task under the priority strategy.
+	StarvationAfter time.Duration
+	// SerializeTabs holds back tasks for a tab that already has a task in
+	// flight, so workers pick up other work instead of blocking on it.
+	SerializeTabs bool
+}
+
+// policyFromConfig builds the queue policy for cfg.
+func policyFromConfig(cfg Config) (QueuePolicy, error) {
+	p := QueuePolicy{
+		Strategy:        cfg.Strategy,
+		Weights:         maps.Clone(cfg.AgentWeights),
+		StarvationAfter: cfg.StarvationAfter,
+		SerializeTabs:   cfg.SerializeTabs,
+	}
+	if p.Strategy == "" {
+		p.Strategy = StrategyFairFIFO
+	}
+	switch p.Strategy {
+	case StrategyFairFIFO, StrategyWeightedFair, StrategyPriority:
+	default:
+		return p, fmt.Errorf("unknown scheduler strategy %q (want fair-fifo, weighted-fair or priority)", p.Strategy)
+	}
+	for agentID, w := range p.Weights {
+		if w <= 0 {
+			return p, fmt.Errorf("agent weight for %q must be positive (got %d)", agentID, w)
+		}
+	}
+	if p.StarvationAfter <= 0 {
+		p.StarvationAfter = defaultStarvationAfter
+	}
+	return p, nil
+}
+
+func (p *QueuePolicy) weight(agentID string) float64 {
+	if w, ok := p.Weights[agentID]; ok && w > 0 {
+		return float64(w)
+	}
+	return 1
+}
+
+// agentBefore reports whether agent a must be served before agent b. When
+// neither is preferred, their next tasks decide.
+func (p *QueuePolicy) agentBefore(a, b *agentQueue) bool {
+	switch p.Strategy {
+	case StrategyPriority:
+		return false
+	case StrategyWeightedFair:
+		if a.pass != b.pass {
+			return a.pass < b.pass
+		}
+	}
+	return a.inflight < b.inflight
+}
+
+// taskBefore reports whether task a must be served before task b.
+func (p *QueuePolicy) taskBefore(a, b *Task, now time.Time) bool {
+	if p.Strategy == StrategyPriority {
+		as, bs := p.starved(a, now), p.starved(b, now)
+		if as != bs {
+			return as
+		}
+		if as {
+			return a.queuedAt.Before(b.queuedAt)
+		}
+	}
+	if a.Priority != b.Priority {
+		return a.Priority < b.Priority
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+func (p *QueuePolicy) starved(t *Task, now time.Time) bool {
+	return !t.queuedAt.IsZero() && now.Sub(t.queuedAt) >= p.StarvationAfter
+}
+
+// scans reports whether an agent's heap must be searched rather than
+// served from the top.
+func (p *QueuePolicy) scans() bool {
+	return p.SerializeTabs || p.Strategy == StrategyPriority
+}
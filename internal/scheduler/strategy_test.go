@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newPolicyQueue(t *testing.T, p QueuePolicy) *TaskQueue {
+	t.Helper()
+	if p.StarvationAfter == 0 {
+		p.StarvationAfter = defaultStarvationAfter
+	}
+	q := NewTaskQueue(1000, 1000)
+	q.SetPolicy(p)
+	return q
+}
+
+func mustEnqueue(t *testing.T, q *TaskQueue, task *Task) {
+	t.Helper()
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = timeNow()
+	}
+	if _, err := q.Enqueue(task); err != nil {
+		t.Fatalf("enqueue %s: %v", task.ID, err)
+	}
+}
+
+func TestPolicyFromConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Strategy = "round-robin"
+	if _, err := policyFromConfig(cfg); err == nil {
+		t.Error("unknown strategy should be rejected")
+	}
+	cfg.Strategy = StrategyWeightedFair
+	cfg.AgentWeights = map[string]int{"a1": 0}
+	if _, err := policyFromConfig(cfg); err == nil {
+		t.Error("non-positive weight should be rejected")
+	}
+
+	s := New(Config{Strategy: "bogus"}, &mockResolver{port: "1"})
+	if got := s.queue.Policy().Strategy; got != StrategyFairFIFO {
+		t.Errorf("invalid strategy should fall back to fair-fifo, got %q", got)
+	}
+}
+
+func TestWeightedFairShares(t *testing.T) {
+	q := newPolicyQueue(t, QueuePolicy{Strategy: StrategyWeightedFair, Weights: map[string]int{"heavy": 3}})
+	for i := range 20 {
+		mustEnqueue(t, q, &Task{ID: fmt.Sprintf("h%d", i), AgentID: "heavy"})
+		mustEnqueue(t, q, &Task{ID: fmt.Sprintf("l%d", i), AgentID: "light"})
+	}
+
+	served := map[string]int{}
+	for range 16 {
+		task := q.Dequeue(100, 100)
+		if task == nil {
+			t.Fatal("expected a task")
+		}
+		served[task.AgentID]++
+		q.Complete(task.AgentID, task.TabID)
+	}
+	if served["heavy"] != 12 || served["light"] != 4 {
+		t.Errorf("expected a 3:1 split, got %v", served)
+	}
+}
+
+func TestWeightedFairIdleAgentBanksNoCredit(t *testing.T) {
+	q := newPolicyQueue(t, QueuePolicy{Strategy: StrategyWeightedFair})
+	for i := range 10 {
+		mustEnqueue(t, q, &Task{ID: fmt.Sprintf("a%d", i), AgentID: "busy"})
+	}
+	for range 8 {
+		task := q.Dequeue(100, 100)
+		q.Complete(task.AgentID, task.TabID)
+	}
+
+	mustEnqueue(t, q, &Task{ID: "n1", AgentID: "late"})
+	mustEnqueue(t, q, &Task{ID: "n2", AgentID: "late"})
+	served := map[string]int{}
+	for range 4 {
+		task := q.Dequeue(100, 100)
+		served[task.AgentID]++
+		q.Complete(task.AgentID, task.TabID)
+	}
+	if served["late"] != 2 || served["busy"] != 2 {
+		t.Errorf("late agent should alternate with the busy one, got %v", served)
+	}
+}
+
+func TestPriorityStrategyAcrossAgents(t *testing.T) {
+	q := newPolicyQueue(t, QueuePolicy{Strategy: StrategyPriority})
+	mustEnqueue(t, q, &Task{ID: "low", AgentID: "a1", Priority: 5})
+	mustEnqueue(t, q, &Task{ID: "high", AgentID: "a2", Priority: 1})
+	mustEnqueue(t, q, &Task{ID: "mid", AgentID: "a2", Priority: 3})
+
+	for _, want := range []string{"high", "mid", "low"} {
+		if got := q.Dequeue(100, 100); got == nil || got.ID != want {
+			t.Fatalf("expected %s, got %+v", want, got)
+		}
+	}
+}
+
+func TestPriorityStrategyStarvation(t *testing.T) {
+	now := withClock(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	q := newPolicyQueue(t, QueuePolicy{Strategy: StrategyPriority, StarvationAfter: 10 * time.Second})
+	mustEnqueue(t, q, &Task{ID: "low", AgentID: "a1", Priority: 9})
+
+	*now = now.Add(5 * time.Second)
+	mustEnqueue(t, q, &Task{ID: "high", AgentID: "a2", Priority: 0})
+	if got := q.Dequeue(100, 100); got.ID != "high" {
+		t.Fatalf("expected high before starvation, got %s", got.ID)
+	}
+
+	mustEnqueue(t, q, &Task{ID: "high2", AgentID: "a2", Priority: 0})
+	*now = now.Add(6 * time.Second)
+	if got := q.Dequeue(100, 100); got.ID != "low" {
+		t.Fatalf("starved task should go first, got %s", got.ID)
+	}
+}
+
+func TestSerializeTabs(t *testing.T) {
+	q := newPolicyQueue(t, QueuePolicy{Strategy: StrategyFairFIFO, SerializeTabs: true})
+	mustEnqueue(t, q, &Task{ID: "t1-a", AgentID: "a1", TabID: "tab1", Priority: 0})
+	mustEnqueue(t, q, &Task{ID: "t1-b", AgentID: "a1", TabID: "tab1", Priority: 0})
+	mustEnqueue(t, q, &Task{ID: "t2-a", AgentID: "a1", TabID: "tab2", Priority: 5})
+
+	first := q.Dequeue(100, 100)
+	if first.ID != "t1-a" {
+		t.Fatalf("expected t1-a first, got %s", first.ID)
+	}
+	if got := q.Dequeue(100, 100); got == nil || got.ID != "t2-a" {
+		t.Fatalf("busy tab should be skipped, got %+v", got)
+	}
+	if got := q.Dequeue(100, 100); got != nil {
+		t.Fatalf("tab1 is still busy, got %s", got.ID)
+	}
+	q.Complete(first.AgentID, first.TabID)
+	if got := q.Dequeue(100, 100); got == nil || got.ID != "t1-b" {
+		t.Fatalf("expected t1-b once tab1 is free, got %+v", got)
+	}
+}
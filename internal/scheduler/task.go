@@ -4,18 +4,23 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/pinchtab/pinchtab/internal/fleet"
 )
 
 // TaskState represents the current state of a task.
 type TaskState string
 
 const (
+	StateWaiting   TaskState = "waiting"
 	StateQueued    TaskState = "queued"
 	StateAssigned  TaskState = "assigned"
 	StateRunning   TaskState = "running"
+	StateRetrying  TaskState = "retrying"
 	StateDone      TaskState = "done"
 	StateFailed    TaskState = "failed"
 	StateCancelled TaskState = "cancelled"
@@ -57,45 +62,99 @@ type Task struct {
 	// CallbackURL receives a POST with the task snapshot on completion.
 	CallbackURL string `json:"callbackUrl,omitempty"`
 
+	// DependsOn lists task IDs that must finish before this task is
+	// queued. Such a task waits in StateWaiting and belongs to DagID.
+	DependsOn           []string `json:"dependsOn,omitempty"`
+	OnDependencyFailure string   `json:"onDependencyFailure,omitempty"`
+	DagID               string   `json:"dagId,omitempty"`
+	// Key is the batch-local name other tasks in the batch use to refer
+	// to this one in dependsOn and result templates.
+	Key string `json:"key,omitempty"`
+
+	// Retry re-runs the task after retryable failures. Attempts records
+	// every execution, and NextAttemptAt is set while in StateRetrying.
+	Retry         *RetryPolicy `json:"retry,omitempty"`
+	Attempts      []Attempt    `json:"attempts,omitempty"`
+	NextAttemptAt time.Time    `json:"nextAttemptAt,omitempty"`
+
+	// IdempotencyKey deduplicates submissions per agent.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+
+	// RejectReason is the machine-readable cause of StateRejected, e.g.
+	// queue_full or rate_limited.
+	RejectReason string `json:"rejectReason,omitempty"`
+
+	// Profile, Labels and CloseTab apply to tasks without a TabID, which
+	// the scheduler places on a tab of an instance running Profile and
+	// carrying Labels. Placement records the tab of the latest attempt.
+	Profile   string            `json:"profile,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	CloseTab  bool              `json:"closeTab,omitempty"`
+	Placement *TabPlacement     `json:"placement,omitempty"`
+
 	// position is the queue position at submission time.
 	Position int `json:"position,omitempty"`
+
+	// dispatched is set once a worker has taken the task off the queue, so
+	// only those tasks release an in-flight slot when they finish.
+	dispatched bool
+	// queuedAt is when the task last entered the queue. It is owned by
+	// TaskQueue and only read under its lock.
+	queuedAt time.Time
+	// observer, if set, is called after every state transition. It is set
+	// before the task is shared and never changed.
+	observer func(t *Task, from TaskState)
 }
 
-// SetState transitions the task to the given state. Returns an error if
-// the transition is invalid (e.g. terminal → anything).
+// SetState transitions the task to the given state and notifies the
+// task's observer. Returns an error if the transition is invalid (e.g.
+// terminal → anything).
 func (t *Task) SetState(next TaskState) error {
+	prev, err := t.setState(next)
+	if err == nil && t.observer != nil {
+		t.observer(t, prev)
+	}
+	return err
+}
+
+func (t *Task) setState(next TaskState) (TaskState, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	if t.State.IsTerminal() {
-		return fmt.Errorf("cannot transition from terminal state %q to %q", t.State, next)
+		return t.State, fmt.Errorf("cannot transition from terminal state %q to %q", t.State, next)
 	}
 
 	switch {
+	case t.State == StateWaiting && (next == StateQueued || next == StateCancelled || next == StateFailed || next == StateRejected):
 	case t.State == StateQueued && (next == StateAssigned || next == StateCancelled || next == StateFailed || next == StateRejected):
 	case t.State == StateAssigned && (next == StateRunning || next == StateCancelled):
-	case t.State == StateRunning && (next == StateDone || next == StateFailed || next == StateCancelled):
+	case t.State == StateRunning && (next == StateDone || next == StateFailed || next == StateCancelled || next == StateRetrying):
+	case t.State == StateRetrying && (next == StateQueued || next == StateCancelled || next == StateFailed):
 	default:
-		return fmt.Errorf("invalid state transition: %q → %q", t.State, next)
+		return t.State, fmt.Errorf("invalid state transition: %q → %q", t.State, next)
 	}
 
-	now := time.Now()
+	now := timeNow()
+	prev := t.State
 	t.State = next
 
 	switch next {
 	case StateAssigned:
-		t.StartedAt = now
+		if t.StartedAt.IsZero() {
+			t.StartedAt = now
+		}
 	case StateRunning:
 		if t.StartedAt.IsZero() {
 			t.StartedAt = now
 		}
-	case StateDone, StateFailed, StateCancelled:
+	case StateDone, StateFailed, StateCancelled, StateRejected:
 		t.CompletedAt = now
 		if !t.StartedAt.IsZero() {
 			t.LatencyMs = now.Sub(t.StartedAt).Milliseconds()
 		}
 	}
-	return nil
+	return prev, nil
 }
 
 // GetState returns the current task state.
@@ -127,8 +186,36 @@ func (t *Task) Snapshot() *Task {
 		Result:      t.Result,
 		Error:       t.Error,
 		CallbackURL: t.CallbackURL,
+		DependsOn:   t.DependsOn,
+		DagID:       t.DagID,
+		Key:         t.Key,
 		Position:    t.Position,
+		Retry:       t.Retry,
+		Attempts:    slices.Clone(t.Attempts),
+
+		OnDependencyFailure: t.OnDependencyFailure,
+		NextAttemptAt:       t.NextAttemptAt,
+		IdempotencyKey:      t.IdempotencyKey,
+		RejectReason:        t.RejectReason,
+		Profile:             t.Profile,
+		Labels:              t.Labels,
+		CloseTab:            t.CloseTab,
+		Placement:           t.Placement,
+	}
+}
+
+// targetTab returns the tab the task runs on: its TabID, or the tab the
+// scheduler placed it on.
+func (t *Task) targetTab() string {
+	if t.TabID != "" {
+		return t.TabID
 	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.Placement != nil {
+		return t.Placement.TabID
+	}
+	return ""
 }
 
 // SubmitRequest is the JSON body for POST /tasks.
@@ -142,6 +229,25 @@ type SubmitRequest struct {
 	Priority    int            `json:"priority,omitempty"`
 	Deadline    string         `json:"deadline,omitempty"`
 	CallbackURL string         `json:"callbackUrl,omitempty"`
+
+	// DependsOn holds task IDs (or, inside a batch, task keys) that must
+	// finish first. Params may reference their results with
+	// {{<id or key>.result.<path>}}.
+	DependsOn           []string `json:"dependsOn,omitempty"`
+	OnDependencyFailure string   `json:"onDependencyFailure,omitempty"`
+
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// IdempotencyKey makes resubmission safe: a second request from the
+	// same agent with the same key returns the original task.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+
+	// Without a TabID the scheduler picks an instance running Profile
+	// ("" for any) and carrying every label in Labels, and runs the task
+	// on a fresh or pooled tab, which is closed afterwards if CloseTab is
+	// set.
+	Profile  string            `json:"profile,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	CloseTab bool              `json:"closeTab,omitempty"`
 }
 
 // Validate checks that the request has the minimum required fields.
@@ -152,11 +258,47 @@ func (r *SubmitRequest) Validate() error {
 	if r.Action == "" {
 		return fmt.Errorf("missing required field 'action'")
 	}
+	if r.TabID != "" && (r.Profile != "" || len(r.Labels) > 0 || r.CloseTab) {
+		return fmt.Errorf("'profile', 'labels' and 'closeTab' only apply to tasks without 'tabId'")
+	}
+	if err := fleet.ValidateLabels(r.Labels); err != nil {
+		return err
+	}
+	if r.Action == ActionMacro {
+		if err := validateMacroParams(r.TabID, r.Params); err != nil {
+			return err
+		}
+	}
 	if strings.TrimSpace(r.CallbackURL) != "" {
 		if err := validateCallbackURL(r.CallbackURL); err != nil {
 			return fmt.Errorf("invalid callbackUrl: %w", err)
 		}
 	}
+	if r.Retry != nil {
+		if err := r.Retry.Validate(); err != nil {
+			return err
+		}
+	}
+	if len(r.IdempotencyKey) > maxIdempotencyKeyLen {
+		return fmt.Errorf("idempotencyKey must be at most %d characters", maxIdempotencyKeyLen)
+	}
+	switch r.OnDependencyFailure {
+	case "", DepFailureCancel, DepFailureContinue:
+	default:
+		return fmt.Errorf("invalid onDependencyFailure %q (want cancel or continue)", r.OnDependencyFailure)
+	}
+	seen := make(map[string]bool, len(r.DependsOn))
+	for _, dep := range r.DependsOn {
+		if dep == "" || seen[dep] {
+			return fmt.Errorf("dependsOn entries must be unique and non-empty")
+		}
+		seen[dep] = true
+	}
+	for _, ref := range templateRefs(r.Params) {
+		if !seen[ref] {
+			return fmt.Errorf("params reference %q, which is not in dependsOn", ref)
+		}
+	}
 	return nil
 }
 
@@ -3,15 +3,15 @@ package scheduler
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io"
-	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
-
-	internalurls "github.com/pinchtab/pinchtab/internal/urls"
 )
 
 var errNoValidatedWebhookIPs = errors.New("no validated callback IPs")
@@ -48,35 +48,32 @@ func newPinnedWebhookClient(target *validatedCallbackTarget) *http.Client {
 	}
 }
 
-// sendWebhook delivers a task snapshot to the configured callbackUrl.
-// Delivery is best-effort: failures are logged but do not affect task state.
-func sendWebhook(callbackURL string, t *Task) {
-	if callbackURL == "" {
-		return
-	}
-	logURL := internalurls.RedactForLog(callbackURL)
-
-	target, err := validateCallbackTarget(callbackURL)
-	if err != nil {
-		slog.Warn("webhook: callback rejected", "task", t.ID, "url", logURL, "err", err)
-		return
-	}
-
-	snap := t.Snapshot()
-	payload, err := json.Marshal(snap)
-	if err != nil {
-		slog.Warn("webhook: failed to marshal task", "task", t.ID, "err", err)
-		return
-	}
+// webhookRequest is one POST of a delivery.
+type webhookRequest struct {
+	DeliveryID string
+	TaskID     string
+	Attempt    int
+	Payload    []byte
+	Secret     string
+}
 
-	req, err := http.NewRequest(http.MethodPost, target.URL.String(), bytes.NewReader(payload))
+// postWebhook makes one delivery attempt to a validated callback target. It
+// returns the response status, or an error if no response was received.
+func postWebhook(target *validatedCallbackTarget, wr webhookRequest) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, target.URL.String(), bytes.NewReader(wr.Payload))
 	if err != nil {
-		slog.Warn("webhook: failed to create request", "task", t.ID, "err", err)
-		return
+		return 0, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-PinchTab-Event", "task.completed")
-	req.Header.Set("X-PinchTab-Task-ID", snap.ID)
+	req.Header.Set("X-PinchTab-Task-ID", wr.TaskID)
+	req.Header.Set("X-PinchTab-Delivery", wr.DeliveryID)
+	req.Header.Set("X-PinchTab-Attempt", strconv.Itoa(wr.Attempt))
+	if wr.Secret != "" {
+		ts := timeNow().Unix()
+		req.Header.Set("X-PinchTab-Timestamp", strconv.FormatInt(ts, 10))
+		req.Header.Set("X-PinchTab-Signature", WebhookSignature(wr.Secret, ts, wr.Payload))
+	}
 
 	client := newPinnedWebhookClient(target)
 	if transport, ok := client.Transport.(*http.Transport); ok {
@@ -85,17 +82,22 @@ func sendWebhook(callbackURL string, t *Task) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		slog.Warn("webhook: delivery failed", "task", t.ID, "url", logURL, "err", err)
-		return
+		return 0, err
 	}
 	// Drain body so the underlying connection can be reused.
 	_, _ = io.Copy(io.Discard, resp.Body)
 	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		slog.Warn("webhook: non-success response", "task", t.ID, "url", logURL, "status", resp.StatusCode)
-		return
-	}
-
-	slog.Info("webhook: delivered", "task", t.ID, "url", logURL, "status", resp.StatusCode)
+// WebhookSignature returns the X-PinchTab-Signature value for a payload:
+// "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the
+// secret. Receivers recompute it with the X-PinchTab-Timestamp header and
+// compare in constant time.
+func WebhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
 }
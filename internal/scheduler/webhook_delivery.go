@@ -0,0 +1,318 @@
+package scheduler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	internalurls "github.com/pinchtab/pinchtab/internal/urls"
+)
+
+// Webhook delivery states.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+const (
+	// defaultWebhookAttempts is how many times a callback is tried before
+	// it is dead-lettered.
+	defaultWebhookAttempts = 5
+	// maxWebhookAttempts bounds WebhookPolicy.MaxAttempts.
+	maxWebhookAttempts = 10
+	// defaultWebhookBackoff is the wait before the first retry.
+	defaultWebhookBackoff = time.Second
+	// maxWebhookBackoff caps the exponential backoff.
+	maxWebhookBackoff = time.Minute
+	// webhookHistory is how many deliveries GET /webhooks/deliveries keeps.
+	webhookHistory = 500
+	// webhookDeadLetters is how many dead deliveries are kept.
+	webhookDeadLetters = 200
+	// maxInflightWebhooks bounds concurrent callback POSTs. Deliveries
+	// waiting for a slot or for their next retry do not count.
+	maxInflightWebhooks = 16
+)
+
+// WebhookPolicy controls callback signing and retries. A non-2xx response
+// or a transport error is retried after Backoff * 2^(n-1), capped at one
+// minute, until MaxAttempts attempts have been made.
+type WebhookPolicy struct {
+	Secret      string
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+func (p WebhookPolicy) normalize() WebhookPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultWebhookAttempts
+	}
+	p.MaxAttempts = min(p.MaxAttempts, maxWebhookAttempts)
+	if p.Backoff <= 0 {
+		p.Backoff = defaultWebhookBackoff
+	}
+	return p
+}
+
+// delay returns the wait after the given failed attempt (1-based).
+func (p WebhookPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d < maxWebhookBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxWebhookBackoff)
+}
+
+// WebhookAttempt is one POST of a delivery.
+type WebhookAttempt struct {
+	Attempt    int       `json:"attempt"`
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	LatencyMs  int64     `json:"latencyMs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// WebhookDelivery records the delivery of one task's callback. URL is
+// redacted for display.
+type WebhookDelivery struct {
+	ID            string           `json:"id"`
+	TaskID        string           `json:"taskId"`
+	AgentID       string           `json:"agentId"`
+	URL           string           `json:"url"`
+	State         string           `json:"state"`
+	Signed        bool             `json:"signed"`
+	Attempts      []WebhookAttempt `json:"attempts"`
+	Error         string           `json:"error,omitempty"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	CompletedAt   *time.Time       `json:"completedAt,omitempty"`
+	NextAttemptAt *time.Time       `json:"nextAttemptAt,omitempty"`
+}
+
+// DeliveryFilter selects deliveries. Empty fields match everything.
+type DeliveryFilter struct {
+	TaskID string
+	State  string
+}
+
+func (f DeliveryFilter) match(d *WebhookDelivery) bool {
+	return (f.TaskID == "" || d.TaskID == f.TaskID) && (f.State == "" || d.State == f.State)
+}
+
+// webhooks delivers task callbacks and keeps a bounded log of deliveries
+// and a dead-letter list of those that exhausted their attempts.
+type webhooks struct {
+	mu      sync.Mutex
+	policy  WebhookPolicy
+	history []*WebhookDelivery
+	dead    []*WebhookDelivery
+	sem     chan struct{}
+	stop    <-chan struct{}
+}
+
+func newWebhooks(policy WebhookPolicy, stop <-chan struct{}) *webhooks {
+	return &webhooks{
+		policy: policy.normalize(),
+		sem:    make(chan struct{}, maxInflightWebhooks),
+		stop:   stop,
+	}
+}
+
+func (w *webhooks) setPolicy(p WebhookPolicy) {
+	w.mu.Lock()
+	w.policy = p.normalize()
+	w.mu.Unlock()
+}
+
+func (w *webhooks) currentPolicy() WebhookPolicy {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.policy
+}
+
+func generateDeliveryID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("whd_%08x", time.Now().UnixNano()&0xFFFFFFFF)
+	}
+	return "whd_" + hex.EncodeToString(b)
+}
+
+// dispatch starts delivering the callback of a terminal task. Deliveries
+// whose target or payload is invalid are dead-lettered straight away.
+func (w *webhooks) dispatch(t *Task) {
+	snap := t.Snapshot()
+	policy := w.currentPolicy()
+	d := &WebhookDelivery{
+		ID:        generateDeliveryID(),
+		TaskID:    snap.ID,
+		AgentID:   snap.AgentID,
+		URL:       internalurls.RedactForLog(snap.CallbackURL),
+		State:     DeliveryPending,
+		Signed:    policy.Secret != "",
+		CreatedAt: timeNow(),
+	}
+	w.mu.Lock()
+	if len(w.history) >= webhookHistory {
+		w.history = slices.Delete(w.history, 0, len(w.history)-webhookHistory+1)
+	}
+	w.history = append(w.history, d)
+	w.mu.Unlock()
+
+	target, err := validateCallbackTarget(snap.CallbackURL)
+	if err != nil {
+		slog.Warn("webhook: callback rejected", "task", snap.ID, "url", d.URL, "err", err)
+		w.finish(d, DeliveryDead, err.Error())
+		return
+	}
+	payload, err := json.Marshal(snap)
+	if err != nil {
+		slog.Warn("webhook: failed to marshal task", "task", snap.ID, "err", err)
+		w.finish(d, DeliveryDead, err.Error())
+		return
+	}
+
+	go w.run(d, target, payload)
+}
+
+// acquire waits for one of the maxInflightWebhooks POST slots. It reports
+// false when the scheduler stops first.
+func (w *webhooks) acquire() bool {
+	select {
+	case w.sem <- struct{}{}:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
+
+func (w *webhooks) release() { <-w.sem }
+
+// run makes the delivery's attempts until one succeeds, they run out, or
+// the scheduler stops. Each attempt holds a POST slot only while the
+// request is in flight, so a burst of callbacks queues rather than
+// fails. The policy is read before each attempt so a reload applies to
+// retries of deliveries already in progress.
+func (w *webhooks) run(d *WebhookDelivery, target *validatedCallbackTarget, payload []byte) {
+	for attempt := 1; ; attempt++ {
+		if !w.acquire() {
+			w.finish(d, DeliveryDead, "scheduler stopped before delivery")
+			return
+		}
+		policy := w.currentPolicy()
+		start := timeNow()
+		status, err := postWebhook(target, webhookRequest{
+			DeliveryID: d.ID,
+			TaskID:     d.TaskID,
+			Attempt:    attempt,
+			Payload:    payload,
+			Secret:     policy.Secret,
+		})
+		w.release()
+		a := WebhookAttempt{Attempt: attempt, At: start, StatusCode: status, LatencyMs: timeNow().Sub(start).Milliseconds()}
+		switch {
+		case err != nil:
+			a.Error = err.Error()
+		case status < 200 || status >= 300:
+			a.Error = fmt.Sprintf("non-success status %d", status)
+		}
+
+		w.mu.Lock()
+		d.Attempts = append(d.Attempts, a)
+		d.Signed = policy.Secret != ""
+		w.mu.Unlock()
+
+		if a.Error == "" {
+			slog.Info("webhook: delivered", "task", d.TaskID, "url", d.URL, "status", status, "attempt", attempt)
+			w.finish(d, DeliveryDelivered, "")
+			return
+		}
+		if attempt >= policy.MaxAttempts {
+			slog.Warn("webhook: delivery failed, dead-lettering", "task", d.TaskID, "url", d.URL, "attempts", attempt, "err", a.Error)
+			w.finish(d, DeliveryDead, a.Error)
+			return
+		}
+
+		wait := policy.delay(attempt)
+		next := timeNow().Add(wait)
+		w.mu.Lock()
+		d.NextAttemptAt = &next
+		w.mu.Unlock()
+		slog.Warn("webhook: delivery failed, retrying", "task", d.TaskID, "url", d.URL, "attempt", attempt, "retryIn", wait, "err", a.Error)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-w.stop:
+			timer.Stop()
+			w.finish(d, DeliveryDead, "scheduler stopped before delivery")
+			return
+		}
+		w.mu.Lock()
+		d.NextAttemptAt = nil
+		w.mu.Unlock()
+	}
+}
+
+func (w *webhooks) finish(d *WebhookDelivery, state, errMsg string) {
+	now := timeNow()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	d.State = state
+	d.Error = errMsg
+	d.CompletedAt = &now
+	d.NextAttemptAt = nil
+	if state == DeliveryDead {
+		if len(w.dead) >= webhookDeadLetters {
+			w.dead = slices.Delete(w.dead, 0, len(w.dead)-webhookDeadLetters+1)
+		}
+		w.dead = append(w.dead, d)
+	}
+}
+
+func copyDelivery(d *WebhookDelivery) WebhookDelivery {
+	c := *d
+	c.Attempts = slices.Clone(d.Attempts)
+	if c.Attempts == nil {
+		c.Attempts = []WebhookAttempt{}
+	}
+	return c
+}
+
+// list returns matching deliveries, newest first.
+func (w *webhooks) list(from []*WebhookDelivery, f DeliveryFilter) []WebhookDelivery {
+	out := []WebhookDelivery{}
+	for i := len(from) - 1; i >= 0; i-- {
+		if f.match(from[i]) {
+			out = append(out, copyDelivery(from[i]))
+		}
+	}
+	return out
+}
+
+// Deliveries returns the retained webhook deliveries, newest first.
+func (s *Scheduler) Deliveries(f DeliveryFilter) []WebhookDelivery {
+	s.webhooks.mu.Lock()
+	defer s.webhooks.mu.Unlock()
+	return s.webhooks.list(s.webhooks.history, f)
+}
+
+// DeadLetters returns the deliveries that were given up on, newest first.
+// They are kept even after they age out of the delivery log.
+func (s *Scheduler) DeadLetters(f DeliveryFilter) []WebhookDelivery {
+	s.webhooks.mu.Lock()
+	defer s.webhooks.mu.Unlock()
+	return s.webhooks.list(s.webhooks.dead, f)
+}
+
+func (c Config) webhookPolicy() WebhookPolicy {
+	return WebhookPolicy{
+		Secret:      c.WebhookSecret,
+		MaxAttempts: c.WebhookMaxAttempts,
+		Backoff:     c.WebhookBackoff,
+	}.normalize()
+}
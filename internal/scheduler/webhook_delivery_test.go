@@ -0,0 +1,219 @@
+package scheduler
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	var gotSig, gotTS, gotDelivery string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-PinchTab-Signature")
+		gotTS = r.Header.Get("X-PinchTab-Timestamp")
+		gotDelivery = r.Header.Get("X-PinchTab-Delivery")
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	d := deliver(t, WebhookPolicy{Secret: "s3cret"}, callbackURL, &Task{ID: "tsk_signed", State: StateDone})
+	if d.State != DeliveryDelivered || !d.Signed {
+		t.Fatalf("expected a signed delivery, got %+v", d)
+	}
+	ts, err := strconv.ParseInt(gotTS, 10, 64)
+	if err != nil {
+		t.Fatalf("bad timestamp header %q", gotTS)
+	}
+	if want := WebhookSignature("s3cret", ts, gotBody); !hmac.Equal([]byte(gotSig), []byte(want)) {
+		t.Errorf("signature %q does not verify (want %q)", gotSig, want)
+	}
+	if WebhookSignature("other", ts, gotBody) == gotSig {
+		t.Error("signature should depend on the secret")
+	}
+	if gotDelivery != d.ID {
+		t.Errorf("expected delivery header %s, got %s", d.ID, gotDelivery)
+	}
+}
+
+func TestWebhookUnsignedWithoutSecret(t *testing.T) {
+	var sig atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sig.Store(r.Header.Get("X-PinchTab-Signature"))
+	}))
+	defer srv.Close()
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	if d := deliver(t, WebhookPolicy{}, callbackURL, &Task{ID: "tsk_unsigned"}); d.Signed || sig.Load() != "" {
+		t.Errorf("no signature expected without a secret, got %q", sig.Load())
+	}
+}
+
+func TestWebhookRetriesUntilSuccess(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(503)
+		}
+	}))
+	defer srv.Close()
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	d := deliver(t, WebhookPolicy{MaxAttempts: 5, Backoff: 10 * time.Millisecond}, callbackURL, &Task{ID: "tsk_retry"})
+	if d.State != DeliveryDelivered || len(d.Attempts) != 3 {
+		t.Fatalf("expected delivery on the third attempt, got %+v", d)
+	}
+	if a := d.Attempts[0]; a.StatusCode != 503 || a.Error == "" {
+		t.Errorf("first attempt should record the 503, got %+v", a)
+	}
+	if a := d.Attempts[2]; a.Attempt != 3 || a.StatusCode != 200 || a.Error != "" {
+		t.Errorf("unexpected last attempt %+v", a)
+	}
+}
+
+func TestWebhookDelay(t *testing.T) {
+	p := WebhookPolicy{Backoff: 10 * time.Second}.normalize()
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 9: time.Minute} {
+		if got := p.delay(attempt); got != want {
+			t.Errorf("delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+	if p.MaxAttempts != defaultWebhookAttempts {
+		t.Errorf("expected default attempts, got %d", p.MaxAttempts)
+	}
+}
+
+func TestWebhookDeadLetterAndHandlers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer srv.Close()
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	cfg := DefaultConfig()
+	cfg.WebhookMaxAttempts = 2
+	cfg.WebhookBackoff = 10 * time.Millisecond
+	s := New(cfg, &mockResolver{port: "1"})
+	mux := http.NewServeMux()
+	s.RegisterHandlers(mux)
+
+	s.finishTask(&Task{ID: "tsk_dead", AgentID: "a1", State: StateFailed, CallbackURL: callbackURL})
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.DeadLetters(DeliveryFilter{})) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery was not dead-lettered: %+v", s.Deliveries(DeliveryFilter{}))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for _, path := range []string{"/webhooks/deliveries?taskId=tsk_dead&state=dead", "/webhooks/dead-letter"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var resp struct {
+			Deliveries []WebhookDelivery `json:"deliveries"`
+			Count      int               `json:"count"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Count != 1 || len(resp.Deliveries[0].Attempts) != 2 || resp.Deliveries[0].Attempts[1].StatusCode != 500 {
+			t.Errorf("%s: unexpected response %s", path, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/webhooks/deliveries?state=delivered", nil))
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["count"] != float64(0) {
+		t.Errorf("state filter should exclude dead deliveries, got %v", resp["count"])
+	}
+}
+
+func TestWebhookStopEndsPendingRetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(502)
+	}))
+	defer srv.Close()
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	stop := make(chan struct{})
+	w := newWebhooks(WebhookPolicy{MaxAttempts: 3, Backoff: time.Hour}, stop)
+	w.dispatch(&Task{ID: "tsk_stop", CallbackURL: callbackURL})
+
+	waitFor := func(cond func(d WebhookDelivery) bool) WebhookDelivery {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			w.mu.Lock()
+			d := copyDelivery(w.history[0])
+			w.mu.Unlock()
+			if cond(d) {
+				return d
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("condition not reached: %+v", d)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor(func(d WebhookDelivery) bool { return d.NextAttemptAt != nil })
+	close(stop)
+	if d := waitFor(func(d WebhookDelivery) bool { return d.State != DeliveryPending }); d.State != DeliveryDead || len(d.Attempts) != 1 {
+		t.Errorf("stop should dead-letter the waiting delivery, got %+v", d)
+	}
+}
+
+func TestWebhookBurstWaitingToRetryIsNotDeadLettered(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(503)
+	}))
+	defer srv.Close()
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	w := newWebhooks(WebhookPolicy{MaxAttempts: 3, Backoff: time.Hour}, stop)
+	n := 2 * maxInflightWebhooks
+	for i := range n {
+		w.dispatch(&Task{ID: "tsk_burst_" + strconv.Itoa(i), CallbackURL: callbackURL})
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		waiting := 0
+		for _, d := range w.history {
+			if d.State == DeliveryPending && len(d.Attempts) == 1 && d.NextAttemptAt != nil {
+				waiting++
+			}
+		}
+		dead := len(w.dead)
+		w.mu.Unlock()
+		if dead != 0 {
+			t.Fatalf("%d deliveries were dead-lettered while others waited to retry", dead)
+		}
+		if waiting == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d deliveries made their first attempt", waiting, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
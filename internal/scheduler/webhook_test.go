@@ -63,6 +63,27 @@ func configureWebhookTestTarget(t *testing.T, serverURL string, timeout time.Dur
 	return callbackURL, dialedAddr, cleanup
 }
 
+// deliver dispatches a task's callback and waits for the delivery to end.
+func deliver(t *testing.T, policy WebhookPolicy, callbackURL string, task *Task) WebhookDelivery {
+	t.Helper()
+	w := newWebhooks(policy, make(chan struct{}))
+	task.CallbackURL = callbackURL
+	w.dispatch(task)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		d := copyDelivery(w.history[0])
+		w.mu.Unlock()
+		if d.State != DeliveryPending {
+			return d
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery still pending: %+v", d)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
 func TestSendWebhookSuccess(t *testing.T) {
 	var received atomic.Bool
 	var gotBody []byte
@@ -92,7 +113,7 @@ func TestSendWebhookSuccess(t *testing.T) {
 		State:   StateDone,
 	}
 
-	sendWebhook(callbackURL, task)
+	deliver(t, WebhookPolicy{MaxAttempts: 1}, callbackURL, task)
 
 	if !received.Load() {
 		t.Fatal("webhook was never received")
@@ -125,23 +146,20 @@ func TestSendWebhookSuccess(t *testing.T) {
 	}
 }
 
-func TestSendWebhookEmptyURL(t *testing.T) {
-	// Should be a no-op, no panic.
-	sendWebhook("", &Task{ID: "tsk_empty"})
-}
-
-func TestSendWebhookUnsupportedScheme(t *testing.T) {
-	sendWebhook("file:///etc/passwd", &Task{ID: "tsk_scheme_1"})
-	sendWebhook("ftp://malicious.host/data", &Task{ID: "tsk_scheme_2"})
-}
-
-func TestSendWebhookInvalidURL(t *testing.T) {
-	sendWebhook("://bad-url", &Task{ID: "tsk_bad_url"})
-}
-
-func TestSendWebhookRejectedHost(t *testing.T) {
-	sendWebhook("http://127.0.0.1/hook", &Task{ID: "tsk_blocked"})
-	sendWebhook("http://169.254.169.254/latest/meta-data", &Task{ID: "tsk_blocked2"})
+func TestSendWebhookRejectedTargets(t *testing.T) {
+	for _, u := range []string{
+		"",
+		"file:///etc/passwd",
+		"ftp://malicious.host/data",
+		"://bad-url",
+		"http://127.0.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+	} {
+		d := deliver(t, WebhookPolicy{MaxAttempts: 3}, u, &Task{ID: "tsk_rejected"})
+		if d.State != DeliveryDead || len(d.Attempts) != 0 || d.Error == "" {
+			t.Errorf("%q should be dead-lettered without an attempt, got %+v", u, d)
+		}
+	}
 }
 
 func TestValidateCallbackURLRejectsResolvedBlockedHost(t *testing.T) {
@@ -165,7 +183,10 @@ func TestSendWebhookServerError(t *testing.T) {
 	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
 	defer cleanup()
 
-	sendWebhook(callbackURL, &Task{ID: "tsk_500", State: StateFailed})
+	d := deliver(t, WebhookPolicy{MaxAttempts: 1}, callbackURL, &Task{ID: "tsk_500", State: StateFailed})
+	if d.State != DeliveryDead || len(d.Attempts) != 1 || d.Attempts[0].StatusCode != 500 {
+		t.Errorf("expected one failed attempt with status 500, got %+v", d)
+	}
 }
 
 func TestSendWebhookRedirectNotFollowed(t *testing.T) {
@@ -187,7 +208,7 @@ func TestSendWebhookRedirectNotFollowed(t *testing.T) {
 	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
 	defer cleanup()
 
-	sendWebhook(callbackURL, &Task{ID: "tsk_redirect"})
+	deliver(t, WebhookPolicy{MaxAttempts: 1}, callbackURL, &Task{ID: "tsk_redirect"})
 
 	if !firstHit.Load() {
 		t.Fatal("expected initial webhook target to receive the request")
@@ -207,7 +228,10 @@ func TestSendWebhookTimeout(t *testing.T) {
 	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Millisecond)
 	defer cleanup()
 
-	sendWebhook(callbackURL, &Task{ID: "tsk_timeout"})
+	d := deliver(t, WebhookPolicy{MaxAttempts: 1}, callbackURL, &Task{ID: "tsk_timeout"})
+	if d.State != DeliveryDead || d.Attempts[0].Error == "" || d.Attempts[0].StatusCode != 0 {
+		t.Errorf("expected a timed-out attempt, got %+v", d)
+	}
 }
 
 func TestWebhookFiredOnFinishTask(t *testing.T) {
@@ -15,6 +15,18 @@
 // or contain tag-like patterns) are treated as CSS. Everything else
 // without a prefix is treated as a ref if it matches the eN pattern,
 // or as CSS otherwise.
+//
+// Two scoping forms may wrap any CSS, XPath or text selector:
+//
+//	"frame:login #user"          → #user inside the iframe named "login"
+//	"frame:\"*/auth/*\" #user"    → frame matched by URL glob (quoted)
+//	"frame:outer frame:inner a"  → nested frames, outermost first
+//	"my-app >>> button.primary"  → button inside my-app's shadow tree
+//	"my-app >>> text:Save"       → text match inside a shadow tree
+//
+// Frames are matched by name or id, then by URL (exact, glob, substring).
+// Every ">>>" segment except the last is a CSS host selector; the last is
+// parsed like any other selector.
 package selector
 
 import (
@@ -34,14 +46,51 @@ const (
 	KindSemantic Kind = "semantic"
 )
 
+// PierceCombinator separates shadow host selectors from the selector
+// matched inside their shadow trees.
+const PierceCombinator = ">>>"
+
+const framePrefix = "frame:"
+
 // Selector is a parsed, unified element selector.
 type Selector struct {
 	Kind  Kind   `json:"kind"`
 	Value string `json:"value"`
+	// Frames lists frame specs (name, id or URL pattern), outermost first.
+	Frames []string `json:"frames,omitempty"`
+	// Pierce lists the CSS shadow host selectors preceding the final
+	// segment of a ">>>" chain.
+	Pierce []string `json:"pierce,omitempty"`
+}
+
+// Scoped reports whether the selector targets a frame or a shadow tree.
+func (s Selector) Scoped() bool {
+	return len(s.Frames) > 0 || len(s.Pierce) > 0
 }
 
 // String returns the canonical string representation with prefix.
 func (s Selector) String() string {
+	if !s.Scoped() {
+		return s.base()
+	}
+	var b strings.Builder
+	for _, f := range s.Frames {
+		b.WriteString(framePrefix)
+		if strings.ContainsAny(f, " \t\"") {
+			b.WriteString(`"` + strings.ReplaceAll(f, `"`, "") + `"`)
+		} else {
+			b.WriteString(f)
+		}
+		b.WriteByte(' ')
+	}
+	for _, host := range s.Pierce {
+		b.WriteString(host + " " + PierceCombinator + " ")
+	}
+	b.WriteString(s.base())
+	return b.String()
+}
+
+func (s Selector) base() string {
 	switch s.Kind {
 	case KindRef:
 		return s.Value
@@ -82,8 +131,72 @@ func (s Selector) IsEmpty() bool {
 //	"tag.class"  → CSS
 //	"//xpath"    → XPath
 //	everything else → CSS (safest default for backward compat)
+//
+// Leading "frame:<spec>" tokens and ">>>" shadow piercing are split off
+// first; see the package documentation.
 func Parse(s string) Selector {
 	s = strings.TrimSpace(s)
+	var frames []string
+	for strings.HasPrefix(s, framePrefix) {
+		spec, rest := cutFrameSpec(s[len(framePrefix):])
+		if spec == "" {
+			break
+		}
+		frames = append(frames, spec)
+		s = strings.TrimSpace(rest)
+	}
+	sel := parsePierce(s)
+	sel.Frames = frames
+	return sel
+}
+
+// cutFrameSpec splits a frame spec from the selector that follows it. The
+// spec ends at the first whitespace unless it is double-quoted.
+func cutFrameSpec(s string) (spec, rest string) {
+	if strings.HasPrefix(s, `"`) {
+		if end := strings.Index(s[1:], `"`); end >= 0 {
+			return s[1 : end+1], s[end+2:]
+		}
+		return s[1:], ""
+	}
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
+
+// parsePierce splits a ">>>" chain into CSS host selectors and the final
+// selector. Selectors with a text:, xpath:, find: or ref: prefix are taken
+// verbatim so their values may contain ">>>".
+func parsePierce(s string) Selector {
+	if !strings.Contains(s, PierceCombinator) || hasValuePrefix(s) {
+		return parseSimple(s)
+	}
+	parts := strings.Split(s, PierceCombinator)
+	hosts := make([]string, 0, len(parts)-1)
+	for _, p := range parts[:len(parts)-1] {
+		p = strings.TrimSpace(p)
+		if after, ok := cutPrefix(p, "css:"); ok {
+			p = strings.TrimSpace(after)
+		}
+		hosts = append(hosts, p)
+	}
+	sel := parseSimple(strings.TrimSpace(parts[len(parts)-1]))
+	sel.Pierce = hosts
+	return sel
+}
+
+func hasValuePrefix(s string) bool {
+	for _, p := range []string{"text:", "xpath:", "find:", "ref:"} {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSimple parses a selector without frame or shadow scoping.
+func parseSimple(s string) Selector {
 	if s == "" {
 		return Selector{}
 	}
@@ -179,10 +292,18 @@ func (s Selector) Validate() error {
 	}
 	switch s.Kind {
 	case KindRef, KindCSS, KindXPath, KindText, KindSemantic:
-		return nil
 	default:
 		return fmt.Errorf("unknown selector kind: %q", s.Kind)
 	}
+	if s.Scoped() && (s.Kind == KindRef || s.Kind == KindSemantic) {
+		return fmt.Errorf("%s selectors cannot be combined with frame: or %s", s.Kind, PierceCombinator)
+	}
+	for _, host := range s.Pierce {
+		if host == "" {
+			return fmt.Errorf("empty shadow host before %s", PierceCombinator)
+		}
+	}
+	return nil
 }
 
 // cutPrefix is a helper for strings.CutPrefix (available in Go 1.20+).
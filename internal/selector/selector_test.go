@@ -299,18 +299,18 @@ func TestSelector_String(t *testing.T) {
 		sel  Selector
 		want string
 	}{
-		{Selector{KindRef, "e5"}, "e5"},
-		{Selector{KindRef, "e0"}, "e0"},
-		{Selector{KindCSS, "#login"}, "css:#login"},
-		{Selector{KindCSS, ".btn"}, "css:.btn"},
-		{Selector{KindCSS, "div > span"}, "css:div > span"},
-		{Selector{KindXPath, "//div"}, "xpath://div"},
-		{Selector{KindXPath, "(//button)[1]"}, "xpath:(//button)[1]"},
-		{Selector{KindText, "Submit"}, "text:Submit"},
-		{Selector{KindText, "with:colon"}, "text:with:colon"},
-		{Selector{KindSemantic, "login button"}, "find:login button"},
-		{Selector{KindNone, ""}, ""},
-		{Selector{KindNone, "something"}, "something"},
+		{Selector{Kind: KindRef, Value: "e5"}, "e5"},
+		{Selector{Kind: KindRef, Value: "e0"}, "e0"},
+		{Selector{Kind: KindCSS, Value: "#login"}, "css:#login"},
+		{Selector{Kind: KindCSS, Value: ".btn"}, "css:.btn"},
+		{Selector{Kind: KindCSS, Value: "div > span"}, "css:div > span"},
+		{Selector{Kind: KindXPath, Value: "//div"}, "xpath://div"},
+		{Selector{Kind: KindXPath, Value: "(//button)[1]"}, "xpath:(//button)[1]"},
+		{Selector{Kind: KindText, Value: "Submit"}, "text:Submit"},
+		{Selector{Kind: KindText, Value: "with:colon"}, "text:with:colon"},
+		{Selector{Kind: KindSemantic, Value: "login button"}, "find:login button"},
+		{Selector{Kind: KindNone, Value: ""}, ""},
+		{Selector{Kind: KindNone, Value: "something"}, "something"},
 	}
 	for _, tt := range tests {
 		if got := tt.sel.String(); got != tt.want {
@@ -341,11 +341,11 @@ func TestSelector_IsEmpty(t *testing.T) {
 
 func TestSelector_Validate(t *testing.T) {
 	valid := []Selector{
-		{KindRef, "e5"},
-		{KindCSS, "#login"},
-		{KindXPath, "//div"},
-		{KindText, "Submit"},
-		{KindSemantic, "login button"},
+		{Kind: KindRef, Value: "e5"},
+		{Kind: KindCSS, Value: "#login"},
+		{Kind: KindXPath, Value: "//div"},
+		{Kind: KindText, Value: "Submit"},
+		{Kind: KindSemantic, Value: "login button"},
 	}
 	for _, s := range valid {
 		if err := s.Validate(); err != nil {
@@ -475,3 +475,68 @@ func TestParse_PrefixPriority(t *testing.T) {
 		t.Errorf("Parse(\"xpath:e5\").Kind = %q, want xpath", s.Kind)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Parse – frame scoping and shadow piercing
+// ---------------------------------------------------------------------------
+
+func TestParse_Scoped(t *testing.T) {
+	tests := []struct {
+		input  string
+		kind   Kind
+		value  string
+		frames []string
+		pierce []string
+	}{
+		{"frame:login #user", KindCSS, "#user", []string{"login"}, nil},
+		{`frame:"*/auth/*" css:#user`, KindCSS, "#user", []string{"*/auth/*"}, nil},
+		{`frame:"My Frame" text:Sign in`, KindText, "Sign in", []string{"My Frame"}, nil},
+		{"frame:outer frame:inner //a", KindXPath, "//a", []string{"outer", "inner"}, nil},
+		{"my-app >>> button.primary", KindCSS, "button.primary", nil, []string{"my-app"}},
+		{"css:my-app >>> #panel >>> text:Save", KindText, "Save", nil, []string{"my-app", "#panel"}},
+		{"frame:checkout pay-form >>> input[name=cc]", KindCSS, "input[name=cc]", []string{"checkout"}, []string{"pay-form"}},
+		{"text:a >>> b", KindText, "a >>> b", nil, nil},
+	}
+	for _, tt := range tests {
+		s := Parse(tt.input)
+		if s.Kind != tt.kind || s.Value != tt.value {
+			t.Errorf("Parse(%q) = %s:%q, want %s:%q", tt.input, s.Kind, s.Value, tt.kind, tt.value)
+		}
+		if !equalStrings(s.Frames, tt.frames) {
+			t.Errorf("Parse(%q).Frames = %q, want %q", tt.input, s.Frames, tt.frames)
+		}
+		if !equalStrings(s.Pierce, tt.pierce) {
+			t.Errorf("Parse(%q).Pierce = %q, want %q", tt.input, s.Pierce, tt.pierce)
+		}
+		if !s.Scoped() && (len(tt.frames) > 0 || len(tt.pierce) > 0) {
+			t.Errorf("Parse(%q).Scoped() = false", tt.input)
+		}
+		if err := s.Validate(); err != nil {
+			t.Errorf("Parse(%q).Validate() = %v", tt.input, err)
+		}
+		again := Parse(s.String())
+		if again.String() != s.String() {
+			t.Errorf("roundtrip %q: %q != %q", tt.input, again.String(), s.String())
+		}
+	}
+}
+
+func TestValidate_Scoped(t *testing.T) {
+	for _, input := range []string{"frame:login e5", "frame:login find:submit", ">>> button", "frame:login"} {
+		if err := Parse(input).Validate(); err == nil {
+			t.Errorf("Parse(%q).Validate() = nil, want error", input)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -15,10 +15,24 @@ import (
 	"github.com/pinchtab/pinchtab/internal/cli"
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/engine"
+	"github.com/pinchtab/pinchtab/internal/fleet"
 	"github.com/pinchtab/pinchtab/internal/handlers"
 )
 
-func RunBridgeServer(cfg *config.RuntimeConfig) {
+// BridgeOptions configures fleet registration for a bridge server.
+type BridgeOptions struct {
+	// Register is the base URL of the PinchTab server to join. Empty
+	// leaves the bridge standalone.
+	Register  string
+	JoinToken string
+	// Name identifies the bridge on the server; AdvertiseURL is where the
+	// server reaches it.
+	Name         string
+	AdvertiseURL string
+	Labels       map[string]string
+}
+
+func RunBridgeServer(cfg *config.RuntimeConfig, opts BridgeOptions) {
 	listenAddr := cfg.ListenAddr()
 	cli.PrintStartupBanner(cfg, cli.StartupBannerOptions{
 		Mode:         "bridge",
@@ -83,10 +97,35 @@ func RunBridgeServer(cfg *config.RuntimeConfig) {
 		}
 	}()
 
+	fleetCtx, stopFleet := context.WithCancel(context.Background())
+	fleetDone := make(chan struct{})
+	if opts.Register != "" {
+		client := &fleet.Client{
+			Server:    opts.Register,
+			JoinToken: opts.JoinToken,
+			Registration: fleet.Registration{
+				Name:    opts.Name,
+				BaseURL: opts.AdvertiseURL,
+				Token:   cfg.Token,
+				Labels:  opts.Labels,
+			},
+			Capacity: bridgeCapacity(bridgeInstance, cfg),
+		}
+		go func() {
+			defer close(fleetDone)
+			client.Run(fleetCtx)
+		}()
+	} else {
+		close(fleetDone)
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
+	// Leave the fleet before tearing down so the server stops routing here.
+	stopFleet()
+	<-fleetDone
 	doShutdown()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -96,6 +135,18 @@ func RunBridgeServer(cfg *config.RuntimeConfig) {
 	}
 }
 
+// bridgeCapacity reports open tabs against the tab limit for fleet
+// heartbeats. Tabs reads as 0 until Chrome has started.
+func bridgeCapacity(b *bridge.Bridge, cfg *config.RuntimeConfig) func() bridge.InstanceCapacity {
+	return func() bridge.InstanceCapacity {
+		capacity := bridge.InstanceCapacity{MaxTabs: cfg.MaxTabs}
+		if targets, err := b.ListTargets(); err == nil {
+			capacity.Tabs = len(targets)
+		}
+		return capacity
+	}
+}
+
 func configureBridgeRouter(h *handlers.Handlers, cfg *config.RuntimeConfig) {
 	mode := engine.Mode(cfg.Engine)
 	if mode != engine.ModeLite && mode != engine.ModeAuto {
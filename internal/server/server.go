@@ -19,6 +19,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/dashboard"
 	"github.com/pinchtab/pinchtab/internal/handlers"
 	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/instance"
 	"github.com/pinchtab/pinchtab/internal/orchestrator"
 	"github.com/pinchtab/pinchtab/internal/profiles"
 	"github.com/pinchtab/pinchtab/internal/scheduler"
@@ -27,6 +28,7 @@ import (
 	// Register strategies
 	_ "github.com/pinchtab/pinchtab/internal/strategy/alwayson"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/autorestart"
+	_ "github.com/pinchtab/pinchtab/internal/strategy/elastic"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/explicit"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/noinstance"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/simple"
@@ -147,36 +149,32 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 	slog.Info("orchestration", "strategy", stratName, "allocation", allocPolicy)
 
 	var sched *scheduler.Scheduler
+	var schedWatcher *scheduler.ConfigWatcher
 	if cfg.Scheduler.Enabled {
-		schedCfg := scheduler.DefaultConfig()
-		schedCfg.Enabled = true
-		if cfg.Scheduler.Strategy != "" {
-			schedCfg.Strategy = cfg.Scheduler.Strategy
-		}
-		if cfg.Scheduler.MaxQueueSize > 0 {
-			schedCfg.MaxQueueSize = cfg.Scheduler.MaxQueueSize
-		}
-		if cfg.Scheduler.MaxPerAgent > 0 {
-			schedCfg.MaxPerAgent = cfg.Scheduler.MaxPerAgent
-		}
-		if cfg.Scheduler.MaxInflight > 0 {
-			schedCfg.MaxInflight = cfg.Scheduler.MaxInflight
-		}
-		if cfg.Scheduler.MaxPerAgentFlight > 0 {
-			schedCfg.MaxPerAgentFlight = cfg.Scheduler.MaxPerAgentFlight
-		}
-		if cfg.Scheduler.ResultTTLSec > 0 {
-			schedCfg.ResultTTL = time.Duration(cfg.Scheduler.ResultTTLSec) * time.Second
-		}
-		if cfg.Scheduler.WorkerCount > 0 {
-			schedCfg.WorkerCount = cfg.Scheduler.WorkerCount
+		schedCfg := schedulerConfig(cfg)
+
+		taskStore, err := scheduler.NewTaskStore(schedCfg.Store, cfg.StateDir)
+		if err != nil {
+			slog.Error("scheduler store", "err", err)
+			os.Exit(1)
 		}
 
-		resolver := &scheduler.ManagerResolver{Mgr: orch.InstanceManager()}
+		resolver := &scheduler.ManagerResolver{Mgr: orch.InstanceManager(), Tabs: instance.NewBridgeClient()}
 		sched = scheduler.New(schedCfg, resolver)
+		sched.SetStore(taskStore)
 		sched.RegisterHandlers(mux)
 		sched.Start()
-		slog.Info("scheduler enabled", "strategy", schedCfg.Strategy, "workers", schedCfg.WorkerCount)
+		if queueAware, ok := activeStrategy.(strategy.QueueAware); ok {
+			queueAware.SetQueueDepth(func() int { return sched.QueueStats().TotalQueued })
+		}
+		slog.Info("scheduler enabled", "strategy", schedCfg.Strategy, "workers", schedCfg.WorkerCount, "store", schedCfg.Store)
+
+		// Re-read the config file so strategy and limit changes apply
+		// without a restart.
+		schedWatcher = scheduler.NewConfigWatcher(schedCfg.WatcherInterval, func() (scheduler.Config, error) {
+			return schedulerConfig(config.Load()), nil
+		}, sched)
+		schedWatcher.Start()
 	}
 
 	mux.HandleFunc("GET /health", configAPI.HandleHealth)
@@ -220,6 +218,9 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 			if err := activeStrategy.Stop(); err != nil {
 				slog.Warn("strategy stop failed", "err", err)
 			}
+			if schedWatcher != nil {
+				schedWatcher.Stop()
+			}
 			if sched != nil {
 				sched.Stop()
 			}
@@ -259,3 +260,77 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 		os.Exit(1)
 	}
 }
+
+// schedulerConfig maps the runtime scheduler settings onto scheduler
+// defaults.
+func schedulerConfig(cfg *config.RuntimeConfig) scheduler.Config {
+	schedCfg := scheduler.DefaultConfig()
+	schedCfg.Enabled = cfg.Scheduler.Enabled
+	if cfg.Scheduler.Strategy != "" {
+		schedCfg.Strategy = cfg.Scheduler.Strategy
+	}
+	if cfg.Scheduler.MaxQueueSize > 0 {
+		schedCfg.MaxQueueSize = cfg.Scheduler.MaxQueueSize
+	}
+	if cfg.Scheduler.MaxPerAgent > 0 {
+		schedCfg.MaxPerAgent = cfg.Scheduler.MaxPerAgent
+	}
+	if cfg.Scheduler.MaxInflight > 0 {
+		schedCfg.MaxInflight = cfg.Scheduler.MaxInflight
+	}
+	if cfg.Scheduler.MaxPerAgentFlight > 0 {
+		schedCfg.MaxPerAgentFlight = cfg.Scheduler.MaxPerAgentFlight
+	}
+	if cfg.Scheduler.ResultTTLSec > 0 {
+		schedCfg.ResultTTL = time.Duration(cfg.Scheduler.ResultTTLSec) * time.Second
+	}
+	if cfg.Scheduler.WorkerCount > 0 {
+		schedCfg.WorkerCount = cfg.Scheduler.WorkerCount
+	}
+	if cfg.Scheduler.Store != "" {
+		schedCfg.Store = cfg.Scheduler.Store
+	}
+	if cfg.Scheduler.InflightPolicy != "" {
+		schedCfg.InflightPolicy = cfg.Scheduler.InflightPolicy
+	}
+	schedCfg.AgentWeights = cfg.Scheduler.AgentWeights
+	if cfg.Scheduler.StarvationAfterSec > 0 {
+		schedCfg.StarvationAfter = time.Duration(cfg.Scheduler.StarvationAfterSec) * time.Second
+	}
+	schedCfg.SerializeTabs = cfg.Scheduler.SerializeTabs
+	schedCfg.Quotas = schedulerQuotas(cfg.Scheduler.Limits)
+	schedCfg.WebhookSecret = cfg.Scheduler.WebhookSecret
+	if cfg.Scheduler.WebhookMaxAttempts > 0 {
+		schedCfg.WebhookMaxAttempts = cfg.Scheduler.WebhookMaxAttempts
+	}
+	if cfg.Scheduler.WebhookBackoffSec > 0 {
+		schedCfg.WebhookBackoff = time.Duration(cfg.Scheduler.WebhookBackoffSec) * time.Second
+	}
+	if cfg.Scheduler.TabPoolSize > 0 {
+		schedCfg.TabPoolSize = cfg.Scheduler.TabPoolSize
+	}
+	return schedCfg
+}
+
+// schedulerQuotas maps configured limits to scheduler quotas. The result is
+// never nil, so a hot reload that removes all limits clears them.
+func schedulerQuotas(l config.SchedulerLimitsConfig) *scheduler.QuotaConfig {
+	agentLimits := func(al config.SchedulerAgentLimits) scheduler.AgentLimits {
+		out := scheduler.AgentLimits{RateLimit: scheduler.RateLimit(al.SchedulerRateLimit)}
+		if len(al.Actions) > 0 {
+			out.Actions = make(map[string]scheduler.RateLimit, len(al.Actions))
+			for action, rl := range al.Actions {
+				out.Actions[action] = scheduler.RateLimit(rl)
+			}
+		}
+		return out
+	}
+	q := &scheduler.QuotaConfig{Default: agentLimits(l.Default)}
+	if len(l.Agents) > 0 {
+		q.Agents = make(map[string]scheduler.AgentLimits, len(l.Agents))
+		for agentID, al := range l.Agents {
+			q.Agents[agentID] = agentLimits(al)
+		}
+	}
+	return q
+}
@@ -180,13 +180,21 @@ func (s *Strategy) RegisterRoutes(mux *http.ServeMux) {
 		"GET /errors", "POST /errors/clear",
 		"GET /clipboard/read", "POST /clipboard/write", "POST /clipboard/copy", "GET /clipboard/paste",
 		"GET /network", "GET /network/stream", "GET /network/{requestId}", "POST /network/clear",
+		"GET /network/har", "GET /network/har/replay", "POST /network/har/replay", "DELETE /network/har/replay",
+		"GET /intercept", "POST /intercept", "DELETE /intercept",
 		"POST /navigate", "POST /back", "POST /forward", "POST /reload",
 		"POST /action", "POST /actions",
 		"POST /dialog",
 		"POST /wait",
 		"POST /tab", "POST /tab/lock", "POST /tab/unlock",
 		"GET /cookies", "POST /cookies",
+		"GET /storage", "POST /storage", "DELETE /storage",
+		"POST /state/export", "POST /state/import",
+		"POST /workflows/run", "POST /extract",
+		"POST /screenshot/compare", "GET /screenshot/baselines", "GET /screenshot/baselines/{name}",
+		"PUT /screenshot/baselines/{name}", "DELETE /screenshot/baselines/{name}", "POST /screenshot/baselines/{name}/accept",
 		"GET /stealth/status", "POST /fingerprint/rotate",
+		"GET /emulate/devices", "POST /emulate",
 		"POST /find",
 	}
 	for _, route := range shorthandRoutes {
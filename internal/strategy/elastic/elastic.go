@@ -0,0 +1,534 @@
+// Package elastic implements the "elastic" allocation strategy.
+//
+// Elastic keeps a pool of managed instances between a minimum and a
+// maximum size. It launches another instance when the scheduler queue or
+// the average tabs per instance crosses a threshold, and stops instances
+// that have been idle for a cooldown period. Shorthand endpoints are
+// proxied to a pool instance chosen by the allocation policy.
+//
+// Each pool instance runs its own profile ("elastic-1", "elastic-2", ...)
+// because a profile can only back one running instance.
+package elastic
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/fleet"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+	"github.com/pinchtab/pinchtab/internal/orchestrator"
+	"github.com/pinchtab/pinchtab/internal/strategy"
+)
+
+const (
+	defaultMinInstances      = 1
+	defaultMaxInstances      = 4
+	defaultScaleUpQueueDepth = 10
+	defaultScaleUpTabs       = 10
+	defaultIdleCooldown      = 5 * time.Minute
+	defaultCheckInterval     = 10 * time.Second
+	defaultProfilePrefix     = "elastic"
+	statusPath               = "/elastic/status"
+	readyPollInterval        = 500 * time.Millisecond
+	readyPollTimeout         = 30 * time.Second
+
+	// idleTabs is the most tabs an idle instance may have: a fresh browser
+	// keeps its initial blank tab. An instance with that many tabs is only
+	// idle once it has also stopped serving requests.
+	idleTabs = 1
+)
+
+// Scale events emitted through the orchestrator's event system.
+const (
+	EventScaledUp   = "instance.scaled_up"
+	EventScaledDown = "instance.scaled_down"
+)
+
+func init() {
+	strategy.MustRegister("elastic", func() strategy.Strategy {
+		return New(Config{})
+	})
+}
+
+// Config configures pool sizing.
+type Config struct {
+	MinInstances           int           // Instances kept running even when idle (0 = use default 1, <0 = none)
+	MaxInstances           int           // Upper bound on pool size (0 = use default 4)
+	ScaleUpQueueDepth      int           // Queued scheduler tasks that trigger a scale-up (0 = use default 10)
+	ScaleUpTabsPerInstance int           // Average tabs per instance that trigger a scale-up (0 = use default 10)
+	IdleCooldown           time.Duration // Idle time before an instance is stopped (0 = use default 5m)
+	CheckInterval          time.Duration // How often the pool is evaluated (0 = use default 10s)
+	ProfilePrefix          string        // Prefix of pool profile names (empty = "elastic")
+}
+
+// pool is the part of the orchestrator the strategy drives.
+type pool interface {
+	Launch(name, port string, headless bool, extensionPaths []string) (*bridge.Instance, error)
+	Stop(id string) error
+	List() []bridge.Instance
+	InstanceLoads() map[string]allocation.Load
+	InstanceActivity() map[string]time.Time
+	EmitEvent(eventType string, inst *bridge.Instance)
+}
+
+// Member is a pool instance as reported by the status endpoint.
+type Member struct {
+	InstanceID string    `json:"instanceId"`
+	Profile    string    `json:"profile"`
+	Status     string    `json:"status"`
+	Tabs       int       `json:"tabs"`
+	LaunchedAt time.Time `json:"launchedAt"`
+	IdleSince  time.Time `json:"idleSince,omitempty"`
+}
+
+// PoolState is the pool as reported by the status endpoint.
+type PoolState struct {
+	MinInstances int      `json:"minInstances"`
+	MaxInstances int      `json:"maxInstances"`
+	QueueDepth   int      `json:"queueDepth"`
+	Members      []Member `json:"members"`
+}
+
+type member struct {
+	profile   string
+	status    string
+	tabs      int
+	launched  time.Time
+	idleSince time.Time
+}
+
+// Strategy grows and shrinks a pool of instances with demand.
+type Strategy struct {
+	orch   *orchestrator.Orchestrator
+	pool   pool
+	config Config
+
+	mu         sync.Mutex
+	members    map[string]*member // by instance ID
+	launching  map[string]bool    // profiles with a launch in progress
+	queueDepth func() int
+	cancel     context.CancelFunc
+}
+
+// New creates an elastic strategy with the given config.
+func New(cfg Config) *Strategy {
+	if cfg.MinInstances == 0 {
+		cfg.MinInstances = defaultMinInstances
+	} else if cfg.MinInstances < 0 {
+		cfg.MinInstances = 0
+	}
+	if cfg.MaxInstances <= 0 {
+		cfg.MaxInstances = defaultMaxInstances
+	}
+	cfg.MinInstances = min(cfg.MinInstances, cfg.MaxInstances)
+	if cfg.ScaleUpQueueDepth <= 0 {
+		cfg.ScaleUpQueueDepth = defaultScaleUpQueueDepth
+	}
+	if cfg.ScaleUpTabsPerInstance <= 0 {
+		cfg.ScaleUpTabsPerInstance = defaultScaleUpTabs
+	}
+	if cfg.IdleCooldown <= 0 {
+		cfg.IdleCooldown = defaultIdleCooldown
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = defaultCheckInterval
+	}
+	if cfg.ProfilePrefix == "" {
+		cfg.ProfilePrefix = defaultProfilePrefix
+	}
+	return &Strategy{config: cfg, members: make(map[string]*member), launching: make(map[string]bool)}
+}
+
+func (s *Strategy) Name() string { return "elastic" }
+
+func (s *Strategy) SetRuntimeConfig(cfg *config.RuntimeConfig) {
+	if cfg == nil {
+		return
+	}
+	// Zero is a valid minimum here: the loaded config defaults it to 1.
+	s.config.MinInstances = max(0, cfg.ElasticMinInstances)
+	if cfg.ElasticMaxInstances > 0 {
+		s.config.MaxInstances = cfg.ElasticMaxInstances
+	}
+	if cfg.ElasticScaleUpQueueDepth > 0 {
+		s.config.ScaleUpQueueDepth = cfg.ElasticScaleUpQueueDepth
+	}
+	if cfg.ElasticScaleUpTabs > 0 {
+		s.config.ScaleUpTabsPerInstance = cfg.ElasticScaleUpTabs
+	}
+	if cfg.ElasticIdleCooldown > 0 {
+		s.config.IdleCooldown = cfg.ElasticIdleCooldown
+	}
+	s.config.MinInstances = min(s.config.MinInstances, s.config.MaxInstances)
+}
+
+// SetOrchestrator injects the orchestrator after construction.
+func (s *Strategy) SetOrchestrator(o *orchestrator.Orchestrator) {
+	s.orch = o
+	s.pool = o
+}
+
+// SetQueueDepth sets how the scheduler backlog is read. Without it only
+// tabs per instance trigger a scale-up.
+func (s *Strategy) SetQueueDepth(depth func() int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queueDepth = depth
+}
+
+// Start warms the pool up to MinInstances and begins evaluating it.
+func (s *Strategy) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	go s.loop(ctx)
+	return nil
+}
+
+// Stop ends pool evaluation. Pool instances are stopped with the
+// orchestrator at shutdown.
+func (s *Strategy) Stop() error {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// RegisterRoutes adds shorthand endpoints that proxy to a pool instance.
+func (s *Strategy) RegisterRoutes(mux *http.ServeMux) {
+	s.orch.RegisterHandlers(mux)
+
+	shorthandRoutes := []string{
+		"GET /snapshot", "GET /screenshot", "GET /text", "GET /pdf", "POST /pdf",
+		"GET /console", "POST /console/clear",
+		"GET /errors", "POST /errors/clear",
+		"GET /clipboard/read", "POST /clipboard/write", "POST /clipboard/copy", "GET /clipboard/paste",
+		"GET /network", "GET /network/stream", "GET /network/{requestId}", "POST /network/clear",
+		"GET /network/har", "GET /network/har/replay", "POST /network/har/replay", "DELETE /network/har/replay",
+		"GET /intercept", "POST /intercept", "DELETE /intercept",
+		"POST /navigate", "POST /back", "POST /forward", "POST /reload",
+		"POST /action", "POST /actions",
+		"POST /dialog",
+		"POST /wait",
+		"POST /tab", "POST /tab/lock", "POST /tab/unlock",
+		"GET /cookies", "POST /cookies",
+		"GET /storage", "POST /storage", "DELETE /storage",
+		"POST /state/export", "POST /state/import",
+		"POST /workflows/run", "POST /extract",
+		"POST /screenshot/compare", "GET /screenshot/baselines", "GET /screenshot/baselines/{name}",
+		"PUT /screenshot/baselines/{name}", "DELETE /screenshot/baselines/{name}", "POST /screenshot/baselines/{name}/accept",
+		"GET /stealth/status", "POST /fingerprint/rotate",
+		"GET /emulate/devices", "POST /emulate",
+		"POST /find",
+	}
+	for _, route := range shorthandRoutes {
+		mux.HandleFunc(route, s.proxyToPool)
+	}
+	strategy.RegisterCapabilityRoute(mux, "POST /evaluate", s.orch.AllowsEvaluate(), "evaluate", "security.allowEvaluate", "evaluate_disabled", s.proxyToPool)
+	strategy.RegisterCapabilityRoute(mux, "GET /download", s.orch.AllowsDownload(), "download", "security.allowDownload", "download_disabled", s.proxyToPool)
+	strategy.RegisterCapabilityRoute(mux, "POST /upload", s.orch.AllowsUpload(), "upload", "security.allowUpload", "upload_disabled", s.proxyToPool)
+	strategy.RegisterCapabilityRoute(mux, "GET /screencast", s.orch.AllowsScreencast(), "screencast", "security.allowScreencast", "screencast_disabled", s.proxyToPool)
+	strategy.RegisterCapabilityRoute(mux, "GET /screencast/tabs", s.orch.AllowsScreencast(), "screencast", "security.allowScreencast", "screencast_disabled", s.proxyToPool)
+	strategy.RegisterCapabilityRoute(mux, "POST /macro", s.orch.AllowsMacro(), "macro", "security.allowMacro", "macro_disabled", s.proxyToPool)
+
+	mux.HandleFunc("GET /tabs", s.handleTabs)
+	mux.HandleFunc("GET "+statusPath, s.handleStatus)
+}
+
+// State returns the pool for observability.
+func (s *Strategy) State() PoolState {
+	queued := s.queued()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := PoolState{
+		MinInstances: s.config.MinInstances,
+		MaxInstances: s.config.MaxInstances,
+		QueueDepth:   queued,
+		Members:      make([]Member, 0, len(s.members)),
+	}
+	for id, m := range s.members {
+		state.Members = append(state.Members, Member{
+			InstanceID: id,
+			Profile:    m.profile,
+			Status:     m.status,
+			Tabs:       m.tabs,
+			LaunchedAt: m.launched,
+			IdleSince:  m.idleSince,
+		})
+	}
+	sort.Slice(state.Members, func(i, j int) bool {
+		return state.Members[i].Profile < state.Members[j].Profile
+	})
+	return state
+}
+
+// --- Internal ---
+
+func (s *Strategy) loop(ctx context.Context) {
+	s.evaluate(time.Now())
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.evaluate(now)
+		}
+	}
+}
+
+// evaluate reconciles the pool with the orchestrator and applies at most
+// one scale-up, or any due scale-downs, per call.
+func (s *Strategy) evaluate(now time.Time) {
+	if s.pool == nil {
+		return
+	}
+	queued := s.queued()
+	s.refresh(now)
+
+	s.mu.Lock()
+	size := len(s.members) + len(s.launching)
+	starting, running, tabs := len(s.launching), 0, 0
+	for _, m := range s.members {
+		switch m.status {
+		case "starting":
+			starting++
+		case "running":
+			running++
+			tabs += m.tabs
+		}
+	}
+	s.mu.Unlock()
+
+	if size < s.config.MinInstances {
+		for range s.config.MinInstances - size {
+			s.scaleUp("min_instances")
+		}
+		return
+	}
+	// Wait for launched instances to come up before judging load again.
+	if starting > 0 || size >= s.config.MaxInstances {
+		s.scaleDown(now, queued)
+		return
+	}
+	switch {
+	case queued >= s.config.ScaleUpQueueDepth:
+		s.scaleUp("queue_depth")
+	case running > 0 && tabs >= s.config.ScaleUpTabsPerInstance*running:
+		s.scaleUp("tabs_per_instance")
+	default:
+		s.scaleDown(now, queued)
+	}
+}
+
+// refresh drops members that are no longer active and records the status,
+// tab count and idle time of the rest. An instance is idle from its last
+// proxied or scheduled request, or from when it was first seen without
+// tabs in use, whichever is later.
+func (s *Strategy) refresh(now time.Time) {
+	status := make(map[string]string)
+	for _, inst := range s.pool.List() {
+		status[inst.ID] = inst.Status
+	}
+	loads := s.pool.InstanceLoads()
+	active := s.pool.InstanceActivity()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, m := range s.members {
+		st := status[id]
+		if st != "starting" && st != "running" {
+			slog.Info("elastic: instance left the pool", "id", id, "profile", m.profile, "status", st)
+			delete(s.members, id)
+			continue
+		}
+		m.status = st
+		load, ok := loads[id]
+		if st != "running" || !ok || !load.Known {
+			m.idleSince = time.Time{}
+			continue
+		}
+		m.tabs = load.Tabs
+		if load.Tabs > idleTabs {
+			m.idleSince = time.Time{}
+			continue
+		}
+		if m.idleSince.IsZero() {
+			m.idleSince = now
+		}
+		if last := active[id]; last.After(m.idleSince) {
+			m.idleSince = last
+		}
+	}
+}
+
+// scaleUp launches one instance on the first free pool profile.
+func (s *Strategy) scaleUp(reason string) (*bridge.Instance, error) {
+	s.mu.Lock()
+	if len(s.members)+len(s.launching) >= s.config.MaxInstances {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("elastic pool is at its maximum of %d instances", s.config.MaxInstances)
+	}
+	// Reserve the profile while launching so concurrent scale-ups pick
+	// another one and respect MaxInstances.
+	profile := s.freeProfileLocked()
+	s.launching[profile] = true
+	s.mu.Unlock()
+
+	inst, err := s.pool.Launch(profile, "", true, nil)
+
+	s.mu.Lock()
+	delete(s.launching, profile)
+	if err == nil {
+		s.members[inst.ID] = &member{profile: profile, status: inst.Status, launched: time.Now()}
+	}
+	size := len(s.members)
+	s.mu.Unlock()
+
+	if err != nil {
+		slog.Error("elastic: scale up failed", "profile", profile, "reason", reason, "err", err)
+		return nil, fmt.Errorf("scale up: %w", err)
+	}
+	slog.Info("elastic: scaled up", "id", inst.ID, "profile", profile, "reason", reason, "size", size)
+	s.pool.EmitEvent(EventScaledUp, inst)
+	return inst, nil
+}
+
+// scaleDown stops instances idle for longer than the cooldown, longest idle
+// first, while the pool stays at or above MinInstances. Nothing is stopped
+// while tasks are queued.
+func (s *Strategy) scaleDown(now time.Time, queued int) {
+	if queued > 0 {
+		return
+	}
+	type candidate struct {
+		id        string
+		profile   string
+		idleSince time.Time
+	}
+	s.mu.Lock()
+	var due []candidate
+	for id, m := range s.members {
+		if !m.idleSince.IsZero() && now.Sub(m.idleSince) >= s.config.IdleCooldown {
+			due = append(due, candidate{id, m.profile, m.idleSince})
+		}
+	}
+	surplus := len(s.members) - s.config.MinInstances
+	s.mu.Unlock()
+
+	slices.SortFunc(due, func(a, b candidate) int { return a.idleSince.Compare(b.idleSince) })
+	for _, c := range due[:max(0, min(len(due), surplus))] {
+		if err := s.pool.Stop(c.id); err != nil {
+			slog.Warn("elastic: scale down failed", "id", c.id, "err", err)
+			continue
+		}
+		s.mu.Lock()
+		delete(s.members, c.id)
+		size := len(s.members)
+		s.mu.Unlock()
+		slog.Info("elastic: scaled down", "id", c.id, "profile", c.profile, "idleFor", now.Sub(c.idleSince).Round(time.Second), "size", size)
+		s.pool.EmitEvent(EventScaledDown, &bridge.Instance{ID: c.id, ProfileName: c.profile, Status: "stopped"})
+	}
+}
+
+// freeProfileLocked returns the lowest-numbered pool profile not in use.
+func (s *Strategy) freeProfileLocked() string {
+	used := maps.Clone(s.launching)
+	for _, m := range s.members {
+		used[m.profile] = true
+	}
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s-%d", s.config.ProfilePrefix, i)
+		if !used[name] {
+			return name
+		}
+	}
+}
+
+func (s *Strategy) queued() int {
+	s.mu.Lock()
+	depth := s.queueDepth
+	s.mu.Unlock()
+	if depth == nil {
+		return 0
+	}
+	return depth()
+}
+
+// proxyToPool proxies to the instance picked by the allocation policy,
+// launching one first when the pool is empty.
+func (s *Strategy) proxyToPool(w http.ResponseWriter, r *http.Request) {
+	target, err := s.ensureRunning(r)
+	if err != nil {
+		httpx.Error(w, 503, err)
+		return
+	}
+	strategy.EnrichForTarget(r, s.orch, target)
+	s.orch.ProxyToTarget(w, r, target+r.URL.Path)
+}
+
+// ensureRunning returns the URL of a running instance for r. With
+// MinInstances 0 the pool may be empty, so one is launched and awaited.
+func (s *Strategy) ensureRunning(r *http.Request) (string, error) {
+	if s.orch == nil {
+		return "", fmt.Errorf("no running instances")
+	}
+	if target := s.orch.TargetURL(r); target != "" {
+		return target, nil
+	}
+	// Pool instances carry no labels, so launching one cannot satisfy a
+	// label selector.
+	if selector := r.Header.Get(fleet.HeaderLabels); selector != "" {
+		return "", fmt.Errorf("no running instances with labels %s", selector)
+	}
+
+	s.mu.Lock()
+	empty := len(s.members)+len(s.launching) == 0
+	s.mu.Unlock()
+	if empty {
+		if _, err := s.scaleUp("request"); err != nil {
+			return "", err
+		}
+	}
+
+	deadline := time.Now().Add(readyPollTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-r.Context().Done():
+			return "", r.Context().Err()
+		case <-time.After(readyPollInterval):
+		}
+		if target := s.orch.TargetURL(r); target != "" {
+			return target, nil
+		}
+	}
+	return "", fmt.Errorf("instance launched but did not become ready in time")
+}
+
+func (s *Strategy) handleTabs(w http.ResponseWriter, r *http.Request) {
+	target := s.orch.TargetURL(r)
+	if target == "" {
+		httpx.JSON(w, 200, map[string]any{"tabs": []any{}})
+		return
+	}
+	s.orch.ProxyToTarget(w, r, target+"/tabs")
+}
+
+func (s *Strategy) handleStatus(w http.ResponseWriter, r *http.Request) {
+	httpx.JSON(w, 200, s.State())
+}
@@ -0,0 +1,304 @@
+package elastic
+
+import (
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+)
+
+// fakePool launches instances in the "starting" state and records what the
+// strategy asked of it.
+type fakePool struct {
+	mu        sync.Mutex
+	instances map[string]bridge.Instance
+	tabs      map[string]int
+	active    map[string]time.Time
+	launched  []string
+	stopped   []string
+	events    []string
+}
+
+func newFakePool() *fakePool {
+	return &fakePool{instances: map[string]bridge.Instance{}, tabs: map[string]int{}, active: map[string]time.Time{}}
+}
+
+func (p *fakePool) Launch(name, _ string, _ bool, _ []string) (*bridge.Instance, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	inst := bridge.Instance{ID: "inst_" + name, ProfileName: name, Status: "starting"}
+	p.instances[inst.ID] = inst
+	p.launched = append(p.launched, name)
+	return &inst, nil
+}
+
+func (p *fakePool) Stop(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	inst := p.instances[id]
+	inst.Status = "stopped"
+	p.instances[id] = inst
+	p.stopped = append(p.stopped, id)
+	return nil
+}
+
+func (p *fakePool) List() []bridge.Instance {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]bridge.Instance, 0, len(p.instances))
+	for _, inst := range p.instances {
+		out = append(out, inst)
+	}
+	return out
+}
+
+func (p *fakePool) InstanceLoads() map[string]allocation.Load {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	loads := map[string]allocation.Load{}
+	for id, inst := range p.instances {
+		if inst.Status == "running" {
+			loads[id] = allocation.Load{Tabs: p.tabs[id], Known: true}
+		}
+	}
+	return loads
+}
+
+func (p *fakePool) InstanceActivity() map[string]time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.active)
+}
+
+func (p *fakePool) EmitEvent(eventType string, inst *bridge.Instance) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, eventType+":"+inst.ProfileName)
+}
+
+// markRunning moves every starting instance to running with the given tabs.
+func (p *fakePool) markRunning(tabs int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, inst := range p.instances {
+		if inst.Status == "starting" {
+			inst.Status = "running"
+			p.instances[id] = inst
+			p.tabs[id] = tabs
+		}
+	}
+}
+
+func (p *fakePool) setTabs(id string, tabs int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tabs[id] = tabs
+}
+
+func (p *fakePool) touch(id string, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active[id] = at
+}
+
+func (p *fakePool) snapshot() (launched, stopped, events string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return strings.Join(p.launched, ","), strings.Join(p.stopped, ","), strings.Join(p.events, ",")
+}
+
+func newTestStrategy(cfg Config) (*Strategy, *fakePool, *int) {
+	s := New(cfg)
+	p := newFakePool()
+	s.pool = p
+	queued := 0
+	s.SetQueueDepth(func() int { return queued })
+	return s, p, &queued
+}
+
+func TestNew_Defaults(t *testing.T) {
+	s := New(Config{})
+	if s.config.MinInstances != defaultMinInstances || s.config.MaxInstances != defaultMaxInstances {
+		t.Errorf("unexpected pool bounds %d-%d", s.config.MinInstances, s.config.MaxInstances)
+	}
+	if s.config.IdleCooldown != defaultIdleCooldown || s.config.ProfilePrefix != defaultProfilePrefix {
+		t.Errorf("unexpected defaults %+v", s.config)
+	}
+	if got := New(Config{MinInstances: -1}).config.MinInstances; got != 0 {
+		t.Errorf("negative minimum should allow an empty pool, got %d", got)
+	}
+}
+
+func TestStrategy_SetRuntimeConfig(t *testing.T) {
+	s := New(Config{})
+	s.SetRuntimeConfig(&config.RuntimeConfig{
+		ElasticMinInstances:      0,
+		ElasticMaxInstances:      6,
+		ElasticScaleUpQueueDepth: 3,
+		ElasticScaleUpTabs:       5,
+		ElasticIdleCooldown:      time.Minute,
+	})
+	c := s.config
+	if c.MinInstances != 0 || c.MaxInstances != 6 || c.ScaleUpQueueDepth != 3 || c.ScaleUpTabsPerInstance != 5 || c.IdleCooldown != time.Minute {
+		t.Fatalf("runtime config not applied: %+v", c)
+	}
+}
+
+func TestEvaluate_WarmsMinInstances(t *testing.T) {
+	s, p, _ := newTestStrategy(Config{MinInstances: 2})
+	s.evaluate(time.Now())
+	s.evaluate(time.Now())
+
+	launched, _, events := p.snapshot()
+	if launched != "elastic-1,elastic-2" {
+		t.Fatalf("expected two pool profiles launched once, got %q", launched)
+	}
+	if events != EventScaledUp+":elastic-1,"+EventScaledUp+":elastic-2" {
+		t.Errorf("unexpected events %q", events)
+	}
+}
+
+func TestEvaluate_ScalesUpOnQueueDepth(t *testing.T) {
+	s, p, queued := newTestStrategy(Config{MinInstances: 1, MaxInstances: 2, ScaleUpQueueDepth: 5})
+	now := time.Now()
+	s.evaluate(now)
+	p.markRunning(1)
+
+	*queued = 4
+	s.evaluate(now)
+	if launched, _, _ := p.snapshot(); launched != "elastic-1" {
+		t.Fatalf("queue below threshold should not scale, got %q", launched)
+	}
+
+	*queued = 5
+	s.evaluate(now)
+	s.evaluate(now)
+	if launched, _, _ := p.snapshot(); launched != "elastic-1,elastic-2" {
+		t.Fatalf("expected one scale-up while the new instance starts, got %q", launched)
+	}
+
+	p.markRunning(1)
+	s.evaluate(now)
+	if launched, _, _ := p.snapshot(); launched != "elastic-1,elastic-2" {
+		t.Fatalf("pool must not grow past maxInstances, got %q", launched)
+	}
+}
+
+func TestEvaluate_ScalesUpOnTabsPerInstance(t *testing.T) {
+	s, p, _ := newTestStrategy(Config{MinInstances: 1, ScaleUpTabsPerInstance: 3})
+	now := time.Now()
+	s.evaluate(now)
+	p.markRunning(2)
+	s.evaluate(now)
+	if launched, _, _ := p.snapshot(); launched != "elastic-1" {
+		t.Fatalf("expected no scale-up under the tab threshold, got %q", launched)
+	}
+
+	p.setTabs("inst_elastic-1", 3)
+	s.evaluate(now)
+	if launched, _, _ := p.snapshot(); launched != "elastic-1,elastic-2" {
+		t.Fatalf("expected a scale-up at the tab threshold, got %q", launched)
+	}
+}
+
+func TestEvaluate_StopsIdleInstancesAfterCooldown(t *testing.T) {
+	s, p, queued := newTestStrategy(Config{MinInstances: 1, IdleCooldown: time.Minute})
+	now := time.Now()
+	s.evaluate(now)
+	p.markRunning(1)
+	*queued = 20
+	s.evaluate(now)
+	p.markRunning(1)
+	*queued = 0
+
+	s.evaluate(now)
+	s.evaluate(now.Add(30 * time.Second))
+	if _, stopped, _ := p.snapshot(); stopped != "" {
+		t.Fatalf("nothing should stop before the cooldown, got %q", stopped)
+	}
+
+	*queued = 1
+	s.evaluate(now.Add(2 * time.Minute))
+	if _, stopped, _ := p.snapshot(); stopped != "" {
+		t.Fatalf("nothing should stop while tasks are queued, got %q", stopped)
+	}
+
+	*queued = 0
+	s.evaluate(now.Add(2 * time.Minute))
+	_, stopped, events := p.snapshot()
+	if strings.Count(stopped, "inst_") != 1 {
+		t.Fatalf("expected one instance stopped down to minInstances, got %q", stopped)
+	}
+	if !strings.Contains(events, EventScaledDown) {
+		t.Errorf("expected a scale-down event, got %q", events)
+	}
+	if got := len(s.State().Members); got != 1 {
+		t.Errorf("expected 1 member left, got %d", got)
+	}
+}
+
+func TestEvaluate_BusyInstanceIsNotIdle(t *testing.T) {
+	s, p, queued := newTestStrategy(Config{MinInstances: 1, IdleCooldown: time.Minute})
+	now := time.Now()
+	s.evaluate(now)
+	p.markRunning(1)
+	*queued = 20
+	s.evaluate(now)
+	p.markRunning(1)
+	*queued = 0
+	s.evaluate(now)
+
+	p.setTabs("inst_elastic-1", 4)
+	p.setTabs("inst_elastic-2", 4)
+	s.evaluate(now.Add(30 * time.Second))
+	p.setTabs("inst_elastic-1", 1)
+	p.setTabs("inst_elastic-2", 1)
+	s.evaluate(now.Add(time.Minute))
+	if _, stopped, _ := p.snapshot(); stopped != "" {
+		t.Fatalf("idle time restarts when an instance has tabs open, got %q", stopped)
+	}
+}
+
+func TestEvaluate_InstanceServingRequestsIsNotIdle(t *testing.T) {
+	s, p, queued := newTestStrategy(Config{MinInstances: 1, IdleCooldown: time.Minute})
+	now := time.Now()
+	s.evaluate(now)
+	p.markRunning(1)
+	*queued = 20
+	s.evaluate(now)
+	p.markRunning(1)
+	*queued = 0
+	s.evaluate(now)
+
+	// Both instances keep their single tab; only elastic-2 is in use.
+	p.touch("inst_elastic-2", now.Add(50*time.Second))
+	s.evaluate(now.Add(90 * time.Second))
+	if _, stopped, _ := p.snapshot(); stopped != "inst_elastic-1" {
+		t.Fatalf("expected only the unused instance to stop, got %q", stopped)
+	}
+
+	s.evaluate(now.Add(3 * time.Minute))
+	if _, stopped, _ := p.snapshot(); stopped != "inst_elastic-1" {
+		t.Fatalf("minInstances must keep the last instance, got %q", stopped)
+	}
+}
+
+func TestEvaluate_ReplacesExitedInstance(t *testing.T) {
+	s, p, _ := newTestStrategy(Config{MinInstances: 1})
+	s.evaluate(time.Now())
+	p.markRunning(1)
+	if err := p.Stop("inst_elastic-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.evaluate(time.Now())
+	launched, _, _ := p.snapshot()
+	if launched != "elastic-1,elastic-1" {
+		t.Fatalf("an exited instance should be replaced on its free profile, got %q", launched)
+	}
+}
@@ -45,7 +45,13 @@ func (s *Strategy) RegisterRoutes(mux *http.ServeMux) {
 		"POST /wait",
 		"POST /tab", "POST /tab/lock", "POST /tab/unlock",
 		"GET /cookies", "POST /cookies",
+		"GET /storage", "POST /storage", "DELETE /storage",
+		"POST /state/export", "POST /state/import",
+		"POST /workflows/run", "POST /extract",
+		"POST /screenshot/compare", "GET /screenshot/baselines", "GET /screenshot/baselines/{name}",
+		"PUT /screenshot/baselines/{name}", "DELETE /screenshot/baselines/{name}", "POST /screenshot/baselines/{name}/accept",
 		"GET /stealth/status", "POST /fingerprint/rotate",
+		"GET /emulate/devices", "POST /emulate",
 		"POST /find",
 	}
 	for _, route := range shorthandRoutes {
@@ -62,7 +68,7 @@ func (s *Strategy) RegisterRoutes(mux *http.ServeMux) {
 }
 
 func (s *Strategy) proxyToFirst(w http.ResponseWriter, r *http.Request) {
-	target := s.orch.FirstRunningURL()
+	target := s.orch.TargetURL(r)
 	if target == "" {
 		httpx.Error(w, 503, fmt.Errorf("no remote instances connected — attach a bridge first"))
 		return
@@ -72,7 +78,7 @@ func (s *Strategy) proxyToFirst(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Strategy) handleTabs(w http.ResponseWriter, r *http.Request) {
-	target := s.orch.FirstRunningURL()
+	target := s.orch.TargetURL(r)
 	if target == "" {
 		httpx.JSON(w, 200, map[string]any{"tabs": []any{}})
 		return
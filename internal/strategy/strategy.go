@@ -32,6 +32,13 @@ type RuntimeConfigAware interface {
 	SetRuntimeConfig(cfg *config.RuntimeConfig)
 }
 
+// QueueAware is implemented by strategies that size the instance pool from
+// scheduler backlog. The dashboard injects the queue depth before Start()
+// when the scheduler is enabled.
+type QueueAware interface {
+	SetQueueDepth(depth func() int)
+}
+
 // Strategy defines a browser allocation approach.
 type Strategy interface {
 	// Name returns the strategy identifier.
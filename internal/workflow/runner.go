@@ -0,0 +1,336 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Executor.Extract when the selector matches no
+// element. Extract steps with a default store the default instead.
+var ErrNotFound = errors.New("element not found")
+
+// Executor performs the browser work of a run against one tab.
+type Executor interface {
+	Navigate(ctx context.Context, n Navigate) (any, error)
+	Action(ctx context.Context, a Action) (any, error)
+	Wait(ctx context.Context, w Wait) (any, error)
+	Extract(ctx context.Context, e Extract) (any, error)
+	// Present reports whether a selector matches an element.
+	Present(ctx context.Context, selector string) (bool, error)
+	// TextPresent reports whether the page text contains text.
+	TextPresent(ctx context.Context, text string) (bool, error)
+	// URLMatches reports whether the current URL matches a /wait url pattern.
+	URLMatches(ctx context.Context, pattern string) (bool, error)
+}
+
+// Step and run statuses.
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+
+	RunPassed = "passed"
+	RunFailed = "failed"
+)
+
+// StepTrace records one executed (or skipped) step. Paths are step indexes
+// joined by "/", with loop iterations in brackets: "3[1]/0" is the first
+// step of the second iteration of the loop at step 3.
+type StepTrace struct {
+	Path       string    `json:"path"`
+	Name       string    `json:"name,omitempty"`
+	Type       string    `json:"type"`
+	Status     string    `json:"status"`
+	SkipReason string    `json:"skipReason,omitempty"`
+	Result     any       `json:"result,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	Name       string         `json:"name,omitempty"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	FailedStep string         `json:"failedStep,omitempty"`
+	Executed   int            `json:"executed"`
+	Failed     int            `json:"failed"`
+	Skipped    int            `json:"skipped"`
+	Vars       map[string]any `json:"vars"`
+	Trace      []StepTrace    `json:"trace"`
+	DurationMs int64          `json:"durationMs"`
+}
+
+type runner struct {
+	exec            Executor
+	vars            map[string]any
+	continueOnError bool
+	res             *Result
+}
+
+// Run executes the workflow and returns its trace. Runs stop at the first
+// failed step unless the step or workflow sets continueOnError.
+func Run(ctx context.Context, wf *Workflow, exec Executor) *Result {
+	start := time.Now()
+	if wf.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(wf.Timeout*float64(time.Second)))
+		defer cancel()
+	}
+	r := &runner{
+		exec:            exec,
+		vars:            map[string]any{},
+		continueOnError: wf.ContinueOnError,
+		res:             &Result{Name: wf.Name, Trace: []StepTrace{}},
+	}
+	maps.Copy(r.vars, wf.Vars)
+
+	err := r.runSteps(ctx, wf.Steps, "")
+	r.res.Status = RunPassed
+	if err != nil {
+		r.res.Status = RunFailed
+		r.res.Error = err.Error()
+	} else {
+		r.res.FailedStep = ""
+	}
+	r.res.Vars = r.vars
+	r.res.DurationMs = time.Since(start).Milliseconds()
+	return r.res
+}
+
+func (r *runner) runSteps(ctx context.Context, steps []Step, prefix string) error {
+	for i := range steps {
+		if err := r.runStep(ctx, steps[i], stepPath(prefix, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *runner) runStep(ctx context.Context, s Step, path string) error {
+	if err := ctx.Err(); err != nil {
+		r.res.FailedStep = path
+		return fmt.Errorf("workflow stopped before step %s: %w", path, err)
+	}
+	if r.res.Executed+r.res.Skipped >= MaxSteps {
+		r.res.FailedStep = path
+		return fmt.Errorf("workflow exceeded %d steps", MaxSteps)
+	}
+
+	start := time.Now()
+	idx := len(r.res.Trace)
+	r.res.Trace = append(r.res.Trace, StepTrace{Path: path, Name: s.Name, Type: s.Type(), StartedAt: start})
+
+	result, skip, err := r.execute(ctx, s, path)
+	tr := &r.res.Trace[idx]
+	tr.DurationMs = time.Since(start).Milliseconds()
+	switch {
+	case skip != "":
+		tr.Status = StatusSkipped
+		tr.SkipReason = skip
+		r.res.Skipped++
+		return nil
+	case err != nil:
+		tr.Status = StatusFailed
+		tr.Error = err.Error()
+		tr.Result = result
+	default:
+		tr.Status = StatusOK
+		tr.Result = result
+	}
+	r.res.Executed++
+	if err == nil {
+		return nil
+	}
+
+	container := s.Loop != nil || s.Steps != nil
+	if !container {
+		r.res.Failed++
+	}
+	if s.ContinueOnError || r.continueOnError {
+		r.res.FailedStep = ""
+		return nil
+	}
+	if r.res.FailedStep == "" {
+		r.res.FailedStep = path
+	}
+	if container {
+		return err
+	}
+	return fmt.Errorf("step %s (%s): %w", path, s.Type(), err)
+}
+
+// execute runs one step after variable expansion and guard evaluation. A
+// non-empty skip reason means the step did not run.
+func (r *runner) execute(ctx context.Context, raw Step, path string) (result any, skip string, err error) {
+	s, err := expandStep(raw, r.vars)
+	if err != nil {
+		return nil, "", err
+	}
+	if s.If != "" {
+		ok, err := r.exec.Present(ctx, s.If)
+		if err != nil {
+			return nil, "", fmt.Errorf("if %q: %w", s.If, err)
+		}
+		if !ok {
+			return nil, fmt.Sprintf("if: %s not present", s.If), nil
+		}
+	}
+	if s.Unless != "" {
+		ok, err := r.exec.Present(ctx, s.Unless)
+		if err != nil {
+			return nil, "", fmt.Errorf("unless %q: %w", s.Unless, err)
+		}
+		if ok {
+			return nil, fmt.Sprintf("unless: %s present", s.Unless), nil
+		}
+	}
+
+	switch s.Type() {
+	case "navigate":
+		result, err = r.exec.Navigate(ctx, *s.Navigate)
+	case "action":
+		result, err = r.exec.Action(ctx, s.Action)
+	case "wait":
+		result, err = r.exec.Wait(ctx, *s.Wait)
+	case "extract":
+		result, err = r.extract(ctx, s.Extract)
+	case "assert":
+		result, err = r.assert(ctx, s.Assert)
+	case "set":
+		maps.Copy(r.vars, s.Set)
+		result = s.Set
+	case "loop":
+		result, err = r.loop(ctx, s.Loop, path)
+	case "steps":
+		err = r.runSteps(ctx, s.Steps, path+"/")
+	default:
+		err = fmt.Errorf("step has no operation")
+	}
+	return result, "", err
+}
+
+func (r *runner) extract(ctx context.Context, e *Extract) (any, error) {
+	v, err := r.exec.Extract(ctx, *e)
+	if err != nil {
+		if e.Default == nil || !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		v = e.Default
+	}
+	r.vars[e.Var] = v
+	return map[string]any{"var": e.Var, "value": v}, nil
+}
+
+func (r *runner) assert(ctx context.Context, a *Assert) (any, error) {
+	var (
+		ok   bool
+		desc string
+		err  error
+	)
+	switch {
+	case a.Selector != "":
+		want := !strings.EqualFold(a.State, "hidden")
+		var present bool
+		present, err = r.exec.Present(ctx, a.Selector)
+		ok = present == want
+		desc = fmt.Sprintf("expected %s to be present", a.Selector)
+		if !want {
+			desc = fmt.Sprintf("expected %s to be absent", a.Selector)
+		}
+	case a.Text != "":
+		ok, err = r.exec.TextPresent(ctx, a.Text)
+		desc = fmt.Sprintf("expected page text to contain %q", a.Text)
+	case a.URL != "":
+		ok, err = r.exec.URLMatches(ctx, a.URL)
+		desc = fmt.Sprintf("expected URL to match %q", a.URL)
+	case a.Var != "":
+		ok, desc, err = r.assertVar(a)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if a.Message != "" {
+			return nil, fmt.Errorf("assertion failed: %s (%s)", a.Message, desc)
+		}
+		return nil, fmt.Errorf("assertion failed: %s", desc)
+	}
+	return map[string]any{"passed": true}, nil
+}
+
+func (r *runner) assertVar(a *Assert) (bool, string, error) {
+	v, found := r.vars[a.Var]
+	if !found {
+		return false, "", fmt.Errorf("undefined variable %q", a.Var)
+	}
+	actual := FormatValue(v)
+	if a.Equals != nil && FormatValue(a.Equals) != actual {
+		return false, fmt.Sprintf("%s = %q, want %q", a.Var, actual, FormatValue(a.Equals)), nil
+	}
+	if a.Contains != "" && !strings.Contains(actual, a.Contains) {
+		return false, fmt.Sprintf("%s = %q, want it to contain %q", a.Var, actual, a.Contains), nil
+	}
+	if a.Matches != "" {
+		re, err := regexp.Compile(a.Matches)
+		if err != nil {
+			return false, "", fmt.Errorf("matches: %w", err)
+		}
+		if !re.MatchString(actual) {
+			return false, fmt.Sprintf("%s = %q, want it to match %q", a.Var, actual, a.Matches), nil
+		}
+	}
+	return true, "", nil
+}
+
+// loop runs the loop body. "times" loops run exactly that often; "while"
+// loops stop when the selector disappears or at max; "until" loops fail if
+// the selector has not appeared by max.
+func (r *runner) loop(ctx context.Context, l *Loop, path string) (any, error) {
+	index := l.Index
+	if index == "" {
+		index = "i"
+	}
+	limit := l.Max
+	if limit == 0 {
+		limit = DefaultLoopMax
+	}
+	n := 0
+	for {
+		if l.Times > 0 {
+			if n >= l.Times {
+				break
+			}
+		} else {
+			cond := l.While
+			if cond == "" {
+				cond = l.Until
+			}
+			present, err := r.exec.Present(ctx, cond)
+			if err != nil {
+				return map[string]any{"iterations": n}, err
+			}
+			if (l.While != "" && !present) || (l.Until != "" && present) {
+				break
+			}
+			if n >= limit {
+				if l.Until != "" {
+					return map[string]any{"iterations": n}, fmt.Errorf("until %s: not present after %d iterations", l.Until, n)
+				}
+				break
+			}
+		}
+		r.vars[index] = n
+		if err := r.runSteps(ctx, l.Steps, fmt.Sprintf("%s[%d]/", path, n)); err != nil {
+			return map[string]any{"iterations": n + 1}, err
+		}
+		n++
+	}
+	return map[string]any{"iterations": n}, nil
+}
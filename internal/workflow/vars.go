@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Expand replaces ${name} references in s with variable values. "$${"
+// produces a literal "${". Unknown variables are an error.
+func Expand(s string, vars map[string]any) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i+2:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated ${ in %q", s)
+		}
+		name := strings.TrimSpace(s[i+2 : i+2+end])
+		v, ok := vars[name]
+		if !ok {
+			return "", fmt.Errorf("undefined variable %q", name)
+		}
+		b.WriteString(s[:i])
+		b.WriteString(FormatValue(v))
+		s = s[i+3+end:]
+	}
+}
+
+// FormatValue renders a variable value for interpolation.
+func FormatValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64, float32, int, int64, bool:
+		return fmt.Sprint(x)
+	default:
+		b, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Sprint(x)
+		}
+		return string(b)
+	}
+}
+
+// expandValue expands variables in every string of a decoded JSON value. A
+// string that is exactly one reference keeps the variable's type, so
+// `ms: ${delay}` yields a number.
+func expandValue(v any, vars map[string]any) (any, error) {
+	switch x := v.(type) {
+	case string:
+		if name, ok := soleReference(x); ok {
+			val, found := vars[name]
+			if !found {
+				return nil, fmt.Errorf("undefined variable %q", name)
+			}
+			return val, nil
+		}
+		return Expand(x, vars)
+	case []any:
+		out := make([]any, len(x))
+		for i, item := range x {
+			e, err := expandValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = e
+		}
+		return out, nil
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, item := range x {
+			e, err := expandValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = e
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func soleReference(s string) (string, bool) {
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") {
+		return "", false
+	}
+	name := s[2 : len(s)-1]
+	if strings.ContainsAny(name, "${}") {
+		return "", false
+	}
+	return strings.TrimSpace(name), true
+}
+
+// expandStep returns a copy of s with variables expanded in its own fields.
+// Nested loop and group steps are expanded when they run, so they see the
+// variables current at that time.
+func expandStep(s Step, vars map[string]any) (Step, error) {
+	nested, loopSteps := s.Steps, []Step(nil)
+	s.Steps = nil
+	if s.Loop != nil {
+		loop := *s.Loop
+		loopSteps, loop.Steps = loop.Steps, nil
+		s.Loop = &loop
+	}
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return Step{}, err
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return Step{}, err
+	}
+	doc, err = expandValue(doc, vars)
+	if err != nil {
+		return Step{}, err
+	}
+	raw, err = json.Marshal(doc)
+	if err != nil {
+		return Step{}, err
+	}
+	var out Step
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return Step{}, fmt.Errorf("after variable expansion: %w", err)
+	}
+	out.Steps = nested
+	if s.Set != nil && out.Set == nil {
+		out.Set = map[string]any{}
+	}
+	if out.Loop != nil {
+		out.Loop.Steps = loopSteps
+	}
+	return out, nil
+}
@@ -0,0 +1,340 @@
+// Package workflow defines the declarative workflow format run by
+// POST /workflows/run and `pinchtab run`, and the runner that executes it.
+//
+// A workflow is a YAML or JSON document with variables and a list of steps.
+// Each step does exactly one thing (navigate, action, wait, extract, assert,
+// set, loop or a nested group of steps) and may be guarded by if/unless
+// selector presence. String fields may reference variables as ${name}.
+//
+//	name: login
+//	vars:
+//	  user: alice
+//	steps:
+//	  - navigate: https://example.com/login
+//	  - action: {kind: fill, selector: "#user", text: "${user}"}
+//	  - action: {kind: click, selector: "text:Sign in"}
+//	  - wait: {url: "*/dashboard*"}
+//	  - extract: {var: greeting, selector: h1}
+//	  - assert: {var: greeting, contains: "${user}"}
+//
+// The runner only handles control flow, variables and tracing; browser work
+// is delegated to an Executor.
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limits that keep a workflow bounded.
+const (
+	// DefaultLoopMax caps while/until loops that set no max.
+	DefaultLoopMax = 100
+	// MaxLoopIterations is the hard cap for any loop.
+	MaxLoopIterations = 1000
+	// MaxSteps caps the number of steps executed in one run, counting
+	// every loop iteration.
+	MaxSteps = 10000
+)
+
+// Workflow is a parsed workflow document.
+type Workflow struct {
+	Name  string         `json:"name,omitempty"`
+	TabID string         `json:"tabId,omitempty"`
+	Vars  map[string]any `json:"vars,omitempty"`
+	// ContinueOnError keeps running after a failed step instead of stopping.
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+	// Timeout bounds the whole run, in seconds.
+	Timeout float64 `json:"timeout,omitempty"`
+	Steps   []Step  `json:"steps"`
+}
+
+// Step is a single workflow step. Exactly one of the operation fields is
+// set.
+type Step struct {
+	Name string `json:"name,omitempty"`
+	// If runs the step only when the selector matches an element; Unless
+	// only when it does not.
+	If              string `json:"if,omitempty"`
+	Unless          string `json:"unless,omitempty"`
+	ContinueOnError bool   `json:"continueOnError,omitempty"`
+
+	Navigate *Navigate      `json:"navigate,omitempty"`
+	Action   Action         `json:"action,omitempty"`
+	Wait     *Wait          `json:"wait,omitempty"`
+	Extract  *Extract       `json:"extract,omitempty"`
+	Assert   *Assert        `json:"assert,omitempty"`
+	Set      map[string]any `json:"set,omitempty"`
+	Loop     *Loop          `json:"loop,omitempty"`
+	Steps    []Step         `json:"steps,omitempty"`
+}
+
+// Action is an /action request body (kind, selector, text, value, key, ...).
+type Action map[string]any
+
+// Navigate opens a URL in the workflow tab. It may be given as a plain URL
+// string.
+type Navigate struct {
+	URL          string  `json:"url"`
+	WaitFor      string  `json:"waitFor,omitempty"`
+	WaitSelector string  `json:"waitSelector,omitempty"`
+	Timeout      float64 `json:"timeout,omitempty"`
+}
+
+// UnmarshalJSON accepts either a URL string or an object.
+func (n *Navigate) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		*n = Navigate{URL: url}
+		return nil
+	}
+	type plain Navigate
+	return json.Unmarshal(data, (*plain)(n))
+}
+
+// Wait uses the /wait modes: selector (with state), text, url, load, fn or
+// a fixed ms duration. Timeout is in milliseconds.
+type Wait struct {
+	Selector string `json:"selector,omitempty"`
+	State    string `json:"state,omitempty"`
+	Text     string `json:"text,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Load     string `json:"load,omitempty"`
+	Fn       string `json:"fn,omitempty"`
+	Ms       *int   `json:"ms,omitempty"`
+	Timeout  *int   `json:"timeout,omitempty"`
+}
+
+// Extract stores a value in a variable: the text of the element matched by
+// Selector, one of its attributes, or the result of a JavaScript expression.
+type Extract struct {
+	Var      string `json:"var"`
+	Selector string `json:"selector,omitempty"`
+	Attr     string `json:"attr,omitempty"`
+	Eval     string `json:"eval,omitempty"`
+	// Default is stored instead of failing when the element is missing.
+	Default any `json:"default,omitempty"`
+}
+
+// Assert fails the step unless its condition holds. Selector checks element
+// presence (State "hidden" checks absence), Text checks the page text, URL
+// checks the current URL like /wait does, and Var compares a variable with
+// Equals, Contains or Matches (a regular expression).
+type Assert struct {
+	Selector string `json:"selector,omitempty"`
+	State    string `json:"state,omitempty"`
+	Text     string `json:"text,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Var      string `json:"var,omitempty"`
+	Equals   any    `json:"equals,omitempty"`
+	Contains string `json:"contains,omitempty"`
+	Matches  string `json:"matches,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Loop repeats its steps Times times, or while/until a selector is present,
+// up to Max iterations. The zero-based iteration number is stored in the
+// variable named by Index ("i" by default).
+type Loop struct {
+	Times int    `json:"times,omitempty"`
+	While string `json:"while,omitempty"`
+	Until string `json:"until,omitempty"`
+	Max   int    `json:"max,omitempty"`
+	Index string `json:"index,omitempty"`
+	Steps []Step `json:"steps"`
+}
+
+// Parse decodes a YAML or JSON workflow and validates it.
+func Parse(data []byte) (*Workflow, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse workflow: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("parse workflow: empty document")
+	}
+	// Round-trip through JSON so the json tags drive decoding for both
+	// formats.
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("parse workflow: %w", err)
+	}
+	var wf Workflow
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&wf); err != nil {
+		return nil, fmt.Errorf("parse workflow: %w", err)
+	}
+	if err := wf.Validate(); err != nil {
+		return nil, err
+	}
+	return &wf, nil
+}
+
+// Validate checks the workflow structure without running it.
+func (wf *Workflow) Validate() error {
+	if len(wf.Steps) == 0 {
+		return fmt.Errorf("workflow has no steps")
+	}
+	if wf.Timeout < 0 {
+		return fmt.Errorf("timeout must be >= 0")
+	}
+	return validateSteps(wf.Steps, "")
+}
+
+func validateSteps(steps []Step, prefix string) error {
+	for i := range steps {
+		path := stepPath(prefix, i)
+		if err := steps[i].validate(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Step) validate(path string) error {
+	kind, n := s.Type(), s.operations()
+	if n == 0 {
+		return fmt.Errorf("step %s: no operation (want one of navigate, action, wait, extract, assert, set, loop, steps)", path)
+	}
+	if n > 1 {
+		return fmt.Errorf("step %s: only one operation per step", path)
+	}
+	if s.If != "" && s.Unless != "" {
+		return fmt.Errorf("step %s: if and unless are mutually exclusive", path)
+	}
+	switch kind {
+	case "navigate":
+		if s.Navigate.URL == "" {
+			return fmt.Errorf("step %s: navigate requires url", path)
+		}
+	case "action":
+		if k, _ := s.Action["kind"].(string); k == "" {
+			return fmt.Errorf("step %s: action requires kind", path)
+		}
+	case "wait":
+		w := s.Wait
+		if w.Ms == nil && w.Selector == "" && w.Text == "" && w.URL == "" && w.Load == "" && w.Fn == "" {
+			return fmt.Errorf("step %s: wait requires one of selector, text, url, load, fn or ms", path)
+		}
+	case "extract":
+		e := s.Extract
+		if e.Var == "" {
+			return fmt.Errorf("step %s: extract requires var", path)
+		}
+		if (e.Selector == "") == (e.Eval == "") {
+			return fmt.Errorf("step %s: extract requires exactly one of selector or eval", path)
+		}
+		if e.Attr != "" && e.Selector == "" {
+			return fmt.Errorf("step %s: extract attr requires selector", path)
+		}
+	case "assert":
+		a := s.Assert
+		set := 0
+		for _, v := range []string{a.Selector, a.Text, a.URL, a.Var} {
+			if v != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("step %s: assert requires exactly one of selector, text, url or var", path)
+		}
+		if a.Var != "" && a.Equals == nil && a.Contains == "" && a.Matches == "" {
+			return fmt.Errorf("step %s: assert var requires equals, contains or matches", path)
+		}
+	case "loop":
+		l := s.Loop
+		modes := 0
+		if l.Times > 0 {
+			modes++
+		}
+		if l.While != "" {
+			modes++
+		}
+		if l.Until != "" {
+			modes++
+		}
+		if modes != 1 {
+			return fmt.Errorf("step %s: loop requires exactly one of times, while or until", path)
+		}
+		if l.Times > MaxLoopIterations || l.Max > MaxLoopIterations {
+			return fmt.Errorf("step %s: loop is limited to %d iterations", path, MaxLoopIterations)
+		}
+		if l.Max < 0 || l.Times < 0 {
+			return fmt.Errorf("step %s: loop bounds must be positive", path)
+		}
+		if len(l.Steps) == 0 {
+			return fmt.Errorf("step %s: loop has no steps", path)
+		}
+		return validateSteps(l.Steps, path+"/")
+	case "steps":
+		return validateSteps(s.Steps, path+"/")
+	}
+	return nil
+}
+
+// Type names the step's operation.
+func (s *Step) Type() string {
+	switch {
+	case s.Navigate != nil:
+		return "navigate"
+	case s.Action != nil:
+		return "action"
+	case s.Wait != nil:
+		return "wait"
+	case s.Extract != nil:
+		return "extract"
+	case s.Assert != nil:
+		return "assert"
+	case s.Set != nil:
+		return "set"
+	case s.Loop != nil:
+		return "loop"
+	case s.Steps != nil:
+		return "steps"
+	}
+	return ""
+}
+
+func (s *Step) operations() int {
+	n := 0
+	for _, set := range []bool{
+		s.Navigate != nil, s.Action != nil, s.Wait != nil, s.Extract != nil,
+		s.Assert != nil, s.Set != nil, s.Loop != nil, s.Steps != nil,
+	} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// UsesEval reports whether any step runs page JavaScript (extract eval or a
+// wait fn), which requires evaluate to be enabled.
+func (wf *Workflow) UsesEval() bool {
+	return stepsUseEval(wf.Steps)
+}
+
+func stepsUseEval(steps []Step) bool {
+	for i := range steps {
+		s := &steps[i]
+		switch {
+		case s.Extract != nil && s.Extract.Eval != "":
+			return true
+		case s.Wait != nil && s.Wait.Fn != "":
+			return true
+		case s.Loop != nil && stepsUseEval(s.Loop.Steps):
+			return true
+		case stepsUseEval(s.Steps):
+			return true
+		}
+	}
+	return false
+}
+
+func stepPath(prefix string, i int) string {
+	return fmt.Sprintf("%s%d", prefix, i)
+}
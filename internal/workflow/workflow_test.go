@@ -0,0 +1,314 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const loginFlow = `
+name: login
+vars:
+  user: alice
+  delay: 5
+steps:
+  - navigate: https://example.com/login
+  - name: accept cookies
+    if: "#cookie-banner"
+    action: {kind: click, selector: "#cookie-accept"}
+  - action: {kind: fill, selector: "#user", text: "${user}"}
+  - wait: {ms: 5}
+  - extract: {var: greeting, selector: h1}
+  - assert: {var: greeting, contains: "${user}"}
+`
+
+func TestParse_YAML(t *testing.T) {
+	wf, err := Parse([]byte(loginFlow))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if wf.Name != "login" || len(wf.Steps) != 6 {
+		t.Fatalf("unexpected workflow: %+v", wf)
+	}
+	if wf.Steps[0].Navigate == nil || wf.Steps[0].Navigate.URL != "https://example.com/login" {
+		t.Errorf("navigate shorthand not decoded: %+v", wf.Steps[0].Navigate)
+	}
+	if got := wf.Steps[1].Type(); got != "action" {
+		t.Errorf("step 1 type = %q", got)
+	}
+}
+
+func TestParse_JSON(t *testing.T) {
+	wf, err := Parse([]byte(`{"steps":[{"navigate":{"url":"https://example.com","waitFor":"dom"}}]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if wf.Steps[0].Navigate.WaitFor != "dom" {
+		t.Errorf("waitFor = %q", wf.Steps[0].Navigate.WaitFor)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no steps":       `name: x`,
+		"two operations": `steps: [{navigate: "https://a", wait: {ms: 1}}]`,
+		"no operation":   `steps: [{name: nothing}]`,
+		"unknown field":  `steps: [{navigat: "https://a"}]`,
+		"action kind":    `steps: [{action: {selector: "#a"}}]`,
+		"extract both":   `steps: [{extract: {var: a, selector: h1, eval: "1"}}]`,
+		"assert empty":   `steps: [{assert: {message: hi}}]`,
+		"loop modes":     `steps: [{loop: {times: 2, while: "#a", steps: [{wait: {ms: 1}}]}}]`,
+		"loop too big":   `steps: [{loop: {times: 5000, steps: [{wait: {ms: 1}}]}}]`,
+		"nested invalid": `steps: [{loop: {times: 2, steps: [{action: {}}]}}]`,
+		"if and unless":  `steps: [{if: "#a", unless: "#b", wait: {ms: 1}}]`,
+	}
+	for name, doc := range tests {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	vars := map[string]any{"user": "alice", "n": float64(3), "ok": true}
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{"hi ${user}", "hi alice"},
+		{"${n} items, ${ok}", "3 items, true"},
+		{"literal $${user}", "literal ${user}"},
+	}
+	for _, tt := range tests {
+		got, err := Expand(tt.in, vars)
+		if err != nil || got != tt.want {
+			t.Errorf("Expand(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := Expand("${missing}", vars); err == nil {
+		t.Error("expected undefined variable error")
+	}
+	if _, err := Expand("${user", vars); err == nil {
+		t.Error("expected unterminated reference error")
+	}
+}
+
+type fakeExec struct {
+	present map[string]bool
+	texts   map[string]string
+	calls   []string
+	// clickRemoves removes a selector from present when it is clicked.
+	clickRemoves map[string]string
+	failAction   string
+}
+
+func (f *fakeExec) Navigate(_ context.Context, n Navigate) (any, error) {
+	f.calls = append(f.calls, "navigate "+n.URL)
+	return map[string]any{"url": n.URL}, nil
+}
+
+func (f *fakeExec) Action(_ context.Context, a Action) (any, error) {
+	kind, _ := a["kind"].(string)
+	sel, _ := a["selector"].(string)
+	f.calls = append(f.calls, kind+" "+sel)
+	if sel == f.failAction {
+		return nil, errors.New("element not interactable")
+	}
+	if rm, ok := f.clickRemoves[sel]; ok {
+		delete(f.present, rm)
+	}
+	return map[string]any{"ok": true}, nil
+}
+
+func (f *fakeExec) Wait(_ context.Context, w Wait) (any, error) {
+	if w.Ms != nil {
+		f.calls = append(f.calls, "wait ms")
+	}
+	return map[string]any{"waited": true}, nil
+}
+
+func (f *fakeExec) Extract(_ context.Context, e Extract) (any, error) {
+	if e.Eval != "" {
+		return float64(42), nil
+	}
+	v, ok := f.texts[e.Selector]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return v, nil
+}
+
+func (f *fakeExec) Present(_ context.Context, sel string) (bool, error) {
+	return f.present[sel], nil
+}
+
+func (f *fakeExec) TextPresent(_ context.Context, text string) (bool, error) {
+	for _, v := range f.texts {
+		if strings.Contains(v, text) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeExec) URLMatches(_ context.Context, pattern string) (bool, error) {
+	return pattern == "*/dashboard", nil
+}
+
+func TestRun_LoginFlow(t *testing.T) {
+	wf, err := Parse([]byte(loginFlow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &fakeExec{texts: map[string]string{"h1": "Welcome, alice"}}
+	res := Run(context.Background(), wf, exec)
+	if res.Status != RunPassed {
+		t.Fatalf("status = %s: %s", res.Status, res.Error)
+	}
+	if res.Skipped != 1 || res.Trace[1].Status != StatusSkipped {
+		t.Errorf("cookie step should be skipped: %+v", res.Trace[1])
+	}
+	if res.Vars["greeting"] != "Welcome, alice" {
+		t.Errorf("greeting = %v", res.Vars["greeting"])
+	}
+	want := []string{"navigate https://example.com/login", "fill #user", "wait ms"}
+	if strings.Join(exec.calls, "|") != strings.Join(want, "|") {
+		t.Errorf("calls = %q, want %q", exec.calls, want)
+	}
+}
+
+func TestRun_StopsOnFailure(t *testing.T) {
+	wf, err := Parse([]byte(`
+steps:
+  - action: {kind: click, selector: "#broken"}
+  - action: {kind: click, selector: "#next"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &fakeExec{failAction: "#broken"}
+	res := Run(context.Background(), wf, exec)
+	if res.Status != RunFailed || res.FailedStep != "0" {
+		t.Fatalf("status = %s failedStep = %q", res.Status, res.FailedStep)
+	}
+	if len(res.Trace) != 1 || len(exec.calls) != 1 {
+		t.Errorf("run should stop after the failed step: %+v", res.Trace)
+	}
+
+	wf.ContinueOnError = true
+	exec = &fakeExec{failAction: "#broken"}
+	res = Run(context.Background(), wf, exec)
+	if res.Status != RunPassed || res.Failed != 1 || len(exec.calls) != 2 {
+		t.Errorf("continueOnError: status=%s failed=%d calls=%q", res.Status, res.Failed, exec.calls)
+	}
+}
+
+func TestRun_Loops(t *testing.T) {
+	wf, err := Parse([]byte(`
+steps:
+  - loop:
+      times: 3
+      index: page
+      steps:
+        - action: {kind: click, selector: "#item-${page}"}
+  - loop:
+      while: ".next"
+      steps:
+        - action: {kind: click, selector: ".next"}
+  - loop:
+      until: "#done"
+      max: 2
+      steps:
+        - wait: {ms: 1}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &fakeExec{
+		present:      map[string]bool{".next": true},
+		clickRemoves: map[string]string{".next": ".next"},
+	}
+	res := Run(context.Background(), wf, exec)
+	if res.Status != RunFailed || res.FailedStep != "2" {
+		t.Fatalf("until loop should fail at max: status=%s failedStep=%q err=%s", res.Status, res.FailedStep, res.Error)
+	}
+	want := []string{"click #item-0", "click #item-1", "click #item-2", "click .next", "wait ms", "wait ms"}
+	if strings.Join(exec.calls, "|") != strings.Join(want, "|") {
+		t.Errorf("calls = %q, want %q", exec.calls, want)
+	}
+	if res.Trace[1].Path != "0[0]/0" {
+		t.Errorf("loop iteration path = %q", res.Trace[1].Path)
+	}
+}
+
+func TestRun_Asserts(t *testing.T) {
+	exec := &fakeExec{
+		present: map[string]bool{"#ok": true},
+		texts:   map[string]string{"body": "Order confirmed"},
+	}
+	pass := []string{
+		`{selector: "#ok"}`,
+		`{selector: "#err", state: hidden}`,
+		`{text: "confirmed"}`,
+		`{url: "*/dashboard"}`,
+		`{var: n, equals: 3}`,
+		`{var: n, matches: "^[0-9]+$"}`,
+	}
+	for _, a := range pass {
+		wf, err := Parse([]byte(`{vars: {n: 3}, steps: [{assert: ` + a + `}]}`))
+		if err != nil {
+			t.Fatalf("%s: %v", a, err)
+		}
+		if res := Run(context.Background(), wf, exec); res.Status != RunPassed {
+			t.Errorf("assert %s: %s", a, res.Error)
+		}
+	}
+	fail := []string{
+		`{selector: "#err"}`,
+		`{text: "declined"}`,
+		`{var: n, equals: 4, message: "wrong count"}`,
+		`{var: missing, equals: 1}`,
+	}
+	for _, a := range fail {
+		wf, err := Parse([]byte(`{vars: {n: 3}, steps: [{assert: ` + a + `}]}`))
+		if err != nil {
+			t.Fatalf("%s: %v", a, err)
+		}
+		if res := Run(context.Background(), wf, exec); res.Status != RunFailed {
+			t.Errorf("assert %s should fail", a)
+		}
+	}
+}
+
+func TestRun_ExtractDefaultAndSet(t *testing.T) {
+	wf, err := Parse([]byte(`
+steps:
+  - extract: {var: price, selector: ".price", default: "n/a"}
+  - extract: {var: answer, eval: "6*7"}
+  - set: {label: "${price}/${answer}"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := Run(context.Background(), wf, &fakeExec{})
+	if res.Status != RunPassed {
+		t.Fatalf("status = %s: %s", res.Status, res.Error)
+	}
+	if res.Vars["label"] != "n/a/42" {
+		t.Errorf("label = %v", res.Vars["label"])
+	}
+	if !wf.UsesEval() {
+		t.Error("UsesEval should report the eval extract")
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	wf, err := Parse([]byte(`steps: [{wait: {ms: 1}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res := Run(ctx, wf, &fakeExec{}); res.Status != RunFailed {
+		t.Errorf("cancelled run should fail, got %s", res.Status)
+	}
+}